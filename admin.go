@@ -0,0 +1,233 @@
+package jogger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+type adminState struct {
+	Level             string                `json:"level"`
+	Format            string                `json:"format"`
+	Sinks             []string              `json:"sinks"`
+	Sampling          adminSampling         `json:"sampling"`
+	SlowSpanThreshold string                `json:"slowSpanThreshold"`
+	DebugRequestIDs   []string              `json:"debugRequestIDs"`
+	SLOs              map[string]string     `json:"slos"`
+	Dropped           DropCounts            `json:"dropped"`
+	Incident          *IncidentStatus       `json:"incident,omitempty"`
+	ErrorBudgets      map[string]SpanBudget `json:"errorBudgets,omitempty"`
+}
+
+// adminSampling is the root sampling rate of WithSampling and the buffer
+// limit of WithTailSampling, zero when tail sampling is off.
+type adminSampling struct {
+	Rate      float64 `json:"rate"`
+	TailLimit int     `json:"tailLimit"`
+}
+
+type adminDebugRequest struct {
+	RequestID string `json:"requestID"`
+	Enabled   bool   `json:"enabled"`
+}
+
+type adminIncident struct {
+	Enabled    bool     `json:"enabled"`
+	Duration   string   `json:"duration"`
+	SampleRate *float64 `json:"sampleRate"`
+}
+
+type adminUpdate struct {
+	Level             *string            `json:"level"`
+	SlowSpanThreshold *string            `json:"slowSpanThreshold"`
+	Debug             *adminDebugRequest `json:"debug"`
+	SLOs              map[string]string  `json:"slos"`
+	Incident          *adminIncident     `json:"incident"`
+}
+
+type adminError struct {
+	Error string `json:"error"`
+}
+
+// AdminHandler returns an http.Handler for inspecting and changing the
+// logger at runtime.
+//
+// GET responds with the current configuration, sampling included, and the
+// drop counts as JSON. PUT accepts a JSON object with any of the following
+// keys and responds with the resulting configuration:
+//
+//	{"level": "debug"}
+//	{"slowSpanThreshold": "500ms"}
+//	{"debug": {"requestID": "abc-123", "enabled": true}}
+//	{"slos": {"db.query": "50ms", "cache.get": "0s"}}
+//	{"incident": {"enabled": true, "duration": "10m", "sampleRate": 0.1}}
+//
+// Changes are applied through SetLevel, SetSlowSpanThreshold,
+// EnableRequestDebug/DisableRequestDebug, RegisterSLO and SetIncidentMode,
+// so they are safe while logging. An SLO target of 0s removes the
+// registration.
+//
+// GET on a path ending in /explain responds with the DumpConfig report,
+// as JSON with ?format=json.
+func AdminHandler() http.Handler {
+	return http.HandlerFunc(serveAdmin)
+}
+
+func serveAdmin(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		if path.Base(r.URL.Path) == "explain" {
+			serveExplain(w, r)
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, currentAdminState())
+	case http.MethodPut:
+		var update adminUpdate
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&update); err != nil {
+			writeAdminJSON(w, http.StatusBadRequest, adminError{Error: "invalid payload: " + err.Error()})
+			return
+		}
+		if err := applyAdminUpdate(update); err != nil {
+			writeAdminJSON(w, http.StatusBadRequest, adminError{Error: err.Error()})
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, currentAdminState())
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		writeAdminJSON(w, http.StatusMethodNotAllowed, adminError{Error: "only GET and PUT are supported"})
+	}
+}
+
+func currentAdminState() adminState {
+	o := currentOutput()
+	state := adminState{
+		Level:             Level().String(),
+		Format:            o.cfg.format,
+		Sinks:             append([]string(nil), o.sinks...),
+		Sampling:          adminSampling{Rate: o.cfg.sampleRate, TailLimit: o.cfg.tailSampling},
+		SlowSpanThreshold: SlowSpanThreshold().String(),
+		DebugRequestIDs:   DebugRequestIDs(),
+		SLOs:              adminSLOs(),
+		Dropped:           Stats().Dropped,
+		ErrorBudgets:      errorBudgets(),
+	}
+	if st := CurrentIncident(); st.Active {
+		state.Incident = &st
+	}
+	return state
+}
+
+// applyAdminUpdate validates the whole update before changing anything, so
+// a bad payload never leaves the logger half reconfigured.
+func applyAdminUpdate(update adminUpdate) error {
+	if update.Level == nil && update.SlowSpanThreshold == nil && update.Debug == nil && update.SLOs == nil && update.Incident == nil {
+		return errors.New("payload must set at least one of level, slowSpanThreshold, debug, slos or incident")
+	}
+
+	var lvl zapcore.Level
+	if update.Level != nil {
+		if err := lvl.UnmarshalText([]byte(*update.Level)); err != nil {
+			return err
+		}
+	}
+
+	var threshold time.Duration
+	if update.SlowSpanThreshold != nil {
+		d, err := time.ParseDuration(*update.SlowSpanThreshold)
+		if err != nil {
+			return err
+		}
+		if d <= 0 {
+			return errors.New("slowSpanThreshold must be positive")
+		}
+		threshold = d
+	}
+
+	if update.Debug != nil && update.Debug.RequestID == "" {
+		return errors.New("debug.requestID must not be empty")
+	}
+
+	targets := make(map[string]time.Duration, len(update.SLOs))
+	for name, v := range update.SLOs {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("slos.%s: %v", name, err)
+		}
+		if d < 0 {
+			return fmt.Errorf("slos.%s must not be negative", name)
+		}
+		targets[name] = d
+	}
+
+	var incidentOpts []IncidentOption
+	if inc := update.Incident; inc != nil {
+		if inc.Duration != "" {
+			d, err := time.ParseDuration(inc.Duration)
+			if err != nil {
+				return fmt.Errorf("incident.duration: %v", err)
+			}
+			if d <= 0 {
+				return errors.New("incident.duration must be positive")
+			}
+			incidentOpts = append(incidentOpts, IncidentDuration(d))
+		}
+		if inc.SampleRate != nil {
+			if *inc.SampleRate < 0 || *inc.SampleRate > 1 {
+				return errors.New("incident.sampleRate must be between 0 and 1")
+			}
+			incidentOpts = append(incidentOpts, IncidentSampleRate(*inc.SampleRate))
+		}
+	}
+
+	if update.Level != nil {
+		SetLevel(lvl)
+	}
+	if update.SlowSpanThreshold != nil {
+		SetSlowSpanThreshold(threshold)
+	}
+	if update.Debug != nil {
+		if update.Debug.Enabled {
+			EnableRequestDebug(update.Debug.RequestID)
+		} else {
+			DisableRequestDebug(update.Debug.RequestID)
+		}
+	}
+	if len(targets) > 0 {
+		updateSLOs(targets)
+	}
+	if update.Incident != nil {
+		SetIncidentMode(update.Incident.Enabled, incidentOpts...)
+	}
+	return nil
+}
+
+func adminSLOs() map[string]string {
+	out := map[string]string{}
+	for name, target := range SLOs() {
+		out[name] = target.String()
+	}
+	return out
+}
+
+func serveExplain(w http.ResponseWriter, r *http.Request) {
+	e := explainConfig()
+	if r.URL.Query().Get("format") == FormatJSON {
+		writeAdminJSON(w, http.StatusOK, e)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_ = e.writeText(w)
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
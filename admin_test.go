@@ -0,0 +1,180 @@
+package jogger_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap/zapcore"
+)
+
+type adminResponse struct {
+	Level             string   `json:"level"`
+	Format            string   `json:"format"`
+	Sinks             []string `json:"sinks"`
+	SlowSpanThreshold string   `json:"slowSpanThreshold"`
+	Sampling          struct {
+		Rate      float64 `json:"rate"`
+		TailLimit int     `json:"tailLimit"`
+	} `json:"sampling"`
+	DebugRequestIDs []string          `json:"debugRequestIDs"`
+	SLOs            map[string]string `json:"slos"`
+	Error           string            `json:"error"`
+}
+
+func doAdmin(t *testing.T, method, body string) (int, adminResponse) {
+	t.Helper()
+	req := httptest.NewRequest(method, "/admin/log", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	jogger.AdminHandler().ServeHTTP(rec, req)
+
+	var resp adminResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid JSON response %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, resp
+}
+
+func restoreAdminDefaults() {
+	jogger.SetLevel(zapcore.InfoLevel)
+	jogger.SetSlowSpanThreshold(0)
+	for _, id := range jogger.DebugRequestIDs() {
+		jogger.DisableRequestDebug(id)
+	}
+	for name := range jogger.SLOs() {
+		jogger.RegisterSLO(name, 0)
+	}
+}
+
+func TestAdminHandlerGet(t *testing.T) {
+	defer restoreAdminDefaults()
+	jogger.SetLevel(zapcore.WarnLevel)
+	jogger.EnableRequestDebug("req-admin-get")
+
+	code, resp := doAdmin(t, http.MethodGet, "")
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if resp.Level != "warn" {
+		t.Errorf("expected level warn, got %q", resp.Level)
+	}
+	if resp.Format != jogger.FormatConsole {
+		t.Errorf("expected console format, got %q", resp.Format)
+	}
+	if len(resp.Sinks) == 0 {
+		t.Error("expected at least one sink")
+	}
+	if resp.SlowSpanThreshold != "1s" {
+		t.Errorf("expected default slow threshold 1s, got %q", resp.SlowSpanThreshold)
+	}
+	if len(resp.DebugRequestIDs) != 1 || resp.DebugRequestIDs[0] != "req-admin-get" {
+		t.Errorf("expected debug request IDs [req-admin-get], got %v", resp.DebugRequestIDs)
+	}
+}
+
+func TestAdminHandlerGetSampling(t *testing.T) {
+	_, resp := doAdmin(t, http.MethodGet, "")
+	if resp.Sampling.Rate != 1 || resp.Sampling.TailLimit != 0 {
+		t.Errorf("expected the default sampling, got %+v", resp.Sampling)
+	}
+
+	configureBuffer(t, jogger.WithSampling(0.25), jogger.WithTailSampling(50))
+	_, resp = doAdmin(t, http.MethodGet, "")
+	if resp.Sampling.Rate != 0.25 || resp.Sampling.TailLimit != 50 {
+		t.Errorf("expected rate 0.25 and tail limit 50, got %+v", resp.Sampling)
+	}
+}
+
+func TestAdminHandlerPut(t *testing.T) {
+	defer restoreAdminDefaults()
+
+	code, resp := doAdmin(t, http.MethodPut, `{"level":"debug","slowSpanThreshold":"250ms","debug":{"requestID":"req-admin-put","enabled":true}}`)
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", code, resp.Error)
+	}
+	if jogger.Level() != zapcore.DebugLevel {
+		t.Errorf("expected debug level, got %v", jogger.Level())
+	}
+	if jogger.SlowSpanThreshold() != 250*time.Millisecond {
+		t.Errorf("expected 250ms threshold, got %v", jogger.SlowSpanThreshold())
+	}
+	if !jogger.RequestDebugEnabled("req-admin-put") {
+		t.Error("expected debug to be enabled for req-admin-put")
+	}
+	if resp.Level != "debug" || resp.SlowSpanThreshold != "250ms" {
+		t.Errorf("response does not reflect update: %+v", resp)
+	}
+
+	code, _ = doAdmin(t, http.MethodPut, `{"debug":{"requestID":"req-admin-put","enabled":false}}`)
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if jogger.RequestDebugEnabled("req-admin-put") {
+		t.Error("expected debug to be disabled for req-admin-put")
+	}
+}
+
+func TestAdminHandlerSLOs(t *testing.T) {
+	defer restoreAdminDefaults()
+	jogger.RegisterSLO("cache.get", 5*time.Millisecond)
+
+	code, resp := doAdmin(t, http.MethodPut, `{"slos":{"db.query":"50ms","cache.get":"0s"}}`)
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", code, resp.Error)
+	}
+	if len(resp.SLOs) != 1 || resp.SLOs["db.query"] != "50ms" {
+		t.Errorf("expected only the db.query SLO, got %v", resp.SLOs)
+	}
+
+	code, resp = doAdmin(t, http.MethodPut, `{"slos":{"db.query":"fast"}}`)
+	if code != http.StatusBadRequest || !strings.Contains(resp.Error, "slos.db.query") {
+		t.Errorf("expected 400 naming the bad SLO, got %d %q", code, resp.Error)
+	}
+	if jogger.SLOs()["db.query"] != 50*time.Millisecond {
+		t.Errorf("expected the invalid update to leave the SLO unchanged, got %v", jogger.SLOs())
+	}
+}
+
+func TestAdminHandlerInvalidPayload(t *testing.T) {
+	defer restoreAdminDefaults()
+
+	cases := map[string]string{
+		"malformed JSON":     `{"level":`,
+		"unknown field":      `{"verbosity":"debug"}`,
+		"empty update":       `{}`,
+		"bad level":          `{"level":"loud"}`,
+		"bad duration":       `{"slowSpanThreshold":"soon"}`,
+		"negative duration":  `{"slowSpanThreshold":"-1s"}`,
+		"missing request ID": `{"debug":{"enabled":true}}`,
+		"partly valid":       `{"level":"error","slowSpanThreshold":"soon"}`,
+	}
+	for name, body := range cases {
+		code, resp := doAdmin(t, http.MethodPut, body)
+		if code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, code)
+		}
+		if resp.Error == "" {
+			t.Errorf("%s: expected error message", name)
+		}
+	}
+
+	if jogger.Level() != zapcore.InfoLevel {
+		t.Errorf("invalid payload must not change level, got %v", jogger.Level())
+	}
+}
+
+func TestAdminHandlerMethodNotAllowed(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/admin/log", nil)
+	rec := httptest.NewRecorder()
+	jogger.AdminHandler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", rec.Code)
+	}
+	if rec.Header().Get("Allow") != "GET, PUT" {
+		t.Errorf("unexpected Allow header %q", rec.Header().Get("Allow"))
+	}
+}
@@ -0,0 +1,263 @@
+package jogger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// AuditMode selects where Audit writes when an audit output is configured.
+type AuditMode int
+
+const (
+	// AuditOnly writes audit events to the audit output alone.
+	AuditOnly AuditMode = iota
+	// AuditAndMain also writes them to the main output.
+	AuditAndMain
+)
+
+// defaultAuditSchema lists the fields every audit event must carry.
+var defaultAuditSchema = []string{"actor", "target", "outcome"}
+
+// WithAuditOutput writes audit events as JSON to w, which may be a file or
+// a network connection. Without it, they go to the main output.
+func WithAuditOutput(w io.Writer, mode AuditMode) Option {
+	return func(c *config) error {
+		if w == nil {
+			return errors.New("jogger: nil audit output")
+		}
+		if mode != AuditOnly && mode != AuditAndMain {
+			return errors.New("jogger: unknown audit mode")
+		}
+		c.auditWriter = w
+		c.auditMode = mode
+		return nil
+	}
+}
+
+// WithAuditSchema replaces the fields every audit event must carry, actor,
+// target and outcome by default.
+func WithAuditSchema(required ...string) Option {
+	return func(c *config) error {
+		c.auditSchema = append([]string{}, required...)
+		return nil
+	}
+}
+
+// AuditDurability sets up WithAuditDurability.
+type AuditDurability struct {
+	// MaxLatency batches the syncs: an event is written at once and synced
+	// together with the events written within MaxLatency after the first
+	// one of the batch, so that Audit and AuditE return at most MaxLatency
+	// plus the sync later. Zero syncs every event on its own.
+	MaxLatency time.Duration
+}
+
+// WithAuditDurability makes Audit and AuditE return only once the event is
+// written to the audit output and synced, so it survives a crash of the
+// process. The audit output of WithAuditOutput must have a Sync method that
+// flushes to stable storage, as *os.File does. AuditE returns the error of
+// the write or the sync; Audit reports it as an internal error. It does not
+// apply to the main output, whatever its settings, and costs a sync per
+// event, or per batch with MaxLatency; see BenchmarkAudit.
+func WithAuditDurability(d AuditDurability) Option {
+	return func(c *config) error {
+		if d.MaxLatency < 0 {
+			return errors.New("jogger: negative audit sync latency")
+		}
+		c.auditDurability = &d
+		return nil
+	}
+}
+
+// auditSinkName names the audit output for WithSinkFields.
+const auditSinkName = "audit"
+
+// auditLoggers are the destinations of Audit.
+type auditLoggers struct {
+	all  *zap.Logger  // every destination, for Audit
+	sink zapcore.Core // the audit output alone, for AuditE; nil without one
+	main *zap.Logger  // the main output, for AuditE; nil with AuditOnly
+}
+
+// newAuditLoggers builds the loggers behind Audit. They are enabled at
+// every level, whatever the levels of the sinks, and leave out sampling and
+// deduplication, so no audit event is dropped.
+func newAuditLoggers(cfg config, sinks []sink, errOut zap.Option) (auditLoggers, error) {
+	main := zap.New(newAuditCore(cfg, sinks), errOut).With(cfg.fields...)
+	if cfg.auditWriter == nil {
+		if cfg.auditDurability != nil {
+			return auditLoggers{}, errors.New("jogger: audit durability needs WithAuditOutput")
+		}
+		return auditLoggers{all: main, main: main}, nil
+	}
+	w := cfg.auditWriter
+	if d := cfg.auditDurability; d != nil {
+		ws, ok := w.(zapcore.WriteSyncer)
+		if !ok {
+			return auditLoggers{}, errors.New("jogger: audit durability needs an audit output with a Sync method")
+		}
+		w = &durableWriter{w: ws, maxLatency: d.MaxLatency}
+	}
+	audit, err := newSink(cfg, SinkConfig{Name: auditSinkName, Writer: w, Format: FormatJSON})
+	if err != nil {
+		return auditLoggers{}, err
+	}
+	sink := audit.ioCore(zapcore.DebugLevel).With(cfg.fields)
+	if cfg.auditMode == AuditOnly {
+		return auditLoggers{all: zap.New(sink, errOut), sink: sink}, nil
+	}
+	return auditLoggers{all: zap.New(zapcore.NewTee(sink, main.Core()), errOut), sink: sink, main: main}, nil
+}
+
+// newAuditCore writes audit events to every sink of the main output. Unlike
+// newCore, it leaves out suppression, deduplication and the size limits,
+// which could drop an event or cut its schema fields; only escaping is
+// kept, so events cannot forge lines in console output.
+func newAuditCore(cfg config, sinks []sink) zapcore.Core {
+	cores := make([]zapcore.Core, 0, len(sinks))
+	for _, s := range sinks {
+		core := s.ioCore(zapcore.DebugLevel)
+		if escape := cfg.escapingEnabled(s.format); escape || cfg.stripANSI {
+			core = newEscapeCore(core, escape, cfg.stripANSI)
+		}
+		cores = append(cores, core)
+	}
+	if len(cores) == 1 {
+		return cores[0]
+	}
+	return zapcore.NewTee(cores...)
+}
+
+// durableWriter syncs w after each write, or with maxLatency after the
+// first write of a batch, and makes Write wait for it.
+type durableWriter struct {
+	w          zapcore.WriteSyncer
+	maxLatency time.Duration
+
+	mu    sync.Mutex
+	batch *syncBatch // the writes waiting for a sync, nil when none
+}
+
+// syncBatch is the result of a sync awaited by several writes.
+type syncBatch struct {
+	done chan struct{}
+	err  error
+}
+
+func (d *durableWriter) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	n, err := d.w.Write(p)
+	if err != nil || d.maxLatency <= 0 {
+		if err == nil {
+			err = d.w.Sync()
+		}
+		d.mu.Unlock()
+		return n, err
+	}
+	b := d.batch
+	if b == nil {
+		b = &syncBatch{done: make(chan struct{})}
+		d.batch = b
+		time.AfterFunc(d.maxLatency, func() { d.syncBatch(b) })
+	}
+	d.mu.Unlock()
+	<-b.done
+	return n, b.err
+}
+
+// Sync syncs w, completing the pending batch.
+func (d *durableWriter) Sync() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	err := d.w.Sync()
+	if b := d.batch; b != nil {
+		d.batch = nil
+		b.err = err
+		close(b.done)
+	}
+	return err
+}
+
+// syncBatch syncs the writes of b, unless Sync did already.
+func (d *durableWriter) syncBatch(b *syncBatch) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.batch != b {
+		return
+	}
+	d.batch = nil
+	b.err = d.w.Sync()
+	close(b.done)
+}
+
+// Audit records a security-relevant event, such as a login, a permission
+// change or a data export, with its action, the request ID and correlation
+// fields of ctx, and a timestamp. Audit events are never sampled,
+// deduplicated, suppressed or cut by WithMaxEntrySize and
+// WithMaxFieldLength, and are logged regardless of the level. An event
+// missing fields of the audit schema is still logged, with the missing
+// names in a schema_violation field.
+func Audit(ctx context.Context, action string, fields ...zap.Field) {
+	ctx = orBackground(ctx)
+	o := outputFor(ctx)
+	o.audit.all.Info("audit", auditFields(ctx, o, action, fields)...)
+}
+
+// AuditE is Audit returning the error of writing the event to the audit
+// output, which includes the sync of WithAuditDurability. Without an audit
+// output it returns nil. With AuditAndMain, errors of the main output are
+// reported as internal errors, as by Audit.
+func AuditE(ctx context.Context, action string, fields ...zap.Field) error {
+	ctx = orBackground(ctx)
+	o := outputFor(ctx)
+	all := auditFields(ctx, o, action, fields)
+	var err error
+	if o.audit.sink != nil {
+		err = o.audit.sink.Write(zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Now(), Message: "audit"}, all)
+	}
+	if o.audit.main != nil {
+		o.audit.main.Info("audit", all...)
+	}
+	return err
+}
+
+// auditFields returns the fields of an audit event.
+func auditFields(ctx context.Context, o *output, action string, fields []zap.Field) []zap.Field {
+	all := make([]zap.Field, 0, len(fields)+6)
+	all = append(all,
+		zap.String("action", action),
+		zap.String(o.cfg.fieldNames.RequestID, RequestID(ctx)),
+		zap.Time("timestamp", time.Now()),
+	)
+	s := scopeOf(ctx)
+	all = correlationFields(ctx, &s, all)
+	all = append(all, fields...)
+	if missing := missingFields(o.auditSchema(), fields); len(missing) > 0 {
+		all = append(all, zap.Strings("schema_violation", missing))
+	}
+	return all
+}
+
+func (o *output) auditSchema() []string {
+	if o.cfg.auditSchema == nil {
+		return defaultAuditSchema
+	}
+	return o.cfg.auditSchema
+}
+
+// missingFields returns the required keys that fields lacks.
+func missingFields(required []string, fields []zap.Field) []string {
+	var missing []string
+	for _, key := range required {
+		if !hasField(fields, key) {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,220 @@
+package jogger_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestAuditGoesToAuditOutputOnly(t *testing.T) {
+	var audit bytes.Buffer
+	main := configureBuffer(t,
+		jogger.WithFormat(jogger.FormatJSON),
+		jogger.WithLevel(zapcore.ErrorLevel),
+		jogger.WithSampling(0),
+		jogger.WithAuditOutput(&audit, jogger.AuditOnly),
+	)
+	ctx := jogger.EnsureRequestID(context.Background())
+
+	jogger.Audit(ctx, "user.login", zap.String("actor", "alice"), zap.String("target", "session"), zap.String("outcome", "success"))
+
+	if main.Len() != 0 {
+		t.Errorf("expected nothing on the main output, got %q", main.String())
+	}
+	entries := decodeEntries(t, &audit)
+	if len(entries) != 1 {
+		t.Fatalf("expected one audit event, got %v", entries)
+	}
+	e := entries[0]
+	if e["action"] != "user.login" || e["actor"] != "alice" || e["requestID"] != jogger.RequestID(ctx) || e["timestamp"] == nil {
+		t.Errorf("unexpected audit event %v", e)
+	}
+	if _, ok := e["schema_violation"]; ok {
+		t.Errorf("expected no schema violation, got %v", e)
+	}
+}
+
+func TestAuditReportsSchemaViolation(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithAuditSchema("actor", "reason"))
+
+	jogger.Audit(context.Background(), "data.export", zap.String("actor", "bob"))
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 1 {
+		t.Fatalf("expected the event on the main output, got %v", entries)
+	}
+	violation, _ := entries[0]["schema_violation"].([]interface{})
+	if len(violation) != 1 || violation[0] != "reason" {
+		t.Errorf("expected reason to be reported missing, got %v", entries[0])
+	}
+}
+
+func TestAuditAndMain(t *testing.T) {
+	var audit bytes.Buffer
+	main := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithAuditOutput(&audit, jogger.AuditAndMain))
+
+	jogger.Audit(context.Background(), "role.grant", zap.String("actor", "a"), zap.String("target", "b"), zap.String("outcome", "denied"))
+
+	if len(decodeEntries(t, &audit)) != 1 || len(decodeEntries(t, main)) != 1 {
+		t.Errorf("expected the event on both outputs, got %q and %q", audit.String(), main.String())
+	}
+}
+
+func TestAuditIgnoresSuppressionAndLimits(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithMaxEntrySize(64), jogger.WithMaxFieldLength(4))
+	remove := jogger.SuppressMatching(func(ent zapcore.Entry, fields []zapcore.Field) bool { return true })
+	defer remove()
+
+	jogger.Audit(context.Background(), "user.delete", zap.String("actor", "administrator"), zap.String("target", "user-12345"), zap.String("outcome", "success"))
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 1 {
+		t.Fatalf("expected the audit event despite the suppression, got %v", entries)
+	}
+	e := entries[0]
+	if e["actor"] != "administrator" || e["target"] != "user-12345" || e["outcome"] != "success" {
+		t.Errorf("expected the schema fields intact, got %v", e)
+	}
+	if _, ok := e["dropped_fields"]; ok {
+		t.Errorf("expected no fields dropped, got %v", e)
+	}
+}
+
+func TestWithAuditOutputRejectsInvalidSettings(t *testing.T) {
+	if err := jogger.Configure(jogger.WithAuditOutput(nil, jogger.AuditOnly)); err == nil {
+		t.Error("expected an error for a nil audit output")
+	}
+	if err := jogger.Configure(jogger.WithAuditOutput(&bytes.Buffer{}, jogger.AuditMode(5))); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
+
+// syncRecorder is an audit output that records its writes and syncs.
+type syncRecorder struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	synced  int // bytes of buf synced
+	syncs   int
+	syncErr error
+}
+
+func (r *syncRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.buf.Write(p)
+}
+
+func (r *syncRecorder) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.syncs++
+	if r.syncErr == nil {
+		r.synced = r.buf.Len()
+	}
+	return r.syncErr
+}
+
+// durable reports whether everything written was synced.
+func (r *syncRecorder) durable() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.synced == r.buf.Len()
+}
+
+func auditLogin(ctx context.Context) error {
+	return jogger.AuditE(ctx, "user.login", zap.String("actor", "alice"), zap.String("target", "session"), zap.String("outcome", "success"))
+}
+
+func TestAuditDurabilitySyncsEachEvent(t *testing.T) {
+	out := &syncRecorder{}
+	configureBuffer(t, jogger.WithAuditOutput(out, jogger.AuditOnly), jogger.WithAuditDurability(jogger.AuditDurability{}))
+
+	for i := 0; i < 3; i++ {
+		if err := auditLogin(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if !out.durable() {
+			t.Fatalf("event %d returned before it was synced", i)
+		}
+	}
+	if out.syncs != 3 {
+		t.Errorf("expected a sync per event, got %d", out.syncs)
+	}
+}
+
+func TestAuditDurabilityBatchesSyncs(t *testing.T) {
+	out := &syncRecorder{}
+	configureBuffer(t, jogger.WithAuditOutput(out, jogger.AuditOnly), jogger.WithAuditDurability(jogger.AuditDurability{MaxLatency: 20 * time.Millisecond}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := auditLogin(context.Background()); err != nil {
+				t.Error(err)
+			}
+			if !out.durable() {
+				t.Error("an event returned before it was synced")
+			}
+		}()
+	}
+	wg.Wait()
+	if strings.Count(out.buf.String(), "\n") != 10 || out.syncs >= 10 {
+		t.Errorf("expected 10 events with fewer syncs, got %d syncs for %q", out.syncs, out.buf.String())
+	}
+}
+
+func TestAuditDurabilityReportsSyncFailure(t *testing.T) {
+	out := &syncRecorder{syncErr: errors.New("disk full")}
+	main := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON),
+		jogger.WithAuditOutput(out, jogger.AuditAndMain), jogger.WithAuditDurability(jogger.AuditDurability{}))
+	var reported []error
+	jogger.SetInternalErrorHandler(func(err error) { reported = append(reported, err) })
+	defer jogger.SetInternalErrorHandler(nil)
+
+	if err := auditLogin(context.Background()); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("expected the sync error from AuditE, got %v", err)
+	}
+	if len(decodeEntries(t, main)) != 1 {
+		t.Errorf("expected the event on the main output anyway, got %q", main.String())
+	}
+	if len(reported) != 0 {
+		t.Errorf("expected AuditE not to report the error it returns, got %v", reported)
+	}
+
+	jogger.Audit(context.Background(), "user.logout", zap.String("actor", "alice"), zap.String("target", "session"), zap.String("outcome", "success"))
+	if len(reported) != 1 || !strings.Contains(reported[0].Error(), "disk full") {
+		t.Errorf("expected Audit to report the sync error, got %v", reported)
+	}
+}
+
+func TestAuditEWithoutAuditOutput(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	if err := auditLogin(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if entries := decodeEntries(t, buf); len(entries) != 1 || entries[0]["action"] != "user.login" {
+		t.Errorf("expected the event on the main output, got %v", entries)
+	}
+}
+
+func TestWithAuditDurabilityRejectsInvalidSettings(t *testing.T) {
+	for name, opts := range map[string][]jogger.Option{
+		"no audit output":  {jogger.WithAuditDurability(jogger.AuditDurability{})},
+		"no Sync method":   {jogger.WithAuditOutput(&bytes.Buffer{}, jogger.AuditOnly), jogger.WithAuditDurability(jogger.AuditDurability{})},
+		"negative latency": {jogger.WithAuditOutput(&syncRecorder{}, jogger.AuditOnly), jogger.WithAuditDurability(jogger.AuditDurability{MaxLatency: -time.Second})},
+	} {
+		if err := jogger.Configure(opts...); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
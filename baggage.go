@@ -0,0 +1,161 @@
+package jogger
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// BaggageHeader is the header Inject writes and Extract reads baggage from,
+// as comma-separated key=value pairs with percent-encoded values.
+const BaggageHeader = "baggage"
+
+// Baggage limits, counting keys and values.
+const (
+	maxBaggageItems = 16
+	maxBaggageBytes = 1024
+)
+
+var baggageKey = &contextKey{"baggage"}
+
+type baggageItem struct {
+	key, value string
+}
+
+// baggage is immutable; WithBaggage copies it.
+type baggage struct {
+	items []baggageItem
+	size  int
+}
+
+// WithBaggage returns a copy of ctx carrying key=value as baggage: it is
+// added to every entry logged with ctx or a context derived from it, and
+// travels to other services with Inject and Extract. Set it at the edge for
+// values such as an experiment variant or API version. Keys keep only
+// letters, digits, '_', '-' and '.'. Setting a key again replaces its value.
+// Baggage holds at most 16 items of 1024 bytes together; an item that does
+// not fit is dropped with a warning.
+func WithBaggage(ctx context.Context, key, value string) context.Context {
+	ctx = orBackground(ctx)
+	key = sanitizeBaggageKey(key)
+	if key == "" {
+		return ctx
+	}
+	b, ok := baggageFrom(ctx).with(key, value)
+	if !ok {
+		FromContext(ctx).Warn("jogger: baggage full, item dropped", zap.String("baggageKey", key), zap.Int("bytes", len(key)+len(value)))
+		return ctx
+	}
+	return context.WithValue(ctx, baggageKey, b)
+}
+
+// Baggage returns a copy of the baggage of ctx.
+func Baggage(ctx context.Context) map[string]string {
+	m := map[string]string{}
+	if b := baggageFrom(orBackground(ctx)); b != nil {
+		for _, it := range b.items {
+			m[it.key] = it.value
+		}
+	}
+	return m
+}
+
+// WithBaggageNamespace adds baggage to entries as baggage.<key> instead of
+// <key>, keeping it apart from other fields.
+func WithBaggageNamespace() Option {
+	return func(c *config) error {
+		c.baggagePrefix = "baggage."
+		return nil
+	}
+}
+
+func baggageFrom(ctx context.Context) *baggage {
+	b, _ := ctx.Value(baggageKey).(*baggage)
+	return b
+}
+
+// with returns b with key set to value, or false if it would exceed the
+// limits.
+func (b *baggage) with(key, value string) (*baggage, bool) {
+	next := &baggage{}
+	if b != nil {
+		next.items = make([]baggageItem, 0, len(b.items)+1)
+		for _, it := range b.items {
+			if it.key != key {
+				next.items = append(next.items, it)
+				next.size += len(it.key) + len(it.value)
+			}
+		}
+	}
+	next.size += len(key) + len(value)
+	if len(next.items) >= maxBaggageItems || next.size > maxBaggageBytes {
+		return b, false
+	}
+	next.items = append(next.items, baggageItem{key, value})
+	return next, true
+}
+
+func sanitizeBaggageKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+			return r
+		}
+		return -1
+	}, key)
+}
+
+// baggageFields appends the baggage of ctx, under the prefix configured
+// with WithBaggageNamespace.
+func baggageFields(ctx context.Context, fields []zap.Field) []zap.Field {
+	b := baggageFrom(ctx)
+	if b == nil {
+		return fields
+	}
+	prefix := outputFor(ctx).cfg.baggagePrefix
+	for _, it := range b.items {
+		fields = append(fields, zap.String(prefix+it.key, it.value))
+	}
+	return fields
+}
+
+func formatBaggage(b *baggage) string {
+	parts := make([]string, len(b.items))
+	for i, it := range b.items {
+		parts[i] = it.key + "=" + url.PathEscape(it.value)
+	}
+	return strings.Join(parts, ",")
+}
+
+// parseBaggage reads a baggage header into ctx, applying the same limits
+// as WithBaggage. Malformed items are skipped.
+func parseBaggage(ctx context.Context, header string) context.Context {
+	var dropped int
+	for _, part := range strings.Split(header, ",") {
+		// Properties after ';' are not supported and ignored.
+		if i := strings.IndexByte(part, ';'); i >= 0 {
+			part = part[:i]
+		}
+		i := strings.IndexByte(part, '=')
+		if i <= 0 {
+			continue
+		}
+		key := sanitizeBaggageKey(strings.TrimSpace(part[:i]))
+		value, err := url.PathUnescape(strings.TrimSpace(part[i+1:]))
+		if key == "" || err != nil {
+			continue
+		}
+		b, ok := baggageFrom(ctx).with(key, value)
+		if !ok {
+			dropped++
+			continue
+		}
+		ctx = context.WithValue(ctx, baggageKey, b)
+	}
+	if dropped > 0 {
+		FromContext(ctx).Warn("jogger: baggage truncated", zap.Int("droppedItems", dropped))
+	}
+	return ctx
+}
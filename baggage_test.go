@@ -0,0 +1,85 @@
+package jogger_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+)
+
+func TestBaggageOnEntries(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	ctx := jogger.WithBaggage(context.Background(), "variant", "b")
+	ctx = jogger.WithBaggage(ctx, "api version!", "2")
+
+	jogger.Info(ctx, "plain")
+	span, sctx := jogger.StartSpan(ctx, "work")
+	jogger.Info(sctx, "inside")
+	err := context.Canceled
+	span.Finish(&err)
+
+	for _, e := range decodeEntries(t, buf) {
+		if e["variant"] != "b" || e["apiversion"] != "2" {
+			t.Errorf("expected the baggage on every entry, got %v", e)
+		}
+	}
+}
+
+func TestBaggageNamespace(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithBaggageNamespace())
+	jogger.Info(jogger.WithBaggage(context.Background(), "variant", "b"), "namespaced")
+
+	if e := decodeEntries(t, buf)[0]; e["baggage.variant"] != "b" || e["variant"] != nil {
+		t.Errorf("expected the baggage under baggage., got %v", e)
+	}
+}
+
+func TestBaggagePropagation(t *testing.T) {
+	ctx := jogger.WithBaggage(context.Background(), "variant", "b, c=d\r\n")
+	h := http.Header{}
+	jogger.Inject(ctx, jogger.HeaderCarrier(h))
+
+	header := h.Get(jogger.BaggageHeader)
+	if strings.ContainsAny(header, " \r\n") || strings.Count(header, ",") != 0 {
+		t.Fatalf("expected a header-safe value, got %q", header)
+	}
+	got := jogger.Baggage(jogger.Extract(context.Background(), jogger.HeaderCarrier(h)))
+	if got["variant"] != "b, c=d\r\n" {
+		t.Errorf("expected the value to survive the hop, got %q", got)
+	}
+}
+
+func TestBaggageLimits(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	ctx := jogger.WithBaggage(context.Background(), "big", strings.Repeat("x", 1000))
+	ctx = jogger.WithBaggage(ctx, "more", strings.Repeat("y", 100))
+
+	if b := jogger.Baggage(ctx); len(b) != 1 || b["more"] != "" {
+		t.Errorf("expected the item over the size cap to be dropped, got %d items", len(b))
+	}
+	if e := decodeEntries(t, buf); len(e) != 1 || e[0]["msg"] != "jogger: baggage full, item dropped" {
+		t.Errorf("expected a warning, got %v", e)
+	}
+
+	buf.Reset()
+	var parts []string
+	for i := 0; i < 20; i++ {
+		parts = append(parts, "k"+strings.Repeat("x", i)+"=v")
+	}
+	ctx = jogger.Extract(context.Background(), jogger.MapCarrier{jogger.BaggageHeader: strings.Join(parts, ",")})
+	if b := jogger.Baggage(ctx); len(b) != 16 {
+		t.Errorf("expected 16 items, got %d", len(b))
+	}
+	if e := decodeEntries(t, buf); len(e) != 1 || e[0]["droppedItems"] != float64(4) {
+		t.Errorf("expected a truncation warning, got %v", e)
+	}
+}
+
+func TestSnapshotCarriesBaggage(t *testing.T) {
+	snap := jogger.Snapshot(jogger.WithBaggage(context.Background(), "variant", "b"))
+	if got := jogger.Baggage(jogger.WithSnapshot(context.Background(), snap)); got["variant"] != "b" {
+		t.Errorf("expected the baggage in the snapshot, got %v", got)
+	}
+}
@@ -0,0 +1,196 @@
+package jogger
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultBatchInterval = 10 * time.Second
+	maxBatchErrorKinds   = 100
+	batchErrorSample     = 10
+	batchOtherErrors     = "(other errors)"
+)
+
+// BatchLogger collapses per-item logging of a large batch into periodic and
+// final summary entries. Create it with NewBatchLogger; it is safe for
+// concurrent use.
+type BatchLogger struct {
+	ok       int64
+	failed   int64
+	lastLog  int64
+	name     string
+	logger   *zap.Logger
+	start    time.Time
+	interval time.Duration
+	warnAt   float64
+	errorAt  float64
+
+	mu     sync.Mutex
+	errors map[string]int64
+}
+
+// A BatchOption configures a BatchLogger.
+type BatchOption func(*BatchLogger)
+
+// BatchInterval sets how often a progress summary is logged while items are
+// being reported. Zero logs only on Flush.
+func BatchInterval(d time.Duration) BatchOption {
+	return func(b *BatchLogger) {
+		b.interval = d
+	}
+}
+
+// BatchThresholds sets the failure ratios above which a summary is logged at
+// Warn and at Error instead of Info. The defaults are 0 and 0.5: any failure
+// warns, and more than half failing is an error.
+func BatchThresholds(warn, error float64) BatchOption {
+	return func(b *BatchLogger) {
+		b.warnAt = warn
+		b.errorAt = error
+	}
+}
+
+// NewBatchLogger returns a BatchLogger for the batch called name.
+func NewBatchLogger(ctx context.Context, name string, opts ...BatchOption) *BatchLogger {
+	b := &BatchLogger{
+		name:     name,
+		logger:   FromContext(ctx),
+		start:    time.Now(),
+		interval: defaultBatchInterval,
+		warnAt:   0,
+		errorAt:  0.5,
+		errors:   make(map[string]int64),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	b.lastLog = b.start.UnixNano()
+	return b
+}
+
+// ItemOK counts a successfully processed item.
+func (b *BatchLogger) ItemOK() {
+	atomic.AddInt64(&b.ok, 1)
+	b.maybeLog()
+}
+
+// ItemFailed counts a failed item. Distinct error messages are tracked up
+// to a fixed number; further kinds are counted together.
+func (b *BatchLogger) ItemFailed(err error) {
+	atomic.AddInt64(&b.failed, 1)
+
+	msg := "<nil>"
+	if err != nil {
+		msg = err.Error()
+	}
+	b.mu.Lock()
+	if _, ok := b.errors[msg]; ok || len(b.errors) < maxBatchErrorKinds {
+		b.errors[msg]++
+	} else {
+		b.errors[batchOtherErrors]++
+	}
+	b.mu.Unlock()
+
+	b.maybeLog()
+}
+
+// Flush logs the final summary.
+func (b *BatchLogger) Flush() {
+	b.log("batch finished")
+}
+
+func (b *BatchLogger) maybeLog() {
+	if b.interval <= 0 {
+		return
+	}
+	now := time.Now().UnixNano()
+	last := atomic.LoadInt64(&b.lastLog)
+	if time.Duration(now-last) >= b.interval && atomic.CompareAndSwapInt64(&b.lastLog, last, now) {
+		b.log("batch progress")
+	}
+}
+
+func (b *BatchLogger) log(msg string) {
+	ok := atomic.LoadInt64(&b.ok)
+	failed := atomic.LoadInt64(&b.failed)
+	elapsed := time.Since(b.start)
+
+	total := ok + failed
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(total) / elapsed.Seconds()
+	}
+
+	lvl := zapcore.InfoLevel
+	if total > 0 {
+		ratio := float64(failed) / float64(total)
+		switch {
+		case ratio > b.errorAt:
+			lvl = zapcore.ErrorLevel
+		case ratio > b.warnAt:
+			lvl = zapcore.WarnLevel
+		}
+	}
+
+	if ce := b.logger.Check(lvl, msg); ce != nil {
+		ce.Write(
+			zap.String("batch", b.name),
+			zap.Int64("ok", ok),
+			zap.Int64("failed", failed),
+			zap.Array("errors", b.topErrors()),
+			zap.Float64("perSecond", rate),
+			zap.Duration("elapsed", elapsed),
+		)
+	}
+}
+
+type errorCount struct {
+	message string
+	count   int64
+}
+
+func (e errorCount) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("message", e.message)
+	enc.AddInt64("count", e.count)
+	return nil
+}
+
+type errorCounts []errorCount
+
+func (es errorCounts) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, e := range es {
+		if err := enc.AppendObject(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// topErrors returns the most frequent error messages, ties broken by message
+// so summaries are stable.
+func (b *BatchLogger) topErrors() errorCounts {
+	b.mu.Lock()
+	all := make(errorCounts, 0, len(b.errors))
+	for msg, n := range b.errors {
+		all = append(all, errorCount{message: msg, count: n})
+	}
+	b.mu.Unlock()
+
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].count != all[j].count {
+			return all[i].count > all[j].count
+		}
+		return all[i].message < all[j].message
+	})
+	if len(all) > batchErrorSample {
+		all = all[:batchErrorSample]
+	}
+	return all
+}
@@ -0,0 +1,127 @@
+package jogger_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+)
+
+func TestBatchLoggerSummary(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	b := jogger.NewBatchLogger(jogger.WithRequestID(context.Background(), "req-batch"), "import-users", jogger.BatchInterval(0))
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			switch {
+			case i%10 == 0:
+				b.ItemFailed(errors.New("duplicate email"))
+			case i%25 == 1:
+				b.ItemFailed(errors.New("invalid name"))
+			default:
+				b.ItemOK()
+			}
+		}(i)
+	}
+	wg.Wait()
+	b.Flush()
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 1 {
+		t.Fatalf("expected a single summary entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e["msg"] != "batch finished" || e["batch"] != "import-users" || e["requestID"] != "req-batch" {
+		t.Errorf("unexpected summary %v", e)
+	}
+	if e["ok"] != float64(86) || e["failed"] != float64(14) {
+		t.Errorf("expected 86 ok and 14 failed, got %v and %v", e["ok"], e["failed"])
+	}
+	if e["level"] != "warn" {
+		t.Errorf("expected failures to escalate to warn, got %v", e["level"])
+	}
+	errs := e["errors"].([]interface{})
+	first := errs[0].(map[string]interface{})
+	if len(errs) != 2 || first["message"] != "duplicate email" || first["count"] != float64(10) {
+		t.Errorf("unexpected error sample %v", errs)
+	}
+}
+
+func TestBatchLoggerEscalation(t *testing.T) {
+	cases := []struct {
+		ok, failed int
+		level      string
+	}{
+		{10, 0, "info"},
+		{9, 1, "warn"},
+		{4, 6, "error"},
+	}
+	for _, c := range cases {
+		buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+		b := jogger.NewBatchLogger(context.Background(), "batch", jogger.BatchInterval(0))
+		for i := 0; i < c.ok; i++ {
+			b.ItemOK()
+		}
+		for i := 0; i < c.failed; i++ {
+			b.ItemFailed(errors.New("boom"))
+		}
+		b.Flush()
+		if got := decodeEntries(t, buf)[0]["level"]; got != c.level {
+			t.Errorf("%d ok/%d failed: expected %s, got %v", c.ok, c.failed, c.level, got)
+		}
+	}
+}
+
+func TestBatchLoggerCustomThresholds(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	b := jogger.NewBatchLogger(context.Background(), "batch", jogger.BatchInterval(0), jogger.BatchThresholds(0.2, 0.9))
+	for i := 0; i < 9; i++ {
+		b.ItemOK()
+	}
+	b.ItemFailed(errors.New("boom"))
+	b.Flush()
+
+	if got := decodeEntries(t, buf)[0]["level"]; got != "info" {
+		t.Errorf("expected 10%% failures to stay info under a 20%% threshold, got %v", got)
+	}
+}
+
+func TestBatchLoggerBoundsErrorKinds(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	b := jogger.NewBatchLogger(context.Background(), "batch", jogger.BatchInterval(0))
+	for i := 0; i < 500; i++ {
+		b.ItemFailed(fmt.Errorf("row %d failed", i))
+	}
+	b.Flush()
+
+	e := decodeEntries(t, buf)[0]
+	errs := e["errors"].([]interface{})
+	if len(errs) != 10 {
+		t.Fatalf("expected 10 sampled errors, got %d", len(errs))
+	}
+	top := errs[0].(map[string]interface{})
+	if top["message"] != "(other errors)" || top["count"] != float64(400) {
+		t.Errorf("expected overflow kinds to be grouped, got %v", top)
+	}
+}
+
+func TestBatchLoggerPeriodicSummary(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	b := jogger.NewBatchLogger(context.Background(), "batch", jogger.BatchInterval(10*time.Millisecond))
+	b.ItemOK()
+	time.Sleep(15 * time.Millisecond)
+	b.ItemOK()
+	b.Flush()
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 2 || entries[0]["msg"] != "batch progress" || entries[1]["ok"] != float64(2) {
+		t.Errorf("expected one progress and one final summary, got %v", entries)
+	}
+}
@@ -0,0 +1,209 @@
+package jogger_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+)
+
+// The hot paths below have allocation budgets, checked by
+// TestAllocationBudgets. The numbers are for JSON output to a discarding
+// writer at Info level. They are targets rather than measurements: logging
+// with a context must cost no more than logging on its cached logger, and
+// spans and requests no more than building one logger and writing their
+// entries. They are published in the README, so update it along with them.
+
+// discardJSON configures JSON output to a discarding writer.
+func discardJSON(tb testing.TB) {
+	tb.Helper()
+	if err := jogger.Configure(jogger.WithOutput(ioutil.Discard), jogger.WithFormat(jogger.FormatJSON)); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+func benchContext() context.Context {
+	_, ctx := jogger.StartSpan(jogger.WithRequestID(context.Background(), "req-bench"), "bench")
+	return ctx
+}
+
+func infoThreeFields(ctx context.Context) {
+	jogger.Info(ctx, "order placed", zap.String("order", "o-1"), zap.Int("items", 3), zap.Bool("gift", false))
+}
+
+func cachedInfoThreeFields(l *zap.Logger) {
+	l.Info("order placed", zap.String("order", "o-1"), zap.Int("items", 3), zap.Bool("gift", false))
+}
+
+func spanWithTags(ctx context.Context) {
+	span, _ := jogger.StartSpan(ctx, "db.query")
+	span.SetTag("table", "orders")
+	span.SetTag("rows", 3)
+	span.SetTag("cached", false)
+	span.Finish(nil)
+}
+
+func BenchmarkInfo(b *testing.B) {
+	discardJSON(b)
+	defer jogger.Configure()
+	ctx := benchContext()
+	l := jogger.FromContext(ctx)
+
+	b.Run("context", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			infoThreeFields(ctx)
+		}
+	})
+	b.Run("cachedLogger", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			cachedInfoThreeFields(l)
+		}
+	})
+}
+
+func BenchmarkFromContextCache(b *testing.B) {
+	discardJSON(b)
+	defer jogger.Configure()
+	ctx := benchContext()
+	cached := jogger.WithZapLogger(ctx, jogger.FromContext(ctx))
+
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			jogger.FromContext(ctx)
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			jogger.FromContext(cached)
+		}
+	})
+}
+
+func BenchmarkSpan(b *testing.B) {
+	discardJSON(b)
+	defer jogger.Configure()
+	ctx := benchContext()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		spanWithTags(ctx)
+	}
+}
+
+func BenchmarkSpanError(b *testing.B) {
+	discardJSON(b)
+	defer jogger.Configure()
+	ctx := benchContext()
+	err := errors.New("timeout")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		span, _ := jogger.StartSpan(ctx, "db.query")
+		span.Finish(&err)
+	}
+}
+
+func BenchmarkMiddleware(b *testing.B) {
+	discardJSON(b)
+	defer jogger.Configure()
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, bc := range []struct {
+		name string
+		h    http.Handler
+	}{
+		{"bare", ok},
+		{"middleware", jogger.Middleware()(ok)},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			r := httptest.NewRequest("GET", "/v1/orders", nil)
+			r.Header.Set("X-Request-ID", "req-bench")
+			w := httptest.NewRecorder()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				bc.h.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// BenchmarkAudit measures the cost of WithAuditDurability on a file, with
+// 32 callers per CPU: no sync, a sync per event, and syncs batched over a
+// millisecond.
+func BenchmarkAudit(b *testing.B) {
+	for _, bc := range []struct {
+		name       string
+		durability *jogger.AuditDurability
+	}{
+		{"unsynced", nil},
+		{"syncEach", &jogger.AuditDurability{}},
+		{"syncBatched", &jogger.AuditDurability{MaxLatency: time.Millisecond}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			f, err := os.Create(filepath.Join(b.TempDir(), "audit.log"))
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer f.Close()
+			opts := []jogger.Option{jogger.WithOutput(ioutil.Discard), jogger.WithAuditOutput(f, jogger.AuditOnly)}
+			if bc.durability != nil {
+				opts = append(opts, jogger.WithAuditDurability(*bc.durability))
+			}
+			if err := jogger.Configure(opts...); err != nil {
+				b.Fatal(err)
+			}
+			defer jogger.Configure()
+			ctx := benchContext()
+
+			b.ReportAllocs()
+			b.SetParallelism(32)
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if err := jogger.AuditE(ctx, "user.login", zap.String("actor", "alice"), zap.String("target", "session"), zap.String("outcome", "success")); err != nil {
+						b.Error(err)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestAllocationBudgets(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector allocates")
+	}
+	discardJSON(t)
+	t.Cleanup(func() { jogger.Configure() })
+	ctx := benchContext()
+	l := jogger.FromContext(ctx)
+	mw := jogger.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	r := httptest.NewRequest("GET", "/v1/orders", nil)
+	r.Header.Set("X-Request-ID", "req-bench")
+	w := httptest.NewRecorder()
+
+	for _, bc := range []struct {
+		name   string
+		budget float64
+		fn     func()
+	}{
+		{"Info via context", 1, func() { infoThreeFields(ctx) }},
+		{"Info via cached logger", 1, func() { cachedInfoThreeFields(l) }},
+		{"FromContext", 0, func() { jogger.FromContext(ctx) }},
+		{"StartSpan, 3 tags, Finish", 30, func() { spanWithTags(ctx) }},
+		{"Middleware request", 40, func() { mw.ServeHTTP(w, r) }},
+	} {
+		if got := testing.AllocsPerRun(100, bc.fn); got > bc.budget {
+			t.Errorf("%s: %v allocations, budget %v", bc.name, got, bc.budget)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package jogger
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// excludedBodyTypes are never captured, whatever the allowed types say.
+var excludedBodyTypes = []string{"multipart/*", "application/octet-stream"}
+
+type bodyCapture struct {
+	max   int
+	types []string
+}
+
+// CaptureRequestBody adds up to maxBytes of the request body to the access
+// log as request_body, with request_body_truncated set when it was longer.
+// Bodies are only captured when Debug is enabled for the request, either by
+// the level or by EnableRequestDebug. contentTypes restricts capture to the
+// listed media types, which may use a wildcard subtype such as "text/*";
+// empty allows every type. Multipart and octet-stream bodies are never
+// captured. The handler still reads the complete body.
+func CaptureRequestBody(maxBytes int, contentTypes []string) MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.requestBody = &bodyCapture{max: maxBytes, types: contentTypes}
+	}
+}
+
+// CaptureResponseBody is like CaptureRequestBody for the response, logged
+// as response_body and response_body_truncated.
+func CaptureResponseBody(maxBytes int, contentTypes []string) MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.responseBody = &bodyCapture{max: maxBytes, types: contentTypes}
+	}
+}
+
+func (b *bodyCapture) allows(contentType string) bool {
+	if b == nil || b.max <= 0 {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+	if matchesMediaType(mediaType, excludedBodyTypes) {
+		return false
+	}
+	return len(b.types) == 0 || matchesMediaType(mediaType, b.types)
+}
+
+func matchesMediaType(mediaType string, patterns []string) bool {
+	for _, p := range patterns {
+		p = strings.ToLower(p)
+		if p == mediaType {
+			return true
+		}
+		if strings.HasSuffix(p, "/*") && strings.HasPrefix(mediaType, p[:len(p)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// captureRequestBody reads up to max+1 bytes of r's body and puts them back
+// in front of the unread remainder, so the handler sees the whole body.
+func (b *bodyCapture) captureRequestBody(r *http.Request) []zap.Field {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+	head, err := ioutil.ReadAll(io.LimitReader(r.Body, int64(b.max)+1))
+	r.Body = replayBody{Reader: io.MultiReader(bytes.NewReader(head), r.Body), Closer: r.Body}
+	if err != nil && len(head) == 0 {
+		return nil
+	}
+
+	truncated := len(head) > b.max
+	if truncated {
+		head = head[:b.max]
+	}
+	return []zap.Field{
+		zap.ByteString("request_body", head),
+		zap.Bool("request_body_truncated", truncated),
+	}
+}
+
+type replayBody struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyTee keeps the first max bytes written to the response.
+type bodyTee struct {
+	capture   *bodyCapture
+	decided   bool
+	enabled   bool
+	buf       bytes.Buffer
+	truncated bool
+}
+
+func (t *bodyTee) write(h http.Header, p []byte) {
+	if !t.decided {
+		t.decided = true
+		t.enabled = t.capture.allows(h.Get("Content-Type"))
+	}
+	if !t.enabled {
+		return
+	}
+	room := t.capture.max - t.buf.Len()
+	if len(p) > room {
+		p = p[:room]
+		t.truncated = true
+	}
+	t.buf.Write(p)
+}
+
+func (t *bodyTee) fields() []zap.Field {
+	if t == nil || !t.enabled {
+		return nil
+	}
+	return []zap.Field{
+		zap.ByteString("response_body", t.buf.Bytes()),
+		zap.Bool("response_body_truncated", t.truncated),
+	}
+}
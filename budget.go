@@ -0,0 +1,366 @@
+package jogger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultBudgetWindow   = 5 * time.Minute
+	defaultBudgetInterval = time.Minute
+	defaultBudgetNames    = 100
+	defaultBudgetBuckets  = 10
+	defaultExemplars      = 5
+
+	// maxBudgetResults caps the results of FinishWithResult counted per
+	// span name; others are counted under "<high-cardinality>".
+	maxBudgetResults = 20
+)
+
+// ErrorBudgetConfig sets up the per-span-name error budget accounting of
+// WithErrorBudget.
+type ErrorBudgetConfig struct {
+	// Window is how far back the counts go, 5 minutes when zero.
+	Window time.Duration
+	// Interval is how often the summary entry is logged, every minute
+	// when zero. A negative Interval logs none, leaving the numbers to
+	// Stats and the admin handler.
+	Interval time.Duration
+	// MaxSpanNames caps the span names counted, 100 when zero; spans with
+	// other names are counted together under "<high-cardinality>".
+	MaxSpanNames int
+	// Buckets is how many slices the window is divided in, 10 when zero.
+	// The window slides by Window/Buckets.
+	Buckets int
+	// Exemplars is how many of the most recent failures of each span name
+	// are kept as examples, 5 when zero. A negative Exemplars keeps none.
+	Exemplars int
+}
+
+// WithErrorBudget counts, for each span name, the spans that finished
+// successfully, with an error and slowly, and by FinishWithResult result,
+// over a sliding window, so the failure ratio of e.g. checkout can be read
+// from the logs alone: a "span error budgets" entry lists them
+// periodically, with the span and request IDs of the most recent failures
+// as exemplars, and they are reported by Stats and the admin handler.
+// Shutdown stops the summary entries. It is off by default.
+func WithErrorBudget(bc ErrorBudgetConfig) Option {
+	return func(c *config) error {
+		if bc.Window < 0 || bc.MaxSpanNames < 0 || bc.Buckets < 0 {
+			return errors.New("jogger: negative error budget setting")
+		}
+		if bc.Window == 0 {
+			bc.Window = defaultBudgetWindow
+		}
+		if bc.Interval == 0 {
+			bc.Interval = defaultBudgetInterval
+		}
+		if bc.MaxSpanNames == 0 {
+			bc.MaxSpanNames = defaultBudgetNames
+		}
+		if bc.Buckets == 0 {
+			bc.Buckets = defaultBudgetBuckets
+		}
+		if bc.Exemplars == 0 {
+			bc.Exemplars = defaultExemplars
+		}
+		c.errorBudget = &bc
+		return nil
+	}
+}
+
+// SpanBudget is the outcome of the spans of one name over the window of
+// WithErrorBudget. Slow spans are those that finished without error over
+// their slow threshold or SLO; they are not counted as failed.
+type SpanBudget struct {
+	Total        uint64  `json:"total"`
+	Failed       uint64  `json:"failed"`
+	Slow         uint64  `json:"slow"`
+	FailureRatio float64 `json:"failureRatio"`
+	// Results counts the spans finished with FinishWithResult by result.
+	Results map[string]uint64 `json:"results,omitempty"`
+	// Exemplars are the most recent failures, newest first.
+	Exemplars []SpanExemplar `json:"exemplars,omitempty"`
+}
+
+// SpanExemplar identifies a failed span counted by WithErrorBudget, to look
+// up its entries.
+type SpanExemplar struct {
+	SpanID    string    `json:"spanID"`
+	RequestID string    `json:"requestID,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// budgetBucket holds the counts of one slice of the window, numbered by
+// its start time divided by the slice width.
+type budgetBucket struct {
+	slot      int64
+	total     uint64
+	failed    uint64
+	slow      uint64
+	results   map[string]uint64
+	exemplars []SpanExemplar // the last failures, oldest first
+}
+
+// budgetName is the ring of buckets of one span name and the results seen
+// for it, bounded by maxBudgetResults.
+type budgetName struct {
+	ring    []budgetBucket
+	results map[string]bool
+}
+
+// budgetTracker keeps a ring of buckets per span name.
+type budgetTracker struct {
+	cfg   ErrorBudgetConfig
+	width time.Duration
+
+	mu    sync.Mutex
+	names map[string]*budgetName
+	stop  chan struct{}
+	done  sync.Once
+}
+
+func newBudgetTracker(bc *ErrorBudgetConfig) *budgetTracker {
+	if bc == nil {
+		return nil
+	}
+	width := bc.Window / time.Duration(bc.Buckets)
+	if width <= 0 {
+		width = 1
+	}
+	return &budgetTracker{cfg: *bc, width: width, names: map[string]*budgetName{}, stop: make(chan struct{})}
+}
+
+// observeSpan is the span hook of the tracker.
+func (b *budgetTracker) observeSpan(fs finishedSpan) {
+	slot := fs.start.Add(fs.duration).UnixNano() / int64(b.width)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	name := fs.name
+	bn, ok := b.names[name]
+	if !ok {
+		if len(b.names) >= b.cfg.MaxSpanNames {
+			name = highCardinality
+			bn = b.names[name]
+		}
+		if bn == nil {
+			bn = &budgetName{ring: make([]budgetBucket, b.cfg.Buckets)}
+			b.names[name] = bn
+		}
+	}
+	bk := &bn.ring[slot%int64(len(bn.ring))]
+	if bk.slot != slot {
+		*bk = budgetBucket{slot: slot}
+	}
+	bk.total++
+	switch {
+	case fs.err != nil:
+		bk.failed++
+		if max := b.cfg.Exemplars; max > 0 {
+			if len(bk.exemplars) == max {
+				bk.exemplars = append(bk.exemplars[:0], bk.exemplars[1:]...)
+			}
+			bk.exemplars = append(bk.exemplars, SpanExemplar{SpanID: fs.id, RequestID: fs.requestID, Time: fs.start.Add(fs.duration)})
+		}
+	case fs.slow:
+		bk.slow++
+	}
+	if fs.result != "" {
+		if bk.results == nil {
+			bk.results = map[string]uint64{}
+		}
+		bk.results[bn.result(fs.result)]++
+	}
+}
+
+// result returns r, or "<high-cardinality>" once maxBudgetResults other
+// results were seen.
+func (bn *budgetName) result(r string) string {
+	if bn.results[r] {
+		return r
+	}
+	if len(bn.results) >= maxBudgetResults {
+		return highCardinality
+	}
+	if bn.results == nil {
+		bn.results = map[string]bool{}
+	}
+	bn.results[r] = true
+	return r
+}
+
+// snapshot sums the buckets of the window ending at now.
+func (b *budgetTracker) snapshot(now time.Time) map[string]SpanBudget {
+	current := now.UnixNano() / int64(b.width)
+	oldest := current - int64(b.cfg.Buckets) + 1
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make(map[string]SpanBudget, len(b.names))
+	for name, bn := range b.names {
+		var sb SpanBudget
+		for _, bk := range bn.ring {
+			if bk.slot < oldest || bk.slot > current {
+				continue
+			}
+			sb.Total += bk.total
+			sb.Failed += bk.failed
+			sb.Slow += bk.slow
+			for r, n := range bk.results {
+				if sb.Results == nil {
+					sb.Results = map[string]uint64{}
+				}
+				sb.Results[r] += n
+			}
+			for i := len(bk.exemplars) - 1; i >= 0; i-- {
+				sb.Exemplars = append(sb.Exemplars, bk.exemplars[i])
+			}
+		}
+		if sb.Total == 0 {
+			continue
+		}
+		sb.FailureRatio = float64(sb.Failed) / float64(sb.Total)
+		sb.Exemplars = latestExemplars(sb.Exemplars, b.cfg.Exemplars)
+		out[name] = sb
+	}
+	return out
+}
+
+// latestExemplars returns the max newest of exemplars, newest first. The
+// exemplars of each bucket come newest first already, so that failures at
+// the same time stay in order.
+func latestExemplars(exemplars []SpanExemplar, max int) []SpanExemplar {
+	if len(exemplars) == 0 {
+		return nil
+	}
+	sort.SliceStable(exemplars, func(i, j int) bool { return exemplars[i].Time.After(exemplars[j].Time) })
+	if len(exemplars) > max {
+		exemplars = exemplars[:max]
+	}
+	return exemplars
+}
+
+// run logs the summary entry every interval until stopped.
+func (b *budgetTracker) run(l *zap.Logger) {
+	if b.cfg.Interval < 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(b.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-b.stop:
+				return
+			case now := <-ticker.C:
+				b.log(l, now)
+			}
+		}
+	}()
+}
+
+func (b *budgetTracker) log(l *zap.Logger, now time.Time) {
+	budgets := b.snapshot(now)
+	if len(budgets) == 0 {
+		return
+	}
+	l.Info("span error budgets", zap.Duration("window", b.cfg.Window), zap.Array("spans", spanBudgets(budgets)))
+}
+
+// close stops the summary entries; it is safe to call more than once.
+func (b *budgetTracker) close() {
+	b.done.Do(func() { close(b.stop) })
+}
+
+// spanBudgets encodes budgets as an array sorted by span name.
+type spanBudgets map[string]SpanBudget
+
+func (s spanBudgets) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sb := s[name]
+		err := enc.AppendObject(zapcore.ObjectMarshalerFunc(func(o zapcore.ObjectEncoder) error {
+			o.AddString("name", name)
+			o.AddUint64("total", sb.Total)
+			o.AddUint64("failed", sb.Failed)
+			o.AddUint64("slow", sb.Slow)
+			o.AddFloat64("failure_ratio", sb.FailureRatio)
+			if len(sb.Results) > 0 {
+				if err := o.AddObject("results", budgetResults(sb.Results)); err != nil {
+					return err
+				}
+			}
+			if len(sb.Exemplars) > 0 {
+				return o.AddArray("exemplars", spanExemplars(sb.Exemplars))
+			}
+			return nil
+		}))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// budgetResults encodes the result counts of a span name, sorted by result.
+type budgetResults map[string]uint64
+
+func (r budgetResults) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	results := make([]string, 0, len(r))
+	for result := range r {
+		results = append(results, result)
+	}
+	sort.Strings(results)
+	for _, result := range results {
+		enc.AddUint64(result, r[result])
+	}
+	return nil
+}
+
+// spanExemplars encodes exemplars with the configured span and request ID
+// keys, so they can be pasted into a query.
+type spanExemplars []SpanExemplar
+
+func (s spanExemplars) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	names := CurrentFieldNames()
+	for _, e := range s {
+		e := e
+		err := enc.AppendObject(zapcore.ObjectMarshalerFunc(func(o zapcore.ObjectEncoder) error {
+			o.AddString(names.SpanID, e.SpanID)
+			if e.RequestID != "" {
+				o.AddString(names.RequestID, e.RequestID)
+			}
+			o.AddTime("time", e.Time)
+			return nil
+		}))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// budgetHook names the span hook of j's error budget tracker.
+func (j *Jogger) budgetHook() string {
+	return fmt.Sprintf("errorBudget.%p", j)
+}
+
+// errorBudgets returns the budgets of the default instance, nil when
+// WithErrorBudget is off.
+func errorBudgets() map[string]SpanBudget {
+	if b := currentOutput().budget; b != nil {
+		return b.snapshot(time.Now())
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package jogger_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+)
+
+func finishSpans(name string, ok, failed int) {
+	for i := 0; i < ok; i++ {
+		span, _ := jogger.StartSpan(context.Background(), name)
+		span.Finish(nil)
+	}
+	err := errors.New("declined")
+	for i := 0; i < failed; i++ {
+		span, _ := jogger.StartSpan(context.Background(), name)
+		span.Finish(&err)
+	}
+}
+
+func TestErrorBudgetStats(t *testing.T) {
+	configureBuffer(t, jogger.WithErrorBudget(jogger.ErrorBudgetConfig{Interval: -1}))
+	finishSpans("checkout", 3, 1)
+	jogger.RegisterSLO("checkout.slow", time.Nanosecond)
+	defer jogger.RegisterSLO("checkout.slow", 0)
+	finishSpans("checkout.slow", 1, 0)
+
+	budgets := jogger.Stats().ErrorBudgets
+	if got := budgets["checkout"]; got.Total != 4 || got.Failed != 1 || got.Slow != 0 || got.FailureRatio != 0.25 {
+		t.Errorf("unexpected checkout budget %+v", got)
+	}
+	if got := budgets["checkout.slow"]; got.Total != 1 || got.Slow != 1 || got.Failed != 0 {
+		t.Errorf("expected the SLO breach counted as slow, got %+v", got)
+	}
+}
+
+func TestErrorBudgetWindowSlides(t *testing.T) {
+	configureBuffer(t, jogger.WithErrorBudget(jogger.ErrorBudgetConfig{Window: 50 * time.Millisecond, Buckets: 5, Interval: -1}))
+	finishSpans("checkout", 0, 2)
+	if got := jogger.Stats().ErrorBudgets["checkout"].Failed; got != 2 {
+		t.Fatalf("expected two failures in the window, got %d", got)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if got, ok := jogger.Stats().ErrorBudgets["checkout"]; ok {
+		t.Errorf("expected the failures to leave the window, got %+v", got)
+	}
+}
+
+func TestErrorBudgetCapsSpanNames(t *testing.T) {
+	configureBuffer(t, jogger.WithErrorBudget(jogger.ErrorBudgetConfig{MaxSpanNames: 2, Interval: -1}))
+	for _, name := range []string{"a", "b", "c", "d"} {
+		finishSpans(name, 1, 0)
+	}
+	budgets := jogger.Stats().ErrorBudgets
+	if len(budgets) != 3 || budgets["<high-cardinality>"].Total != 2 {
+		t.Errorf("expected two names and the rest counted together, got %v", budgets)
+	}
+}
+
+func TestErrorBudgetSummaryEntry(t *testing.T) {
+	buf := configureSyncBuffer(t, jogger.WithErrorBudget(jogger.ErrorBudgetConfig{Interval: 10 * time.Millisecond}))
+	finishSpans("checkout", 1, 1)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(buf.String(), "span error budgets") {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the summary entry")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	var e struct {
+		Spans []map[string]interface{} `json:"spans"`
+	}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.Contains(line, "span error budgets") {
+			if err := json.Unmarshal([]byte(line), &e); err != nil {
+				t.Fatal(err)
+			}
+			break
+		}
+	}
+	if len(e.Spans) != 1 || e.Spans[0]["name"] != "checkout" || e.Spans[0]["failure_ratio"] != 0.5 {
+		t.Errorf("unexpected summary %v", e.Spans)
+	}
+	if ex, _ := e.Spans[0]["exemplars"].([]interface{}); len(ex) != 1 || ex[0].(map[string]interface{})["spanID"] == "" {
+		t.Errorf("expected the failure as an exemplar, got %v", e.Spans[0]["exemplars"])
+	}
+
+	if err := jogger.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	n := strings.Count(buf.String(), "span error budgets")
+	time.Sleep(50 * time.Millisecond)
+	if got := strings.Count(buf.String(), "span error budgets"); got != n {
+		t.Errorf("expected no summary entries after Shutdown, got %d more", got-n)
+	}
+}
+
+func TestErrorBudgetExemplars(t *testing.T) {
+	configureBuffer(t, jogger.WithErrorBudget(jogger.ErrorBudgetConfig{Window: 50 * time.Millisecond, Buckets: 5, Exemplars: 2, Interval: -1}))
+	err := errors.New("declined")
+	var spanIDs []string
+	for i := 0; i < 4; i++ {
+		ctx := jogger.WithRequestID(context.Background(), fmt.Sprint("req-", i))
+		span, ctx := jogger.StartSpan(ctx, "checkout")
+		spanIDs = append(spanIDs, jogger.ScopeFrom(ctx).SpanID)
+		span.Finish(&err)
+	}
+	finishSpans("checkout", 1, 0)
+
+	ex := jogger.Stats().ErrorBudgets["checkout"].Exemplars
+	if len(ex) != 2 || ex[0].SpanID != spanIDs[3] || ex[0].RequestID != "req-3" || ex[1].SpanID != spanIDs[2] {
+		t.Errorf("expected the two newest failures, newest first, got %+v", ex)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	finishSpans("checkout", 1, 0)
+	if ex := jogger.Stats().ErrorBudgets["checkout"].Exemplars; len(ex) != 0 {
+		t.Errorf("expected the exemplars to leave with the window, got %+v", ex)
+	}
+}
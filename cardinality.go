@@ -0,0 +1,177 @@
+package jogger
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultCardinalityReset = time.Hour
+
+	// highCardinality replaces the values of a guarded field beyond its
+	// limit.
+	highCardinality = "<high-cardinality>"
+)
+
+// WithCardinalityGuard bounds the number of distinct string values of the
+// fields named keys, such as a route tag that might end up holding raw URLs
+// by mistake. Once maxDistinct values of a key have been seen, values not
+// seen before are replaced with "<high-cardinality>", and a Warn entry
+// naming the key is logged the first time. The seen values are forgotten
+// every hour, or as set with WithCardinalityResetInterval. The audit output
+// is not guarded.
+func WithCardinalityGuard(keys []string, maxDistinct int) Option {
+	return func(c *config) error {
+		if len(keys) == 0 {
+			return errors.New("jogger: cardinality guard without keys")
+		}
+		if maxDistinct < 1 {
+			return errors.New("jogger: cardinality limit must be positive")
+		}
+		c.cardinalityKeys = append([]string(nil), keys...)
+		c.cardinalityMax = maxDistinct
+		return nil
+	}
+}
+
+// WithCardinalityResetInterval sets how often WithCardinalityGuard forgets
+// the values it has seen, an hour by default.
+func WithCardinalityResetInterval(d time.Duration) Option {
+	return func(c *config) error {
+		if d <= 0 {
+			return errors.New("jogger: cardinality reset interval must be positive")
+		}
+		c.cardinalityReset = d
+		return nil
+	}
+}
+
+// cardinalityGuard keeps the distinct values seen per guarded key, up to
+// max each, so its memory is bounded by the number of keys times max.
+type cardinalityGuard struct {
+	keys     map[string]bool
+	max      int
+	interval time.Duration
+
+	mu     sync.Mutex
+	since  time.Time
+	seen   map[string]map[string]struct{}
+	warned map[string]bool
+}
+
+func newCardinalityGuard(keys []string, max int, interval time.Duration) *cardinalityGuard {
+	if interval <= 0 {
+		interval = defaultCardinalityReset
+	}
+	g := &cardinalityGuard{
+		keys:     make(map[string]bool, len(keys)),
+		max:      max,
+		interval: interval,
+		since:    time.Now(),
+		seen:     make(map[string]map[string]struct{}, len(keys)),
+		warned:   map[string]bool{},
+	}
+	for _, k := range keys {
+		g.keys[k] = true
+	}
+	return g
+}
+
+// clamp replaces the values of guarded fields beyond the limit, copying
+// fields on the first change. It returns the keys that reached their limit
+// for the first time.
+func (g *cardinalityGuard) clamp(fields []zapcore.Field) ([]zapcore.Field, []string) {
+	var exceeded []string
+	copied := false
+	for i, f := range fields {
+		if !g.keys[f.Key] || f.Type != zapcore.StringType {
+			continue
+		}
+		ok, first := g.allow(f.Key, f.String)
+		if ok {
+			continue
+		}
+		if first {
+			exceeded = append(exceeded, f.Key)
+		}
+		if !copied {
+			fields = append([]zapcore.Field(nil), fields...)
+			copied = true
+		}
+		fields[i] = zap.String(f.Key, highCardinality)
+	}
+	return fields, exceeded
+}
+
+// allow records value for key and reports whether it is within the limit,
+// and whether it is the first value of key to exceed it.
+func (g *cardinalityGuard) allow(key, value string) (ok, first bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if now := time.Now(); now.Sub(g.since) >= g.interval {
+		g.since = now
+		g.seen = make(map[string]map[string]struct{}, len(g.keys))
+	}
+	values := g.seen[key]
+	if values == nil {
+		values = make(map[string]struct{})
+		g.seen[key] = values
+	}
+	if _, ok := values[value]; ok {
+		return true, false
+	}
+	if len(values) < g.max {
+		values[value] = struct{}{}
+		return true, false
+	}
+	first = !g.warned[key]
+	g.warned[key] = true
+	return false, first
+}
+
+// cardinalityCore applies a cardinalityGuard to the fields of With and of
+// every entry.
+type cardinalityCore struct {
+	zapcore.Core
+	guard *cardinalityGuard
+}
+
+func newCardinalityCore(c zapcore.Core, g *cardinalityGuard) zapcore.Core {
+	return &cardinalityCore{Core: c, guard: g}
+}
+
+func (c *cardinalityCore) With(fields []zapcore.Field) zapcore.Core {
+	fields, exceeded := c.guard.clamp(fields)
+	c.warn(exceeded)
+	return &cardinalityCore{Core: c.Core.With(fields), guard: c.guard}
+}
+
+func (c *cardinalityCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *cardinalityCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	fields, exceeded := c.guard.clamp(fields)
+	err := c.Core.Write(ent, fields)
+	c.warn(exceeded)
+	return err
+}
+
+// warn logs that keys reached their limit.
+func (c *cardinalityCore) warn(keys []string) {
+	if len(keys) == 0 || !c.Enabled(zapcore.WarnLevel) {
+		return
+	}
+	for _, key := range keys {
+		ent := zapcore.Entry{Level: zapcore.WarnLevel, Time: time.Now(), Message: "jogger: too many distinct values, new ones replaced"}
+		_ = c.Core.Write(ent, []zapcore.Field{zap.String("field", key), zap.Int("maxDistinct", c.guard.max)})
+	}
+}
@@ -0,0 +1,77 @@
+package jogger_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+)
+
+func TestWithCardinalityGuardInvalid(t *testing.T) {
+	if err := jogger.Configure(jogger.WithCardinalityGuard(nil, 10)); err == nil {
+		t.Error("expected an error without keys")
+	}
+	if err := jogger.Configure(jogger.WithCardinalityGuard([]string{"route"}, 0)); err == nil {
+		t.Error("expected an error for a zero limit")
+	}
+	if err := jogger.Configure(jogger.WithCardinalityResetInterval(0)); err == nil {
+		t.Error("expected an error for a zero interval")
+	}
+}
+
+func TestCardinalityGuardClamps(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithCardinalityGuard([]string{"route"}, 3))
+
+	for i := 0; i < 5; i++ {
+		jogger.Info(context.Background(), "request", zap.String("route", fmt.Sprintf("/items/%d?q=x", i)), zap.String("user", fmt.Sprint(i)))
+	}
+	jogger.Info(context.Background(), "request", zap.String("route", "/items/1?q=x"))
+	jogger.FromContext(context.Background()).With(zap.String("route", "/items/9")).Info("with")
+
+	var routes []interface{}
+	warnings := 0
+	for _, e := range decodeEntries(t, buf) {
+		if e["msg"] == "jogger: too many distinct values, new ones replaced" {
+			warnings++
+			if e["field"] != "route" || e["maxDistinct"] != float64(3) {
+				t.Errorf("expected the warning to name the key, got %v", e)
+			}
+			continue
+		}
+		routes = append(routes, e["route"])
+		if e["user"] == "<high-cardinality>" {
+			t.Error("expected unguarded keys to be left alone")
+		}
+	}
+	want := []interface{}{"/items/0?q=x", "/items/1?q=x", "/items/2?q=x", "<high-cardinality>", "<high-cardinality>", "/items/1?q=x", "<high-cardinality>"}
+	if fmt.Sprint(routes) != fmt.Sprint(want) {
+		t.Errorf("expected routes %v, got %v", want, routes)
+	}
+	if warnings != 1 {
+		t.Errorf("expected one warning, got %d", warnings)
+	}
+}
+
+func TestCardinalityGuardResets(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON),
+		jogger.WithCardinalityGuard([]string{"route"}, 1),
+		jogger.WithCardinalityResetInterval(20*time.Millisecond))
+
+	jogger.Info(context.Background(), "request", zap.String("route", "/a"))
+	jogger.Info(context.Background(), "request", zap.String("route", "/b"))
+	time.Sleep(30 * time.Millisecond)
+	jogger.Info(context.Background(), "request", zap.String("route", "/c"))
+
+	var routes []interface{}
+	for _, e := range decodeEntries(t, buf) {
+		if e["msg"] == "request" {
+			routes = append(routes, e["route"])
+		}
+	}
+	if fmt.Sprint(routes) != "[/a <high-cardinality> /c]" {
+		t.Errorf("expected the limit to apply again after the reset, got %v", routes)
+	}
+}
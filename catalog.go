@@ -0,0 +1,99 @@
+package jogger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Message is an entry of the message catalog, see RegisterMessage.
+type Message struct {
+	Code     string        `json:"code"`
+	Template string        `json:"template"`
+	Level    zapcore.Level `json:"level"`
+}
+
+var (
+	catalogMu     sync.Mutex
+	catalog       atomic.Value // map[string]Message, replaced on every change
+	catalogFrozen int32
+)
+
+func init() {
+	catalog.Store(map[string]Message{})
+}
+
+// RegisterMessage adds code to the message catalog: Log with code then
+// logs template as the message, at level, with a code field. The {key}
+// placeholders of template are filled from the fields of each Log call,
+// as with WithMessageTemplates, and the fields are still written as
+// fields. Registering a code again replaces it.
+//
+// Codes are best registered during program initialization. After Freeze
+// the catalog does not change and RegisterMessage returns ErrFrozen.
+func RegisterMessage(code, template string, level zapcore.Level) error {
+	if code == "" {
+		return errors.New("jogger: message code must not be empty")
+	}
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	if atomic.LoadInt32(&catalogFrozen) != 0 {
+		return ErrFrozen
+	}
+	cur := catalog.Load().(map[string]Message)
+	next := make(map[string]Message, len(cur)+1)
+	for c, m := range cur {
+		next[c] = m
+	}
+	next[code] = Message{Code: code, Template: template, Level: level}
+	catalog.Store(next)
+	return nil
+}
+
+// Messages returns the message catalog, sorted by code.
+func Messages() []Message {
+	cur := catalog.Load().(map[string]Message)
+	out := make([]Message, 0, len(cur))
+	for _, m := range cur {
+		out = append(out, m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Code < out[j].Code })
+	return out
+}
+
+// DumpMessages writes the message catalog to w as a JSON array sorted by
+// code, for generating documentation.
+func DumpMessages(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(Messages())
+}
+
+// Log logs the catalog message registered for code, at its level, with a
+// code field. An unregistered code is logged at Warn with the code as the
+// message and unknown_code=true, and reported in development mode.
+func Log(ctx context.Context, code string, fields ...zap.Field) {
+	ctx = goroutineFallback(orBackground(ctx))
+	checkFields(ctx, fields)
+	m, ok := catalog.Load().(map[string]Message)[code]
+	if !ok {
+		outputFor(ctx).misuse("jogger: unregistered message code", zap.String("code", code))
+		m = Message{Code: code, Template: code, Level: zapcore.WarnLevel}
+		fields = append(fields[:len(fields):len(fields)], zap.Bool("unknown_code", true))
+	}
+	fields = requireRequestID(ctx, m.Level, fields)
+	if ce := FromContext(ctx).Check(m.Level, m.Template); ce != nil {
+		if ok {
+			ce.Message = interpolate(ce.Message, fields)
+		}
+		ce.Write(append(fields[:len(fields):len(fields)], zap.String("code", code))...)
+	}
+}
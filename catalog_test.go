@@ -0,0 +1,103 @@
+package jogger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLogRegisteredMessage(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	if err := jogger.RegisterMessage("PAY-001", "payment declined", zapcore.WarnLevel); err != nil {
+		t.Fatal(err)
+	}
+
+	jogger.Log(context.Background(), "PAY-001", zap.String("card", "visa"))
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e["msg"] != "payment declined" || e["level"] != "warn" || e["code"] != "PAY-001" || e["card"] != "visa" {
+		t.Errorf("unexpected entry %v", e)
+	}
+	if _, ok := e["unknown_code"]; ok {
+		t.Errorf("expected no unknown_code on a registered code, got %v", e)
+	}
+}
+
+func TestLogRespectsRegisteredLevel(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	if err := jogger.RegisterMessage("CACHE-001", "cache refreshed", zapcore.DebugLevel); err != nil {
+		t.Fatal(err)
+	}
+
+	jogger.Log(context.Background(), "CACHE-001")
+
+	if entries := decodeEntries(t, buf); len(entries) != 0 {
+		t.Errorf("expected the debug message filtered, got %v", entries)
+	}
+}
+
+func TestLogUnknownCode(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	jogger.Log(context.Background(), "NOPE-404")
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e["msg"] != "NOPE-404" || e["level"] != "warn" || e["code"] != "NOPE-404" || e["unknown_code"] != true {
+		t.Errorf("unexpected entry %v", e)
+	}
+}
+
+func TestLogUnknownCodeInDevelopment(t *testing.T) {
+	configureBuffer(t, jogger.WithDevelopment())
+	expectPanic(t, "unregistered code", func() {
+		jogger.Log(context.Background(), "NOPE-500")
+	})
+}
+
+func TestRegisterMessageRejectsEmptyCode(t *testing.T) {
+	if err := jogger.RegisterMessage("", "no code", zapcore.InfoLevel); err == nil {
+		t.Error("expected an error for an empty code")
+	}
+}
+
+func TestDumpMessages(t *testing.T) {
+	for _, m := range []jogger.Message{
+		{Code: "DUMP-002", Template: "second", Level: zapcore.ErrorLevel},
+		{Code: "DUMP-001", Template: "first", Level: zapcore.InfoLevel},
+	} {
+		if err := jogger.RegisterMessage(m.Code, m.Template, m.Level); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jogger.DumpMessages(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var dumped []map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &dumped); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	var got []map[string]string
+	for _, m := range dumped {
+		if m["code"] == "DUMP-001" || m["code"] == "DUMP-002" {
+			got = append(got, m)
+		}
+	}
+	if len(got) != 2 || got[0]["code"] != "DUMP-001" || got[1]["template"] != "second" || got[1]["level"] != "error" {
+		t.Errorf("expected both messages sorted by code, got %v", got)
+	}
+}
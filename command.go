@@ -0,0 +1,140 @@
+package jogger
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultMaxLines is the number of output lines a CmdRunner logs before it
+// starts dropping them.
+const DefaultMaxLines = 1000
+
+// maxLineBytes bounds how much of a line without a newline is buffered
+// before it is logged anyway.
+const maxLineBytes = 64 << 10
+
+// CmdRunner runs an external command and logs its output.
+type CmdRunner struct {
+	// Cmd is the command to run. Set Dir, Env or Stdin on it before Run;
+	// Stdout and Stderr are replaced.
+	Cmd *exec.Cmd
+	// MaxLines caps the output lines logged over both streams. Further
+	// lines are counted and reported in a single entry when the command
+	// exits. Zero or less means no cap.
+	MaxLines int
+
+	ctx context.Context
+}
+
+// Command returns a CmdRunner for exec.CommandContext(ctx, name, arg...).
+func Command(ctx context.Context, name string, arg ...string) *CmdRunner {
+	ctx = orBackground(ctx)
+	return &CmdRunner{
+		Cmd:      exec.CommandContext(ctx, name, arg...),
+		MaxLines: DefaultMaxLines,
+		ctx:      ctx,
+	}
+}
+
+// Run starts the command and waits for it inside a span tagged with the
+// command name and exit code. Each line the command writes to stdout is
+// logged at Info and each stderr line at Warn, with a cmd field. A final
+// line without a newline is logged when the command exits.
+func (r *CmdRunner) Run() (err error) {
+	name := r.Cmd.Path
+	if len(r.Cmd.Args) > 0 {
+		name = r.Cmd.Args[0]
+	}
+
+	span, ctx := StartSpan(r.ctx, "exec")
+	defer span.Finish(&err)
+	span.SetTag("cmd", name)
+
+	out := &cmdOutput{
+		logger:   FromContext(ctx).With(zap.String("cmd", name)),
+		maxLines: r.MaxLines,
+	}
+	stdout := &lineWriter{out: out, level: zapcore.InfoLevel}
+	stderr := &lineWriter{out: out, level: zapcore.WarnLevel}
+	r.Cmd.Stdout, r.Cmd.Stderr = stdout, stderr
+
+	err = r.Cmd.Run()
+	stdout.flush()
+	stderr.flush()
+	out.reportDropped()
+
+	if r.Cmd.ProcessState != nil {
+		span.SetTag("exit_code", r.Cmd.ProcessState.ExitCode())
+	}
+	return err
+}
+
+// cmdOutput is shared by both streams of a command, whose writers exec
+// calls from separate goroutines.
+type cmdOutput struct {
+	logger   *zap.Logger
+	maxLines int
+
+	mu      sync.Mutex
+	lines   int
+	dropped int
+}
+
+func (o *cmdOutput) log(lvl zapcore.Level, line []byte) {
+	o.mu.Lock()
+	if o.maxLines > 0 && o.lines >= o.maxLines {
+		o.dropped++
+		o.mu.Unlock()
+		return
+	}
+	o.lines++
+	o.mu.Unlock()
+
+	if ce := o.logger.Check(lvl, string(line)); ce != nil {
+		ce.Write()
+	}
+}
+
+func (o *cmdOutput) reportDropped() {
+	if o.dropped > 0 {
+		o.logger.Warn("command output truncated", zap.Int("droppedLines", o.dropped))
+	}
+}
+
+// lineWriter splits a stream into lines and logs each one at level.
+type lineWriter struct {
+	out   *cmdOutput
+	level zapcore.Level
+	buf   []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.buf = append(w.buf, p...)
+			if len(w.buf) >= maxLineBytes {
+				w.flush()
+			}
+			break
+		}
+		w.buf = append(w.buf, p[:i]...)
+		w.flush()
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+func (w *lineWriter) flush() {
+	if len(w.buf) == 0 {
+		return
+	}
+	w.out.log(w.level, bytes.TrimSuffix(w.buf, []byte{'\r'}))
+	w.buf = w.buf[:0]
+}
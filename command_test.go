@@ -0,0 +1,84 @@
+package jogger_test
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+)
+
+func shell(t *testing.T, ctx context.Context, script string) *jogger.CmdRunner {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	return jogger.Command(ctx, "sh", "-c", script)
+}
+
+func TestCommandLogsOutput(t *testing.T) {
+	buf := configureSyncBuffer(t)
+
+	ctx := jogger.WithRequestID(context.Background(), "req-cmd")
+	err := shell(t, ctx, `echo first; echo oops >&2; printf 'no newline'`).Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries := decodeEntries(t, bytes.NewBufferString(buf.String()))
+	if len(entries) != 4 {
+		t.Fatalf("expected three output lines and the span, got %d", len(entries))
+	}
+	byMsg := map[string]map[string]interface{}{}
+	for _, e := range entries[:3] {
+		byMsg[e["msg"].(string)] = e
+	}
+	if e := byMsg["first"]; e == nil || e["level"] != "info" || e["cmd"] != "sh" || e["requestID"] != "req-cmd" {
+		t.Errorf("unexpected stdout entry %v", e)
+	}
+	if e := byMsg["oops"]; e == nil || e["level"] != "warn" {
+		t.Errorf("unexpected stderr entry %v", e)
+	}
+	if byMsg["no newline"] == nil {
+		t.Errorf("expected the partial final line to be logged, got %v", entries)
+	}
+
+	span := entries[3]
+	if span["span"] != "exec" || span["cmd"] != "sh" || span["exit_code"] != float64(0) {
+		t.Errorf("unexpected span entry %v", span)
+	}
+}
+
+func TestCommandExitCode(t *testing.T) {
+	buf := configureSyncBuffer(t)
+
+	if err := shell(t, context.Background(), "exit 3").Run(); err == nil {
+		t.Fatal("expected an error for a failing command")
+	}
+	e := decodeEntries(t, bytes.NewBufferString(buf.String()))[0]
+	if e["level"] != "error" || e["exit_code"] != float64(3) {
+		t.Errorf("unexpected span entry %v", e)
+	}
+}
+
+func TestCommandMaxLines(t *testing.T) {
+	buf := configureSyncBuffer(t)
+
+	r := shell(t, context.Background(), "for i in 1 2 3 4 5; do echo line $i; done")
+	r.MaxLines = 2
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := decodeEntries(t, bytes.NewBufferString(buf.String()))
+	if len(entries) != 4 {
+		t.Fatalf("expected two lines, the truncation notice and the span, got %d", len(entries))
+	}
+	if entries[1]["msg"] != "line 2" {
+		t.Errorf("expected the first lines to be kept, got %v", entries[1])
+	}
+	if entries[2]["msg"] != "command output truncated" || entries[2]["droppedLines"] != float64(3) {
+		t.Errorf("unexpected truncation entry %v", entries[2])
+	}
+}
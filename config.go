@@ -0,0 +1,271 @@
+package jogger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	FormatConsole = "console"
+	FormatJSON    = "json"
+)
+
+const (
+	EnvLevel  = "JOGGER_LEVEL"
+	EnvFormat = "JOGGER_FORMAT"
+)
+
+const defaultSlowSpanThreshold = 1 * time.Second
+
+var (
+	level             = zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	slowSpanThreshold = int64(defaultSlowSpanThreshold)
+	slowSpanLevel     = zap.NewAtomicLevelAt(zapcore.WarnLevel)
+
+	debugMu  sync.RWMutex
+	debugIDs = map[string]struct{}{}
+)
+
+// SetLevel changes the minimum level of the base logger. It is safe to call
+// while other goroutines are logging.
+func SetLevel(l zapcore.Level) {
+	level.SetLevel(l)
+}
+
+// Level returns the current minimum level of the base logger.
+func Level() zapcore.Level {
+	return level.Level()
+}
+
+// SetSlowSpanThreshold sets the duration after which a finished span is
+// reported as slow. Non-positive values restore the default.
+func SetSlowSpanThreshold(d time.Duration) {
+	if d <= 0 {
+		d = defaultSlowSpanThreshold
+	}
+	atomic.StoreInt64(&slowSpanThreshold, int64(d))
+}
+
+// SlowSpanThreshold returns the duration after which a span is reported as
+// slow.
+func SlowSpanThreshold() time.Duration {
+	return time.Duration(atomic.LoadInt64(&slowSpanThreshold))
+}
+
+// SetSlowSpanLevel sets the level slow spans finish at, Warn by default.
+// SlowLevel overrides it for the spans of a name. It is safe to call while
+// other goroutines are logging.
+func SetSlowSpanLevel(l zapcore.Level) {
+	slowSpanLevel.SetLevel(l)
+}
+
+// SlowSpanLevel returns the level slow spans finish at.
+func SlowSpanLevel() zapcore.Level {
+	return slowSpanLevel.Level()
+}
+
+// EnableRequestDebug makes every log and span carrying requestID emit at
+// Debug level regardless of the base level.
+func EnableRequestDebug(requestID string) {
+	if requestID == "" {
+		return
+	}
+	debugMu.Lock()
+	debugIDs[requestID] = struct{}{}
+	debugMu.Unlock()
+}
+
+// DisableRequestDebug reverts requestID to the base level.
+func DisableRequestDebug(requestID string) {
+	debugMu.Lock()
+	delete(debugIDs, requestID)
+	debugMu.Unlock()
+}
+
+// RequestDebugEnabled reports whether requestID has debug logging enabled.
+func RequestDebugEnabled(requestID string) bool {
+	if requestID == "" {
+		return false
+	}
+	debugMu.RLock()
+	_, ok := debugIDs[requestID]
+	debugMu.RUnlock()
+	return ok
+}
+
+// DebugRequestIDs returns the request IDs with debug logging enabled, sorted.
+func DebugRequestIDs() []string {
+	debugMu.RLock()
+	ids := make([]string, 0, len(debugIDs))
+	for id := range debugIDs {
+		ids = append(ids, id)
+	}
+	debugMu.RUnlock()
+	sort.Strings(ids)
+	return ids
+}
+
+type config struct {
+	level            zapcore.Level
+	levelSource      string
+	format           string
+	formatSource     string
+	writer           io.Writer
+	maxFieldLength   int
+	maxEntrySize     int
+	escaping         *bool
+	stripANSI        bool
+	goroutineID      bool
+	fieldNames       FieldNames
+	development      bool
+	louderContexts   bool
+	errorBudget      *ErrorBudgetConfig
+	baggagePrefix    string
+	exitSummary      io.Writer
+	stack            *StackConfig
+	spanTimestamps   *bool
+	clockSkew        *time.Duration
+	requireRequestID bool
+	zapOptions       []zap.Option
+	coreWrappers     []func(zapcore.Core) zapcore.Core
+	sampleRate       float64
+	tailSampling     int
+	messageTemplates bool
+	dedupWindow      time.Duration
+	dedupScope       DedupScope
+	cardinalityKeys  []string
+	cardinalityMax   int
+	cardinalityReset time.Duration
+	auditWriter      io.Writer
+	auditMode        AuditMode
+	auditSchema      []string
+	auditDurability  *AuditDurability
+	eventSink        *SinkConfig
+	sinks            []SinkConfig
+	routes           []route
+	sinkFields       map[string][]zap.Field
+	owned            []io.Closer
+	fields           []zap.Field
+}
+
+// An Option changes one aspect of the configuration built by Configure.
+type Option func(*config) error
+
+func defaultConfig() config {
+	env, _ := readEnv()
+	return config{
+		level:        env.level,
+		levelSource:  env.levelSource,
+		format:       env.format,
+		formatSource: env.formatSource,
+		writer:       os.Stdout,
+		sampleRate:   1,
+		fieldNames:   defaultFieldNames,
+	}
+}
+
+// ErrFrozen is returned by Configure and RegisterMessage after Freeze.
+var ErrFrozen = errors.New("jogger: configuration is frozen")
+
+// Freeze makes every later Configure call fail with ErrFrozen, so that
+// libraries cannot replace the configuration an application set up. The
+// message catalog is frozen too. The level can still be changed at
+// runtime, and SIGHUP still reloads it.
+func Freeze() {
+	std.Freeze()
+	atomic.StoreInt32(&catalogFrozen, 1)
+}
+
+// Configure replaces the logger configuration. Each call starts from the
+// defaults, taken from JOGGER_LEVEL and JOGGER_FORMAT, and applies opts in
+// order. If any option is invalid the current configuration is kept and
+// the error is returned.
+//
+// Configure may be called while other goroutines are logging. The new
+// sinks and cores are built completely before they are swapped in at once,
+// so every entry is written with either the old or the new configuration.
+// Concurrent Configure calls are applied one after the other.
+func Configure(opts ...Option) error {
+	return std.Configure(opts...)
+}
+
+// WithLevel sets the minimum level.
+func WithLevel(l zapcore.Level) Option {
+	return func(c *config) error {
+		c.level = l
+		c.levelSource = sourceConfigure
+		return nil
+	}
+}
+
+// WithFormat selects the encoder, FormatConsole or FormatJSON.
+func WithFormat(format string) Option {
+	return func(c *config) error {
+		if format != FormatConsole && format != FormatJSON {
+			return fmt.Errorf("jogger: unknown format %q", format)
+		}
+		c.format = format
+		c.formatSource = sourceConfigure
+		return nil
+	}
+}
+
+// WithOutput writes entries to w instead of stdout.
+func WithOutput(w io.Writer) Option {
+	return func(c *config) error {
+		if w == nil {
+			return errors.New("jogger: nil output")
+		}
+		c.writer = w
+		return nil
+	}
+}
+
+type envConfig struct {
+	level        zapcore.Level
+	levelSource  string
+	format       string
+	formatSource string
+}
+
+// readEnv reads JOGGER_LEVEL and JOGGER_FORMAT. Unset or invalid variables
+// fall back to the defaults; invalid ones are also reported in the error.
+func readEnv() (envConfig, error) {
+	cfg := envConfig{
+		level:        zapcore.InfoLevel,
+		levelSource:  sourceDefault,
+		format:       FormatConsole,
+		formatSource: sourceDefault,
+	}
+	var err error
+
+	if v := os.Getenv(EnvLevel); v != "" {
+		var l zapcore.Level
+		if uerr := l.UnmarshalText([]byte(v)); uerr != nil {
+			err = fmt.Errorf("jogger: invalid %s %q", EnvLevel, v)
+		} else {
+			cfg.level = l
+			cfg.levelSource = sourceEnv
+		}
+	}
+
+	if v := os.Getenv(EnvFormat); v != "" {
+		if v != FormatConsole && v != FormatJSON {
+			err = fmt.Errorf("jogger: invalid %s %q", EnvFormat, v)
+		} else {
+			cfg.format = v
+			cfg.formatSource = sourceEnv
+		}
+	}
+
+	return cfg, err
+}
@@ -0,0 +1,32 @@
+package jogger
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestFreeze(t *testing.T) {
+	defer func() {
+		std.mu.Lock()
+		std.frozen = false
+		std.mu.Unlock()
+		atomic.StoreInt32(&catalogFrozen, 0)
+		Configure()
+	}()
+	if err := Configure(WithFormat(FormatJSON)); err != nil {
+		t.Fatal(err)
+	}
+
+	Freeze()
+	if err := Configure(WithFormat(FormatConsole)); err != ErrFrozen {
+		t.Fatalf("expected ErrFrozen, got %v", err)
+	}
+	if f := currentOutput().cfg.format; f != FormatJSON {
+		t.Errorf("expected the frozen configuration to be kept, got format %q", f)
+	}
+	if err := RegisterMessage("frozen.test", "too late", zapcore.InfoLevel); err != ErrFrozen {
+		t.Errorf("expected the message catalog frozen, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package jogger_test
+
+import (
+	"context"
+	"io/ioutil"
+	"sync"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+)
+
+// TestConfigureWhileLogging is meant for -race: loggers, spans and
+// Configure calls run concurrently.
+func TestConfigureWhileLogging(t *testing.T) {
+	if err := jogger.Configure(jogger.WithOutput(ioutil.Discard)); err != nil {
+		t.Fatal(err)
+	}
+	defer jogger.Configure()
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := jogger.WithRequestID(context.Background(), "stress")
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				span, sctx := jogger.StartSpan(ctx, "work")
+				jogger.Info(sctx, "working", zap.Int("n", 1))
+				jogger.Error(sctx, "failed")
+				span.Finish(nil)
+			}
+		}()
+	}
+
+	var cwg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		cwg.Add(1)
+		go func(i int) {
+			defer cwg.Done()
+			for j := 0; j < 50; j++ {
+				format := jogger.FormatJSON
+				if (i+j)%2 == 0 {
+					format = jogger.FormatConsole
+				}
+				if err := jogger.Configure(jogger.WithOutput(ioutil.Discard), jogger.WithFormat(format), jogger.WithDeduplication(0, jogger.DedupGlobal)); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(i)
+	}
+	cwg.Wait()
+	close(done)
+	wg.Wait()
+}
@@ -0,0 +1,47 @@
+package jogger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// contextKey is the type of the keys jogger stores its context values
+// under. Being unexported and compared by pointer, it cannot collide with
+// the keys of other packages, and values can only be set through the
+// functions that validate them, such as WithRequestID.
+type contextKey struct {
+	name string
+}
+
+func (k *contextKey) String() string {
+	return "jogger." + k.name
+}
+
+// legacyScope returns the scope of a context no scope was stored in, made
+// of the values stored under the deprecated exported keys.
+func legacyScope(ctx context.Context) Scope {
+	var s Scope
+	s.RequestID, _ = ctx.Value(RequestIDKey).(string)
+	s.SpanID, _ = ctx.Value(SpanKey).(string)
+	s.zap, _ = ctx.Value(LoggerKey).(*zap.Logger)
+	s.name, _ = ctx.Value(NameKey).(string)
+	s.userID, _ = ctx.Value(UserIDKey).(string)
+	s.tenantID, _ = ctx.Value(TenantIDKey).(string)
+	s.worker = ctx.Value(WorkerIDKey)
+	return s
+}
+
+// SpanID returns the ID of the current span of ctx, or "" outside a span.
+func SpanID(ctx context.Context) string {
+	return scopeOf(orBackground(ctx)).SpanID
+}
+
+// WithZapLogger returns a copy of ctx whose FromContext returns l as is,
+// instead of a logger built from the configuration.
+func WithZapLogger(ctx context.Context, l *zap.Logger) context.Context {
+	ctx = orBackground(ctx)
+	s := scopeOf(ctx)
+	s.zap = l
+	return withScope(ctx, s)
+}
@@ -0,0 +1,131 @@
+package jogger_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestDeprecatedContextKeysStillRead(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	ctx := context.WithValue(context.Background(), jogger.RequestIDKey, "req-old")
+	ctx = context.WithValue(ctx, jogger.SpanKey, "span-old")
+	ctx = context.WithValue(ctx, jogger.NameKey, "legacy")
+	if jogger.RequestID(ctx) != "req-old" || jogger.SpanID(ctx) != "span-old" {
+		t.Errorf("expected the accessors to read the old keys, got %q and %q", jogger.RequestID(ctx), jogger.SpanID(ctx))
+	}
+	jogger.Info(ctx, "old keys")
+
+	e := decodeEntries(t, buf)[0]
+	if e["requestID"] != "req-old" || e["span"] != "span-old" || e["logger"] != "legacy" {
+		t.Errorf("expected the old-style values on the entry, got %v", e)
+	}
+}
+
+func TestContextKeysPreferAccessors(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	ctx := context.WithValue(context.Background(), jogger.RequestIDKey, "req-old")
+	ctx = context.WithValue(ctx, jogger.NameKey, "legacy")
+	ctx = jogger.WithRequestID(ctx, "req-new")
+	span, ctx := jogger.StartSpan(ctx, "keys")
+	ctx = context.WithValue(ctx, jogger.SpanKey, "span-old")
+	jogger.Info(ctx, "new keys")
+	span.Finish(nil)
+
+	if jogger.RequestID(ctx) != "req-new" {
+		t.Errorf("expected WithRequestID to take precedence, got %q", jogger.RequestID(ctx))
+	}
+	e := decodeEntries(t, buf)[0]
+	if e["requestID"] != "req-new" || e["span"] == "span-old" || e["span"] != jogger.SpanID(ctx) {
+		t.Errorf("expected the values set through jogger, got %v", e)
+	}
+	if e["logger"] != "legacy" {
+		t.Errorf("expected the old logger name to still apply, got %v", e["logger"])
+	}
+}
+
+func TestContextKeysCarriedIntoScope(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	ctx := context.WithValue(context.Background(), jogger.RequestIDKey, "req-old")
+	ctx = jogger.Named(ctx, "worker")
+	ctx = context.WithValue(ctx, jogger.RequestIDKey, "req-late")
+	if jogger.RequestID(ctx) != "req-old" {
+		t.Errorf("expected the request ID carried into the scope, got %q", jogger.RequestID(ctx))
+	}
+	jogger.Info(ctx, "old key")
+
+	e := decodeEntries(t, buf)[0]
+	if e["requestID"] != "req-old" || e["logger"] != "worker" {
+		t.Errorf("expected the request ID of the old key, got %v", e)
+	}
+}
+
+func TestFromContextReusesLogger(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	ctx := jogger.WithRequestID(context.Background(), "req-1")
+	l := jogger.FromContext(ctx)
+	if jogger.FromContext(ctx) != l {
+		t.Error("expected the logger of a context to be built once")
+	}
+	defer jogger.SetLevel(jogger.Level())
+	jogger.SetLevel(zapcore.DebugLevel)
+	if jogger.FromContext(ctx) == l {
+		t.Error("expected the logger built again after the level changed")
+	}
+
+	jogger.Info(jogger.WithUserID(ctx, "u-1"), "late user")
+	if e := decodeEntries(t, buf)[0]; e["requestID"] != "req-1" || e["userID"] != "u-1" {
+		t.Errorf("expected a correlation value set after the scope, got %v", e)
+	}
+}
+
+func TestWithZapLogger(t *testing.T) {
+	custom := zap.NewNop()
+	ctx := context.WithValue(context.Background(), jogger.LoggerKey, zap.NewExample())
+	ctx = jogger.WithZapLogger(ctx, custom)
+	if jogger.FromContext(ctx) != custom {
+		t.Error("expected WithZapLogger to take precedence over LoggerKey")
+	}
+	if jogger.FromContext(jogger.Named(ctx, "worker")) == custom {
+		t.Error("expected Named to name a copy of the logger")
+	}
+}
+
+func TestContextKeysDoNotCollide(t *testing.T) {
+	type otherKey string
+	ctx := context.WithValue(context.Background(), otherKey("requestID"), "foreign")
+	if jogger.RequestID(ctx) != "" {
+		t.Errorf("expected keys of other packages to be ignored, got %q", jogger.RequestID(ctx))
+	}
+}
+
+func TestDeprecatedKeysKeptInStep(t *testing.T) {
+	ctx := jogger.WithUserID(jogger.WithRequestID(context.Background(), "req-1"), "u-1")
+	ctx = jogger.WithWorkerID(jogger.WithTenantID(ctx, "acme"), 3)
+	span, ctx := jogger.StartSpan(ctx, "keys")
+	defer span.Finish(nil)
+
+	for key, want := range map[jogger.ContextKey]interface{}{
+		jogger.RequestIDKey: "req-1",
+		jogger.SpanKey:      jogger.SpanID(ctx),
+		jogger.UserIDKey:    "u-1",
+		jogger.TenantIDKey:  "acme",
+		jogger.WorkerIDKey:  3,
+	} {
+		if got := ctx.Value(key); got != want {
+			t.Errorf("expected %v under %s, got %v", want, key, got)
+		}
+	}
+
+	legacy := context.WithValue(context.Background(), jogger.UserIDKey, "u-old")
+	if jogger.UserID(legacy) != "u-old" || jogger.UserID(jogger.WithRequestID(legacy, "req-2")) != "u-old" {
+		t.Error("expected a user ID stored under the deprecated key to be read")
+	}
+}
@@ -0,0 +1,71 @@
+package jogger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// WithMinLevel returns a copy of ctx whose loggers, from FromContext and
+// StartSpan, drop entries below level, so a host can quiet a noisy call
+// tree without touching the global level. Entries below the global level
+// stay dropped: a context is only made quieter, unless the configuration
+// has WithLouderContexts. The innermost WithMinLevel of a context applies.
+// The sampling decision is not affected: a sampled-out request still
+// leaves out its access log and successful spans. A logger stored with
+// WithZapLogger is returned as is.
+func WithMinLevel(ctx context.Context, level zapcore.Level) context.Context {
+	ctx = orBackground(ctx)
+	s := scopeOf(ctx)
+	s.level = &level
+	return withScope(ctx, s)
+}
+
+// WithLouderContexts lets WithMinLevel lower the level below the global
+// one, so that a context can turn on Debug for its call tree, as
+// EnableRequestDebug does for a request. It is off by default.
+func WithLouderContexts() Option {
+	return func(c *config) error {
+		c.louderContexts = true
+		return nil
+	}
+}
+
+// withContextLevel applies the level of WithMinLevel to l, a logger of o.
+func (s *Scope) withContextLevel(o *output, l *zap.Logger) *zap.Logger {
+	if s.level == nil || s.zap != nil {
+		return l
+	}
+	lvl := *s.level
+	if lvl < Level() && o.cfg.louderContexts {
+		l = o.debug
+		if s.name != "" {
+			l = l.Named(s.name)
+		}
+	}
+	return l.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return minLevelCore{c, lvl}
+	}))
+}
+
+// minLevelCore drops the entries below its level.
+type minLevelCore struct {
+	zapcore.Core
+	min zapcore.Level
+}
+
+func (c minLevelCore) Enabled(lvl zapcore.Level) bool {
+	return lvl >= c.min && c.Core.Enabled(lvl)
+}
+
+func (c minLevelCore) With(fields []zapcore.Field) zapcore.Core {
+	return minLevelCore{c.Core.With(fields), c.min}
+}
+
+func (c minLevelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level < c.min {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
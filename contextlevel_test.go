@@ -0,0 +1,91 @@
+package jogger_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap/zapcore"
+)
+
+func messages(entries []map[string]interface{}) []interface{} {
+	var msgs []interface{}
+	for _, e := range entries {
+		msgs = append(msgs, e["msg"])
+	}
+	return msgs
+}
+
+func TestWithMinLevelQuiets(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	ctx := jogger.WithMinLevel(context.Background(), zapcore.WarnLevel)
+
+	jogger.Info(ctx, "noisy")
+	jogger.Warn(ctx, "kept")
+	span, spanCtx := jogger.StartSpan(ctx, "sub.call")
+	jogger.Info(spanCtx, "noisy in span")
+	span.Finish(nil)
+	failed, _ := jogger.StartSpan(ctx, "sub.call")
+	err := errors.New("boom")
+	failed.Finish(&err)
+	jogger.Info(context.Background(), "elsewhere")
+
+	got := messages(decodeEntries(t, buf))
+	want := []interface{}{"kept", "span finished with error", "elsewhere"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestWithMinLevelCannotBeLouderByDefault(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	ctx := jogger.WithMinLevel(context.Background(), zapcore.DebugLevel)
+
+	jogger.Debug(ctx, "hidden")
+	jogger.Info(ctx, "shown")
+
+	if got := messages(decodeEntries(t, buf)); len(got) != 1 || got[0] != "shown" {
+		t.Errorf("expected the global level to still apply, got %v", got)
+	}
+}
+
+func TestWithMinLevelInnermostApplies(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	ctx := jogger.WithMinLevel(context.Background(), zapcore.ErrorLevel)
+	ctx = jogger.WithMinLevel(ctx, zapcore.InfoLevel)
+
+	jogger.Info(ctx, "shown")
+
+	if got := messages(decodeEntries(t, buf)); len(got) != 1 {
+		t.Errorf("expected the inner level to apply, got %v", got)
+	}
+}
+
+func TestWithLouderContexts(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithLouderContexts())
+	ctx := jogger.WithMinLevel(context.Background(), zapcore.DebugLevel)
+
+	jogger.Debug(ctx, "louder")
+	jogger.Debug(context.Background(), "hidden")
+
+	if got := messages(decodeEntries(t, buf)); len(got) != 1 || got[0] != "louder" {
+		t.Errorf("expected Debug for the context only, got %v", got)
+	}
+}
+
+func TestWithMinLevelKeepsSampling(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithLouderContexts(), jogger.WithSampling(0))
+	ctx := jogger.WithMinLevel(jogger.EnsureRequestID(context.Background()), zapcore.DebugLevel)
+	if jogger.IsSampled(ctx) {
+		t.Fatal("expected the request sampled out")
+	}
+
+	span, spanCtx := jogger.StartSpan(ctx, "sub.call")
+	jogger.Debug(spanCtx, "detail")
+	span.Finish(nil)
+
+	if got := messages(decodeEntries(t, buf)); len(got) != 1 || got[0] != "detail" {
+		t.Errorf("expected the sampled-out span finish left out, got %v", got)
+	}
+}
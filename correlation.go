@@ -0,0 +1,225 @@
+package jogger
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// The keys of the user and tenant IDs, for RegisterCorrelationKey. The
+// values are kept in the scope of the context, and stored under the keys
+// only for code reading them directly.
+//
+// Deprecated: Use WithUserID and UserID, WithTenantID and TenantID to
+// store and read the values.
+const (
+	UserIDKey   ContextKey = "userID"
+	TenantIDKey ContextKey = "tenantID"
+)
+
+type correlationKey struct {
+	key    ContextKey
+	field  string
+	header string
+	boxed  interface{} // key as an interface, converted once
+	scoped bool        // set for the keys of scopedKeys
+}
+
+// scopedKeys are the correlation keys of this package. Their values are
+// kept in the scope rather than under the keys, so FromContext gets them
+// with the scope instead of looking each one up. The keys mapped to true
+// are exported and also hold the value, for code reading it directly.
+var scopedKeys = map[ContextKey]bool{
+	UserIDKey:          true,
+	TenantIDKey:        true,
+	clientRequestIDKey: false,
+	WorkerIDKey:        true,
+}
+
+// correlation returns the value of key, one of scopedKeys.
+func (s *Scope) correlation(key ContextKey) interface{} {
+	switch key {
+	case UserIDKey:
+		return s.userID
+	case TenantIDKey:
+		return s.tenantID
+	case clientRequestIDKey:
+		return s.client
+	}
+	return s.worker
+}
+
+// setCorrelation sets the value of key, one of scopedKeys.
+func (s *Scope) setCorrelation(key ContextKey, v interface{}) {
+	switch key {
+	case UserIDKey:
+		s.userID, _ = v.(string)
+	case TenantIDKey:
+		s.tenantID, _ = v.(string)
+	case clientRequestIDKey:
+		s.client, _ = v.(string)
+	default:
+		s.worker = v
+	}
+}
+
+func newCorrelationKey(key ContextKey, field string) correlationKey {
+	_, scoped := scopedKeys[key]
+	return correlationKey{key: key, field: field, boxed: key, scoped: scoped}
+}
+
+// value returns the value of k in ctx, whose scope is s.
+func (k *correlationKey) value(ctx context.Context, s *Scope) interface{} {
+	if k.scoped {
+		return s.correlation(k.key)
+	}
+	return ctx.Value(k.boxed)
+}
+
+// withScopedValue returns a copy of ctx with v as the value of key, one of
+// scopedKeys.
+func withScopedValue(ctx context.Context, key ContextKey, v interface{}) context.Context {
+	if scopedKeys[key] {
+		ctx = context.WithValue(ctx, key, v)
+	}
+	s := scopeOf(ctx)
+	s.setCorrelation(key, v)
+	return withScope(ctx, s)
+}
+
+// A CorrelationOption changes how a registered correlation key is handled.
+type CorrelationOption func(*correlationKey)
+
+// PropagateAs makes Inject and Extract carry the key across process
+// boundaries under the given header or metadata name.
+func PropagateAs(header string) CorrelationOption {
+	return func(k *correlationKey) {
+		k.header = header
+	}
+}
+
+var (
+	correlationMu     sync.Mutex
+	correlationKeys   atomic.Value
+	correlationFrozen int32
+)
+
+func init() {
+	correlationKeys.Store([]correlationKey{
+		newCorrelationKey(UserIDKey, "userID"),
+		newCorrelationKey(TenantIDKey, "tenantID"),
+		newCorrelationKey(clientRequestIDKey, "client_request_id"),
+		newCorrelationKey(WorkerIDKey, "worker"),
+	})
+}
+
+// RegisterCorrelationKey makes FromContext and StartSpan add the value stored
+// under key to every entry as fieldName, the same way userID and tenantID
+// are handled. Registering a key again replaces its settings.
+//
+// Keys must be registered during program initialization, typically from an
+// init function. The registry is frozen the first time it is used, and
+// RegisterCorrelationKey panics after that.
+func RegisterCorrelationKey(key ContextKey, fieldName string, opts ...CorrelationOption) {
+	correlationMu.Lock()
+	defer correlationMu.Unlock()
+
+	if atomic.LoadInt32(&correlationFrozen) != 0 {
+		panic("jogger: RegisterCorrelationKey called after the correlation registry was used")
+	}
+
+	reg := newCorrelationKey(key, fieldName)
+	for _, opt := range opts {
+		opt(&reg)
+	}
+
+	old := correlationKeys.Load().([]correlationKey)
+	keys := make([]correlationKey, 0, len(old)+1)
+	replaced := false
+	for _, k := range old {
+		if k.key == key {
+			k = reg
+			replaced = true
+		}
+		keys = append(keys, k)
+	}
+	if !replaced {
+		keys = append(keys, reg)
+	}
+	correlationKeys.Store(keys)
+}
+
+func registeredCorrelationKeys() []correlationKey {
+	if atomic.LoadInt32(&correlationFrozen) == 0 {
+		atomic.StoreInt32(&correlationFrozen, 1)
+	}
+	return correlationKeys.Load().([]correlationKey)
+}
+
+// correlationFields appends the correlation values and the baggage of ctx,
+// whose scope is s.
+func correlationFields(ctx context.Context, s *Scope, fields []zap.Field) []zap.Field {
+	return contextCorrelationFields(ctx, s.correlationFields(fields))
+}
+
+// correlationFields appends the correlation values kept in s.
+func (s *Scope) correlationFields(fields []zap.Field) []zap.Field {
+	for _, k := range registeredCorrelationKeys() {
+		if k.scoped {
+			fields = appendCorrelation(fields, k.field, s.correlation(k.key))
+		}
+	}
+	return fields
+}
+
+// contextCorrelationFields appends the values of the registered keys that
+// are stored in ctx under the keys themselves, and the baggage of ctx.
+func contextCorrelationFields(ctx context.Context, fields []zap.Field) []zap.Field {
+	for _, k := range registeredCorrelationKeys() {
+		if !k.scoped {
+			fields = appendCorrelation(fields, k.field, ctx.Value(k.boxed))
+		}
+	}
+	return baggageFields(ctx, fields)
+}
+
+func appendCorrelation(fields []zap.Field, key string, v interface{}) []zap.Field {
+	switch v := v.(type) {
+	case nil:
+	case string:
+		if v != "" {
+			fields = append(fields, zap.String(key, v))
+		}
+	default:
+		fields = append(fields, zap.Any(key, v))
+	}
+	return fields
+}
+
+// WithUserID stores the authenticated user's ID in ctx.
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return withScopedValue(orBackground(ctx), UserIDKey, userID)
+}
+
+// WithTenantID stores the tenant's ID in ctx.
+func WithTenantID(ctx context.Context, tenantID string) context.Context {
+	return withScopedValue(orBackground(ctx), TenantIDKey, tenantID)
+}
+
+// RequestID returns the request ID stored in ctx, or "".
+func RequestID(ctx context.Context) string {
+	id := scopeOf(orBackground(ctx)).RequestID
+	return id
+}
+
+// UserID returns the user ID stored in ctx, or "".
+func UserID(ctx context.Context) string {
+	return scopeOf(orBackground(ctx)).userID
+}
+
+// TenantID returns the tenant ID stored in ctx, or "".
+func TenantID(ctx context.Context) string {
+	return scopeOf(orBackground(ctx)).tenantID
+}
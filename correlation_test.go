@@ -0,0 +1,91 @@
+package jogger_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+)
+
+func TestUserAndTenantIDAccessors(t *testing.T) {
+	ctx := jogger.WithRequestID(context.Background(), "req-1")
+	ctx = jogger.WithUserID(ctx, "user-42")
+	ctx = jogger.WithTenantID(ctx, "acme")
+
+	if got := jogger.RequestID(ctx); got != "req-1" {
+		t.Errorf("expected request ID req-1, got %q", got)
+	}
+	if got := jogger.UserID(ctx); got != "user-42" {
+		t.Errorf("expected user ID user-42, got %q", got)
+	}
+	if got := jogger.TenantID(ctx); got != "acme" {
+		t.Errorf("expected tenant ID acme, got %q", got)
+	}
+	if got := jogger.UserID(context.Background()); got != "" {
+		t.Errorf("expected empty user ID, got %q", got)
+	}
+}
+
+func TestFromContextIncludesUserAndTenant(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	ctx := jogger.WithUserID(context.Background(), "user-42")
+	ctx = jogger.WithTenantID(ctx, "acme")
+	jogger.Info(ctx, "with ids")
+	jogger.Info(context.Background(), "without ids")
+
+	entries := decodeEntries(t, buf)
+	if entries[0]["userID"] != "user-42" || entries[0]["tenantID"] != "acme" {
+		t.Errorf("expected userID and tenantID fields, got %v", entries[0])
+	}
+	if _, ok := entries[1]["userID"]; ok {
+		t.Errorf("expected no userID field when unset, got %v", entries[1])
+	}
+}
+
+func TestStartSpanIncludesUserAndTenant(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	ctx := jogger.WithUserID(context.Background(), "user-42")
+	ctx = jogger.WithTenantID(ctx, "acme")
+	span, _ := jogger.StartSpan(ctx, "tenant-span")
+	span.Finish(nil)
+
+	e := decodeEntries(t, buf)[0]
+	if e["userID"] != "user-42" || e["tenantID"] != "acme" {
+		t.Errorf("expected userID and tenantID on span finish, got %v", e)
+	}
+}
+
+const sessionKey jogger.ContextKey = "test-session"
+
+// Correlation keys must be registered before the registry is first used.
+func init() {
+	jogger.RegisterCorrelationKey(sessionKey, "sessionID", jogger.PropagateAs("X-Session-ID"))
+}
+
+func TestRegisterCorrelationKey(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	ctx := context.WithValue(context.Background(), sessionKey, "sess-9")
+	jogger.Info(ctx, "registered key")
+	span, _ := jogger.StartSpan(ctx, "session-span")
+	span.Finish(nil)
+
+	for _, e := range decodeEntries(t, buf) {
+		if e["sessionID"] != "sess-9" {
+			t.Errorf("expected sessionID field, got %v", e)
+		}
+	}
+}
+
+func TestRegisterCorrelationKeyAfterUsePanics(t *testing.T) {
+	jogger.FromContext(context.Background())
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected RegisterCorrelationKey to panic once the registry is in use")
+		}
+	}()
+	jogger.RegisterCorrelationKey("too-late", "tooLate")
+}
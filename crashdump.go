@@ -0,0 +1,175 @@
+package jogger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxCrashEntries bounds the entries kept by EnableCrashDump.
+const maxCrashEntries = 1000
+
+// crashRecorder keeps the last entries written, encoded as JSON lines, in a
+// ring buffer.
+type crashRecorder struct {
+	path string
+	enc  zapcore.Encoder
+
+	mu      sync.Mutex
+	entries [][]byte
+	next    int
+	total   uint64
+}
+
+var crashDump atomic.Value // *crashRecorder
+
+func init() {
+	crashDump.Store((*crashRecorder)(nil))
+}
+
+// EnableCrashDump keeps the last 1000 entries logged by any instance in
+// memory, so that FlushOnPanic and Main can write them to path along with
+// the panic value and stack when the process dies from a panic. Those are
+// the entries explaining the crash, and the ones most likely to be lost in
+// an asynchronous sink. Enabling again discards the entries so far.
+func EnableCrashDump(path string) {
+	enc, _ := newEncoder(FormatJSON)
+	crashDump.Store(&crashRecorder{path: path, enc: enc, entries: make([][]byte, 0, maxCrashEntries)})
+}
+
+// DisableCrashDump stops keeping entries and discards them.
+func DisableCrashDump() {
+	crashDump.Store((*crashRecorder)(nil))
+}
+
+// FlushOnPanic calls fn. If fn panics, the crash dump of EnableCrashDump is
+// written, the output is synced and the panic continues. Panics in other
+// goroutines are not seen, so wrap their functions on their own.
+func FlushOnPanic(fn func()) {
+	defer func() {
+		if v := recover(); v != nil {
+			writeCrashDump(v, debug.Stack())
+			_ = Sync()
+			panic(v)
+		}
+	}()
+	fn()
+}
+
+// Main runs the main function of a program: it calls fn with FlushOnPanic,
+// then Shutdown. An error returned by fn is logged and makes the process
+// exit with status 1.
+//
+//	func main() {
+//		jogger.EnableCrashDump("/var/log/app/crash.log")
+//		jogger.Main(run)
+//	}
+func Main(fn func() error) {
+	var err error
+	FlushOnPanic(func() { err = fn() })
+	if err != nil {
+		Error(context.Background(), "exiting", zap.Error(err))
+	}
+	if serr := Shutdown(); serr != nil {
+		reportInternalError(serr)
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// writeCrashDump writes the panic, its stack and the kept entries to the
+// crash dump file, if one is enabled.
+func writeCrashDump(v interface{}, stack []byte) {
+	r := crashDump.Load().(*crashRecorder)
+	if r == nil || r.path == "" {
+		return
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "panic: %v\ntime: %s\n\n", v, time.Now().Format(time.RFC3339Nano))
+	buf.Write(stack)
+	entries, total := r.snapshot()
+	fmt.Fprintf(&buf, "\nlast %d of %d entries:\n", len(entries), total)
+	for _, e := range entries {
+		buf.Write(e)
+	}
+	if err := ioutil.WriteFile(r.path, buf.Bytes(), 0644); err != nil {
+		reportInternalError(err)
+	}
+}
+
+func (r *crashRecorder) record(ent zapcore.Entry, fields []zapcore.Field) {
+	b, err := r.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return
+	}
+	line := append([]byte(nil), b.Bytes()...)
+	b.Free()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.total++
+	if len(r.entries) < maxCrashEntries {
+		r.entries = append(r.entries, line)
+		return
+	}
+	r.entries[r.next] = line
+	r.next = (r.next + 1) % maxCrashEntries
+}
+
+// snapshot returns the kept entries, oldest first, and how many were
+// recorded in total.
+func (r *crashRecorder) snapshot() ([][]byte, uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([][]byte, 0, len(r.entries))
+	out = append(out, r.entries[r.next:]...)
+	out = append(out, r.entries[:r.next]...)
+	return out, r.total
+}
+
+// crashCore records entries for the crash dump while it is enabled. It
+// keeps the fields of With itself, so that it costs nothing more than a
+// slice append while disabled.
+type crashCore struct {
+	zapcore.Core
+	fields []zapcore.Field
+}
+
+func newCrashCore(c zapcore.Core) zapcore.Core {
+	return &crashCore{Core: c}
+}
+
+func (c *crashCore) With(fields []zapcore.Field) zapcore.Core {
+	return &crashCore{
+		Core:   c.Core.With(fields),
+		fields: append(c.fields[:len(c.fields):len(c.fields)], fields...),
+	}
+}
+
+func (c *crashCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *crashCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if r := crashDump.Load().(*crashRecorder); r != nil {
+		all := fields
+		if len(c.fields) > 0 {
+			all = append(c.fields[:len(c.fields):len(c.fields)], fields...)
+		}
+		r.record(ent, all)
+	}
+	return c.Core.Write(ent, fields)
+}
@@ -0,0 +1,86 @@
+package jogger_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+)
+
+// TestCrashDumpHelper is the crashing process of TestCrashDump, run only
+// when it sets JOGGER_CRASH_DUMP.
+func TestCrashDumpHelper(t *testing.T) {
+	path := os.Getenv("JOGGER_CRASH_DUMP")
+	if path == "" {
+		t.Skip("run by TestCrashDump")
+	}
+	jogger.EnableCrashDump(path)
+	jogger.Main(func() error {
+		for i := 0; i < 1005; i++ {
+			jogger.Info(jogger.WithRequestID(context.Background(), "req-crash"), fmt.Sprintf("step %d", i))
+		}
+		jogger.FromContext(context.Background()).With(zap.String("component", "worker")).Warn("about to fail")
+		panic("boom")
+	})
+}
+
+func TestCrashDump(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jogger-crash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "crash.log")
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestCrashDumpHelper$")
+	cmd.Env = append(os.Environ(), "JOGGER_CRASH_DUMP="+path)
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected the helper to crash, got\n%s", out)
+	}
+	if !strings.Contains(string(out), "panic: boom") {
+		t.Errorf("expected the panic to continue, got\n%s", out)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dump := string(b)
+	for _, want := range []string{
+		"panic: boom\n",
+		"jogger_test.TestCrashDumpHelper",
+		"last 1000 of 1006 entries:\n",
+		`"msg":"about to fail","component":"worker"`,
+		`"msg":"step 999","requestID":"req-crash"`,
+	} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("expected %q in the dump, got\n%s", want, dump)
+		}
+	}
+	if strings.Contains(dump, `"step 5"`) {
+		t.Error("expected the oldest entries to be dropped")
+	}
+	if strings.Index(dump, `"step 6"`) > strings.Index(dump, `"about to fail"`) {
+		t.Error("expected the entries oldest first")
+	}
+}
+
+func TestFlushOnPanicWithoutCrashDump(t *testing.T) {
+	jogger.DisableCrashDump()
+	expectPanic(t, "FlushOnPanic", func() {
+		jogger.FlushOnPanic(func() { panic("boom") })
+	})
+	ran := false
+	jogger.FlushOnPanic(func() { ran = true })
+	if !ran {
+		t.Error("expected fn to run")
+	}
+}
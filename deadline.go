@@ -0,0 +1,35 @@
+package jogger
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// deadlineFields reports how much of a deadline is left at now.
+type deadlineFields struct {
+	remaining time.Duration
+}
+
+func (d deadlineFields) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddFloat64("deadline_remaining_ms", float64(d.remaining)/float64(time.Millisecond))
+	enc.AddBool("had_deadline", true)
+	return nil
+}
+
+func deadlineField(deadline, now time.Time) zap.Field {
+	return zap.Inline(deadlineFields{deadline.Sub(now)})
+}
+
+// DeadlineField returns deadline_remaining_ms, negative once the deadline
+// has passed, and had_deadline=true for the deadline of ctx. It adds
+// nothing when ctx has no deadline.
+func DeadlineField(ctx context.Context) zap.Field {
+	deadline, ok := orBackground(ctx).Deadline()
+	if !ok {
+		return zap.Skip()
+	}
+	return deadlineField(deadline, time.Now())
+}
@@ -0,0 +1,68 @@
+package jogger_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+)
+
+func TestSpanDeadlineFields(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithLevel(zap.DebugLevel))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	span, _ := jogger.StartSpan(ctx, "budgeted")
+	span.Finish(nil)
+	plain, _ := jogger.StartSpan(context.Background(), "unbounded")
+	plain.Finish(nil)
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("expected two span entries, got %v", entries)
+	}
+	if remaining, _ := entries[0]["deadline_remaining_ms"].(float64); entries[0]["had_deadline"] != true || remaining < 59*60*1000 {
+		t.Errorf("expected about an hour left, got %v", entries[0])
+	}
+	if _, ok := entries[1]["deadline_remaining_ms"]; ok || entries[1]["had_deadline"] != nil {
+		t.Errorf("expected no deadline fields without a deadline, got %v", entries[1])
+	}
+}
+
+func TestSpanDeadlineExceeded(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	span, _ := jogger.StartSpan(ctx, "late")
+	<-ctx.Done()
+	err := ctx.Err()
+	span.Finish(&err)
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 1 {
+		t.Fatalf("expected one span entry, got %v", entries)
+	}
+	if remaining, _ := entries[0]["deadline_remaining_ms"].(float64); remaining >= 0 {
+		t.Errorf("expected a negative remaining time, got %v", entries[0])
+	}
+}
+
+func TestDeadlineField(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	jogger.Info(ctx, "with", jogger.DeadlineField(ctx))
+	jogger.Info(context.Background(), "without", jogger.DeadlineField(context.Background()))
+
+	entries := decodeEntries(t, buf)
+	if entries[0]["had_deadline"] != true || entries[0]["deadline_remaining_ms"] == nil {
+		t.Errorf("expected the deadline fields, got %v", entries[0])
+	}
+	if _, ok := entries[1]["had_deadline"]; ok {
+		t.Errorf("expected no deadline fields, got %v", entries[1])
+	}
+}
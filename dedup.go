@@ -0,0 +1,247 @@
+package jogger
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DedupScope selects which entries WithDeduplication compares.
+type DedupScope int
+
+const (
+	// DedupGlobal compares each Error entry with the previous one logged
+	// by the process.
+	DedupGlobal DedupScope = iota
+	// DedupPerRequest compares each Error entry with the previous one of
+	// the same request ID.
+	DedupPerRequest
+)
+
+// maxDedupScopes bounds the per-request state kept by DedupPerRequest.
+const maxDedupScopes = 1024
+
+// WithDeduplication collapses repeated Error entries. An entry with the
+// same level, message and error_fingerprint as the previous one in its
+// scope, written less than window ago, is suppressed and counted. The
+// count is reported in a "previous message repeated N times" entry when a
+// different Error entry arrives in the scope, when the window expires, and
+// on Shutdown. Zero disables deduplication.
+func WithDeduplication(window time.Duration, scope DedupScope) Option {
+	return func(c *config) error {
+		if window < 0 {
+			return errors.New("jogger: deduplication window must not be negative")
+		}
+		if scope != DedupGlobal && scope != DedupPerRequest {
+			return fmt.Errorf("jogger: unknown deduplication scope %d", scope)
+		}
+		c.dedupWindow = window
+		c.dedupScope = scope
+		return nil
+	}
+}
+
+// dedupState is the last written Error entry of a scope and how many
+// identical entries were suppressed since.
+type dedupState struct {
+	key        string
+	written    time.Time
+	ent        zapcore.Entry
+	core       zapcore.Core
+	fields     []zapcore.Field
+	suppressed int
+	timer      *time.Timer
+}
+
+// deduper holds the scopes of one output, shared by its cores.
+type deduper struct {
+	window time.Duration
+	scope  DedupScope
+	// requestIDKey is the request ID field separating DedupPerRequest
+	// scopes.
+	requestIDKey string
+
+	mu     sync.Mutex
+	scopes map[string]*dedupState
+}
+
+func newDeduper(window time.Duration, scope DedupScope, requestIDKey string) *deduper {
+	if window <= 0 {
+		return nil
+	}
+	return &deduper{window: window, scope: scope, requestIDKey: requestIDKey, scopes: map[string]*dedupState{}}
+}
+
+// repeat is a pending "previous message repeated" entry.
+type repeat struct {
+	st    *dedupState
+	count int
+}
+
+func (r repeat) write() {
+	if r.count == 0 {
+		return
+	}
+	ent := r.st.ent
+	ent.Time = time.Now()
+	ent.Message = fmt.Sprintf("previous message repeated %d times", r.count)
+	ent.Stack = ""
+	fields := append([]zapcore.Field{
+		zap.String("repeated_message", r.st.ent.Message),
+		zap.Int("repeat_count", r.count),
+	}, r.st.fields...)
+	_ = r.st.core.Write(ent, fields)
+}
+
+// take removes the pending count of st. d.mu must be held.
+func (st *dedupState) take() repeat {
+	if st.timer != nil {
+		st.timer.Stop()
+		st.timer = nil
+	}
+	r := repeat{st: st, count: st.suppressed}
+	st.suppressed = 0
+	return r
+}
+
+// admit reports whether an entry is written, and returns the repeat entry
+// of its scope to write first.
+func (d *deduper) admit(scope, key string, ent zapcore.Entry, core zapcore.Core, fp string) (bool, repeat) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	st := d.scopes[scope]
+	if st != nil && st.key == key && ent.Time.Sub(st.written) < d.window {
+		st.suppressed++
+		if st.timer == nil {
+			st.timer = time.AfterFunc(d.window-ent.Time.Sub(st.written), func() { d.expire(scope, st) })
+		}
+		return false, repeat{}
+	}
+
+	var pending repeat
+	if st != nil {
+		pending = st.take()
+	} else if len(d.scopes) >= maxDedupScopes {
+		pending = d.evict()
+	}
+	var fields []zapcore.Field
+	if fp != "" {
+		fields = []zapcore.Field{zap.String("error_fingerprint", fp)}
+	}
+	d.scopes[scope] = &dedupState{key: key, written: ent.Time, ent: ent, core: core, fields: fields}
+	return true, pending
+}
+
+// evict drops one scope to make room, preferring one without suppressed
+// entries. d.mu must be held.
+func (d *deduper) evict() repeat {
+	var victim string
+	for scope, st := range d.scopes {
+		victim = scope
+		if st.suppressed == 0 {
+			break
+		}
+	}
+	r := d.scopes[victim].take()
+	delete(d.scopes, victim)
+	return r
+}
+
+func (d *deduper) expire(scope string, st *dedupState) {
+	d.mu.Lock()
+	if d.scopes[scope] != st {
+		d.mu.Unlock()
+		return
+	}
+	st.timer = nil
+	r := st.take()
+	delete(d.scopes, scope)
+	d.mu.Unlock()
+	r.write()
+}
+
+// flush writes every pending repeat entry and forgets all scopes.
+func (d *deduper) flush() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	pending := make([]repeat, 0, len(d.scopes))
+	for scope, st := range d.scopes {
+		pending = append(pending, st.take())
+		delete(d.scopes, scope)
+	}
+	d.mu.Unlock()
+	for _, r := range pending {
+		r.write()
+	}
+}
+
+// dedupCore routes Error entries through its output's deduper. It
+// remembers the request ID and error added with With to scope and
+// fingerprint entries of such loggers.
+type dedupCore struct {
+	zapcore.Core
+	d         *deduper
+	requestID string
+	err       error
+}
+
+func newDedupCore(c zapcore.Core, d *deduper) zapcore.Core {
+	if d == nil {
+		return c
+	}
+	return &dedupCore{Core: c, d: d}
+}
+
+func (c *dedupCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &dedupCore{Core: c.Core.With(fields), d: c.d, requestID: c.requestID, err: c.err}
+	for _, f := range fields {
+		if f.Key == c.d.requestIDKey && f.Type == zapcore.StringType {
+			clone.requestID = f.String
+		}
+	}
+	if err := errorField(fields); err != nil {
+		clone.err = err
+	}
+	return clone
+}
+
+func (c *dedupCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *dedupCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if ent.Level < zapcore.ErrorLevel {
+		return c.Core.Write(ent, fields)
+	}
+	scope := ""
+	if c.d.scope == DedupPerRequest {
+		scope = c.requestID
+		for _, f := range fields {
+			if f.Key == c.d.requestIDKey && f.Type == zapcore.StringType {
+				scope = f.String
+			}
+		}
+	}
+	fp := entryFingerprint(ent.Message, fields, c.err)
+	key := ent.Level.String() + "\x00" + ent.Message + "\x00" + fp
+
+	ok, pending := c.d.admit(scope, key, ent, c.Core, fp)
+	pending.write()
+	if !ok {
+		return nil
+	}
+	if fp != "" {
+		fields = append(fields[:len(fields):len(fields)], zap.String("error_fingerprint", fp))
+	}
+	return c.Core.Write(ent, fields)
+}
@@ -0,0 +1,88 @@
+package jogger_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+)
+
+func TestWithDeduplicationRejectsInvalidSettings(t *testing.T) {
+	if err := jogger.Configure(jogger.WithDeduplication(-time.Second, jogger.DedupGlobal)); err == nil {
+		t.Error("expected an error for a negative window")
+	}
+	if err := jogger.Configure(jogger.WithDeduplication(time.Second, jogger.DedupScope(7))); err == nil {
+		t.Error("expected an error for an unknown scope")
+	}
+}
+
+func TestDeduplicationCollapsesRepeats(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithDeduplication(time.Minute, jogger.DedupGlobal))
+	ctx := context.Background()
+
+	for i := 0; i < 4; i++ {
+		jogger.Error(ctx, "db down", zap.Error(errors.New("connection refused")))
+	}
+	jogger.Info(ctx, "not an error")
+	jogger.Error(ctx, "cache down", zap.Error(errors.New("timeout")))
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries, got %v", entries)
+	}
+	first, repeat := entries[0], entries[2]
+	if first["msg"] != "db down" || entries[1]["msg"] != "not an error" {
+		t.Errorf("unexpected entries %v", entries)
+	}
+	if repeat["msg"] != "previous message repeated 3 times" || repeat["repeat_count"] != float64(3) ||
+		repeat["repeated_message"] != "db down" || repeat["level"] != "error" {
+		t.Errorf("unexpected repeat entry %v", repeat)
+	}
+	if repeat["error_fingerprint"] == nil || repeat["error_fingerprint"] != first["error_fingerprint"] {
+		t.Errorf("expected the repeat entry to carry the original fingerprint, got %v and %v", repeat, first)
+	}
+	if entries[3]["msg"] != "cache down" {
+		t.Errorf("expected the different error after the repeat entry, got %v", entries[3])
+	}
+}
+
+func TestDeduplicationWindowExpires(t *testing.T) {
+	buf := configureSyncBuffer(t, jogger.WithDeduplication(20*time.Millisecond, jogger.DedupGlobal))
+	ctx := context.Background()
+
+	jogger.Error(ctx, "db down")
+	jogger.Error(ctx, "db down")
+	time.Sleep(100 * time.Millisecond)
+	jogger.Error(ctx, "db down")
+
+	entries := decodeEntries(t, bytes.NewBufferString(buf.String()))
+	if len(entries) != 3 || entries[1]["repeat_count"] != float64(1) || entries[2]["msg"] != "db down" {
+		t.Errorf("expected the repeat entry on expiry and the error logged again, got %v", entries)
+	}
+}
+
+func TestDeduplicationPerRequest(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithDeduplication(time.Minute, jogger.DedupPerRequest))
+	a := jogger.WithRequestID(context.Background(), "a")
+	b := jogger.WithRequestID(context.Background(), "b")
+
+	jogger.Error(a, "db down")
+	jogger.Error(b, "db down")
+	jogger.Error(a, "db down")
+	if entries := decodeEntries(t, buf); len(entries) != 2 {
+		t.Fatalf("expected one entry per request, got %v", entries)
+	}
+
+	buf.Reset()
+	if err := jogger.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	entries := decodeEntries(t, buf)
+	if len(entries) != 1 || entries[0]["repeat_count"] != float64(1) || entries[0]["requestID"] != "a" {
+		t.Errorf("expected Shutdown to flush the pending count of request a, got %v", entries)
+	}
+}
@@ -0,0 +1,90 @@
+package jogger
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// devMaxFieldLength is the largest string or byte field development mode
+// accepts when WithMaxFieldLength is not set.
+const devMaxFieldLength = 64 << 10
+
+// devOutputs counts the outputs in use with development mode on, so the
+// checks cost a single load while none is.
+var devOutputs int32
+
+// WithDevelopment switches to the console format and turns on misuse
+// detection: finishing a span twice, SetTag after Finish, fields named like
+// the ones jogger adds, nil contexts, string fields longer than the field
+// length limit (64 KiB without WithMaxFieldLength) and WithRequestID with
+// an empty ID are logged at DPanic, which panics in development mode. Use
+// it in tests and CI; off, the checks cost a branch.
+func WithDevelopment() Option {
+	return func(c *config) error {
+		c.development = true
+		c.format = FormatConsole
+		c.formatSource = sourceConfigure
+		return nil
+	}
+}
+
+func developmentActive() bool {
+	return atomic.LoadInt32(&devOutputs) != 0
+}
+
+// misuse reports an API misuse at DPanic if o is in development mode.
+func (o *output) misuse(msg string, fields ...zap.Field) {
+	if o.cfg.development {
+		o.base.DPanic(msg, fields...)
+	}
+}
+
+// checkFields reports fields colliding with the ones jogger adds and
+// oversized values, in development mode.
+func checkFields(ctx context.Context, fields []zap.Field) {
+	if !developmentActive() {
+		return
+	}
+	o := outputFor(ctx)
+	if !o.cfg.development {
+		return
+	}
+	limit := o.cfg.maxFieldLength
+	if limit <= 0 {
+		limit = devMaxFieldLength
+	}
+	prefixed := scopeOf(ctx).prefix != ""
+	for _, f := range fields {
+		if o.reserved(f.Key) || prefixed && f.Key == prefixKey {
+			o.misuse("jogger: field collides with a field jogger adds", zap.String("field", f.Key))
+		}
+		if n := fieldLength(f); n > limit {
+			o.misuse("jogger: oversized field", zap.String("field", f.Key), zap.Int("bytes", n), zap.Int("limit", limit))
+		}
+	}
+}
+
+// reserved reports whether jogger itself adds fields named key.
+func (o *output) reserved(key string) bool {
+	switch key {
+	case "ts", "level", "msg", "logger", "caller", "stacktrace", "parentSpanID", "self_time", "error_fingerprint", "span_start", "span_end", "clock_skew_ms",
+		o.cfg.fieldNames.RequestID, o.cfg.fieldNames.Span, o.cfg.fieldNames.SpanID, o.cfg.fieldNames.Duration:
+		return true
+	}
+	return false
+}
+
+func fieldLength(f zap.Field) int {
+	switch f.Type {
+	case zapcore.StringType:
+		return len(f.String)
+	case zapcore.ByteStringType, zapcore.BinaryType:
+		if b, ok := f.Interface.([]byte); ok {
+			return len(b)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,64 @@
+package jogger_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+)
+
+func expectPanic(t *testing.T, name string, fn func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%s: expected a DPanic", name)
+		}
+	}()
+	fn()
+}
+
+func TestDevelopmentModeDetectsMisuse(t *testing.T) {
+	configureBuffer(t, jogger.WithDevelopment(), jogger.WithMaxFieldLength(16))
+	ctx := context.Background()
+
+	expectPanic(t, "double finish", func() {
+		span, _ := jogger.StartSpan(ctx, "twice")
+		span.Finish(nil)
+		span.Finish(nil)
+	})
+	expectPanic(t, "late tag", func() {
+		span, _ := jogger.StartSpan(ctx, "late")
+		span.Finish(nil)
+		span.SetTag("k", "v")
+	})
+	expectPanic(t, "reserved tag", func() {
+		span, _ := jogger.StartSpan(ctx, "reserved")
+		span.SetTag("spanID", "mine")
+	})
+	expectPanic(t, "reserved field", func() {
+		jogger.Info(ctx, "collision", zap.String("requestID", "mine"))
+	})
+	expectPanic(t, "nil context", func() {
+		jogger.Info(nil, "nil context")
+	})
+	expectPanic(t, "oversized field", func() {
+		jogger.Info(ctx, "big", zap.String("body", strings.Repeat("x", 17)))
+	})
+	expectPanic(t, "empty request ID", func() {
+		jogger.WithRequestID(ctx, "")
+	})
+}
+
+func TestMisuseIgnoredOutsideDevelopment(t *testing.T) {
+	configureBuffer(t)
+	ctx := context.Background()
+
+	span, _ := jogger.StartSpan(ctx, "twice")
+	span.Finish(nil)
+	span.Finish(nil)
+	span.SetTag("spanID", "late")
+	jogger.Info(ctx, "collision", zap.String("requestID", "mine"))
+	jogger.WithRequestID(ctx, "")
+}
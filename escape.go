@@ -0,0 +1,187 @@
+package jogger
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// WithEscaping toggles escaping of control characters in messages and
+// logger names: newlines and carriage returns become \n and \r, and other
+// control characters, ANSI escape sequences included, become \xNN, so that
+// user input in a message cannot forge entries or recolor the terminal. It
+// is on by default with FormatConsole, which writes messages verbatim, and
+// not needed with FormatJSON. Field values are JSON encoded by both
+// formats and are always escaped.
+func WithEscaping(on bool) Option {
+	return func(c *config) error {
+		c.escaping = &on
+		return nil
+	}
+}
+
+// WithStripANSI removes ANSI escape sequences from messages and string
+// field values, in either format, so that colored output captured from
+// other tools reads as plain text. It is off by default.
+func WithStripANSI(on bool) Option {
+	return func(c *config) error {
+		c.stripANSI = on
+		return nil
+	}
+}
+
+// escapingEnabled reports whether cfg escapes messages of a sink in
+// format, defaulting by format.
+func (c config) escapingEnabled(format string) bool {
+	if c.escaping != nil {
+		return *c.escaping
+	}
+	return format == FormatConsole
+}
+
+// escapeCore cleans messages and string fields before they reach the
+// encoder.
+type escapeCore struct {
+	zapcore.Core
+	escape bool
+	strip  bool
+}
+
+func newEscapeCore(c zapcore.Core, escape, strip bool) zapcore.Core {
+	return &escapeCore{Core: c, escape: escape, strip: strip}
+}
+
+func (c *escapeCore) With(fields []zapcore.Field) zapcore.Core {
+	return &escapeCore{Core: c.Core.With(c.cleanFields(fields)), escape: c.escape, strip: c.strip}
+}
+
+func (c *escapeCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *escapeCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = c.clean(ent.Message)
+	ent.LoggerName = c.clean(ent.LoggerName)
+	return c.Core.Write(ent, c.cleanFields(fields))
+}
+
+func (c *escapeCore) clean(s string) string {
+	if c.strip {
+		s = stripANSI(s)
+	}
+	if c.escape {
+		s = escapeControl(s)
+	}
+	return s
+}
+
+// cleanFields strips ANSI sequences from string values. The input slice is
+// copied before any change, since it belongs to the caller.
+func (c *escapeCore) cleanFields(fields []zapcore.Field) []zapcore.Field {
+	if !c.strip {
+		return fields
+	}
+	copied := false
+	for i, f := range fields {
+		if f.Type != zapcore.StringType || strings.IndexByte(f.String, 0x1b) < 0 {
+			continue
+		}
+		if !copied {
+			fields = append([]zapcore.Field(nil), fields...)
+			copied = true
+		}
+		fields[i] = zap.String(f.Key, stripANSI(f.String))
+	}
+	return fields
+}
+
+const hexDigits = "0123456789abcdef"
+
+// escapeControl replaces C0 and C1 control characters other than tab, and
+// DEL, with printable escapes.
+func escapeControl(s string) string {
+	i := 0
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if isEscaped(r) {
+			break
+		}
+		i += size
+	}
+	if i == len(s) {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s) + 8)
+	b.WriteString(s[:i])
+	for _, r := range s[i:] {
+		switch {
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r < 0x100 && isEscaped(r):
+			b.WriteString(`\x`)
+			b.WriteByte(hexDigits[r>>4])
+			b.WriteByte(hexDigits[r&0xf])
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func isEscaped(r rune) bool {
+	return (r < 0x20 && r != '\t') || (r >= 0x7f && r <= 0x9f)
+}
+
+// stripANSI removes CSI sequences (ESC [ ... final byte), OSC sequences
+// (ESC ] ... BEL or ESC \) and other escapes such as ESC ( B. Text after
+// an unterminated sequence is dropped along with it.
+func stripANSI(s string) string {
+	if strings.IndexByte(s, 0x1b) < 0 {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != 0x1b {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+1 >= len(s) {
+			break
+		}
+		switch s[i+1] {
+		case '[':
+			j := i + 2
+			for j < len(s) && (s[j] < 0x40 || s[j] > 0x7e) {
+				j++
+			}
+			i = j
+		case ']':
+			j := i + 2
+			for j < len(s) && s[j] != 0x07 && !(s[j] == 0x1b && j+1 < len(s) && s[j+1] == '\\') {
+				j++
+			}
+			if j < len(s) && s[j] == 0x1b {
+				j++
+			}
+			i = j
+		default:
+			// ESC, any intermediate bytes, then the final byte.
+			j := i + 1
+			for j < len(s) && s[j] >= 0x20 && s[j] <= 0x2f {
+				j++
+			}
+			i = j
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package jogger_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+)
+
+func TestConsoleEscapesForgedEntries(t *testing.T) {
+	buf := configureBuffer(t)
+
+	forged := "login failed\n2026-01-01T00:00:00.000Z\tINFO\tadmin logged in\r"
+	jogger.FromContext(context.Background()).Named("auth\nfake").Warn(forged, zap.String("user", "bob\n{\"admin\":true}"))
+
+	out := strings.TrimSuffix(buf.String(), "\n")
+	if strings.ContainsAny(out, "\n\r") {
+		t.Fatalf("expected a single line, got %q", out)
+	}
+	if !strings.Contains(out, `login failed\n2026-01-01T00:00:00.000Z`) || !strings.Contains(out, `admin logged in\r`) {
+		t.Errorf("expected escaped newlines in the message, got %q", out)
+	}
+	if !strings.Contains(out, `auth\nfake`) {
+		t.Errorf("expected an escaped logger name, got %q", out)
+	}
+}
+
+func TestConsoleEscapesANSI(t *testing.T) {
+	buf := configureBuffer(t)
+
+	jogger.Info(context.Background(), "\x1b[2J\x1b[31mred\x1b[0m \x07bell \u009bcsi\ttab")
+
+	out := buf.String()
+	if strings.Contains(out, "red\x1b") || strings.Contains(out, "\x07") || strings.Contains(out, "\u009b") {
+		t.Fatalf("expected no raw control characters from the message, got %q", out)
+	}
+	if !strings.Contains(out, `\x1b[2J\x1b[31mred\x1b[0m \x07bell \x9bcsi`+"\ttab") {
+		t.Errorf("expected visible escapes with the tab kept, got %q", out)
+	}
+}
+
+func TestEscapingCanBeTurnedOff(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithEscaping(false))
+
+	jogger.Info(context.Background(), "two\nlines")
+	if !strings.Contains(buf.String(), "two\nlines") {
+		t.Errorf("expected the raw message, got %q", buf.String())
+	}
+}
+
+func TestJSONStripANSI(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithStripANSI(true))
+
+	logger := jogger.FromContext(context.Background()).With(zap.String("prompt", "\x1b]0;title\x07$ "))
+	logger.Info("\x1b[1;32mok\x1b[0m\nnext", zap.String("out", "\x1b[33mwarn\x1b[0m \x1b(Bdone"), zap.Int("n", 1))
+	logger.Info("cut \x1b[31")
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("expected two entries, got %q", buf.String())
+	}
+	e := entries[0]
+	if e["msg"] != "ok\nnext" || e["out"] != "warn done" || e["prompt"] != "$ " {
+		t.Errorf("expected ANSI sequences stripped and newlines left to the encoder, got %v", e)
+	}
+	if entries[1]["msg"] != "cut " {
+		t.Errorf("expected an unterminated sequence to be dropped, got %v", entries[1])
+	}
+}
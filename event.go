@@ -0,0 +1,146 @@
+package jogger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// eventSinkName is the default name of the event sink for WithSinkFields.
+const eventSinkName = "events"
+
+// defaultEventVersion is the schema version of event types registered
+// without one, or not registered.
+const defaultEventVersion = "1"
+
+// WithEventSink writes the events of EmitEvent to their own sink instead of
+// the main output. It is named "events" unless sc names it, and written as
+// JSON unless sc sets a format.
+func WithEventSink(sc SinkConfig) Option {
+	return func(c *config) error {
+		if sc.Writer == nil {
+			return errors.New("jogger: event sink without a writer")
+		}
+		if sc.Name == "" {
+			sc.Name = eventSinkName
+		}
+		if sc.Format == "" {
+			sc.Format = FormatJSON
+		}
+		c.eventSink = &sc
+		return nil
+	}
+}
+
+// newEventLogger builds the logger behind EmitEvent. Like the audit logger,
+// it leaves out sampling and deduplication and ignores the logger level.
+func newEventLogger(cfg config, sinks []sink, errOut zap.Option) (*zap.Logger, error) {
+	if cfg.eventSink == nil {
+		always := func(sink) zapcore.LevelEnabler { return zapcore.DebugLevel }
+		return zap.New(newCore(cfg, sinks, always, nil, nil), errOut).With(cfg.fields...), nil
+	}
+	events, err := newSink(cfg, *cfg.eventSink)
+	if err != nil {
+		return nil, err
+	}
+	core := newCore(cfg, []sink{events}, func(s sink) zapcore.LevelEnabler { return s.enabler(zapcore.DebugLevel) }, nil, nil)
+	return zap.New(core, errOut).With(cfg.fields...), nil
+}
+
+// eventSchema is what RegisterEventType records for an event type.
+type eventSchema struct {
+	version  string
+	required []string
+}
+
+var (
+	eventSchemasMu sync.Mutex
+	eventSchemas   atomic.Value // map[string]eventSchema, replaced on every change
+)
+
+func init() {
+	eventSchemas.Store(map[string]eventSchema{})
+}
+
+// RegisterEventType sets the schema version of the events of eventType and
+// the top-level payload fields they must carry. An event missing some is
+// still written, with their names in schema_violation.
+func RegisterEventType(eventType, version string, required ...string) {
+	if version == "" {
+		version = defaultEventVersion
+	}
+	eventSchemasMu.Lock()
+	defer eventSchemasMu.Unlock()
+
+	cur := eventSchemas.Load().(map[string]eventSchema)
+	next := make(map[string]eventSchema, len(cur)+1)
+	for k, v := range cur {
+		next[k] = v
+	}
+	next[eventType] = eventSchema{version: version, required: append([]string(nil), required...)}
+	eventSchemas.Store(next)
+}
+
+// EmitEvent writes a business event, such as "order_created", separate from
+// free-form logs: an "event" entry at Info with the envelope fields
+// event_type, event_time, event_version, the request ID and trace ID of ctx
+// and the payload as JSON. Events go to the sink of WithEventSink, or to the
+// main output, and are never sampled or deduplicated. A payload that does
+// not marshal to JSON is not written; an Error entry reports it instead.
+func EmitEvent(ctx context.Context, eventType string, payload interface{}) {
+	ctx = orBackground(ctx)
+	o := outputFor(ctx)
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		FromContext(ctx).Error("jogger: event payload does not marshal to JSON", zap.String("event_type", eventType), zap.Error(err))
+		return
+	}
+
+	schema, ok := eventSchemas.Load().(map[string]eventSchema)[eventType]
+	if !ok {
+		schema.version = defaultEventVersion
+	}
+	s := scopeOf(ctx)
+	fields := make([]zap.Field, 0, 7)
+	fields = append(fields,
+		zap.String("event_type", eventType),
+		zap.Time("event_time", time.Now()),
+		zap.String("event_version", schema.version),
+	)
+	if s.RequestID != "" {
+		fields = append(fields, zap.String(o.cfg.fieldNames.RequestID, s.RequestID))
+	}
+	if s.TraceID != "" {
+		fields = append(fields, zap.String("traceID", s.TraceID))
+	}
+	fields = append(fields, zap.Reflect("payload", json.RawMessage(raw)))
+	if missing := missingPayloadFields(schema.required, raw); len(missing) > 0 {
+		fields = append(fields, zap.Strings("schema_violation", missing))
+	}
+	o.events.Info("event", fields...)
+}
+
+// missingPayloadFields returns the required keys the JSON object raw
+// lacks, all of them when it is not an object.
+func missingPayloadFields(required []string, raw json.RawMessage) []string {
+	if len(required) == 0 {
+		return nil
+	}
+	var obj map[string]json.RawMessage
+	_ = json.Unmarshal(raw, &obj)
+	var missing []string
+	for _, key := range required {
+		if _, ok := obj[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
@@ -0,0 +1,78 @@
+package jogger_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap/zapcore"
+)
+
+type orderCreated struct {
+	OrderID string `json:"orderID"`
+	Total   int    `json:"total"`
+}
+
+func TestEmitEvent(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	ctx := jogger.WithRequestID(context.Background(), "req-event")
+
+	jogger.EmitEvent(ctx, "order_created", orderCreated{OrderID: "o-1", Total: 42})
+
+	e := decodeEntries(t, buf)[0]
+	if e["msg"] != "event" || e["level"] != "info" || e["event_type"] != "order_created" || e["event_version"] != "1" || e["requestID"] != "req-event" {
+		t.Errorf("unexpected envelope %v", e)
+	}
+	if _, ok := e["event_time"]; !ok {
+		t.Error("expected event_time")
+	}
+	payload, ok := e["payload"].(map[string]interface{})
+	if !ok || payload["orderID"] != "o-1" || payload["total"] != float64(42) {
+		t.Errorf("expected the payload as a JSON object, got %v", e["payload"])
+	}
+}
+
+func TestEmitEventSink(t *testing.T) {
+	var events bytes.Buffer
+	main := configureBuffer(t,
+		jogger.WithFormat(jogger.FormatJSON),
+		jogger.WithLevel(zapcore.ErrorLevel),
+		jogger.WithEventSink(jogger.SinkConfig{Writer: &events}),
+	)
+
+	jogger.EmitEvent(context.Background(), "order_created", orderCreated{OrderID: "o-2"})
+
+	if main.Len() != 0 {
+		t.Errorf("expected nothing in the main output, got %s", main)
+	}
+	if got := decodeEntries(t, &events); len(got) != 1 || got[0]["event_type"] != "order_created" {
+		t.Errorf("expected the event in its sink despite the level, got %v", got)
+	}
+	if err := jogger.Configure(jogger.WithEventSink(jogger.SinkConfig{})); err == nil {
+		t.Error("expected an error for an event sink without a writer")
+	}
+}
+
+func TestEmitEventSchema(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	jogger.RegisterEventType("payment_failed", "3", "orderID", "reason")
+
+	jogger.EmitEvent(context.Background(), "payment_failed", map[string]interface{}{"orderID": "o-3"})
+	jogger.EmitEvent(context.Background(), "payment_failed", make(chan int))
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("expected the event and an error, got %v", entries)
+	}
+	e := entries[0]
+	if e["event_version"] != "3" {
+		t.Errorf("expected the registered version, got %v", e["event_version"])
+	}
+	if v, ok := e["schema_violation"].([]interface{}); !ok || len(v) != 1 || v[0] != "reason" {
+		t.Errorf("expected the missing field reported, got %v", e["schema_violation"])
+	}
+	if e := entries[1]; e["level"] != "error" || e["event_type"] != "payment_failed" {
+		t.Errorf("expected an error for a payload that does not marshal, got %v", e)
+	}
+}
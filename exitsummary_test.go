@@ -0,0 +1,59 @@
+package jogger_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+)
+
+func TestExitSummary(t *testing.T) {
+	var summary bytes.Buffer
+	configureBuffer(t, jogger.WithExitSummary(&summary))
+
+	ctx := context.Background()
+	jogger.Info(ctx, "started")
+	for i := 0; i < 3; i++ {
+		jogger.Error(ctx, fmt.Sprintf("order %d failed", i), zap.Error(errors.New("timeout")))
+	}
+	jogger.Error(ctx, "payment declined")
+	span, _ := jogger.StartSpan(ctx, "summary.slow")
+	span.Finish(nil)
+
+	if summary.Len() != 0 {
+		t.Fatalf("expected nothing before Shutdown, got %q", summary.String())
+	}
+	if err := jogger.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	out := summary.String()
+	for _, want := range []string{"jogger summary", "info 2", "error 4", "dropped", "top errors", "3  ", "order 0 failed", "payment declined", "slowest spans", "summary.slow"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in the summary, got\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "order 0 failed") > strings.Index(out, "payment declined") {
+		t.Errorf("expected the most frequent error first, got\n%s", out)
+	}
+}
+
+func TestExitSummaryOff(t *testing.T) {
+	var summary bytes.Buffer
+	configureBuffer(t, jogger.WithExitSummary(&summary))
+	configureBuffer(t)
+	jogger.Error(context.Background(), "failed")
+	if err := jogger.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	if summary.Len() != 0 {
+		t.Errorf("expected no summary once reconfigured without it, got %q", summary.String())
+	}
+	if err := jogger.Configure(jogger.WithExitSummary(nil)); err == nil {
+		t.Error("expected an error for a nil writer")
+	}
+}
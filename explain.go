@@ -0,0 +1,236 @@
+package jogger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Sources of the configuration values reported by DumpConfig.
+const (
+	sourceDefault   = "default"
+	sourceEnv       = "env"
+	sourceConfigure = "Configure"
+	sourceRuntime   = "runtime"
+	sourceIncident  = "incident"
+)
+
+// explainValue is one setting and where it came from.
+type explainValue struct {
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+type explainSink struct {
+	Name   string `json:"name"`
+	Format string `json:"format"`
+	Level  string `json:"level"`
+	Route  bool   `json:"route,omitempty"`
+}
+
+type explainCorrelationKey struct {
+	Key    string `json:"key"`
+	Field  string `json:"field"`
+	Header string `json:"header,omitempty"`
+}
+
+type explainSpan struct {
+	Name     string `json:"name"`
+	Settings string `json:"settings"`
+}
+
+// configExplanation is the resolved configuration of the default instance
+// and the process-wide registries, as DumpConfig reports it.
+type configExplanation struct {
+	Level             explainValue            `json:"level"`
+	Format            explainValue            `json:"format"`
+	Sinks             []explainSink           `json:"sinks"`
+	SinksSource       string                  `json:"sinksSource"`
+	Sampling          explainValue            `json:"sampling"`
+	SlowSpanThreshold explainValue            `json:"slowSpanThreshold"`
+	SlowSpanLevel     explainValue            `json:"slowSpanLevel"`
+	CorrelationKeys   []explainCorrelationKey `json:"correlationKeys"`
+	Spans             []explainSpan           `json:"spans"`
+	SLOs              map[string]string       `json:"slos"`
+	DebugRequestIDs   []string                `json:"debugRequestIDs"`
+	Incident          *IncidentStatus         `json:"incident,omitempty"`
+}
+
+// DumpConfig writes the effective configuration to w in a human-readable
+// form, for diagnosing why entries do or do not show up: the level and
+// format, the sinks with their levels, the sampling rate, the slow span
+// threshold and level, the registered correlation keys, the ConfigureSpan
+// overrides, the SLOs, the requests with debug enabled and incident mode. Each
+// setting names its source: default, env for JOGGER_LEVEL and
+// JOGGER_FORMAT, Configure, runtime for changes such as SetLevel, or
+// incident for SetIncidentMode. The AdminHandler "explain" endpoint serves
+// the same report, also as JSON.
+func DumpConfig(w io.Writer) error {
+	return explainConfig().writeText(w)
+}
+
+// DumpConfigJSON is DumpConfig with JSON output.
+func DumpConfigJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(explainConfig())
+}
+
+func explainConfig() configExplanation {
+	o := currentOutput()
+	cfg := o.cfg
+
+	e := configExplanation{
+		Level:       explainValue{Level().String(), cfg.levelSource},
+		Format:      explainValue{cfg.format, cfg.formatSource},
+		SinksSource: sourceDefault,
+		Sampling:    explainValue{fmt.Sprint(cfg.sampleRate), sourceDefault},
+		SLOs:        adminSLOs(),
+	}
+	if Level() != cfg.level {
+		e.Level.Source = sourceRuntime
+	}
+	if st := CurrentIncident(); st.Active {
+		e.Incident = &st
+		if st.SampleRate >= 1 {
+			e.Level.Source = sourceIncident
+		}
+	}
+	if len(cfg.sinks) > 0 || len(cfg.routes) > 0 || cfg.writer != os.Stdout {
+		e.SinksSource = sourceConfigure
+	}
+	if cfg.sampleRate != 1 {
+		e.Sampling.Source = sourceConfigure
+	}
+	e.SlowSpanThreshold = explainValue{SlowSpanThreshold().String(), sourceDefault}
+	if SlowSpanThreshold() != defaultSlowSpanThreshold {
+		e.SlowSpanThreshold.Source = sourceRuntime
+	}
+	e.SlowSpanLevel = explainValue{SlowSpanLevel().String(), sourceDefault}
+	if SlowSpanLevel() != zapcore.WarnLevel {
+		e.SlowSpanLevel.Source = sourceRuntime
+	}
+
+	for _, s := range o.built {
+		e.Sinks = append(e.Sinks, explainSink{Name: s.name, Format: s.format, Level: sinkLevel(s.level), Route: s.route != nil})
+	}
+	if cfg.auditWriter != nil {
+		e.Sinks = append(e.Sinks, explainSink{Name: auditSinkName, Format: FormatJSON, Level: "all"})
+	}
+	if sc := cfg.eventSink; sc != nil {
+		e.Sinks = append(e.Sinks, explainSink{Name: sc.Name, Format: sc.Format, Level: "events"})
+	}
+
+	for _, k := range registeredCorrelationKeys() {
+		e.CorrelationKeys = append(e.CorrelationKeys, explainCorrelationKey{Key: string(k.key), Field: k.field, Header: k.header})
+	}
+
+	reg := spanConfigs.Load().(*spanRegistry)
+	for name, opts := range reg.options {
+		var s spanSettings
+		for _, opt := range opts {
+			opt(&s)
+		}
+		e.Spans = append(e.Spans, explainSpan{Name: name, Settings: s.String()})
+	}
+	sort.Slice(e.Spans, func(i, j int) bool { return e.Spans[i].Name < e.Spans[j].Name })
+
+	e.DebugRequestIDs = DebugRequestIDs()
+	return e
+}
+
+// sinkLevel describes the level of a sink: the logger level when it has
+// none of its own.
+func sinkLevel(l zapcore.LevelEnabler) string {
+	switch l := l.(type) {
+	case nil:
+		return "logger level"
+	case zapcore.Level:
+		return l.String() + " and above"
+	}
+	return "custom"
+}
+
+// String lists the settings that are set, as in "slow=50ms silent".
+func (s spanSettings) String() string {
+	var parts []string
+	if s.slow > 0 {
+		parts = append(parts, "slow="+s.slow.String())
+	}
+	if s.levelSet {
+		parts = append(parts, "level="+s.level.String())
+	}
+	if s.slowLevelSet {
+		parts = append(parts, "slowLevel="+s.slowLevel.String())
+	}
+	if s.sampleRateSet {
+		parts = append(parts, fmt.Sprintf("sampleRate=%v", s.sampleRate))
+	}
+	if s.silent {
+		parts = append(parts, "silent")
+	}
+	if s.lateTagGrace > 0 {
+		parts = append(parts, "lateTagGrace="+s.lateTagGrace.String())
+	}
+	results := make([]string, 0, len(s.resultLevels))
+	for r := range s.resultLevels {
+		results = append(results, r)
+	}
+	sort.Strings(results)
+	for _, r := range results {
+		parts = append(parts, "result."+r+"="+s.resultLevels[r].String())
+	}
+	return strings.Join(parts, " ")
+}
+
+func (e configExplanation) writeText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "level\t%s\t(%s)\n", e.Level.Value, e.Level.Source)
+	fmt.Fprintf(tw, "format\t%s\t(%s)\n", e.Format.Value, e.Format.Source)
+	fmt.Fprintf(tw, "sampling\t%s\t(%s)\n", e.Sampling.Value, e.Sampling.Source)
+	fmt.Fprintf(tw, "slow span threshold\t%s\t(%s)\n", e.SlowSpanThreshold.Value, e.SlowSpanThreshold.Source)
+	fmt.Fprintf(tw, "slow span level\t%s\t(%s)\n", e.SlowSpanLevel.Value, e.SlowSpanLevel.Source)
+	fmt.Fprintf(tw, "sinks\t\t(%s)\n", e.SinksSource)
+	for _, s := range e.Sinks {
+		route := ""
+		if s.Route {
+			route = ", route"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s%s\n", s.Name, s.Format, s.Level, route)
+	}
+	fmt.Fprintln(tw, "correlation keys")
+	for _, k := range e.CorrelationKeys {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", k.Key, k.Field, k.Header)
+	}
+	if len(e.Spans) > 0 {
+		fmt.Fprintln(tw, "span overrides")
+	}
+	for _, s := range e.Spans {
+		fmt.Fprintf(tw, "  %s\t%s\n", s.Name, s.Settings)
+	}
+	if len(e.SLOs) > 0 {
+		fmt.Fprintln(tw, "slos")
+	}
+	names := make([]string, 0, len(e.SLOs))
+	for name := range e.SLOs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(tw, "  %s\t%s\n", name, e.SLOs[name])
+	}
+	if e.Incident != nil {
+		fmt.Fprintf(tw, "incident mode\ton until %s\tsample rate %v\n", e.Incident.Until.Format(time.RFC3339), e.Incident.SampleRate)
+	}
+	if len(e.DebugRequestIDs) > 0 {
+		fmt.Fprintf(tw, "debug requests\t%s\n", strings.Join(e.DebugRequestIDs, ", "))
+	}
+	return tw.Flush()
+}
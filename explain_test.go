@@ -0,0 +1,79 @@
+package jogger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestDumpConfigSources(t *testing.T) {
+	os.Setenv(jogger.EnvLevel, "warn")
+	defer os.Unsetenv(jogger.EnvLevel)
+	configureBuffer(t,
+		jogger.WithFormat(jogger.FormatJSON),
+		jogger.WithSink(jogger.SinkConfig{Name: "errors", Writer: &bytes.Buffer{}, Level: zapcore.ErrorLevel}),
+	)
+	jogger.ConfigureSpan("explain.*", jogger.SlowThreshold(50*time.Millisecond), jogger.SilentOnSuccess(true))
+	defer jogger.ConfigureSpan("explain.*")
+
+	var buf bytes.Buffer
+	if err := jogger.DumpConfig(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := strings.Join(strings.Fields(buf.String()), " ")
+	for _, want := range []string{"level warn (env)", "format json (Configure)", "slow span threshold 1s (default)", "errors json error and above", "userID userID", "explain.* slow=50ms silent"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in the report, got\n%s", want, out)
+		}
+	}
+
+	jogger.SetLevel(zapcore.DebugLevel)
+	defer jogger.SetLevel(zapcore.InfoLevel)
+	buf.Reset()
+	if err := jogger.DumpConfigJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var e struct {
+		Level struct{ Value, Source string }
+		Sinks []struct{ Name, Level string }
+	}
+	if err := json.Unmarshal(buf.Bytes(), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Level.Value != "debug" || e.Level.Source != "runtime" {
+		t.Errorf("expected a runtime debug level, got %+v", e.Level)
+	}
+	if len(e.Sinks) != 1 || e.Sinks[0].Name != "errors" {
+		t.Errorf("unexpected sinks %+v", e.Sinks)
+	}
+}
+
+func TestAdminHandlerExplain(t *testing.T) {
+	configureBuffer(t)
+
+	rec := httptest.NewRecorder()
+	jogger.AdminHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/log/explain", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(strings.Join(strings.Fields(rec.Body.String()), " "), "level info (default)") {
+		t.Errorf("unexpected explain response %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	jogger.AdminHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/log/explain?format=json", nil))
+	var e struct {
+		Format struct{ Value, Source string }
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &e); err != nil {
+		t.Fatalf("invalid JSON response %q: %v", rec.Body.String(), err)
+	}
+	if e.Format.Value != jogger.FormatConsole || e.Format.Source != "default" {
+		t.Errorf("unexpected format %+v", e.Format)
+	}
+}
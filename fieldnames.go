@@ -0,0 +1,65 @@
+package jogger
+
+import "fmt"
+
+// FieldNames are the keys of the correlation fields the package emits.
+type FieldNames struct {
+	// RequestID is the request ID key, "requestID" by default.
+	RequestID string
+	// Span is the span name key, also used for the current span ID of
+	// entries logged within a span, "span" by default.
+	Span string
+	// SpanID is the span ID key of span entries, "spanID" by default.
+	SpanID string
+	// Duration is the key of span and access log durations, "duration" by
+	// default. Renaming it does not change the unit, which stays seconds.
+	Duration string
+}
+
+var defaultFieldNames = FieldNames{
+	RequestID: "requestID",
+	Span:      "span",
+	SpanID:    "spanID",
+	Duration:  "duration",
+}
+
+// WithFieldNames renames the correlation fields, for log schemas such as
+// request_id and span_id. FromContext, StartSpan, Finish, Audit and the
+// middlewares all use the configured names. Empty names keep their
+// default; names must be distinct.
+func WithFieldNames(names FieldNames) Option {
+	return func(c *config) error {
+		names = names.withDefaults()
+		seen := map[string]bool{}
+		for _, n := range []string{names.RequestID, names.Span, names.SpanID, names.Duration} {
+			if seen[n] {
+				return fmt.Errorf("jogger: field name %q used twice", n)
+			}
+			seen[n] = true
+		}
+		c.fieldNames = names
+		return nil
+	}
+}
+
+func (n FieldNames) withDefaults() FieldNames {
+	if n.RequestID == "" {
+		n.RequestID = defaultFieldNames.RequestID
+	}
+	if n.Span == "" {
+		n.Span = defaultFieldNames.Span
+	}
+	if n.SpanID == "" {
+		n.SpanID = defaultFieldNames.SpanID
+	}
+	if n.Duration == "" {
+		n.Duration = defaultFieldNames.Duration
+	}
+	return n
+}
+
+// CurrentFieldNames returns the field names configured on the default
+// instance, for integrations emitting the same fields.
+func CurrentFieldNames() FieldNames {
+	return currentOutput().cfg.fieldNames
+}
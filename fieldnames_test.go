@@ -0,0 +1,73 @@
+package jogger_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+)
+
+var snakeCase = jogger.FieldNames{RequestID: "request_id", Span: "span_name", SpanID: "span_id", Duration: "duration_ms"}
+
+func keys(e map[string]interface{}) string {
+	var ks []string
+	for k := range e {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	return strings.Join(ks, ",")
+}
+
+func TestWithFieldNames(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithFieldNames(snakeCase))
+	ctx := jogger.WithRequestID(context.Background(), "req-1")
+
+	jogger.Info(ctx, "plain")
+	span, sctx := jogger.StartSpan(ctx, "load")
+	jogger.Info(sctx, "inside")
+	err := errors.New("boom")
+	span.Finish(&err)
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 3 {
+		t.Fatalf("expected three entries, got %v", entries)
+	}
+	for i, want := range []string{
+		"level,msg,request_id,ts",
+		"level,msg,request_id,span_name,ts",
+		"duration_ms,error,error_fingerprint,level,msg,request_id,self_time,span_end,span_id,span_name,span_start,ts",
+	} {
+		if got := keys(entries[i]); got != want {
+			t.Errorf("entry %d: expected keys %s, got %s", i, want, got)
+		}
+	}
+	if entries[1]["span_name"] != entries[2]["span_id"] {
+		t.Errorf("expected the span ID under span_name inside the span, got %v", entries[1])
+	}
+}
+
+func TestWithFieldNamesMiddleware(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithFieldNames(snakeCase))
+	h := jogger.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	serve(t, h, httptest.NewRequest("GET", "/", nil))
+
+	e := decodeEntries(t, buf)[0]
+	if e["request_id"] == nil || e["duration_ms"] == nil || e["requestID"] != nil || e["duration"] != nil {
+		t.Errorf("expected the renamed fields in the access log, got %v", e)
+	}
+}
+
+func TestWithFieldNamesRejectsDuplicates(t *testing.T) {
+	if err := jogger.Configure(jogger.WithFieldNames(jogger.FieldNames{SpanID: "span"})); err == nil {
+		t.Error("expected an error for a name used twice")
+	}
+	if got := jogger.CurrentFieldNames(); got.SpanID != "spanID" {
+		t.Errorf("expected the configuration to be kept, got %+v", got)
+	}
+}
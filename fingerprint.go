@@ -0,0 +1,175 @@
+package jogger
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// A Fingerprinter returns the error_fingerprint of an Error entry from its
+// message and error field, which is nil when the entry has none.
+type Fingerprinter func(msg string, err error) string
+
+var fingerprinter atomic.Value // Fingerprinter
+
+func init() {
+	fingerprinter.Store(Fingerprinter(DefaultFingerprint))
+}
+
+// SetFingerprinter replaces the algorithm behind the error_fingerprint
+// field. nil restores DefaultFingerprint; a fingerprinter returning ""
+// leaves the field out.
+func SetFingerprinter(fn func(msg string, err error) string) {
+	if fn == nil {
+		fn = DefaultFingerprint
+	}
+	fingerprinter.Store(Fingerprinter(fn))
+}
+
+var (
+	uuidPattern   = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	hexPattern    = regexp.MustCompile(`\b(0x[0-9a-fA-F]+|[0-9a-fA-F]{8,})\b`)
+	numberPattern = regexp.MustCompile(`[0-9]+`)
+)
+
+// normalizeMessage replaces UUIDs, hexadecimal strings and numbers in msg
+// with placeholders, so messages differing only in IDs compare equal.
+func normalizeMessage(msg string) string {
+	msg = uuidPattern.ReplaceAllString(msg, "<uuid>")
+	msg = hexPattern.ReplaceAllStringFunc(msg, func(s string) string {
+		// Long runs of hex letters alone are more likely words.
+		if strings.HasPrefix(s, "0x") || strings.ContainsAny(s, "0123456789") {
+			return "<hex>"
+		}
+		return s
+	})
+	return numberPattern.ReplaceAllString(msg, "<n>")
+}
+
+// DefaultFingerprint hashes msg and the error's message, both normalized
+// with normalizeMessage, with the types of the error's wrap chain, into 16
+// hexadecimal characters.
+func DefaultFingerprint(msg string, err error) string {
+	h := fnv.New64a()
+	h.Write([]byte(normalizeMessage(msg)))
+	if err != nil {
+		for e := err; e != nil; e = unwrapError(e) {
+			fmt.Fprintf(h, "|%T", e)
+		}
+		h.Write([]byte("|" + normalizeMessage(err.Error())))
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// unwrapError follows both the Go 1.13 Unwrap and the github.com/pkg/errors
+// Cause conventions, without requiring either.
+func unwrapError(err error) error {
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		return e.Unwrap()
+	case interface{ Cause() error }:
+		return e.Cause()
+	}
+	return nil
+}
+
+// fingerprintCore adds error_fingerprint to Error and higher entries that
+// do not have one yet. It remembers an error field added with With so
+// entries of such loggers are fingerprinted with it.
+type fingerprintCore struct {
+	zapcore.Core
+	err     error
+	summary *exitSummary // counts the fingerprints for WithExitSummary
+}
+
+func newFingerprintCore(c zapcore.Core, summary *exitSummary) zapcore.Core {
+	return &fingerprintCore{Core: c, summary: summary}
+}
+
+func (c *fingerprintCore) With(fields []zapcore.Field) zapcore.Core {
+	err := c.err
+	if e := errorField(fields); e != nil {
+		err = e
+	}
+	return &fingerprintCore{Core: c.Core.With(fields), err: err, summary: c.summary}
+}
+
+func (c *fingerprintCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *fingerprintCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if ent.Level < zapcore.ErrorLevel {
+		return c.Core.Write(ent, fields)
+	}
+	fp, ok := stringField(fields, "error_fingerprint")
+	if !ok {
+		if fp = entryFingerprint(ent.Message, fields, c.err); fp != "" {
+			fields = append(fields[:len(fields):len(fields)], zap.String("error_fingerprint", fp))
+		}
+	}
+	if c.summary != nil && fp != "" {
+		c.summary.countError(fp, ent.Message)
+	}
+	return c.Core.Write(ent, fields)
+}
+
+// entryFingerprint fingerprints an entry with the error in its fields, or
+// withErr, the error added with With, when it has none.
+func entryFingerprint(msg string, fields []zapcore.Field, withErr error) string {
+	err := errorField(fields)
+	if err == nil {
+		err = withErr
+	}
+	return fingerprinter.Load().(Fingerprinter)(msg, err)
+}
+
+// stringField returns the value of the field named key, and whether there
+// is one.
+func stringField(fields []zapcore.Field, key string) (string, bool) {
+	for _, f := range fields {
+		if f.Key == key {
+			return f.String, true
+		}
+	}
+	return "", false
+}
+
+func hasField(fields []zapcore.Field, key string) bool {
+	for _, f := range fields {
+		if f.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// errorField returns the error of the first error field, preferring the
+// one named "error".
+func errorField(fields []zapcore.Field) error {
+	var found error
+	for _, f := range fields {
+		if f.Type != zapcore.ErrorType {
+			continue
+		}
+		err, ok := f.Interface.(error)
+		if !ok {
+			continue
+		}
+		if f.Key == "error" {
+			return err
+		}
+		if found == nil {
+			found = err
+		}
+	}
+	return found
+}
@@ -0,0 +1,89 @@
+package jogger_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+)
+
+type notFoundError struct{ id string }
+
+func (e notFoundError) Error() string { return "user " + e.id + " not found" }
+
+type wrappedError struct{ err error }
+
+func (e wrappedError) Error() string { return "lookup: " + e.err.Error() }
+func (e wrappedError) Unwrap() error { return e.err }
+
+func TestErrorFingerprintIgnoresIDs(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	ctx := context.Background()
+	jogger.Error(ctx, "order 1234 failed", zap.Error(wrappedError{notFoundError{"0f8fad5b-d9cb-469f-a165-70867728950e"}}))
+	jogger.Error(ctx, "order 98 failed", zap.Error(wrappedError{notFoundError{"7c9e6679-7425-40de-944b-e07fc1f90ae7"}}))
+	jogger.Error(ctx, "order 98 failed", zap.Error(notFoundError{"7c9e6679-7425-40de-944b-e07fc1f90ae7"}))
+	jogger.Error(ctx, "order 98 failed", zap.Error(errors.New("timeout after 0xdeadbeef")))
+	jogger.Warn(ctx, "order 98 failed", zap.Error(errors.New("not fingerprinted")))
+
+	entries := decodeEntries(t, buf)
+	fp := func(i int) interface{} { return entries[i]["error_fingerprint"] }
+	if fp(0) == nil || fp(0) != fp(1) {
+		t.Errorf("expected errors differing only in IDs to share a fingerprint, got %v and %v", fp(0), fp(1))
+	}
+	if fp(2) == fp(1) {
+		t.Error("expected a different wrap chain to change the fingerprint")
+	}
+	if fp(3) == fp(1) || fp(3) == nil {
+		t.Errorf("expected a different error to change the fingerprint, got %v", fp(3))
+	}
+	if _, ok := entries[4]["error_fingerprint"]; ok {
+		t.Errorf("expected no fingerprint below Error, got %v", entries[4])
+	}
+}
+
+func TestErrorFingerprintOnSpansAndWith(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	for _, id := range []string{"a1b2c3d4e5f6", "99ffee001122"} {
+		err := fmt.Errorf("query %s timed out", id)
+		span, _ := jogger.StartSpan(context.Background(), "db.query")
+		span.Finish(&err)
+	}
+	logger := jogger.FromContext(context.Background()).With(zap.Error(errors.New("disk 7 full")))
+	logger.Error("write failed")
+	logger.With(zap.String("k", "v")).Error("write failed")
+
+	entries := decodeEntries(t, buf)
+	if entries[0]["error_fingerprint"] == nil || entries[0]["error_fingerprint"] != entries[1]["error_fingerprint"] {
+		t.Errorf("expected errored spans to share a fingerprint, got %v and %v", entries[0], entries[1])
+	}
+	if entries[2]["error_fingerprint"] == nil || entries[2]["error_fingerprint"] != entries[3]["error_fingerprint"] {
+		t.Errorf("expected the With error to be fingerprinted, got %v and %v", entries[2], entries[3])
+	}
+}
+
+func TestSetFingerprinter(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	jogger.SetFingerprinter(func(msg string, err error) string {
+		if err == nil {
+			return ""
+		}
+		return "custom:" + msg
+	})
+	defer jogger.SetFingerprinter(nil)
+
+	jogger.Error(context.Background(), "boom", zap.Error(errors.New("x")))
+	jogger.Error(context.Background(), "no error")
+
+	entries := decodeEntries(t, buf)
+	if entries[0]["error_fingerprint"] != "custom:boom" {
+		t.Errorf("expected the custom fingerprint, got %v", entries[0])
+	}
+	if _, ok := entries[1]["error_fingerprint"]; ok {
+		t.Errorf("expected an empty fingerprint to be left out, got %v", entries[1])
+	}
+}
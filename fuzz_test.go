@@ -0,0 +1,121 @@
+package jogger_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+)
+
+// The fuzz targets run their seeds with the other tests; run one with, for
+// instance, go test -run '^$' -fuzz FuzzSanitizeID -fuzztime 30s.
+
+// maxRequestIDLength is the default of SetMaxRequestIDLength.
+const maxRequestIDLength = 128
+
+// adversarialIDs are inputs that broke or could break the parsers.
+var adversarialIDs = []string{
+	"",
+	"req-1",
+	"  padded  ",
+	strings.Repeat("x", 10<<10),
+	strings.Repeat("é", 5<<10),
+	"\x1b[31mred\x1b[0m",
+	"\x1b]0;title\x07",
+	"line\nforged=entry",
+	"cr\rlf",
+	"\xff\xfe\xfd",
+	"a\xc3",
+	"  ",
+	"\x00null",
+	" nbsp ",
+}
+
+// checkCleanID fails if id is not what SanitizeID promises.
+func checkCleanID(t *testing.T, in, id string) {
+	t.Helper()
+	if !utf8.ValidString(id) {
+		t.Fatalf("invalid UTF-8 %q from %q", id, in)
+	}
+	for _, r := range id {
+		if unicode.IsControl(r) {
+			t.Fatalf("control character %U in %q from %q", r, id, in)
+		}
+	}
+	if len(id) > maxRequestIDLength {
+		t.Fatalf("%d bytes from %q, more than the maximum", len(id), in)
+	}
+	if strings.TrimSpace(id) != id {
+		t.Fatalf("surrounding spaces in %q from %q", id, in)
+	}
+}
+
+func FuzzSanitizeID(f *testing.F) {
+	for _, id := range adversarialIDs {
+		f.Add(id)
+	}
+	f.Fuzz(func(t *testing.T, in string) {
+		id := jogger.SanitizeID(in)
+		checkCleanID(t, in, id)
+		if again := jogger.SanitizeID(id); again != id {
+			t.Fatalf("not idempotent: %q then %q", id, again)
+		}
+	})
+}
+
+func FuzzExtract(f *testing.F) {
+	for _, id := range adversarialIDs {
+		f.Add(id, "user=ann,tenant="+id, "1")
+	}
+	f.Add("req-1", "=,,;=;=", "maybe")
+	f.Add("req-1", strings.Repeat("k=v,", 1000), "0")
+	f.Fuzz(func(t *testing.T, requestID, baggage, sampled string) {
+		h := http.Header{}
+		h.Set(jogger.RequestIDHeader, requestID)
+		h.Set(jogger.BaggageHeader, baggage)
+		h.Set(jogger.SampledHeader, sampled)
+		ctx := jogger.Extract(context.Background(), jogger.HeaderCarrier(h))
+		checkCleanID(t, requestID, jogger.RequestID(ctx))
+
+		out := http.Header{}
+		jogger.Inject(ctx, jogger.HeaderCarrier(out))
+		again := jogger.Extract(context.Background(), jogger.HeaderCarrier(out))
+		if jogger.RequestID(again) != jogger.RequestID(ctx) {
+			t.Fatalf("request ID %q came back as %q", jogger.RequestID(ctx), jogger.RequestID(again))
+		}
+		if want, got := jogger.Baggage(ctx), jogger.Baggage(again); !reflect.DeepEqual(want, got) {
+			t.Fatalf("baggage %q came back as %q", want, got)
+		}
+	})
+}
+
+func FuzzConsoleEncode(f *testing.F) {
+	var buf bytes.Buffer
+	if err := jogger.Configure(jogger.WithOutput(&buf)); err != nil {
+		f.Fatal(err)
+	}
+	f.Cleanup(func() { jogger.Configure() })
+	for _, s := range adversarialIDs {
+		f.Add(s, s)
+	}
+	f.Fuzz(func(t *testing.T, msg, value string) {
+		buf.Reset()
+		jogger.Info(context.Background(), msg, zap.String("value", value))
+		out := buf.String()
+		if strings.Count(out, "\n") != 1 || !strings.HasSuffix(out, "\n") {
+			t.Fatalf("entry is not one line: %q", out)
+		}
+		// The console format colors the level itself, so only the
+		// message and fields after it must be free of escape sequences.
+		if i := strings.Index(out, "\x1b[0m"); i >= 0 && strings.ContainsRune(out[i+len("\x1b[0m"):], '\x1b') {
+			t.Fatalf("escape sequence written: %q", out)
+		}
+	})
+}
@@ -1,8 +1,10 @@
 module github.com/cheesycoffee/jogger
 
-go 1.12
+go 1.18
 
 require (
 	github.com/google/uuid v1.6.0
 	go.uber.org/zap v1.27.0
 )
+
+require go.uber.org/multierr v1.10.0 // indirect
@@ -0,0 +1,55 @@
+package jogger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// WorkerIDKey holds the worker index set with WithWorkerID.
+//
+// Deprecated: Use WithWorkerID. The index is kept in the scope of the
+// context, and stored under the key only for code reading it directly.
+const WorkerIDKey ContextKey = "workerID"
+
+// WithGoroutineID adds the ID of the logging goroutine to every entry as
+// goroutine, so entries of one goroutine can be grouped. It is off by
+// default: the ID is parsed from runtime.Stack, which costs a few
+// microseconds per entry, several times the cost of the entry itself (see
+// BenchmarkGoroutineID). Worker pools can use the cheaper WithWorkerID
+// instead.
+func WithGoroutineID() Option {
+	return func(c *config) error {
+		c.goroutineID = true
+		return nil
+	}
+}
+
+// WithWorkerID stores the index of the worker handling ctx, which
+// FromContext and StartSpan add to entries as worker.
+func WithWorkerID(ctx context.Context, id int) context.Context {
+	return withScopedValue(orBackground(ctx), WorkerIDKey, id)
+}
+
+// goroutineCore adds the goroutine field. It wraps the whole chain so the
+// ID is computed once per entry rather than once per sink.
+type goroutineCore struct {
+	zapcore.Core
+}
+
+func (c goroutineCore) With(fields []zapcore.Field) zapcore.Core {
+	return goroutineCore{c.Core.With(fields)}
+}
+
+func (c goroutineCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c goroutineCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	fields = append(fields[:len(fields):len(fields)], zap.Uint64("goroutine", goroutineID()))
+	return c.Core.Write(ent, fields)
+}
@@ -0,0 +1,92 @@
+package jogger_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"sync"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+)
+
+func TestWithGoroutineID(t *testing.T) {
+	buf := configureSyncBuffer(t, jogger.WithGoroutineID())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			jogger.Info(context.Background(), "first")
+			jogger.Info(context.Background(), "second")
+		}()
+	}
+	wg.Wait()
+
+	perGoroutine := map[float64]int{}
+	for _, e := range decodeEntries(t, bytes.NewBufferString(buf.String())) {
+		id, ok := e["goroutine"].(float64)
+		if !ok || id == 0 {
+			t.Fatalf("expected a goroutine ID, got %v", e)
+		}
+		perGoroutine[id]++
+	}
+	if len(perGoroutine) != 2 {
+		t.Errorf("expected two entries from each of two goroutines, got %v", perGoroutine)
+	}
+	for id, n := range perGoroutine {
+		if n != 2 {
+			t.Errorf("expected goroutine %v to have two entries, got %d", id, n)
+		}
+	}
+}
+
+func TestGoroutineIDOffByDefault(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	jogger.Info(context.Background(), "plain")
+	if _, ok := decodeEntries(t, buf)[0]["goroutine"]; ok {
+		t.Error("expected no goroutine field by default")
+	}
+}
+
+func TestWithWorkerID(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	ctx := jogger.WithWorkerID(context.Background(), 3)
+
+	jogger.Info(ctx, "job done")
+	span, _ := jogger.StartSpan(ctx, "job")
+	err := context.Canceled
+	span.Finish(&err)
+
+	for _, e := range decodeEntries(t, buf) {
+		if e["worker"] != float64(3) {
+			t.Errorf("expected worker 3, got %v", e)
+		}
+	}
+}
+
+func BenchmarkGoroutineID(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		opts []jogger.Option
+		ctx  context.Context
+	}{
+		{"off", nil, context.Background()},
+		{"goroutineID", []jogger.Option{jogger.WithGoroutineID()}, context.Background()},
+		{"workerID", nil, jogger.WithWorkerID(context.Background(), 1)},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			opts := append([]jogger.Option{jogger.WithOutput(ioutil.Discard), jogger.WithFormat(jogger.FormatJSON)}, bc.opts...)
+			if err := jogger.Configure(opts...); err != nil {
+				b.Fatal(err)
+			}
+			defer jogger.Configure()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				jogger.Info(bc.ctx, "benchmark")
+			}
+		})
+	}
+}
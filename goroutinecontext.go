@@ -0,0 +1,89 @@
+package jogger
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// goroutineContexts holds the contexts of SetGoroutineContext by goroutine
+// ID. goroutineContextsUsed stays 0 until the first SetGoroutineContext, so
+// code not using the fallback never looks up a goroutine ID.
+var (
+	goroutineContextsUsed int32
+	goroutineContextsMu   sync.Mutex
+	goroutineContexts     = map[uint64]context.Context{}
+)
+
+// SetGoroutineContext makes ctx the fallback context of the calling
+// goroutine: Debug, Info, Warn and Error given a context without a request
+// ID or span log with the correlation values of ctx instead, and so do
+// DebugNoCtx, InfoNoCtx, WarnNoCtx and ErrorNoCtx.
+//
+// It is a crutch for migrating code whose functions do not take a context
+// yet, and is best removed once they do. A goroutine's context must be
+// cleared with ClearGoroutineContext before the goroutine ends, or it is
+// kept around, and passed on to whatever the goroutine does next. The
+// Middleware clears it when a request ends. Until SetGoroutineContext is
+// first called, the fallback costs nothing.
+func SetGoroutineContext(ctx context.Context) {
+	ctx = orBackground(ctx)
+	id := goroutineID()
+	goroutineContextsMu.Lock()
+	goroutineContexts[id] = ctx
+	goroutineContextsMu.Unlock()
+	atomic.StoreInt32(&goroutineContextsUsed, 1)
+}
+
+// ClearGoroutineContext removes the fallback context of the calling
+// goroutine.
+func ClearGoroutineContext() {
+	if atomic.LoadInt32(&goroutineContextsUsed) == 0 {
+		return
+	}
+	id := goroutineID()
+	goroutineContextsMu.Lock()
+	delete(goroutineContexts, id)
+	goroutineContextsMu.Unlock()
+}
+
+// goroutineFallback returns the goroutine context when ctx carries no
+// correlation values and one is set.
+func goroutineFallback(ctx context.Context) context.Context {
+	if atomic.LoadInt32(&goroutineContextsUsed) == 0 {
+		return ctx
+	}
+	if s := scopeOf(ctx); s.RequestID != "" || s.SpanID != "" {
+		return ctx
+	}
+	id := goroutineID()
+	goroutineContextsMu.Lock()
+	fallback, ok := goroutineContexts[id]
+	goroutineContextsMu.Unlock()
+	if !ok {
+		return ctx
+	}
+	return fallback
+}
+
+// DebugNoCtx logs at Debug with the context of SetGoroutineContext, if any.
+func DebugNoCtx(msg string, fields ...zap.Field) {
+	Debug(context.TODO(), msg, fields...)
+}
+
+// InfoNoCtx logs at Info with the context of SetGoroutineContext, if any.
+func InfoNoCtx(msg string, fields ...zap.Field) {
+	Info(context.TODO(), msg, fields...)
+}
+
+// WarnNoCtx logs at Warn with the context of SetGoroutineContext, if any.
+func WarnNoCtx(msg string, fields ...zap.Field) {
+	Warn(context.TODO(), msg, fields...)
+}
+
+// ErrorNoCtx logs at Error with the context of SetGoroutineContext, if any.
+func ErrorNoCtx(msg string, fields ...zap.Field) {
+	Error(context.TODO(), msg, fields...)
+}
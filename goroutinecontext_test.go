@@ -0,0 +1,70 @@
+package jogger_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+)
+
+func TestGoroutineContext(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	defer jogger.ClearGoroutineContext()
+
+	jogger.SetGoroutineContext(jogger.WithRequestID(context.Background(), "req-legacy"))
+	jogger.InfoNoCtx("no context")
+	jogger.Info(context.TODO(), "todo context")
+	jogger.Info(jogger.WithRequestID(context.Background(), "req-own"), "own context")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		jogger.InfoNoCtx("other goroutine")
+	}()
+	<-done
+	jogger.ClearGoroutineContext()
+	jogger.InfoNoCtx("cleared")
+
+	want := map[string]interface{}{
+		"no context":      "req-legacy",
+		"todo context":    "req-legacy",
+		"own context":     "req-own",
+		"other goroutine": nil,
+		"cleared":         nil,
+	}
+	entries := decodeEntries(t, buf)
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %d", len(want), len(entries))
+	}
+	for _, e := range entries {
+		if got := e["requestID"]; got != want[e["msg"].(string)] {
+			t.Errorf("expected %q logged with request ID %v, got %v", e["msg"], want[e["msg"].(string)], got)
+		}
+	}
+}
+
+func TestMiddlewareClearsGoroutineContext(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	defer jogger.ClearGoroutineContext()
+
+	h := jogger.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jogger.SetGoroutineContext(r.Context())
+		jogger.InfoNoCtx("in handler")
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	jogger.InfoNoCtx("after request")
+
+	for _, e := range decodeEntries(t, buf) {
+		switch e["msg"] {
+		case "in handler":
+			if e["requestID"] == nil {
+				t.Error("expected the handler entry to carry the request ID")
+			}
+		case "after request":
+			if e["requestID"] != nil {
+				t.Errorf("expected the goroutine context cleared, got %v", e)
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package jogger
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Heartbeat logs msg at Info level every interval until stop is called or
+// ctx is done, so long operations don't look hung. fn, if not nil, supplies
+// extra fields such as progress counters for each beat. stop is safe to call
+// more than once; the goroutine also exits on its own when ctx ends. An
+// interval of zero or less logs a warning, reported in development mode,
+// and starts no heartbeat.
+func Heartbeat(ctx context.Context, interval time.Duration, msg string, fn func() []zap.Field) (stop func()) {
+	ctx = orBackground(ctx)
+	logger := FromContext(ctx)
+	if interval <= 0 {
+		fields := []zap.Field{zap.String("heartbeat", msg), zap.Duration("interval", interval)}
+		outputFor(ctx).misuse("jogger: heartbeat interval must be positive", fields...)
+		logger.Warn("jogger: heartbeat interval must be positive", fields...)
+		return func() {}
+	}
+	start := time.Now()
+	done := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case <-ticker.C:
+				fields := []zap.Field{zap.Duration("elapsed", time.Since(start))}
+				if fn != nil {
+					fields = append(fields, fn()...)
+				}
+				logger.Info(msg, fields...)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+const (
+	defaultProgressEvery    = 1000
+	defaultProgressInterval = 10 * time.Second
+)
+
+// ProgressCounter counts processed items and logs progress periodically.
+// Create it with Progress.
+type ProgressCounter struct {
+	count    int64
+	lastLog  int64
+	every    int64
+	interval time.Duration
+	start    time.Time
+	name     string
+	logger   *zap.Logger
+}
+
+// A ProgressOption configures a ProgressCounter.
+type ProgressOption func(*ProgressCounter)
+
+// ProgressEvery logs progress each time another n items are counted.
+func ProgressEvery(n int64) ProgressOption {
+	return func(p *ProgressCounter) {
+		p.every = n
+	}
+}
+
+// ProgressInterval logs progress when d has passed since the last entry.
+func ProgressInterval(d time.Duration) ProgressOption {
+	return func(p *ProgressCounter) {
+		p.interval = d
+	}
+}
+
+// Progress returns a counter for the operation name that logs "progress"
+// every 1000 items or every 10 seconds, whichever comes first.
+func Progress(ctx context.Context, name string, opts ...ProgressOption) *ProgressCounter {
+	p := &ProgressCounter{
+		every:    defaultProgressEvery,
+		interval: defaultProgressInterval,
+		start:    time.Now(),
+		name:     name,
+		logger:   FromContext(ctx),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.lastLog = p.start.UnixNano()
+	return p
+}
+
+// Incr adds n processed items and logs progress when a threshold is crossed.
+func (p *ProgressCounter) Incr(n int64) {
+	total := atomic.AddInt64(&p.count, n)
+
+	crossed := p.every > 0 && total/p.every != (total-n)/p.every
+	now := time.Now().UnixNano()
+	last := atomic.LoadInt64(&p.lastLog)
+	due := p.interval > 0 && time.Duration(now-last) >= p.interval
+
+	if (crossed || due) && atomic.CompareAndSwapInt64(&p.lastLog, last, now) {
+		p.log("progress", total)
+	}
+}
+
+// Count returns the number of items counted so far.
+func (p *ProgressCounter) Count() int64 {
+	return atomic.LoadInt64(&p.count)
+}
+
+// Done logs the final count.
+func (p *ProgressCounter) Done() {
+	p.log("progress done", atomic.LoadInt64(&p.count))
+}
+
+func (p *ProgressCounter) log(msg string, total int64) {
+	elapsed := time.Since(p.start)
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(total) / elapsed.Seconds()
+	}
+	p.logger.Info(msg,
+		zap.String("operation", p.name),
+		zap.Int64("count", total),
+		zap.Float64("perSecond", rate),
+		zap.Duration("elapsed", elapsed),
+	)
+}
@@ -0,0 +1,136 @@
+package jogger_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+)
+
+// syncBuffer guards a buffer written by background goroutines.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func configureSyncBuffer(t *testing.T, opts ...jogger.Option) *syncBuffer {
+	t.Helper()
+	buf := &syncBuffer{}
+	opts = append([]jogger.Option{jogger.WithOutput(buf), jogger.WithFormat(jogger.FormatJSON)}, opts...)
+	if err := jogger.Configure(opts...); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { jogger.Configure() })
+	return buf
+}
+
+func TestHeartbeat(t *testing.T) {
+	buf := configureSyncBuffer(t)
+
+	ctx := jogger.WithRequestID(context.Background(), "req-heartbeat")
+	stop := jogger.Heartbeat(ctx, 10*time.Millisecond, "still exporting", func() []zap.Field {
+		return []zap.Field{zap.Int("rows", 42)}
+	})
+	time.Sleep(55 * time.Millisecond)
+	stop()
+	stop()
+
+	out := buf.String()
+	if n := strings.Count(out, "still exporting"); n < 3 {
+		t.Errorf("expected at least 3 heartbeats, got %d:\n%s", n, out)
+	}
+	if !strings.Contains(out, `"requestID":"req-heartbeat"`) || !strings.Contains(out, `"rows":42`) {
+		t.Errorf("expected correlation and fn fields:\n%s", out)
+	}
+
+	after := strings.Count(buf.String(), "still exporting")
+	time.Sleep(30 * time.Millisecond)
+	if n := strings.Count(buf.String(), "still exporting"); n != after {
+		t.Errorf("expected no heartbeats after stop, got %d more", n-after)
+	}
+}
+
+func TestHeartbeatStopsWithContext(t *testing.T) {
+	buf := configureSyncBuffer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	jogger.Heartbeat(ctx, 5*time.Millisecond, "beat", nil)
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+
+	before := strings.Count(buf.String(), "beat")
+	time.Sleep(30 * time.Millisecond)
+	if n := strings.Count(buf.String(), "beat"); n != before {
+		t.Errorf("expected heartbeat goroutine to exit with the context, got %d more beats", n-before)
+	}
+}
+
+func TestHeartbeatRejectsInvalidInterval(t *testing.T) {
+	buf := configureSyncBuffer(t)
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		stop := jogger.Heartbeat(context.Background(), interval, "beat", nil)
+		stop()
+	}
+
+	if n := strings.Count(buf.String(), "jogger: heartbeat interval must be positive"); n != 2 {
+		t.Errorf("expected a warning per invalid interval, got %d:\n%s", n, buf.String())
+	}
+
+	configureBuffer(t, jogger.WithDevelopment())
+	expectPanic(t, "zero heartbeat interval", func() {
+		jogger.Heartbeat(context.Background(), 0, "beat", nil)
+	})
+}
+
+func TestProgressEvery(t *testing.T) {
+	buf := configureSyncBuffer(t)
+
+	p := jogger.Progress(context.Background(), "import", jogger.ProgressEvery(10), jogger.ProgressInterval(0))
+	for i := 0; i < 35; i++ {
+		p.Incr(1)
+	}
+	p.Done()
+
+	out := buf.String()
+	if n := strings.Count(out, `"msg":"progress"`); n != 3 {
+		t.Errorf("expected 3 progress entries, got %d:\n%s", n, out)
+	}
+	if !strings.Contains(out, `"msg":"progress done"`) || !strings.Contains(out, `"count":35`) {
+		t.Errorf("expected final progress entry with count 35:\n%s", out)
+	}
+	if p.Count() != 35 {
+		t.Errorf("expected count 35, got %d", p.Count())
+	}
+}
+
+func TestProgressInterval(t *testing.T) {
+	buf := configureSyncBuffer(t)
+
+	p := jogger.Progress(context.Background(), "scan", jogger.ProgressEvery(0), jogger.ProgressInterval(10*time.Millisecond))
+	p.Incr(1)
+	time.Sleep(15 * time.Millisecond)
+	p.Incr(1)
+
+	if n := strings.Count(buf.String(), `"msg":"progress"`); n != 1 {
+		t.Errorf("expected 1 time-based progress entry, got %d", n)
+	}
+}
@@ -0,0 +1,152 @@
+package jogger
+
+import (
+	"hash/fnv"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const defaultIncidentDuration = 15 * time.Minute
+
+type incidentSettings struct {
+	duration   time.Duration
+	sampleRate float64
+}
+
+// An IncidentOption changes how SetIncidentMode escalates logging.
+type IncidentOption func(*incidentSettings)
+
+// IncidentDuration sets how long incident mode lasts before it turns itself
+// off, 15 minutes by default.
+func IncidentDuration(d time.Duration) IncidentOption {
+	return func(s *incidentSettings) {
+		if d > 0 {
+			s.duration = d
+		}
+	}
+}
+
+// IncidentSampleRate escalates only the given fraction, between 0 and 1, of
+// requests to Debug instead of lowering the level for everything. Requests
+// are picked by their ID, so a request is logged completely or not at all.
+func IncidentSampleRate(rate float64) IncidentOption {
+	return func(s *incidentSettings) {
+		s.sampleRate = math.Max(0, math.Min(1, rate))
+	}
+}
+
+// IncidentStatus describes incident mode, see SetIncidentMode.
+type IncidentStatus struct {
+	Active     bool      `json:"active"`
+	Since      time.Time `json:"since"`
+	Until      time.Time `json:"until"`
+	SampleRate float64   `json:"sampleRate"`
+}
+
+// incidentState is the active incident mode. It is replaced as a whole and
+// read without locking by loggerFor.
+type incidentState struct {
+	IncidentStatus
+	threshold uint32 // request ID hashes below it are escalated
+	previous  zapcore.Level
+	lowered   bool
+	timer     *time.Timer
+}
+
+var (
+	incidentMu sync.Mutex
+	incident   atomic.Value // *incidentState, nil when off
+)
+
+func init() {
+	incident.Store((*incidentState)(nil))
+}
+
+// SetIncidentMode turns incident mode on or off. While on, the default
+// instance logs at Debug level, or only the fraction of requests set with
+// IncidentSampleRate does, and it turns itself off after IncidentDuration,
+// so extra verbosity is never forgotten. Entering and leaving each log one
+// Info entry; turning it on again while on only updates the settings and
+// restarts the duration. The state is reported by Stats and DumpConfig.
+func SetIncidentMode(on bool, opts ...IncidentOption) {
+	incidentMu.Lock()
+	defer incidentMu.Unlock()
+
+	cur := incident.Load().(*incidentState)
+	if !on {
+		if cur != nil {
+			endIncident(cur, "manual")
+		}
+		return
+	}
+
+	settings := incidentSettings{duration: defaultIncidentDuration, sampleRate: 1}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	now := time.Now()
+	st := &incidentState{
+		IncidentStatus: IncidentStatus{Active: true, Since: now, Until: now.Add(settings.duration), SampleRate: settings.sampleRate},
+		threshold:      uint32(settings.sampleRate * math.MaxUint32),
+		previous:       Level(),
+	}
+	if cur != nil {
+		cur.timer.Stop()
+		st.Since = cur.Since
+		st.previous, st.lowered = cur.previous, cur.lowered
+	}
+	switch {
+	case settings.sampleRate >= 1 && !st.lowered:
+		st.lowered = true
+		SetLevel(zapcore.DebugLevel)
+	case settings.sampleRate < 1 && st.lowered:
+		st.lowered = false
+		SetLevel(st.previous)
+	}
+	st.timer = time.AfterFunc(settings.duration, func() {
+		incidentMu.Lock()
+		defer incidentMu.Unlock()
+		if incident.Load().(*incidentState) == st {
+			endIncident(st, "expired")
+		}
+	})
+	incident.Store(st)
+
+	if cur == nil {
+		announce("jogger: incident mode on", zap.Duration("duration", settings.duration), zap.Float64("sampleRate", settings.sampleRate))
+	}
+}
+
+// endIncident turns incident mode off. incidentMu must be held.
+func endIncident(st *incidentState, reason string) {
+	st.timer.Stop()
+	incident.Store((*incidentState)(nil))
+	if st.lowered && Level() == zapcore.DebugLevel {
+		SetLevel(st.previous)
+	}
+	announce("jogger: incident mode off", zap.String("reason", reason), zap.Duration("lasted", time.Since(st.Since)))
+}
+
+// CurrentIncident returns the state of incident mode.
+func CurrentIncident() IncidentStatus {
+	if st := incident.Load().(*incidentState); st != nil {
+		return st.IncidentStatus
+	}
+	return IncidentStatus{}
+}
+
+// incidentDebug reports whether incident mode escalates requestID.
+func incidentDebug(requestID string) bool {
+	st := incident.Load().(*incidentState)
+	if st == nil || st.lowered || requestID == "" {
+		return false
+	}
+	h := fnv.New32a()
+	h.Write([]byte(requestID))
+	return h.Sum32() < st.threshold
+}
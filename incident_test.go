@@ -0,0 +1,95 @@
+package jogger_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestIncidentMode(t *testing.T) {
+	buf := configureSyncBuffer(t)
+	defer jogger.SetIncidentMode(false)
+
+	jogger.SetIncidentMode(true, jogger.IncidentDuration(50*time.Millisecond))
+	jogger.SetIncidentMode(true, jogger.IncidentDuration(50*time.Millisecond))
+	if jogger.Level() != zapcore.DebugLevel {
+		t.Errorf("expected Debug during an incident, got %v", jogger.Level())
+	}
+	if st := jogger.Stats().Incident; st == nil || !st.Active {
+		t.Errorf("expected the incident in Stats, got %+v", st)
+	}
+	var report bytes.Buffer
+	_ = jogger.DumpConfig(&report)
+	if !strings.Contains(report.String(), "incident mode") {
+		t.Errorf("expected the incident in DumpConfig, got\n%s", report.String())
+	}
+	jogger.Debug(context.Background(), "verbose")
+
+	waitFor(t, "incident mode to expire", func() bool { return !jogger.CurrentIncident().Active })
+	if jogger.Level() != zapcore.InfoLevel {
+		t.Errorf("expected the level restored, got %v", jogger.Level())
+	}
+
+	var on, off int
+	for _, e := range decodeEntries(t, bytes.NewBufferString(buf.String())) {
+		switch e["msg"] {
+		case "jogger: incident mode on":
+			on++
+		case "jogger: incident mode off":
+			off++
+			if e["reason"] != "expired" {
+				t.Errorf("expected the incident to expire, got %v", e)
+			}
+		}
+	}
+	if on != 1 || off != 1 {
+		t.Errorf("expected one entry entering and one leaving, got %d and %d", on, off)
+	}
+}
+
+func TestIncidentModeSampleRate(t *testing.T) {
+	buf := configureSyncBuffer(t)
+	jogger.SetIncidentMode(true, jogger.IncidentSampleRate(0.5))
+	defer jogger.SetIncidentMode(false)
+
+	if jogger.Level() != zapcore.InfoLevel {
+		t.Errorf("expected the level to stay for a partial incident, got %v", jogger.Level())
+	}
+	for i := 0; i < 1000; i++ {
+		ctx := jogger.WithRequestID(context.Background(), fmt.Sprintf("req-%d", i))
+		jogger.Debug(ctx, "sampled")
+	}
+	jogger.SetIncidentMode(false)
+
+	n := strings.Count(buf.String(), `"sampled"`)
+	if n < 400 || n > 600 {
+		t.Errorf("expected about half of the requests at Debug, got %d of 1000", n)
+	}
+}
+
+func TestAdminHandlerIncident(t *testing.T) {
+	defer restoreAdminDefaults()
+	defer jogger.SetIncidentMode(false)
+
+	code, resp := doAdmin(t, http.MethodPut, `{"incident":{"enabled":true,"duration":"1m"}}`)
+	if code != http.StatusOK || resp.Level != "debug" {
+		t.Errorf("expected incident mode to lower the level, got %d %+v", code, resp)
+	}
+	if !jogger.CurrentIncident().Active {
+		t.Error("expected incident mode on")
+	}
+	if code, _ := doAdmin(t, http.MethodPut, `{"incident":{"enabled":true,"sampleRate":2}}`); code != http.StatusBadRequest {
+		t.Errorf("expected a bad sample rate to be rejected, got %d", code)
+	}
+	doAdmin(t, http.MethodPut, `{"incident":{"enabled":false}}`)
+	if jogger.CurrentIncident().Active || jogger.Level() != zapcore.InfoLevel {
+		t.Error("expected incident mode off and the level restored")
+	}
+}
@@ -0,0 +1,176 @@
+package jogger
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// A Jogger is a logging setup of its own: output, sinks and level. The
+// package-level functions use a default instance, configured with
+// Configure; New creates more, such as for a library embedded in a host
+// application or for tests that need isolation.
+//
+// Contexts record the instance that produced them: the contexts returned
+// by StartSpan and WithContext, and the request contexts of Middleware,
+// make the package-level functions log through that instance too, so code
+// downstream of a handler needs no reference to it. Runtime controls such
+// as EnableRequestDebug, RegisterSLO and ConfigureSpan apply to every
+// instance.
+type Jogger struct {
+	out   atomic.Value // *output
+	level zap.AtomicLevel
+
+	mu     sync.Mutex // serializes configuration changes
+	frozen bool
+}
+
+var std = &Jogger{level: level}
+
+// New returns an instance configured with opts, which start from the same
+// defaults as Configure.
+func New(opts ...Option) (*Jogger, error) {
+	j := &Jogger{level: zap.NewAtomicLevel()}
+	if err := j.Configure(opts...); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func (j *Jogger) output() *output {
+	return j.out.Load().(*output)
+}
+
+// Configure replaces the configuration of j, see the package-level
+// Configure.
+func (j *Jogger) Configure(opts ...Option) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.frozen {
+		return ErrFrozen
+	}
+
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return err
+		}
+	}
+
+	o, err := newOutput(cfg, j.level)
+	if err != nil {
+		return err
+	}
+	j.level.SetLevel(cfg.level)
+	j.swap(o)
+	return nil
+}
+
+// Freeze makes later Configure calls on j fail with ErrFrozen.
+func (j *Jogger) Freeze() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.frozen = true
+}
+
+// SetLevel changes the minimum level of j.
+func (j *Jogger) SetLevel(l zapcore.Level) {
+	j.level.SetLevel(l)
+}
+
+// Level returns the minimum level of j.
+func (j *Jogger) Level() zapcore.Level {
+	return j.level.Level()
+}
+
+// Sync flushes every sink of j, the audit output included.
+func (j *Jogger) Sync() error {
+	return j.output().sync()
+}
+
+// Shutdown writes the entries j holds back, such as deduplication counts,
+// and syncs its output. The trace events file is process-wide and written
+// by the package-level Shutdown.
+func (j *Jogger) Shutdown() error {
+	o := j.output()
+	if o.budget != nil {
+		o.budget.close()
+	}
+	o.dedup.flush()
+	err := o.sync()
+	if o.summary != nil {
+		if serr := o.summary.render(); err == nil {
+			err = serr
+		}
+	}
+	return err
+}
+
+// summaryHook names the span hook of j's exit summary.
+func (j *Jogger) summaryHook() string {
+	return fmt.Sprintf("exitSummary.%p", j)
+}
+
+// WithContext returns a copy of ctx bound to j: the package-level functions
+// given it, or a context derived from it, log through j.
+func (j *Jogger) WithContext(ctx context.Context) context.Context {
+	ctx = orBackground(ctx)
+	s := scopeOf(ctx)
+	if s.instance == j {
+		return ctx
+	}
+	s.instance = j
+	return withScope(ctx, s)
+}
+
+// FromContext returns the logger of j with the correlation fields of ctx.
+func (j *Jogger) FromContext(ctx context.Context) *zap.Logger {
+	return FromContext(j.WithContext(ctx))
+}
+
+// StartSpan starts a span logging through j, see the package-level
+// StartSpan. The returned context is bound to j.
+func (j *Jogger) StartSpan(ctx context.Context, name string, opts ...SpanOption) (Span, context.Context) {
+	return StartSpan(j.WithContext(ctx), name, opts...)
+}
+
+func (j *Jogger) Debug(ctx context.Context, msg string, fields ...zap.Field) {
+	logAt(ctx, j, zapcore.DebugLevel, msg, fields)
+}
+
+func (j *Jogger) Info(ctx context.Context, msg string, fields ...zap.Field) {
+	logAt(ctx, j, zapcore.InfoLevel, msg, fields)
+}
+
+func (j *Jogger) Warn(ctx context.Context, msg string, fields ...zap.Field) {
+	logAt(ctx, j, zapcore.WarnLevel, msg, fields)
+}
+
+func (j *Jogger) Error(ctx context.Context, msg string, fields ...zap.Field) {
+	logAt(ctx, j, zapcore.ErrorLevel, msg, fields)
+}
+
+// Middleware returns the package-level Middleware logging through j. The
+// request contexts it passes on are bound to j.
+func (j *Jogger) Middleware(opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	mw := Middleware(opts...)
+	return func(next http.Handler) http.Handler {
+		return j.bind(mw(next))
+	}
+}
+
+// Recoverer returns the package-level Recoverer logging through j.
+func (j *Jogger) Recoverer(next http.Handler) http.Handler {
+	return j.bind(Recoverer(next))
+}
+
+func (j *Jogger) bind(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r.WithContext(j.WithContext(r.Context())))
+	})
+}
@@ -0,0 +1,91 @@
+package jogger_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+)
+
+func newInstance(t *testing.T, opts ...jogger.Option) (*jogger.Jogger, *bytes.Buffer) {
+	t.Helper()
+	var buf bytes.Buffer
+	j, err := jogger.New(append([]jogger.Option{jogger.WithOutput(&buf), jogger.WithFormat(jogger.FormatJSON)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return j, &buf
+}
+
+func TestInstancesAreIndependent(t *testing.T) {
+	def := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	a, bufA := newInstance(t)
+	b, bufB := newInstance(t, jogger.WithLevel(zap.WarnLevel), jogger.WithFieldNames(jogger.FieldNames{RequestID: "request_id"}))
+	ctx := jogger.WithRequestID(context.Background(), "req-1")
+
+	a.Info(ctx, "to a")
+	b.Info(ctx, "dropped by b's level")
+	b.Warn(ctx, "to b")
+	jogger.Info(ctx, "to default")
+
+	if e := decodeEntries(t, bufA); len(e) != 1 || e[0]["msg"] != "to a" || e[0]["requestID"] != "req-1" {
+		t.Errorf("unexpected entries of a: %v", e)
+	}
+	if e := decodeEntries(t, bufB); len(e) != 1 || e[0]["msg"] != "to b" || e[0]["request_id"] != "req-1" {
+		t.Errorf("unexpected entries of b: %v", e)
+	}
+	if e := decodeEntries(t, def); len(e) != 1 || e[0]["msg"] != "to default" {
+		t.Errorf("unexpected entries of the default instance: %v", e)
+	}
+}
+
+func TestInstanceContextsStayBound(t *testing.T) {
+	def := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	a, bufA := newInstance(t)
+
+	span, ctx := a.StartSpan(context.Background(), "work")
+	jogger.Info(ctx, "inside")
+	inner, _ := jogger.StartSpan(ctx, "inner")
+	inner.Finish(nil)
+	err := context.Canceled
+	span.Finish(&err)
+
+	if e := decodeEntries(t, bufA); len(e) != 3 || e[0]["msg"] != "inside" || e[1]["span"] != "inner" {
+		t.Errorf("expected the derived context to log through a, got %v", e)
+	}
+	if def.Len() != 0 {
+		t.Errorf("expected nothing on the default instance, got %s", def)
+	}
+}
+
+func TestInstanceMiddleware(t *testing.T) {
+	def := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	a, bufA := newInstance(t)
+	h := a.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jogger.Info(r.Context(), "handled")
+	}))
+
+	serve(t, h, httptest.NewRequest("GET", "/", nil))
+
+	if e := decodeEntries(t, bufA); len(e) != 2 || e[0]["msg"] != "handled" || e[0]["requestID"] != e[1]["requestID"] {
+		t.Errorf("expected the handler and access log entries on a, got %v", e)
+	}
+	if def.Len() != 0 {
+		t.Errorf("expected nothing on the default instance, got %s", def)
+	}
+}
+
+func TestInstanceFreeze(t *testing.T) {
+	a, _ := newInstance(t)
+	a.Freeze()
+	if err := a.Configure(); err != jogger.ErrFrozen {
+		t.Errorf("expected ErrFrozen, got %v", err)
+	}
+	if err := jogger.Configure(); err != nil {
+		t.Errorf("expected the default instance to stay configurable, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package jogger
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const internalErrorInterval = 10 * time.Second
+
+var (
+	internalErrorHandler atomic.Value
+
+	internalMu         sync.Mutex
+	internalLastReport time.Time
+	internalFailures   uint64
+	internalFallback   = newFallbackLogger(zapcore.Lock(os.Stderr))
+)
+
+// SetInternalErrorHandler replaces the default reporting of jogger's own
+// failures, such as a sink write error. fn is called synchronously for
+// every failure, so it must be fast and must not log through jogger. A nil
+// fn restores the default, which writes at most one "jogger internal error"
+// line to stderr every 10 seconds.
+func SetInternalErrorHandler(fn func(error)) {
+	internalErrorHandler.Store(fn)
+}
+
+func reportInternalError(err error) {
+	if fn, ok := internalErrorHandler.Load().(func(error)); ok && fn != nil {
+		fn(err)
+		return
+	}
+
+	internalMu.Lock()
+	internalFailures++
+	now := time.Now()
+	if !internalLastReport.IsZero() && now.Sub(internalLastReport) < internalErrorInterval {
+		internalMu.Unlock()
+		return
+	}
+	failures := internalFailures
+	internalFailures = 0
+	internalLastReport = now
+	fallback := internalFallback
+	internalMu.Unlock()
+
+	fallback.Error("jogger internal error",
+		zap.Error(err),
+		zap.Uint64("failures", failures),
+		zap.Uint64("sinkErrors", atomic.LoadUint64(&stats.sinkErrors)),
+	)
+}
+
+// newFallbackLogger builds the logger used to report internal errors. It
+// never shares a sink with the main output, so a failing sink cannot take
+// its own error reports down with it.
+func newFallbackLogger(w zapcore.WriteSyncer) *zap.Logger {
+	encoderCfg := zap.NewProductionEncoderConfig()
+	encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+	return zap.New(zapcore.NewCore(zapcore.NewConsoleEncoder(encoderCfg), w, zapcore.DebugLevel))
+}
+
+// internalErrorOutput adapts zap's ErrorOutput so zap's own diagnostics are
+// reported the same way as sink failures.
+type internalErrorOutput struct{}
+
+func (internalErrorOutput) Write(p []byte) (int, error) {
+	reportInternalError(errors.New(strings.TrimSpace(string(p))))
+	return len(p), nil
+}
+
+func (internalErrorOutput) Sync() error {
+	return nil
+}
@@ -0,0 +1,88 @@
+package jogger
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func newFailingLogger() *zap.Logger {
+	enc, _ := newEncoder(FormatJSON)
+	core := zapcore.NewCore(enc, zapcore.AddSync(failingWriter{}), zapcore.DebugLevel)
+	return zap.New(newStatsCore(core), zap.ErrorOutput(internalErrorOutput{}))
+}
+
+func TestInternalErrorHandlerReceivesSinkFailures(t *testing.T) {
+	ResetStats()
+	defer ResetStats()
+
+	var got []error
+	SetInternalErrorHandler(func(err error) { got = append(got, err) })
+	defer SetInternalErrorHandler(nil)
+
+	l := newFailingLogger()
+	l.Info("one")
+	l.Info("two")
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 reported errors, got %d", len(got))
+	}
+	if got[0].Error() != "disk full" {
+		t.Errorf("expected sink error, got %v", got[0])
+	}
+	if Stats().SinkErrors != 2 {
+		t.Errorf("expected 2 sink errors, got %d", Stats().SinkErrors)
+	}
+}
+
+func TestInternalErrorFallbackIsRateLimited(t *testing.T) {
+	ResetStats()
+	defer ResetStats()
+
+	var buf bytes.Buffer
+	internalMu.Lock()
+	prevFallback := internalFallback
+	internalFallback = newFallbackLogger(zapcore.AddSync(&buf))
+	internalLastReport = time.Time{}
+	internalFailures = 0
+	internalMu.Unlock()
+	defer func() {
+		internalMu.Lock()
+		internalFallback = prevFallback
+		internalLastReport = time.Time{}
+		internalFailures = 0
+		internalMu.Unlock()
+	}()
+
+	l := newFailingLogger()
+	for i := 0; i < 3; i++ {
+		l.Info("lost")
+	}
+	if n := strings.Count(buf.String(), "jogger internal error"); n != 1 {
+		t.Fatalf("expected 1 internal error line within the interval, got %d:\n%s", n, buf.String())
+	}
+	if !strings.Contains(buf.String(), "disk full") {
+		t.Errorf("expected the sink error in the report, got %s", buf.String())
+	}
+
+	internalMu.Lock()
+	internalLastReport = time.Now().Add(-internalErrorInterval)
+	internalMu.Unlock()
+	buf.Reset()
+
+	l.Info("lost again")
+	if !strings.Contains(buf.String(), `"failures": 3`) {
+		t.Errorf("expected the interval's failures to be reported, got %s", buf.String())
+	}
+}
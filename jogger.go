@@ -2,7 +2,7 @@ package jogger
 
 import (
 	"context"
-	"os"
+	"sort"
 	"sync"
 	"time"
 
@@ -14,109 +14,393 @@ import (
 type ContextKey string
 
 type Span struct {
-	logger *zap.Logger
-	start  time.Time
-	fields []zap.Field
-	mu     sync.Mutex
+	name          string
+	id            string
+	parentID      string
+	requestID     string
+	node          *treeNode
+	parent        *spanChildren
+	children      *spanChildren
+	settings      spanSettings
+	sampled       bool
+	strict        *output // set in development mode
+	finished      bool
+	finishedAt    time.Time
+	late          []zap.Field
+	lateTimer     *time.Timer
+	logger        *zap.Logger
+	start         time.Time
+	startWall     time.Time // start without its monotonic reading
+	skewThreshold time.Duration
+	names         FieldNames
+	deadline      time.Time
+	hasDeadline   bool
+	timestamps    bool
+	fields        []zap.Field
+	events        []spanEvent
+	droppedEvents int
+	links         []spanLink
+	droppedLinks  int
+	mu            sync.Mutex
 }
 
+// The exported context keys are kept for code that stores or reads the
+// values directly. Values stored under them are read in contexts this
+// package has not stored anything in yet, and carried over by the first
+// function of this package that does, such as WithRequestID. WithRequestID
+// and StartSpan also store the IDs under them.
+//
+// Deprecated: Use WithRequestID and RequestID, StartSpan and SpanID,
+// WithZapLogger and Named instead. The keys will be removed in a future
+// release.
 const (
 	RequestIDKey ContextKey = "requestID"
 	SpanKey      ContextKey = "currentSpan"
 	LoggerKey    ContextKey = "currentLogger"
+	NameKey      ContextKey = "loggerName"
 )
 
-var baseLogger *zap.Logger
-
-func init() {
-	encoderCfg := zap.NewProductionEncoderConfig()
-	encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
-	encoderCfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
-
-	consoleEncoder := zapcore.NewConsoleEncoder(encoderCfg)
-
-	core := zapcore.NewCore(consoleEncoder, zapcore.AddSync(os.Stdout), zapcore.InfoLevel)
-
-	baseLogger = zap.New(core)
+// orBackground lets every exported function accept a nil context, which
+// context.Context methods would otherwise panic on.
+func orBackground(ctx context.Context) context.Context {
+	if ctx == nil {
+		if developmentActive() {
+			currentOutput().misuse("jogger: nil context")
+		}
+		return context.Background()
+	}
+	return ctx
 }
 
+// WithRequestID returns a copy of ctx carrying requestID, cleaned with
+// SanitizeID. An ID that is empty once cleaned, or that does not match the
+// format set with SetRequestIDFormat, is replaced with a generated one.
 func WithRequestID(ctx context.Context, requestID string) context.Context {
-	return context.WithValue(ctx, RequestIDKey, requestID)
+	ctx = orBackground(ctx)
+	if requestID == "" && developmentActive() {
+		outputFor(ctx).misuse("jogger: WithRequestID called with an empty request ID")
+	}
+	rid, client := normalizeRequestID(requestID)
+	s := scopeOf(ctx)
+	s.RequestID = rid
+	if client != "" {
+		s.client = client
+	}
+	// The deprecated key is kept up to date for code reading it directly.
+	return withScope(context.WithValue(ctx, RequestIDKey, rid), s)
 }
 
 func FromContext(ctx context.Context) *zap.Logger {
-	fields := []zap.Field{}
-
-	if rid, ok := ctx.Value(RequestIDKey).(string); ok {
-		fields = append(fields, zap.String("requestID", rid))
+	ctx = orBackground(ctx)
+	var l *zap.Logger
+	if stored, ok := ctx.Value(scopeContextKey).(*scopeValue); ok {
+		l = stored.logger()
+	} else {
+		s := legacyScope(ctx)
+		l = s.derive(s.output())
 	}
-	if span, ok := ctx.Value(SpanKey).(string); ok {
-		fields = append(fields, zap.String("span", span))
+	// Registered correlation keys and baggage are stored in ctx on their
+	// own and may be set after the scope, so they are not part of the
+	// derived logger.
+	if fields := contextCorrelationFields(ctx, nil); len(fields) > 0 {
+		l = l.With(fields...)
 	}
+	return l
+}
 
-	if l, ok := ctx.Value(LoggerKey).(*zap.Logger); ok {
-		return l.With(fields...)
+// derive returns the logger of s with the fields of s.
+func (s *Scope) derive(o *output) *zap.Logger {
+	names := o.cfg.fieldNames
+	fields := s.scopeFields(names, make([]zap.Field, 0, 4+len(s.Fields)))
+	if s.SpanID != "" {
+		fields = append(fields, zap.String(names.Span, s.SpanID))
+	}
+	fields = s.correlationFields(fields)
+	fields = append(fields, s.Fields...)
+	fields = s.prefixField(fields)
+	return s.logger(o).With(fields...)
+}
+
+// logger returns the logger stored with WithZapLogger, or the base logger
+// of o for the request ID, named after the component of s, filtered by the
+// level of WithMinLevel and buffered by StartTailSampling.
+func (s *Scope) logger(o *output) *zap.Logger {
+	if s.zap != nil {
+		return s.zap
+	}
+	l := o.loggerFor(s.RequestID)
+	if s.name != "" {
+		l = l.Named(s.name)
 	}
+	return s.withTail(s.withContextLevel(o, l))
+}
 
-	return baseLogger.With(fields...)
+// Named returns a copy of ctx whose logger is named after a component, such
+// as "cache". Nested names are joined with a dot, e.g. "api.cache".
+func Named(ctx context.Context, name string) context.Context {
+	ctx = orBackground(ctx)
+	s := scopeOf(ctx)
+	if s.zap != nil {
+		s.zap = s.zap.Named(name)
+	} else if s.name != "" {
+		s.name += "." + name
+	} else {
+		s.name = name
+	}
+	return withScope(ctx, s)
 }
 
-func StartSpan(ctx context.Context, name string) (Span, context.Context) {
-	requestID, _ := ctx.Value(RequestIDKey).(string)
+// StartSpan starts a span named name that logs with the request ID and
+// correlation fields of ctx, and returns it with a context carrying its
+// span ID. A span already in ctx becomes its parent, reported in
+// parentSpanID. When ctx has a deadline, Finish reports the time left in
+// deadline_remaining_ms and had_deadline. opts override the defaults
+// registered with ConfigureSpan.
+func StartSpan(ctx context.Context, name string, opts ...SpanOption) (Span, context.Context) {
+	ctx = orBackground(ctx)
+	s := scopeOf(ctx)
+	requestID, parentID := s.RequestID, s.SpanID
 	spanID := uuid.New().String()
+	start := time.Now()
+	o := s.output()
+	names := o.cfg.fieldNames
 
 	fields := []zap.Field{
-		zap.String("span", name),
-		zap.String("spanID", spanID),
+		zap.String(names.Span, name),
+		zap.String(names.SpanID, spanID),
 	}
-
-	if requestID != "" {
-		fields = append(fields, zap.String("requestID", requestID))
+	if parentID != "" {
+		fields = append(fields, zap.String("parentSpanID", parentID))
 	}
 
-	l := baseLogger.With(fields...)
+	fields = s.scopeFields(names, fields)
+	fields = correlationFields(ctx, &s, fields)
+	fields = append(fields, s.Fields...)
+	fields = s.prefixField(fields)
 
-	ctx = context.WithValue(ctx, SpanKey, spanID)
+	l := s.logger(o).With(fields...)
+
+	deadline, hasDeadline := ctx.Deadline()
+	parent := s.children
+	children := &spanChildren{}
+	s.SpanID, s.SpanName, s.children = spanID, name, children
+	ctx = withScope(context.WithValue(ctx, SpanKey, spanID), s)
+
+	var strict *output
+	if o.cfg.development {
+		strict = o
+	}
 
 	return Span{
-		logger: l,
-		start:  time.Now(),
+		name:          name,
+		id:            spanID,
+		parentID:      parentID,
+		requestID:     requestID,
+		node:          spanTreeFrom(ctx).add(name, spanID, parentID, start),
+		parent:        parent,
+		children:      children,
+		settings:      spanSettingsFor(name, opts),
+		sampled:       IsSampled(ctx) || s.tail != nil,
+		strict:        strict,
+		logger:        l,
+		start:         start,
+		names:         names,
+		deadline:      deadline,
+		hasDeadline:   hasDeadline,
+		timestamps:    o.cfg.spanTimestampsEnabled(),
+		startWall:     start.Round(0),
+		skewThreshold: o.cfg.clockSkewThreshold(),
 	}, ctx
 }
 
+// SetTag adds a field to the span's finish entry. Tags set after Finish are
+// dropped, counted in Stats().LateTags and reported once per span name,
+// unless the span has a LateTagGrace: tags arriving within it are logged in
+// a "span tags (late)" entry instead.
 func (s *Span) SetTag(key string, value interface{}) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.checkTag(key)
+	if s.finished {
+		s.lateTag(zap.Any(key, value))
+		return
+	}
 	s.fields = append(s.fields, zap.Any(key, value))
 }
 
+// SetTags adds several fields to the span's finish entry at once, which is
+// cheaper than as many SetTag calls. Tags set after Finish are handled as
+// with SetTag.
+func (s *Span) SetTags(fields ...zap.Field) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, f := range fields {
+		s.checkTag(f.Key)
+	}
+	if s.finished {
+		for _, f := range fields {
+			s.lateTag(f)
+		}
+		return
+	}
+	s.fields = append(s.fields, fields...)
+}
+
+// SetTagMap adds the entries of m as tags, like SetTags, in the order of
+// their keys so the finish entry is the same on every run.
+func (s *Span) SetTagMap(m map[string]interface{}) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fields := make([]zap.Field, len(keys))
+	for i, k := range keys {
+		fields[i] = zap.Any(k, m[k])
+	}
+	s.SetTags(fields...)
+}
+
+// checkTag reports, in development mode, a tag named like a field jogger
+// adds. s.mu must be held.
+func (s *Span) checkTag(key string) {
+	if s.strict != nil && s.strict.reserved(key) {
+		s.strict.misuse("jogger: tag collides with a field jogger adds", zap.String("span", s.name), zap.String("tag", key))
+	}
+}
+
+// Finish logs the span's finish entry: at Error when err points to a
+// non-nil error, at the slow level, Warn by default, with slow=true when
+// the span was slow or breached its SLO, and at the span's finish level
+// otherwise. The duration is time.Since the start,
+// measured on the monotonic clock, so it is right even when the wall clock
+// is stepped meanwhile; a wall clock that moved differently by more than the
+// threshold of WithClockSkewThreshold is reported in clock_skew_ms.
 func (s *Span) Finish(err *error) {
+	var spanErr error
+	if err != nil {
+		spanErr = *err
+	}
+	s.finish(spanErr, "")
+}
+
+// finish does the work of Finish and FinishWithResult.
+func (s *Span) finish(spanErr error, result string) {
 	s.mu.Lock()
+	twice := s.finished
+	s.finished = true
+	s.finishedAt = time.Now()
 	fieldsCopy := make([]zap.Field, len(s.fields))
 	copy(fieldsCopy, s.fields)
+	fieldsCopy = s.appendEventFields(fieldsCopy)
+	fieldsCopy = s.appendLinkFields(fieldsCopy)
 	s.mu.Unlock()
+	if twice && s.strict != nil {
+		s.strict.misuse("jogger: span finished twice", zap.String("span", s.name))
+	}
+
+	now := time.Now()
+	elapsed := now.Sub(s.start)
+	s.parent.add(interval{s.start, s.start.Add(elapsed)})
+	fieldsCopy = append(fieldsCopy,
+		zap.Duration(s.names.Duration, elapsed),
+		zap.Duration("self_time", elapsed-s.children.close(s.start, s.start.Add(elapsed))),
+	)
+	if s.timestamps {
+		fieldsCopy = append(fieldsCopy, spanTimestampFields(s.start, elapsed)...)
+	}
+	if skew, ok := clockSkew(s.startWall, now, elapsed, s.skewThreshold); ok {
+		fieldsCopy = append(fieldsCopy, skew)
+	}
+	if s.hasDeadline {
+		fieldsCopy = append(fieldsCopy, deadlineField(s.deadline, s.start.Add(elapsed)))
+	}
+
+	target, hasSLO := sloFor(s.name)
+	breached := hasSLO && elapsed > target
+	if hasSLO {
+		fieldsCopy = append(fieldsCopy,
+			zap.Float64("slo_target_ms", float64(target)/float64(time.Millisecond)),
+			zap.Bool("slo_breached", breached),
+		)
+	}
 
-	elapsed := time.Since(s.start)
-	fieldsCopy = append(fieldsCopy, zap.Duration("duration", elapsed))
+	if result != "" {
+		fieldsCopy = append(fieldsCopy, zap.String("result", result))
+	}
 
-	if err != nil && *err != nil {
-		fieldsCopy = append(fieldsCopy, zap.Error(*err))
+	s.node.finish(elapsed, spanErr)
+	slow := spanErr == nil && (breached || elapsed > s.settings.slowThreshold())
+	if slow {
+		fieldsCopy = append(fieldsCopy, zap.Bool("slow", true))
+	}
+	runSpanHooks(finishedSpan{
+		name:      s.name,
+		id:        s.id,
+		parentID:  s.parentID,
+		requestID: s.requestID,
+		start:     s.start,
+		duration:  elapsed,
+		err:       spanErr,
+		slow:      slow,
+		result:    result,
+	})
+
+	if lvl, ok := s.settings.resultLevels[result]; ok && result != "" {
+		if spanErr != nil {
+			fieldsCopy = append(fieldsCopy, zap.Error(spanErr))
+		}
+		if ce := s.logger.Check(lvl, "span finished with result"); ce != nil {
+			ce.Write(fieldsCopy...)
+		}
+	} else if spanErr != nil {
+		fieldsCopy = append(fieldsCopy, zap.Error(spanErr))
 		s.logger.Error("span finished with error", fieldsCopy...)
-	} else if elapsed > 1*time.Second {
-		s.logger.Warn("span finished slowly", fieldsCopy...)
-	} else {
-		s.logger.Info("span finished successfully", fieldsCopy...)
+	} else if slow {
+		if ce := s.logger.Check(s.settings.slowFinishLevel(), "span finished slowly"); ce != nil {
+			ce.Write(fieldsCopy...)
+		}
+	} else if s.settings.logsSuccess() {
+		if !s.sampled {
+			if s.logger.Core().Enabled(s.settings.successLevel()) {
+				countDrop(dropSampling)
+			}
+		} else if ce := s.logger.Check(s.settings.successLevel(), "span finished successfully"); ce != nil {
+			ce.Write(fieldsCopy...)
+		}
 	}
 }
 
+func Debug(ctx context.Context, msg string, fields ...zap.Field) {
+	logAt(ctx, nil, zapcore.DebugLevel, msg, fields)
+}
+
 func Info(ctx context.Context, msg string, fields ...zap.Field) {
-	FromContext(ctx).Info(msg, fields...)
+	logAt(ctx, nil, zapcore.InfoLevel, msg, fields)
 }
 
 func Warn(ctx context.Context, msg string, fields ...zap.Field) {
-	FromContext(ctx).Warn(msg, fields...)
+	logAt(ctx, nil, zapcore.WarnLevel, msg, fields)
 }
 
 func Error(ctx context.Context, msg string, fields ...zap.Field) {
-	FromContext(ctx).Error(msg, fields...)
+	logAt(ctx, nil, zapcore.ErrorLevel, msg, fields)
+}
+
+// logAt is what the logging helpers of the package and of instances share:
+// it takes the goroutine context when ctx has no correlation values, binds
+// it to j if set, applies the development and WithRequireRequestID checks
+// from Info up, and writes the entry.
+func logAt(ctx context.Context, j *Jogger, lvl zapcore.Level, msg string, fields []zap.Field) {
+	ctx = goroutineFallback(orBackground(ctx))
+	if j != nil {
+		ctx = j.WithContext(ctx)
+	}
+	checkFields(ctx, fields)
+	if lvl >= zapcore.InfoLevel {
+		fields = requireRequestID(ctx, lvl, fields)
+	}
+	if ce := FromContext(ctx).Check(lvl, msg); ce != nil {
+		writeEntry(ctx, ce, fields)
+	}
 }
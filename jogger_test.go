@@ -103,3 +103,105 @@ func TestInfoWarnErrorLogging(t *testing.T) {
 	jogger.Warn(ctx, "warn message")
 	jogger.Error(ctx, "error message", zap.Error(errors.New("fail")))
 }
+
+func TestNilAndTODOContexts(t *testing.T) {
+	contexts := map[string]context.Context{
+		"nil":  nil,
+		"TODO": context.TODO(),
+	}
+	for name, ctx := range contexts {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Fatalf("panicked with %s context: %v", name, r)
+				}
+			}()
+
+			if jogger.FromContext(ctx) == nil {
+				t.Fatal("expected logger")
+			}
+			jogger.Debug(ctx, "debug with "+name+" context")
+			jogger.Info(ctx, "info with "+name+" context")
+			jogger.Warn(ctx, "warn with "+name+" context")
+			jogger.Error(ctx, "error with "+name+" context")
+
+			span, spanCtx := jogger.StartSpan(ctx, "span-"+name)
+			if spanCtx == nil {
+				t.Fatal("expected span context")
+			}
+			span.Finish(nil)
+
+			if got := jogger.RequestID(jogger.WithRequestID(ctx, "rid")); got != "rid" {
+				t.Errorf("expected request ID rid, got %q", got)
+			}
+			if got := jogger.UserID(jogger.WithUserID(ctx, "uid")); got != "uid" {
+				t.Errorf("expected user ID uid, got %q", got)
+			}
+			if got := jogger.TenantID(jogger.WithTenantID(ctx, "tid")); got != "tid" {
+				t.Errorf("expected tenant ID tid, got %q", got)
+			}
+			if jogger.RequestID(ctx) != "" || jogger.UserID(ctx) != "" || jogger.TenantID(ctx) != "" {
+				t.Error("expected empty accessors")
+			}
+
+			c := jogger.MapCarrier{}
+			jogger.Inject(ctx, c)
+			if len(c) != 0 {
+				t.Errorf("expected nothing injected, got %v", c)
+			}
+			if jogger.Extract(ctx, c) == nil {
+				t.Error("expected extracted context")
+			}
+		})
+	}
+}
+
+func TestNamedNesting(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	ctx := jogger.WithRequestID(context.Background(), "req-named")
+	api := jogger.Named(ctx, "api")
+	cache := jogger.Named(api, "cache")
+
+	jogger.Info(api, "from api")
+	jogger.Info(cache, "from cache")
+
+	entries := decodeEntries(t, buf)
+	if entries[0]["logger"] != "api" {
+		t.Errorf("expected logger api, got %v", entries[0]["logger"])
+	}
+	if entries[1]["logger"] != "api.cache" {
+		t.Errorf("expected logger api.cache, got %v", entries[1]["logger"])
+	}
+	for _, e := range entries {
+		if e["requestID"] != "req-named" {
+			t.Errorf("expected requestID to survive naming, got %v", e)
+		}
+	}
+}
+
+func TestStartSpanInheritsName(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	ctx := jogger.Named(jogger.WithRequestID(context.Background(), "req-span-name"), "payment-client")
+	span, _ := jogger.StartSpan(ctx, "charge")
+	span.Finish(nil)
+
+	e := decodeEntries(t, buf)[0]
+	if e["logger"] != "payment-client" {
+		t.Errorf("expected span to inherit logger name, got %v", e["logger"])
+	}
+	if e["requestID"] != "req-span-name" {
+		t.Errorf("expected requestID on span, got %v", e["requestID"])
+	}
+}
+
+func TestNamedWithCustomLogger(t *testing.T) {
+	custom := zap.NewNop()
+	ctx := context.WithValue(context.Background(), jogger.LoggerKey, custom)
+	ctx = jogger.Named(ctx, "worker")
+
+	if l := jogger.FromContext(ctx); l == custom {
+		t.Error("expected a named copy of the custom logger in the context")
+	}
+}
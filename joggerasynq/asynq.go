@@ -0,0 +1,61 @@
+// Package joggerasynq carries jogger's request ID and correlation values
+// through asynq task headers and logs each task run as a span.
+package joggerasynq
+
+import (
+	"context"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"github.com/hibiken/asynq"
+)
+
+// EnqueuedAtHeader is the task header NewTask records the enqueue time in.
+const EnqueuedAtHeader = "X-Enqueued-At"
+
+// NewTask returns an asynq task whose headers carry the request ID and
+// propagated correlation values of ctx and the time it was created.
+func NewTask(ctx context.Context, typename string, payload []byte, opts ...asynq.Option) *asynq.Task {
+	headers := map[string]string{
+		EnqueuedAtHeader: time.Now().UTC().Format(time.RFC3339Nano),
+	}
+	jogger.Inject(ctx, jogger.MapCarrier(headers))
+	return asynq.NewTaskWithHeaders(typename, payload, headers, opts...)
+}
+
+// Middleware is an asynq.MiddlewareFunc that runs each task inside a span
+// named after the task type, with the correlation values from the task
+// headers in the handler's context and a new request ID when there are
+// none. The span is tagged with the queue, task ID, retry count and max
+// retries and, for tasks created with NewTask, queueLatency: the time since
+// the task was first enqueued, retries included. It finishes with the
+// handler's error.
+//
+//	mux.Use(joggerasynq.Middleware)
+func Middleware(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) (err error) {
+		headers := t.Headers()
+		ctx = jogger.Extract(ctx, jogger.MapCarrier(headers))
+		ctx = jogger.EnsureRequestID(ctx)
+
+		span, ctx := jogger.StartSpan(ctx, t.Type())
+		defer span.Finish(&err)
+		if queue, ok := asynq.GetQueueName(ctx); ok {
+			span.SetTag("queue", queue)
+		}
+		if id, ok := asynq.GetTaskID(ctx); ok {
+			span.SetTag("taskID", id)
+		}
+		if n, ok := asynq.GetRetryCount(ctx); ok {
+			span.SetTag("retryCount", n)
+		}
+		if n, ok := asynq.GetMaxRetry(ctx); ok {
+			span.SetTag("maxRetry", n)
+		}
+		if at, err := time.Parse(time.RFC3339Nano, headers[EnqueuedAtHeader]); err == nil {
+			span.SetTag("queueLatency", time.Since(at))
+		}
+
+		return next.ProcessTask(ctx, t)
+	})
+}
@@ -0,0 +1,79 @@
+package joggerasynq_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"github.com/cheesycoffee/jogger/joggerasynq"
+	"github.com/hibiken/asynq"
+)
+
+func run(t *testing.T, task *asynq.Task, fn func(context.Context, *asynq.Task) error) (map[string]interface{}, error) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jogger.Configure(jogger.WithOutput(&buf), jogger.WithFormat(jogger.FormatJSON)); err != nil {
+		t.Fatal(err)
+	}
+	defer jogger.Configure()
+
+	err := joggerasynq.Middleware(asynq.HandlerFunc(fn)).ProcessTask(context.Background(), task)
+
+	var e map[string]interface{}
+	if jerr := json.Unmarshal([]byte(strings.TrimSpace(buf.String())), &e); jerr != nil {
+		t.Fatal(jerr)
+	}
+	return e, err
+}
+
+func TestMiddlewareCorrelatesTask(t *testing.T) {
+	task := joggerasynq.NewTask(jogger.WithRequestID(context.Background(), "req-enqueue"), "email:send", []byte(`{}`))
+	time.Sleep(5 * time.Millisecond)
+
+	var handled string
+	e, err := run(t, task, func(ctx context.Context, t *asynq.Task) error {
+		handled = jogger.RequestID(ctx)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if handled != "req-enqueue" {
+		t.Errorf("expected the enqueuing request ID in the handler, got %q", handled)
+	}
+	if e["span"] != "email:send" || e["requestID"] != "req-enqueue" {
+		t.Errorf("unexpected task span %v", e)
+	}
+	if latency, ok := e["queueLatency"].(float64); !ok || latency < 0.005 {
+		t.Errorf("expected queueLatency of at least 5ms, got %v", e["queueLatency"])
+	}
+}
+
+func TestMiddlewareHandlerError(t *testing.T) {
+	task := asynq.NewTask("report:build", nil)
+
+	var handled string
+	e, err := run(t, task, func(ctx context.Context, t *asynq.Task) error {
+		handled = jogger.RequestID(ctx)
+		return errors.New("render failed")
+	})
+
+	if err == nil || err.Error() != "render failed" {
+		t.Errorf("expected the handler error to be returned, got %v", err)
+	}
+	if handled == "" {
+		t.Error("expected a generated request ID for a task without headers")
+	}
+	if e["level"] != "error" || e["error"] != "render failed" {
+		t.Errorf("expected the span to finish with the handler error, got %v", e)
+	}
+	if _, ok := e["queueLatency"]; ok {
+		t.Error("queueLatency needs the enqueue time header")
+	}
+}
@@ -0,0 +1,114 @@
+package joggeraws
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	snstypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/cheesycoffee/jogger"
+)
+
+// SQSCarrier adapts SQS message attributes to jogger.Carrier.
+type SQSCarrier map[string]sqstypes.MessageAttributeValue
+
+func (c SQSCarrier) Get(key string) string {
+	if v, ok := c[key]; ok && v.StringValue != nil {
+		return *v.StringValue
+	}
+	return ""
+}
+
+func (c SQSCarrier) Set(key, value string) {
+	c[key] = sqstypes.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(value)}
+}
+
+// SNSCarrier adapts SNS message attributes to jogger.Carrier.
+type SNSCarrier map[string]snstypes.MessageAttributeValue
+
+func (c SNSCarrier) Get(key string) string {
+	if v, ok := c[key]; ok && v.StringValue != nil {
+		return *v.StringValue
+	}
+	return ""
+}
+
+func (c SNSCarrier) Set(key, value string) {
+	c[key] = snstypes.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(value)}
+}
+
+type messagingConfig struct {
+	names map[string]string
+}
+
+// A MessagingOption configures the message attribute helpers.
+type MessagingOption func(*messagingConfig)
+
+// AttributeNames renames the attributes the request ID and propagated
+// correlation values are stored in, keyed by their header name such as
+// jogger.RequestIDHeader. See jogger.RenameKeys.
+func AttributeNames(names map[string]string) MessagingOption {
+	return func(c *messagingConfig) {
+		c.names = names
+	}
+}
+
+func carrier(c jogger.Carrier, opts []MessagingOption) jogger.Carrier {
+	var cfg messagingConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return jogger.RenameKeys(c, cfg.names)
+}
+
+// InjectSQS adds the request ID and propagated correlation values of ctx to
+// attrs, allocating it if nil, and returns it for use as the
+// MessageAttributes of a SendMessage input. SQS allows 10 attributes per
+// message.
+func InjectSQS(ctx context.Context, attrs map[string]sqstypes.MessageAttributeValue, opts ...MessagingOption) map[string]sqstypes.MessageAttributeValue {
+	if attrs == nil {
+		attrs = map[string]sqstypes.MessageAttributeValue{}
+	}
+	jogger.Inject(ctx, carrier(SQSCarrier(attrs), opts))
+	return attrs
+}
+
+// InjectSNS is InjectSQS for the MessageAttributes of an SNS Publish input.
+// Subscribed SQS queues receive them as message attributes when raw message
+// delivery is enabled.
+func InjectSNS(ctx context.Context, attrs map[string]snstypes.MessageAttributeValue, opts ...MessagingOption) map[string]snstypes.MessageAttributeValue {
+	if attrs == nil {
+		attrs = map[string]snstypes.MessageAttributeValue{}
+	}
+	jogger.Inject(ctx, carrier(SNSCarrier(attrs), opts))
+	return attrs
+}
+
+// ExtractSQS returns a copy of ctx carrying the request ID and correlation
+// values found in msg's attributes, with a new request ID when msg has none.
+func ExtractSQS(ctx context.Context, msg sqstypes.Message, opts ...MessagingOption) context.Context {
+	ctx = jogger.Extract(ctx, carrier(SQSCarrier(msg.MessageAttributes), opts))
+	ctx = jogger.EnsureRequestID(ctx)
+	return ctx
+}
+
+// WrapSQSReceive returns a handler that runs fn for each message inside a
+// "consume <queue>" span, with the context from ExtractSQS. The span is
+// tagged with the delivery attempt when the message carries the
+// ApproximateReceiveCount system attribute. Receive with
+// MessageAttributeNames set to "All" so the correlation attributes are
+// returned.
+func WrapSQSReceive(queue string, fn func(context.Context, sqstypes.Message) error, opts ...MessagingOption) func(context.Context, sqstypes.Message) error {
+	return func(ctx context.Context, msg sqstypes.Message) (err error) {
+		span, ctx := jogger.StartSpan(ExtractSQS(ctx, msg, opts...), "consume "+queue)
+		defer span.Finish(&err)
+		if n, err := strconv.Atoi(msg.Attributes[string(sqstypes.MessageSystemAttributeNameApproximateReceiveCount)]); err == nil {
+			span.SetTag("deliveryAttempt", n)
+		}
+		if msg.MessageId != nil {
+			span.SetTag("messageID", *msg.MessageId)
+		}
+		return fn(ctx, msg)
+	}
+}
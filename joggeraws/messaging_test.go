@@ -0,0 +1,64 @@
+package joggeraws_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/cheesycoffee/jogger"
+	"github.com/cheesycoffee/jogger/joggeraws"
+)
+
+func TestSQSRoundTrip(t *testing.T) {
+	buf := capture(t)
+
+	attrs := joggeraws.InjectSQS(jogger.WithRequestID(context.Background(), "req-pub"), nil)
+	msg := sqstypes.Message{
+		MessageId:         aws.String("m-1"),
+		MessageAttributes: attrs,
+		Attributes:        map[string]string{"ApproximateReceiveCount": "3"},
+	}
+
+	var handled string
+	handle := joggeraws.WrapSQSReceive("orders", func(ctx context.Context, m sqstypes.Message) error {
+		handled = jogger.RequestID(ctx)
+		return errors.New("poison message")
+	})
+	if err := handle(context.Background(), msg); err == nil {
+		t.Fatal("expected the handler error to be returned")
+	}
+
+	if handled != "req-pub" {
+		t.Errorf("expected the published request ID in the handler, got %q", handled)
+	}
+	e := lastEntry(t, buf)
+	if e["span"] != "consume orders" || e["deliveryAttempt"] != float64(3) || e["messageID"] != "m-1" || e["level"] != "error" {
+		t.Errorf("unexpected consume span %v", e)
+	}
+}
+
+func TestSQSMissingAttributesGetRequestID(t *testing.T) {
+	ctx := joggeraws.ExtractSQS(context.Background(), sqstypes.Message{})
+	if jogger.RequestID(ctx) == "" {
+		t.Error("expected a generated request ID")
+	}
+}
+
+func TestAttributeNames(t *testing.T) {
+	names := joggeraws.AttributeNames(map[string]string{jogger.RequestIDHeader: "request_id"})
+
+	attrs := joggeraws.InjectSNS(jogger.WithRequestID(context.Background(), "req-sns"), nil, names)
+	if v := attrs["request_id"]; v.StringValue == nil || *v.StringValue != "req-sns" || *v.DataType != "String" {
+		t.Errorf("expected the request ID under the configured name, got %v", attrs)
+	}
+
+	sqsAttrs := map[string]sqstypes.MessageAttributeValue{
+		"request_id": {DataType: aws.String("String"), StringValue: aws.String("req-sqs")},
+	}
+	ctx := joggeraws.ExtractSQS(context.Background(), sqstypes.Message{MessageAttributes: sqsAttrs}, names)
+	if got := jogger.RequestID(ctx); got != "req-sqs" {
+		t.Errorf("expected the request ID from the configured name, got %q", got)
+	}
+}
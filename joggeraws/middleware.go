@@ -0,0 +1,71 @@
+// Package joggeraws logs AWS SDK for Go v2 calls as jogger spans.
+package joggeraws
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/cheesycoffee/jogger"
+)
+
+// AppendMiddlewares adds middleware to cfg that runs every SDK call made
+// with it inside an "aws" span tagged with the service, operation, region,
+// HTTP status, number of attempts and the request ID AWS returned, which
+// support cases ask for. Calls slower than jogger.SlowSpanThreshold are
+// logged at Warn like any span. Credentials, headers and payloads are never
+// logged.
+func AppendMiddlewares(cfg *aws.Config) {
+	cfg.APIOptions = append(cfg.APIOptions, func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(spanMiddleware, middleware.After)
+	})
+}
+
+var spanMiddleware = middleware.InitializeMiddlewareFunc("JoggerSpan", func(
+	ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler,
+) (out middleware.InitializeOutput, metadata middleware.Metadata, err error) {
+	span, ctx := jogger.StartSpan(ctx, "aws")
+	defer span.Finish(&err)
+	span.SetTag("service", awsmiddleware.GetServiceID(ctx))
+	span.SetTag("operation", awsmiddleware.GetOperationName(ctx))
+	span.SetTag("region", awsmiddleware.GetRegion(ctx))
+
+	out, metadata, err = next.HandleInitialize(ctx, in)
+
+	if status := statusCode(metadata, err); status != 0 {
+		span.SetTag("status", status)
+	}
+	if id := requestID(metadata, err); id != "" {
+		span.SetTag("awsRequestID", id)
+	}
+	if results, ok := retry.GetAttemptResults(metadata); ok {
+		span.SetTag("attempts", len(results.Results))
+	}
+	return out, metadata, err
+})
+
+func statusCode(metadata middleware.Metadata, err error) int {
+	var respErr *smithyhttp.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.HTTPStatusCode()
+	}
+	if resp, ok := awsmiddleware.GetRawResponse(metadata).(*smithyhttp.Response); ok {
+		return resp.StatusCode
+	}
+	return 0
+}
+
+func requestID(metadata middleware.Metadata, err error) string {
+	if id, ok := awsmiddleware.GetRequestIDMetadata(metadata); ok {
+		return id
+	}
+	var idErr interface{ ServiceRequestID() string }
+	if errors.As(err, &idErr) {
+		return idErr.ServiceRequestID()
+	}
+	return ""
+}
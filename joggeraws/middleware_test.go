@@ -0,0 +1,134 @@
+package joggeraws_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/cheesycoffee/jogger"
+	"github.com/cheesycoffee/jogger/joggeraws"
+)
+
+type response struct {
+	status int
+	body   string
+}
+
+// fakeHTTP answers SDK requests with canned responses in order.
+type fakeHTTP struct {
+	responses []response
+}
+
+func (f *fakeHTTP) Do(r *http.Request) (*http.Response, error) {
+	resp := f.responses[0]
+	if len(f.responses) > 1 {
+		f.responses = f.responses[1:]
+	}
+	h := http.Header{}
+	h.Set("x-amzn-RequestId", "aws-req-1")
+	h.Set("Content-Type", "application/x-amz-json-1.0")
+	return &http.Response{
+		StatusCode: resp.status,
+		Header:     h,
+		Body:       ioutil.NopCloser(strings.NewReader(resp.body)),
+		Request:    r,
+	}, nil
+}
+
+func sqsClient(responses ...response) *sqs.Client {
+	cfg := aws.Config{
+		Region: "eu-west-1",
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "s3cr3t-key"}, nil
+		}),
+		HTTPClient: &fakeHTTP{responses: responses},
+		Retryer: func() aws.Retryer {
+			return retry.NewStandard(func(o *retry.StandardOptions) {
+				o.MaxAttempts = 3
+				o.Backoff = retry.BackoffDelayerFunc(func(int, error) (time.Duration, error) { return 0, nil })
+			})
+		},
+	}
+	joggeraws.AppendMiddlewares(&cfg)
+	return sqs.NewFromConfig(cfg)
+}
+
+func capture(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	if err := jogger.Configure(jogger.WithOutput(buf), jogger.WithFormat(jogger.FormatJSON)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { jogger.Configure() })
+	return buf
+}
+
+func lastEntry(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var e map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &e); err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestCallSpan(t *testing.T) {
+	buf := capture(t)
+	client := sqsClient(response{200, `{"QueueUrls":["https://sqs/q"]}`})
+
+	ctx := jogger.WithRequestID(context.Background(), "req-aws")
+	if _, err := client.ListQueues(ctx, &sqs.ListQueuesInput{QueueNamePrefix: aws.String("orders")}); err != nil {
+		t.Fatal(err)
+	}
+
+	e := lastEntry(t, buf)
+	want := map[string]interface{}{
+		"span":         "aws",
+		"requestID":    "req-aws",
+		"service":      "SQS",
+		"operation":    "ListQueues",
+		"region":       "eu-west-1",
+		"status":       float64(200),
+		"awsRequestID": "aws-req-1",
+		"attempts":     float64(1),
+	}
+	for k, v := range want {
+		if e[k] != v {
+			t.Errorf("%s: expected %v, got %v", k, v, e[k])
+		}
+	}
+	for _, secret := range []string{"AKIDEXAMPLE", "s3cr3t-key", "orders", "https://sqs/q"} {
+		if strings.Contains(buf.String(), secret) {
+			t.Errorf("log must not contain credentials or payloads, found %q", secret)
+		}
+	}
+}
+
+func TestCallSpanRetriesAndErrors(t *testing.T) {
+	buf := capture(t)
+	client := sqsClient(
+		response{500, `{"__type":"InternalError","message":"try again"}`},
+		response{400, `{"__type":"com.amazonaws.sqs#QueueDoesNotExist","message":"no such queue"}`},
+	)
+
+	if _, err := client.GetQueueUrl(context.Background(), &sqs.GetQueueUrlInput{QueueName: aws.String("missing")}); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	e := lastEntry(t, buf)
+	if e["level"] != "error" || e["status"] != float64(400) || e["attempts"] != float64(2) {
+		t.Errorf("unexpected span entry %v", e)
+	}
+	if e["awsRequestID"] != "aws-req-1" {
+		t.Errorf("expected the AWS request ID on failed calls, got %v", e["awsRequestID"])
+	}
+}
@@ -0,0 +1,136 @@
+// Package joggerce carries jogger's request ID and correlation values in
+// CloudEvents extension attributes.
+package joggerce
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+
+	"github.com/cheesycoffee/jogger"
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/cloudevents/sdk-go/v2/types"
+	"github.com/google/uuid"
+)
+
+const (
+	// RequestIDExtension is the extension attribute holding the request ID.
+	RequestIDExtension = "requestid"
+	// TraceParentExtension is the distributed tracing extension attribute,
+	// in W3C traceparent format.
+	TraceParentExtension = "traceparent"
+)
+
+// eventCarrier adapts event extensions to jogger.Carrier. Header names are
+// turned into valid extension names: lowercase letters and digits only, so
+// X-Session-ID is stored as xsessionid.
+type eventCarrier struct {
+	e *event.Event
+}
+
+func (c eventCarrier) Get(key string) string {
+	v, ok := c.e.Extensions()[extensionName(key)]
+	if !ok {
+		return ""
+	}
+	s, err := types.ToString(v)
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+func (c eventCarrier) Set(key, value string) {
+	c.e.SetExtension(extensionName(key), value)
+}
+
+func extensionName(key string) string {
+	if key == jogger.RequestIDHeader {
+		return RequestIDExtension
+	}
+	var b strings.Builder
+	for _, r := range strings.ToLower(key) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Inject sets the requestid extension of e, the extensions of correlation
+// keys registered with jogger.PropagateAs, and traceparent when the request
+// ID is a UUID, which is the case for IDs jogger generates.
+func Inject(ctx context.Context, e *event.Event) {
+	if e == nil || e.Context == nil {
+		return
+	}
+	jogger.Inject(ctx, eventCarrier{e: e})
+	if tp := traceParent(ctx); tp != "" {
+		e.SetExtension(TraceParentExtension, tp)
+	}
+}
+
+// Extract returns a copy of ctx carrying the correlation values in e's
+// extensions. The request ID comes from requestid, or from the trace ID of
+// a valid traceparent, and is generated when neither is usable. Invalid
+// extensions are ignored.
+func Extract(ctx context.Context, e event.Event) context.Context {
+	if e.Context == nil {
+		return jogger.EnsureRequestID(ctx)
+	}
+	c := eventCarrier{e: &e}
+	ctx = jogger.Extract(ctx, c)
+	if jogger.RequestID(ctx) == "" {
+		if traceID, ok := parseTraceParent(c.Get(TraceParentExtension)); ok {
+			ctx = jogger.WithRequestID(ctx, traceID.String())
+		}
+	}
+	return jogger.EnsureRequestID(ctx)
+}
+
+// WrapHandler returns a receiver function that runs fn for each event inside
+// a span named after the event type and tagged with its source, subject and
+// ID, with the context from Extract.
+func WrapHandler(fn func(context.Context, event.Event) error) func(context.Context, event.Event) error {
+	return func(ctx context.Context, e event.Event) (err error) {
+		span, ctx := jogger.StartSpan(Extract(ctx, e), e.Type())
+		defer span.Finish(&err)
+		span.SetTag("source", e.Source())
+		span.SetTag("eventID", e.ID())
+		if subject := e.Subject(); subject != "" {
+			span.SetTag("subject", subject)
+		}
+		return fn(ctx, e)
+	}
+}
+
+// traceParent builds a version 00 traceparent from the request ID as trace
+// ID and the current span ID as parent ID.
+func traceParent(ctx context.Context) string {
+	traceID, err := uuid.Parse(jogger.RequestID(ctx))
+	if err != nil {
+		return ""
+	}
+	parent := uuid.New()
+	if id, err := uuid.Parse(jogger.SpanID(ctx)); err == nil {
+		parent = id
+	}
+	return "00-" + hex.EncodeToString(traceID[:]) + "-" + hex.EncodeToString(parent[:8]) + "-01"
+}
+
+func parseTraceParent(tp string) (uuid.UUID, bool) {
+	parts := strings.Split(tp, "-")
+	if len(parts) != 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return uuid.UUID{}, false
+	}
+	b, err := hex.DecodeString(parts[1])
+	if err != nil {
+		return uuid.UUID{}, false
+	}
+	var id uuid.UUID
+	copy(id[:], b)
+	if id == (uuid.UUID{}) {
+		return uuid.UUID{}, false
+	}
+	return id, true
+}
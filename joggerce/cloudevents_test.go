@@ -0,0 +1,96 @@
+package joggerce_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"github.com/cheesycoffee/jogger/joggerce"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+func newEvent() event.Event {
+	e := event.New()
+	e.SetID("evt-1")
+	e.SetType("order.created")
+	e.SetSource("/orders")
+	e.SetSubject("order-42")
+	return e
+}
+
+func TestInjectExtract(t *testing.T) {
+	const rid = "0f8fad5b-d9cb-469f-a165-70867728950e"
+	span, ctx := jogger.StartSpan(jogger.WithRequestID(context.Background(), rid), "publish")
+	defer span.Finish(nil)
+
+	e := newEvent()
+	joggerce.Inject(ctx, &e)
+
+	if err := e.Validate(); err != nil {
+		t.Fatalf("injected extensions made the event invalid: %v", err)
+	}
+	if got := e.Extensions()[joggerce.RequestIDExtension]; got != rid {
+		t.Errorf("expected requestid extension, got %v", got)
+	}
+	tp, _ := e.Extensions()[joggerce.TraceParentExtension].(string)
+	if !strings.HasPrefix(tp, "00-0f8fad5bd9cb469fa16570867728950e-") || len(tp) != 55 {
+		t.Errorf("unexpected traceparent %q", tp)
+	}
+
+	if got := jogger.RequestID(joggerce.Extract(context.Background(), e)); got != rid {
+		t.Errorf("expected the request ID to round-trip, got %q", got)
+	}
+}
+
+func TestExtractFallbacks(t *testing.T) {
+	e := newEvent()
+	e.SetExtension(joggerce.TraceParentExtension, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if got := jogger.RequestID(joggerce.Extract(context.Background(), e)); got != "4bf92f35-77b3-4da6-a3ce-929d0e0e4736" {
+		t.Errorf("expected the request ID from the trace ID, got %q", got)
+	}
+
+	bad := newEvent()
+	bad.SetExtension(joggerce.TraceParentExtension, "garbage")
+	bad.SetExtension(joggerce.RequestIDExtension, 42)
+	if jogger.RequestID(joggerce.Extract(context.Background(), bad)) == "" {
+		t.Error("expected a generated request ID for invalid extensions")
+	}
+
+	if jogger.RequestID(joggerce.Extract(context.Background(), event.Event{})) == "" {
+		t.Error("expected a generated request ID for an empty event")
+	}
+}
+
+func TestWrapHandler(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jogger.Configure(jogger.WithOutput(&buf), jogger.WithFormat(jogger.FormatJSON)); err != nil {
+		t.Fatal(err)
+	}
+	defer jogger.Configure()
+
+	e := newEvent()
+	joggerce.Inject(jogger.WithRequestID(context.Background(), "req-ce"), &e)
+
+	var handled string
+	err := joggerce.WrapHandler(func(ctx context.Context, e event.Event) error {
+		handled = jogger.RequestID(ctx)
+		return nil
+	})(context.Background(), e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if handled != "req-ce" {
+		t.Errorf("expected the producer's request ID in the handler, got %q", handled)
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(buf.String())), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["span"] != "order.created" || entry["source"] != "/orders" || entry["subject"] != "order-42" || entry["eventID"] != "evt-1" {
+		t.Errorf("unexpected event span %v", entry)
+	}
+}
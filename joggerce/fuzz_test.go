@@ -0,0 +1,51 @@
+package joggerce_test
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"github.com/cheesycoffee/jogger/joggerce"
+	"github.com/google/uuid"
+)
+
+func FuzzExtractTraceparent(f *testing.F) {
+	for _, tp := range []string{
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+		"00-zzf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"--------",
+		"garbage",
+		"",
+		strings.Repeat("0", 10<<10),
+		"\x1b[31m00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-\xff\xfe-00f067aa0ba902b7-01",
+	} {
+		f.Add(tp)
+	}
+	f.Fuzz(func(t *testing.T, tp string) {
+		e := newEvent()
+		if err := e.Context.SetExtension(joggerce.TraceParentExtension, tp); err != nil {
+			t.Skip("not a valid extension value")
+		}
+		rid := jogger.RequestID(joggerce.Extract(context.Background(), e))
+		id, err := uuid.Parse(rid)
+		if err != nil {
+			t.Fatalf("request ID %q from %q is neither the trace ID nor generated", rid, tp)
+		}
+
+		parts := strings.Split(tp, "-")
+		if len(parts) != 4 || len(parts[0]) != 2 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+			return
+		}
+		if b, err := hex.DecodeString(parts[1]); err == nil && len(b) == 16 && id != (uuid.UUID{}) && strings.Trim(parts[1], "0") != "" {
+			if want := strings.ToLower(parts[1]); hex.EncodeToString(id[:]) != want {
+				t.Fatalf("request ID %q does not round-trip the trace ID of %q", rid, tp)
+			}
+		}
+	})
+}
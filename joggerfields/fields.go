@@ -0,0 +1,91 @@
+// Package joggerfields provides field constructors for values that are
+// logged constantly. The constructors defer formatting to encoding time, so
+// entries dropped by the level check cost almost nothing.
+package joggerfields
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type httpRequest struct {
+	r *http.Request
+}
+
+func (h httpRequest) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("method", h.r.Method)
+	if h.r.URL != nil {
+		enc.AddString("path", h.r.URL.Path)
+	}
+	enc.AddString("host", h.r.Host)
+	enc.AddInt64("contentLength", h.r.ContentLength)
+	return nil
+}
+
+// HTTPRequest logs r's method, path, host and content length under the key
+// "request". Headers and body are never included.
+func HTTPRequest(r *http.Request) zap.Field {
+	if r == nil {
+		return zap.Skip()
+	}
+	return zap.Object("request", httpRequest{r: r})
+}
+
+type byteSize int64
+
+func (b byteSize) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddInt64("bytes", int64(b))
+	enc.AddString("human", humanBytes(int64(b)))
+	return nil
+}
+
+// ByteSize logs n both as raw bytes and human readable, e.g.
+// {"bytes": 1572864, "human": "1.5 MiB"}.
+func ByteSize(key string, n int64) zap.Field {
+	return zap.Object(key, byteSize(n))
+}
+
+var byteUnits = []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+func humanBytes(n int64) string {
+	if n < 1024 && n > -1024 {
+		return strconv.FormatInt(n, 10) + " B"
+	}
+	v := float64(n)
+	unit := -1
+	for (v >= 1024 || v <= -1024) && unit < len(byteUnits)-1 {
+		v /= 1024
+		unit++
+	}
+	return strconv.FormatFloat(v, 'f', 1, 64) + " " + byteUnits[unit]
+}
+
+type timeRange struct {
+	from, to time.Time
+}
+
+func (t timeRange) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddTime("from", t.from)
+	enc.AddTime("to", t.to)
+	enc.AddDuration("duration", t.to.Sub(t.from))
+	return nil
+}
+
+// TimeRange logs the interval [from, to] with its duration.
+func TimeRange(key string, from, to time.Time) zap.Field {
+	return zap.Object(key, timeRange{from: from, to: to})
+}
+
+// Stringer logs s.String(), called only if the entry is encoded. A nil s is
+// logged as "<nil>".
+func Stringer(key string, s fmt.Stringer) zap.Field {
+	if s == nil {
+		return zap.String(key, "<nil>")
+	}
+	return zap.Stringer(key, s)
+}
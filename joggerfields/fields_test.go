@@ -0,0 +1,131 @@
+package joggerfields_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger/joggerfields"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// encodeJSON runs fields through zap's JSON encoder and decodes the result.
+func encodeJSON(t *testing.T, fields ...zap.Field) map[string]interface{} {
+	t.Helper()
+	cfg := zap.NewProductionEncoderConfig()
+	cfg.EncodeTime = zapcore.RFC3339TimeEncoder
+	cfg.EncodeDuration = zapcore.StringDurationEncoder
+	buf, err := zapcore.NewJSONEncoder(cfg).EncodeEntry(zapcore.Entry{Message: "m"}, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	return m
+}
+
+func TestHTTPRequest(t *testing.T) {
+	r := httptest.NewRequest("POST", "http://api.example.com/v1/users?token=secret", strings.NewReader("body"))
+	r.Header.Set("Authorization", "Bearer secret")
+
+	m := encodeJSON(t, joggerfields.HTTPRequest(r))
+	req, ok := m["request"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected request object, got %v", m)
+	}
+	want := map[string]interface{}{
+		"method":        "POST",
+		"path":          "/v1/users",
+		"host":          "api.example.com",
+		"contentLength": float64(4),
+	}
+	for k, v := range want {
+		if req[k] != v {
+			t.Errorf("expected %s=%v, got %v", k, v, req[k])
+		}
+	}
+	if len(req) != len(want) {
+		t.Errorf("expected only %d keys, got %v", len(want), req)
+	}
+}
+
+func TestHTTPRequestNil(t *testing.T) {
+	m := encodeJSON(t, joggerfields.HTTPRequest(nil))
+	if _, ok := m["request"]; ok {
+		t.Errorf("expected nil request to be skipped, got %v", m)
+	}
+}
+
+func TestByteSize(t *testing.T) {
+	cases := []struct {
+		n     int64
+		human string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1572864, "1.5 MiB"},
+		{5 << 30, "5.0 GiB"},
+	}
+	for _, c := range cases {
+		m := encodeJSON(t, joggerfields.ByteSize("size", c.n))
+		size := m["size"].(map[string]interface{})
+		if size["bytes"] != float64(c.n) {
+			t.Errorf("%d: expected raw bytes, got %v", c.n, size["bytes"])
+		}
+		if size["human"] != c.human {
+			t.Errorf("%d: expected %q, got %v", c.n, c.human, size["human"])
+		}
+	}
+}
+
+func TestTimeRange(t *testing.T) {
+	from := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	to := from.Add(90 * time.Second)
+
+	m := encodeJSON(t, joggerfields.TimeRange("window", from, to))
+	w := m["window"].(map[string]interface{})
+	if w["from"] != "2026-10-01T12:00:00Z" || w["to"] != "2026-10-01T12:01:30Z" {
+		t.Errorf("unexpected bounds %v", w)
+	}
+	if w["duration"] != "1m30s" {
+		t.Errorf("expected duration 1m30s, got %v", w["duration"])
+	}
+}
+
+type countingStringer struct {
+	calls *int
+}
+
+func (s countingStringer) String() string {
+	*s.calls++
+	return "expensive"
+}
+
+func TestStringerIsDeferred(t *testing.T) {
+	calls := 0
+	f := joggerfields.Stringer("value", countingStringer{calls: &calls})
+
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&strings.Builder{}), zapcore.InfoLevel)
+	zap.New(core).Debug("suppressed", f)
+	if calls != 0 {
+		t.Fatalf("expected String not to be called for a suppressed entry, got %d calls", calls)
+	}
+
+	m := encodeJSON(t, f)
+	if m["value"] != "expensive" || calls != 1 {
+		t.Errorf("expected one String call when encoded, got %d and %v", calls, m["value"])
+	}
+}
+
+func TestStringerNil(t *testing.T) {
+	m := encodeJSON(t, joggerfields.Stringer("value", nil))
+	if m["value"] != "<nil>" {
+		t.Errorf("expected <nil>, got %v", m["value"])
+	}
+}
@@ -0,0 +1,35 @@
+// Package joggergateway carries jogger's request ID and correlation values
+// from grpc-gateway HTTP requests into the gRPC calls they are translated
+// to.
+package joggergateway
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/cheesycoffee/jogger"
+	"github.com/cheesycoffee/jogger/joggergrpc"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/metadata"
+)
+
+// WithCorrelation returns a ServeMuxOption whose metadata annotator copies
+// the request ID and the correlation keys registered with
+// jogger.PropagateAs into the outgoing gRPC metadata, under the keys
+// joggergrpc's server interceptors read. The values come from the request
+// context when jogger.Middleware wraps the gateway, and from the request
+// headers otherwise.
+func WithCorrelation() runtime.ServeMuxOption {
+	return runtime.WithMetadata(Annotate)
+}
+
+// Annotate is the metadata annotator installed by WithCorrelation, for
+// muxes that combine several annotators.
+func Annotate(ctx context.Context, r *http.Request) metadata.MD {
+	if jogger.RequestID(ctx) == "" {
+		ctx = jogger.Extract(ctx, jogger.HeaderCarrier(r.Header))
+	}
+	md := metadata.MD{}
+	jogger.Inject(ctx, joggergrpc.MetadataCarrier(md))
+	return md
+}
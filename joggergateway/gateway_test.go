@@ -0,0 +1,121 @@
+package joggergateway_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"github.com/cheesycoffee/jogger/joggergateway"
+	"github.com/cheesycoffee/jogger/joggergrpc"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// entries returns the logged entries keyed by message.
+func (b *syncBuffer) entries(t *testing.T) map[string]map[string]interface{} {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := map[string]map[string]interface{}{}
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		var e map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		out[e["msg"].(string)] = e
+	}
+	return out
+}
+
+// gateway serves /healthz through grpc-gateway to an in-memory gRPC server
+// running the joggergrpc interceptors.
+func gateway(t *testing.T) http.Handler {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(grpc.UnaryInterceptor(joggergrpc.UnaryServerInterceptor()))
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return runtime.NewServeMux(
+		joggergateway.WithCorrelation(),
+		runtime.WithHealthEndpointAt(healthpb.NewHealthClient(conn), "/healthz"),
+	)
+}
+
+func configure(t *testing.T) *syncBuffer {
+	t.Helper()
+	joggergrpc.SuppressHealthChecks(false) // the health service stands in for an application service
+	buf := &syncBuffer{}
+	if err := jogger.Configure(jogger.WithOutput(buf), jogger.WithFormat(jogger.FormatJSON)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { jogger.Configure() })
+	return buf
+}
+
+func TestRequestIDHeaderReachesGRPCHandler(t *testing.T) {
+	buf := configure(t)
+	mux := gateway(t)
+
+	r := httptest.NewRequest("GET", "/healthz", nil)
+	r.Header.Set("X-Request-ID", "req-gateway")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, r)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body)
+	}
+
+	call := buf.entries(t)["finished call"]
+	if call == nil || call["requestID"] != "req-gateway" {
+		t.Errorf("expected the HTTP request ID in the gRPC handler's logs, got %v", call)
+	}
+}
+
+func TestMiddlewareRequestIDReachesGRPCHandler(t *testing.T) {
+	buf := configure(t)
+	h := jogger.Middleware()(gateway(t))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/healthz", nil))
+
+	byMsg := buf.entries(t)
+	call, access := byMsg["finished call"], byMsg["GET /healthz -> 200"]
+	if call == nil || access == nil {
+		t.Fatalf("expected gRPC and access log entries, got %v", byMsg)
+	}
+	if call["requestID"] == nil || call["requestID"] != access["requestID"] {
+		t.Errorf("expected the generated request ID in both entries, got %v and %v", call["requestID"], access["requestID"])
+	}
+}
@@ -0,0 +1,28 @@
+module github.com/cheesycoffee/jogger/joggergateway
+
+go 1.26.0
+
+require (
+	github.com/cheesycoffee/jogger v0.0.0
+	github.com/cheesycoffee/jogger/joggergrpc v0.0.0
+	github.com/grpc-ecosystem/grpc-gateway/v2 v2.31.0
+	google.golang.org/grpc v1.84.0
+)
+
+require (
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/stretchr/testify v1.12.1 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	go.uber.org/zap v1.27.0 // indirect
+	golang.org/x/net v0.59.0 // indirect
+	golang.org/x/sys v0.48.0 // indirect
+	golang.org/x/text v0.42.0 // indirect
+	google.golang.org/genproto/googleapis/api v0.0.0-20260921155816-b14227669459 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260918162117-cecb64721679 // indirect
+	google.golang.org/protobuf v1.36.12 // indirect
+)
+
+replace (
+	github.com/cheesycoffee/jogger => ../
+	github.com/cheesycoffee/jogger/joggergrpc => ../joggergrpc
+)
@@ -0,0 +1,127 @@
+// Package joggergql provides a gqlgen handler extension that logs GraphQL
+// operations as jogger spans.
+package joggergql
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/cheesycoffee/jogger"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.uber.org/zap"
+)
+
+// Tracer is a gqlgen handler extension that runs each operation inside a
+// span named after the operation and tagged with its type, complexity when
+// the complexity extension is installed and persisted query hash when the
+// APQ extension is. For subscriptions every event gets its own span.
+//
+// Errors in the response are logged with their path: resolver errors at
+// Error, and request errors such as failed validation, which have no path,
+// at Warn.
+//
+//	srv.Use(joggergql.Tracer{ResolverThreshold: 100 * time.Millisecond})
+type Tracer struct {
+	// ResolverThreshold, when positive, logs a span for every resolver
+	// that takes at least this long.
+	ResolverThreshold time.Duration
+	// LogQuery tags operation spans with the full query text. It is off
+	// by default because queries may embed literal arguments.
+	LogQuery bool
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+	graphql.FieldInterceptor
+} = Tracer{}
+
+func (Tracer) ExtensionName() string {
+	return "JoggerTracer"
+}
+
+func (Tracer) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (t Tracer) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	// Requests rejected before an operation was selected, such as on
+	// parse or validation errors, get no span.
+	if !graphql.HasOperationContext(ctx) || graphql.GetOperationContext(ctx).Operation == nil {
+		resp := next(ctx)
+		if resp != nil {
+			logErrors(ctx, resp.Errors)
+		}
+		return resp
+	}
+
+	opCtx := graphql.GetOperationContext(ctx)
+	span, ctx := jogger.StartSpan(ctx, operationName(opCtx))
+	span.SetTag("operation", string(opCtx.Operation.Operation))
+	if stats := extension.GetComplexityStats(ctx); stats != nil {
+		span.SetTag("complexity", stats.Complexity)
+	}
+	if stats := extension.GetApqStats(ctx); stats != nil {
+		span.SetTag("persistedQuery", stats.Hash)
+	}
+	if t.LogQuery {
+		span.SetTag("query", opCtx.RawQuery)
+	}
+
+	resp := next(ctx)
+
+	var err error
+	if resp != nil {
+		err = logErrors(ctx, resp.Errors)
+	}
+	span.Finish(&err)
+	return resp
+}
+
+func (t Tracer) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if t.ResolverThreshold <= 0 || fc == nil || !fc.IsResolver {
+		return next(ctx)
+	}
+
+	start := time.Now()
+	span, spanCtx := jogger.StartSpan(ctx, fc.Object+"."+fc.Field.Name)
+	res, err := next(spanCtx)
+	if time.Since(start) >= t.ResolverThreshold {
+		span.SetTag("path", fc.Path().String())
+		span.Finish(&err)
+	}
+	return res, err
+}
+
+func operationName(opCtx *graphql.OperationContext) string {
+	if opCtx.Operation.Name != "" {
+		return opCtx.Operation.Name
+	}
+	if opCtx.OperationName != "" {
+		return opCtx.OperationName
+	}
+	return "anonymous"
+}
+
+// logErrors logs errs and returns the first resolver error, if any.
+func logErrors(ctx context.Context, errs gqlerror.List) error {
+	var first error
+	logger := jogger.FromContext(ctx)
+	for _, e := range errs {
+		if len(e.Path) == 0 {
+			logger.Warn("graphql request error", zap.String("error", e.Message))
+			continue
+		}
+		logger.Error("graphql resolver error",
+			zap.String("path", e.Path.String()),
+			zap.String("error", e.Message),
+		)
+		if first == nil {
+			first = e
+		}
+	}
+	return first
+}
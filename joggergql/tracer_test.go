@@ -0,0 +1,203 @@
+package joggergql_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/cheesycoffee/jogger"
+	"github.com/cheesycoffee/jogger/joggergql"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// newServer serves a schema without generated code whose resolvers return
+// a name, sleep or fail.
+func newServer(tracer joggergql.Tracer) *handler.Server {
+	schema := gqlparser.MustLoadSchema(&ast.Source{Input: `
+		type Query {
+			name: String!
+			slow: String!
+			broken: String!
+		}
+	`})
+	resolvers := map[string]func() (interface{}, error){
+		"name":   func() (interface{}, error) { return "ann", nil },
+		"slow":   func() (interface{}, error) { time.Sleep(20 * time.Millisecond); return "done", nil },
+		"broken": func() (interface{}, error) { return nil, errors.New("database down") },
+	}
+
+	srv := handler.New(&graphql.ExecutableSchemaMock{
+		SchemaFunc: func() *ast.Schema { return schema },
+		ComplexityFunc: func(context.Context, string, string, int, map[string]interface{}) (int, bool) {
+			return 1, true
+		},
+		ExecFunc: func(ctx context.Context) graphql.ResponseHandler {
+			ran := false
+			return func(ctx context.Context) *graphql.Response {
+				if ran {
+					return nil
+				}
+				ran = true
+				opCtx := graphql.GetOperationContext(ctx)
+				data := map[string]interface{}{}
+				for _, sel := range opCtx.Operation.SelectionSet {
+					f := sel.(*ast.Field)
+					fctx := graphql.WithFieldContext(ctx, &graphql.FieldContext{
+						Object:     "Query",
+						Field:      graphql.CollectedField{Field: f},
+						IsResolver: true,
+					})
+					res, err := opCtx.ResolverMiddleware(fctx, func(context.Context) (interface{}, error) {
+						return resolvers[f.Name]()
+					})
+					if err != nil {
+						graphql.AddError(fctx, err)
+						continue
+					}
+					data[f.Alias] = res
+				}
+				b, _ := json.Marshal(data)
+				return &graphql.Response{Data: b}
+			}
+		},
+	})
+	srv.AddTransport(transport.POST{})
+	srv.Use(extension.FixedComplexityLimit(100))
+	srv.Use(extension.AutomaticPersistedQuery{Cache: lru.New[string](10)})
+	srv.Use(tracer)
+	return srv
+}
+
+func query(t *testing.T, srv *handler.Server, body map[string]interface{}) []map[string]interface{} {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jogger.Configure(jogger.WithOutput(&buf), jogger.WithFormat(jogger.FormatJSON)); err != nil {
+		t.Fatal(err)
+	}
+	defer jogger.Configure()
+
+	b, _ := json.Marshal(body)
+	r := httptest.NewRequest("POST", "/query", bytes.NewReader(b))
+	r.Header.Set("Content-Type", "application/json")
+	srv.ServeHTTP(httptest.NewRecorder(), r)
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var e map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestTracerOperationSpan(t *testing.T) {
+	entries := query(t, newServer(joggergql.Tracer{}), map[string]interface{}{
+		"query": "query GetUser { name slow }",
+	})
+
+	if len(entries) != 1 {
+		t.Fatalf("expected only the operation span, got %v", entries)
+	}
+	e := entries[0]
+	if e["span"] != "GetUser" || e["operation"] != "query" || e["complexity"] != float64(2) {
+		t.Errorf("unexpected operation span %v", e)
+	}
+	if _, ok := e["query"]; ok {
+		t.Error("query text should not be logged by default")
+	}
+}
+
+func TestTracerPersistedQueryHash(t *testing.T) {
+	q := "{ name }"
+	sum := sha256.Sum256([]byte(q))
+	hash := hex.EncodeToString(sum[:])
+
+	entries := query(t, newServer(joggergql.Tracer{}), map[string]interface{}{
+		"query": q,
+		"extensions": map[string]interface{}{
+			"persistedQuery": map[string]interface{}{"version": 1, "sha256Hash": hash},
+		},
+	})
+
+	e := entries[0]
+	if e["span"] != "anonymous" || e["persistedQuery"] != hash {
+		t.Errorf("expected the persisted query hash tagged, got %v", e)
+	}
+	if _, ok := e["query"]; ok {
+		t.Error("query text should not be logged for persisted queries")
+	}
+}
+
+func TestTracerLogQuery(t *testing.T) {
+	entries := query(t, newServer(joggergql.Tracer{LogQuery: true}), map[string]interface{}{
+		"query": "{ name }",
+	})
+	if entries[0]["query"] != "{ name }" {
+		t.Errorf("expected the query text, got %v", entries[0])
+	}
+}
+
+func TestTracerResolverErrors(t *testing.T) {
+	entries := query(t, newServer(joggergql.Tracer{}), map[string]interface{}{
+		"query": "query Broken { name broken }",
+	})
+
+	if len(entries) != 2 {
+		t.Fatalf("expected the error and the span, got %v", entries)
+	}
+	errEntry, span := entries[0], entries[1]
+	if errEntry["level"] != "error" || errEntry["path"] != "broken" || errEntry["error"] != "database down" {
+		t.Errorf("unexpected error entry %v", errEntry)
+	}
+	if span["msg"] != "span finished with error" || span["span"] != "Broken" {
+		t.Errorf("expected the operation span to fail, got %v", span)
+	}
+}
+
+func TestTracerRequestErrors(t *testing.T) {
+	entries := query(t, newServer(joggergql.Tracer{}), map[string]interface{}{
+		"query": "{ missing }",
+	})
+
+	if len(entries) != 1 {
+		t.Fatalf("expected one validation error entry, got %v", entries)
+	}
+	if e := entries[0]; e["level"] != "warn" || !strings.Contains(e["error"].(string), "missing") {
+		t.Errorf("unexpected request error entry %v", e)
+	}
+}
+
+func TestTracerSlowResolvers(t *testing.T) {
+	entries := query(t, newServer(joggergql.Tracer{ResolverThreshold: 10 * time.Millisecond}), map[string]interface{}{
+		"query": "query Mixed { name slow }",
+	})
+
+	if len(entries) != 2 {
+		t.Fatalf("expected the slow resolver and operation spans, got %v", entries)
+	}
+	resolver, op := entries[0], entries[1]
+	if resolver["span"] != "Query.slow" || resolver["path"] != "slow" {
+		t.Errorf("unexpected resolver span %v", resolver)
+	}
+	if op["span"] != "Mixed" {
+		t.Errorf("unexpected operation span %v", op)
+	}
+}
@@ -0,0 +1,61 @@
+package joggergrpc
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap/zapcore"
+)
+
+// healthService is the method prefix of the standard health service.
+const healthService = "/grpc.health.v1.Health/"
+
+var (
+	healthMu     sync.Mutex
+	healthRemove func() // set while health checks are suppressed
+	healthChosen bool   // SuppressHealthChecks was called
+)
+
+// HealthChecks matches the entries of calls to the grpc.health.v1.Health
+// service, for jogger.SuppressMatching.
+func HealthChecks(_ zapcore.Entry, fields []zapcore.Field) bool {
+	for _, f := range fields {
+		if f.Key == "grpc.method" && f.Type == zapcore.StringType {
+			return strings.HasPrefix(f.String, healthService)
+		}
+	}
+	return false
+}
+
+// SuppressHealthChecks turns the suppression of successful health check
+// calls on or off for the process. The interceptors turn it on when they
+// are created, unless SuppressHealthChecks was called before.
+func SuppressHealthChecks(on bool) {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	healthChosen = true
+	setHealthSuppression(on)
+}
+
+// defaultHealthSuppression turns the suppression on unless the program has
+// chosen otherwise.
+func defaultHealthSuppression() {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	if !healthChosen {
+		setHealthSuppression(true)
+	}
+}
+
+// setHealthSuppression does the work of SuppressHealthChecks. healthMu must
+// be held.
+func setHealthSuppression(on bool) {
+	switch {
+	case on && healthRemove == nil:
+		healthRemove = jogger.SuppressMatching(HealthChecks)
+	case !on && healthRemove != nil:
+		healthRemove()
+		healthRemove = nil
+	}
+}
@@ -0,0 +1,192 @@
+// Package joggergrpc provides gRPC server interceptors that carry jogger's
+// request ID and correlation values through gRPC metadata and write one log
+// entry per call.
+package joggergrpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDMetadataKey is the metadata key the request ID is read from and
+// echoed in, the gRPC spelling of jogger.RequestIDHeader.
+var RequestIDMetadataKey = strings.ToLower(jogger.RequestIDHeader)
+
+// MetadataCarrier adapts gRPC metadata to jogger.Carrier.
+type MetadataCarrier metadata.MD
+
+func (c MetadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c MetadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+type config struct {
+	echoKey        string
+	payloadBytes   int
+	redact         map[string]bool
+	streamPayloads int
+}
+
+// An Option configures the interceptors.
+type Option func(*config)
+
+// EchoRequestID sets the response header metadata key the resolved request
+// ID is sent back in. The default is RequestIDMetadataKey; an empty key
+// turns echoing off.
+func EchoRequestID(key string) Option {
+	return func(c *config) {
+		c.echoKey = strings.ToLower(key)
+	}
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		echoKey:        RequestIDMetadataKey,
+		redact:         redactKeys(defaultRedactedFields),
+		streamPayloads: defaultStreamPayloads,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	defaultHealthSuppression()
+	return cfg
+}
+
+// UnaryServerInterceptor returns an interceptor that extracts the request
+// ID and propagated correlation values from the incoming metadata,
+// generating a request ID when there is none, and logs each call.
+func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
+	cfg := newConfig(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		ctx = cfg.prepare(ctx, func(md metadata.MD) { grpc.SetHeader(ctx, md) })
+
+		resp, err := handler(ctx, req)
+		jogger.FinishTailSampling(ctx, callLevel(err) >= zapcore.ErrorLevel)
+		var payloads []zap.Field
+		if cfg.logsPayloads(ctx) {
+			payloads = cfg.payloadFields("grpc.request", req)
+			if err == nil {
+				payloads = append(payloads, cfg.payloadFields("grpc.response", resp)...)
+			}
+		}
+		logCall(ctx, info.FullMethod, err, time.Since(start), payloads...)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of
+// UnaryServerInterceptor.
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	cfg := newConfig(opts)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx := cfg.prepare(ss.Context(), func(md metadata.MD) { ss.SetHeader(md) })
+
+		stream := &serverStream{ServerStream: ss, ctx: ctx}
+		if cfg.logsPayloads(ctx) {
+			stream.cfg, stream.method = cfg, info.FullMethod
+		}
+		err := handler(srv, stream)
+		jogger.FinishTailSampling(ctx, callLevel(err) >= zapcore.ErrorLevel)
+		logCall(ctx, info.FullMethod, err, time.Since(start))
+		return err
+	}
+}
+
+// prepare returns ctx with the correlation values from its incoming
+// metadata and passes the echoed request ID to setHeader.
+func (c *config) prepare(ctx context.Context, setHeader func(metadata.MD)) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = jogger.Extract(ctx, MetadataCarrier(md))
+	ctx = jogger.StartTailSampling(jogger.EnsureRequestID(ctx))
+	if c.echoKey != "" {
+		setHeader(metadata.Pairs(c.echoKey, jogger.RequestID(ctx)))
+	}
+	return ctx
+}
+
+// callLevel is Error for server-side failures, Warn for errors caused by
+// the caller and Info otherwise.
+func callLevel(err error) zapcore.Level {
+	switch status.Code(err) {
+	case codes.OK:
+		return zapcore.InfoLevel
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.FailedPrecondition,
+		codes.OutOfRange, codes.ResourceExhausted, codes.Aborted:
+		return zapcore.WarnLevel
+	}
+	return zapcore.ErrorLevel
+}
+
+// logCall logs a finished call at its callLevel, with extra fields.
+// Successful calls of requests sampled out are not logged, as with
+// jogger.Middleware.
+func logCall(ctx context.Context, method string, err error, elapsed time.Duration, extra ...zap.Field) {
+	code := status.Code(err)
+	lvl := callLevel(err)
+	if lvl == zapcore.InfoLevel && !jogger.IsSampled(ctx) {
+		return
+	}
+	ce := jogger.FromContext(ctx).Check(lvl, "finished call")
+	if ce == nil {
+		return
+	}
+	fields := []zap.Field{
+		zap.String("grpc.method", method),
+		zap.String("grpc.code", code.String()),
+		zap.Duration(jogger.CurrentFieldNames().Duration, elapsed),
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	ce.Write(append(fields, extra...)...)
+}
+
+// serverStream overrides the stream's context with the correlated one and,
+// when cfg is set, logs the first messages in each direction.
+type serverStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	cfg    *config
+	method string
+	recv   int
+	sent   int
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *serverStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil && s.cfg != nil && s.recv < s.cfg.streamPayloads {
+		s.recv++
+		s.cfg.logStreamMessage(s.ctx, s.method, "recv", s.recv, m)
+	}
+	return err
+}
+
+func (s *serverStream) SendMsg(m interface{}) error {
+	if s.cfg != nil && s.sent < s.cfg.streamPayloads {
+		s.sent++
+		s.cfg.logStreamMessage(s.ctx, s.method, "send", s.sent, m)
+	}
+	return s.ServerStream.SendMsg(m)
+}
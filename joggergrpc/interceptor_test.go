@@ -0,0 +1,384 @@
+package joggergrpc_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"github.com/cheesycoffee/jogger/joggergrpc"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/sourcecontextpb"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) entries(t *testing.T) []map[string]interface{} {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var e map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// configureBuffer captures JSON output. Health checks, which the tests use
+// as their service, are logged like any other call.
+func configureBuffer(t *testing.T, opts ...jogger.Option) *syncBuffer {
+	t.Helper()
+	joggergrpc.SuppressHealthChecks(false)
+	buf := &syncBuffer{}
+	if err := jogger.Configure(append([]jogger.Option{jogger.WithOutput(buf), jogger.WithFormat(jogger.FormatJSON)}, opts...)...); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { jogger.Configure() })
+	return buf
+}
+
+// healthClient serves the standard health service over an in-memory
+// listener with the interceptors installed.
+func healthClient(t *testing.T, opts ...joggergrpc.Option) healthpb.HealthClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(joggergrpc.UnaryServerInterceptor(opts...)),
+		grpc.StreamInterceptor(joggergrpc.StreamServerInterceptor(opts...)),
+	)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestUnaryInterceptorEchoesRequestID(t *testing.T) {
+	buf := configureBuffer(t)
+	client := healthClient(t)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "req-grpc")
+	var header metadata.MD
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}, grpc.Header(&header)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := header.Get("x-request-id"); len(got) != 1 || got[0] != "req-grpc" {
+		t.Errorf("expected request ID echoed in header metadata, got %v", got)
+	}
+	entries := buf.entries(t)
+	if len(entries) != 1 {
+		t.Fatalf("expected one call entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e["requestID"] != "req-grpc" || e["grpc.method"] != "/grpc.health.v1.Health/Check" || e["grpc.code"] != "OK" {
+		t.Errorf("unexpected call entry %v", e)
+	}
+}
+
+func TestUnaryInterceptorGeneratesRequestID(t *testing.T) {
+	buf := configureBuffer(t)
+	client := healthClient(t)
+
+	var header metadata.MD
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}, grpc.Header(&header)); err != nil {
+		t.Fatal(err)
+	}
+
+	got := header.Get("x-request-id")
+	if len(got) != 1 || got[0] == "" {
+		t.Fatalf("expected generated request ID echoed, got %v", got)
+	}
+	if e := buf.entries(t)[0]; e["requestID"] != got[0] {
+		t.Errorf("logged request ID %v does not match echoed %q", e["requestID"], got[0])
+	}
+}
+
+func TestUnaryInterceptorLogsClientErrorsAtWarn(t *testing.T) {
+	buf := configureBuffer(t)
+	client := healthClient(t)
+
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "missing"}); err == nil {
+		t.Fatal("expected NotFound for an unknown service")
+	}
+	if e := buf.entries(t)[0]; e["level"] != "warn" || e["grpc.code"] != "NotFound" {
+		t.Errorf("unexpected call entry %v", e)
+	}
+}
+
+func TestUnaryInterceptorTailSampling(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithSampling(0), jogger.WithTailSampling(10))
+	intercept := joggergrpc.UnaryServerInterceptor(joggergrpc.EchoRequestID(""))
+	info := &grpc.UnaryServerInfo{FullMethod: "/orders.Orders/Get"}
+
+	for _, code := range []codes.Code{codes.NotFound, codes.Internal} {
+		code := code
+		md := metadata.Pairs(joggergrpc.RequestIDMetadataKey, "req-"+code.String())
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+		intercept(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			jogger.Info(ctx, "loading order")
+			return nil, status.Error(code, "failed")
+		})
+	}
+
+	var got []string
+	for _, e := range buf.entries(t) {
+		got = append(got, e["requestID"].(string)+" "+e["msg"].(string))
+	}
+	want := []string{"req-NotFound finished call", "req-Internal loading order", "req-Internal finished call"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("expected only the failed call's entries kept, got %v", got)
+	}
+}
+
+func TestUnaryInterceptorSkipsUnsampledCall(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithSampling(0))
+	intercept := joggergrpc.UnaryServerInterceptor(joggergrpc.EchoRequestID(""))
+	info := &grpc.UnaryServerInfo{FullMethod: "/orders.Orders/Get"}
+
+	for _, code := range []codes.Code{codes.OK, codes.NotFound} {
+		code := code
+		md := metadata.Pairs(joggergrpc.RequestIDMetadataKey, "req-"+code.String())
+		intercept(metadata.NewIncomingContext(context.Background(), md), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(code, "done")
+		})
+	}
+
+	entries := buf.entries(t)
+	if len(entries) != 1 || entries[0]["requestID"] != "req-NotFound" {
+		t.Errorf("expected only the failed call logged, got %v", entries)
+	}
+}
+
+// fakeStream is a server stream with a fixed context.
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s fakeStream) Context() context.Context { return s.ctx }
+
+func TestStreamInterceptorSkipsUnsampledCall(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithSampling(0))
+	intercept := joggergrpc.StreamServerInterceptor(joggergrpc.EchoRequestID(""))
+	info := &grpc.StreamServerInfo{FullMethod: "/orders.Orders/Watch"}
+
+	for _, code := range []codes.Code{codes.OK, codes.Internal} {
+		code := code
+		md := metadata.Pairs(joggergrpc.RequestIDMetadataKey, "req-"+code.String())
+		ss := fakeStream{ctx: metadata.NewIncomingContext(context.Background(), md)}
+		intercept(nil, ss, info, func(srv interface{}, stream grpc.ServerStream) error {
+			return status.Error(code, "done")
+		})
+	}
+
+	entries := buf.entries(t)
+	if len(entries) != 1 || entries[0]["requestID"] != "req-Internal" {
+		t.Errorf("expected only the failed call logged, got %v", entries)
+	}
+}
+
+func TestStreamInterceptorEchoesRequestID(t *testing.T) {
+	configureBuffer(t)
+	client := healthClient(t, joggergrpc.EchoRequestID("Request-Id"))
+
+	ctx, cancel := context.WithCancel(metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "req-stream"))
+	defer cancel()
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, err := stream.Header()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := header.Get("request-id"); len(got) != 1 || got[0] != "req-stream" {
+		t.Errorf("expected request ID in the configured key, got %v", header)
+	}
+	if got := header.Get("x-request-id"); len(got) != 0 {
+		t.Errorf("expected only the configured key, got %v", header)
+	}
+}
+
+func TestHealthChecksSuppressed(t *testing.T) {
+	buf := configureBuffer(t)
+	joggergrpc.SuppressHealthChecks(true)
+	t.Cleanup(func() { joggergrpc.SuppressHealthChecks(false) })
+	jogger.ResetStats()
+	client := healthClient(t)
+
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "missing"}); err == nil {
+		t.Fatal("expected NotFound for an unknown service")
+	}
+	var checks []map[string]interface{}
+	for _, e := range buf.entries(t) {
+		if e["grpc.method"] == "/grpc.health.v1.Health/Check" {
+			checks = append(checks, e)
+		}
+	}
+	if len(checks) != 1 || checks[0]["grpc.code"] != "NotFound" {
+		t.Errorf("expected only the failed check logged, got %v", checks)
+	}
+	if got := jogger.Stats().Dropped.Suppressed; got != 1 {
+		t.Errorf("expected one suppressed entry, got %d", got)
+	}
+}
+
+// checkEntry returns the entry of the health Check call, ignoring the late
+// entries of streams from earlier tests.
+func checkEntry(t *testing.T, buf *syncBuffer) map[string]interface{} {
+	t.Helper()
+	for _, e := range buf.entries(t) {
+		if e["grpc.method"] == "/grpc.health.v1.Health/Check" {
+			return e
+		}
+	}
+	t.Fatal("no entry for the Check call")
+	return nil
+}
+
+func TestLogPayloadsAtDebug(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithLevel(zapcore.DebugLevel))
+	client := healthClient(t, joggergrpc.LogPayloads(1024))
+
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	e := checkEntry(t, buf)
+	if e["grpc.request"] != "{}" || e["grpc.response"] != `{"status":"SERVING"}` {
+		t.Errorf("unexpected payloads in %v", e)
+	}
+}
+
+func TestLogPayloadsOnlyAtDebug(t *testing.T) {
+	buf := configureBuffer(t)
+	client := healthClient(t, joggergrpc.LogPayloads(1024))
+
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if e := checkEntry(t, buf); e["grpc.request"] != nil || e["grpc.response"] != nil {
+		t.Errorf("expected no payloads at Info, got %v", e)
+	}
+}
+
+func TestLogPayloadsRedactsAndTruncates(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithLevel(zapcore.DebugLevel))
+	client := healthClient(t, joggergrpc.LogPayloads(16), joggergrpc.RedactFields("Service"))
+
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "hunter2"}); err == nil {
+		t.Fatal("expected NotFound for an unknown service")
+	}
+	e := checkEntry(t, buf)
+	if req, _ := e["grpc.request"].(string); strings.Contains(req, "hunter2") || req != `{"service":"[RED` {
+		t.Errorf("expected a redacted, truncated request, got %q", req)
+	}
+	if e["grpc.request_truncated"] != true {
+		t.Errorf("expected the request marked truncated, got %v", e)
+	}
+	if _, ok := e["grpc.response"]; ok {
+		t.Errorf("expected no response for a failed call, got %v", e)
+	}
+}
+
+func TestLogPayloadsRedactsSnakeCaseFields(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithLevel(zapcore.DebugLevel))
+	intercept := joggergrpc.UnaryServerInterceptor(joggergrpc.LogPayloads(1024), joggergrpc.RedactFields("file_name"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/files.Files/Get"}
+
+	intercept(context.Background(), &sourcecontextpb.SourceContext{FileName: "secrets/prod.yaml"}, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return map[string]string{"file_name": "secrets/dev.yaml"}, nil
+	})
+
+	entries := buf.entries(t)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %v", entries)
+	}
+	if e := entries[0]; e["grpc.request"] != `{"fileName":"[REDACTED]"}` || e["grpc.response"] != `{"file_name":"[REDACTED]"}` {
+		t.Errorf("expected the snake_case field redacted under both spellings, got %v", e)
+	}
+}
+
+func TestLogPayloadsLimitsStreamMessages(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithLevel(zapcore.DebugLevel))
+	client := healthClient(t, joggergrpc.LogPayloads(1024), joggergrpc.MaxStreamPayloads(1))
+
+	ctx, cancel := context.WithCancel(metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "req-payloads"))
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	deadline := time.Now().Add(5 * time.Second)
+	var entries []map[string]interface{}
+	for {
+		entries = entries[:0]
+		for _, e := range buf.entries(t) {
+			if e["requestID"] == "req-payloads" {
+				entries = append(entries, e)
+			}
+		}
+		if n := len(entries); n > 0 && entries[n-1]["msg"] == "finished call" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for the call entry, got %v", entries)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	got := map[string]interface{}{}
+	for _, e := range entries {
+		if e["msg"] == "stream message" {
+			got[e["grpc.direction"].(string)] = e["grpc.payload"]
+		}
+	}
+	if len(entries) != 3 || got["recv"] != "{}" || got["send"] != `{"status":"SERVING"}` {
+		t.Errorf("expected one message logged per direction, got %v", entries)
+	}
+}
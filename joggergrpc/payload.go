@@ -0,0 +1,153 @@
+package joggergrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultStreamPayloads = 5
+	redacted              = "[REDACTED]"
+)
+
+// defaultRedactedFields are the message fields masked unless RedactFields
+// says otherwise.
+var defaultRedactedFields = []string{"password", "token", "secret", "authorization"}
+
+// LogPayloads adds the request and response messages, as JSON cut at
+// maxBytes, to the entries of calls made while Debug is enabled, globally
+// or for the request ID: unary calls get grpc.request and grpc.response on
+// their "finished call" entry, and streams a "stream message" entry for
+// each of the first messages in each direction, see MaxStreamPayloads.
+// Fields named password, token, secret or authorization, or as set with
+// RedactFields, are masked at any depth.
+func LogPayloads(maxBytes int) Option {
+	return func(c *config) {
+		c.payloadBytes = maxBytes
+	}
+}
+
+// RedactFields replaces the names of the message fields LogPayloads masks.
+// Names match the JSON names of the fields regardless of case and
+// underscores, so api_token matches the apiToken protojson writes for a
+// proto field named api_token.
+func RedactFields(names ...string) Option {
+	return func(c *config) {
+		c.redact = redactKeys(names)
+	}
+}
+
+// MaxStreamPayloads sets how many messages per direction LogPayloads logs
+// for a stream, 5 by default.
+func MaxStreamPayloads(n int) Option {
+	return func(c *config) {
+		c.streamPayloads = n
+	}
+}
+
+func redactKeys(names []string) map[string]bool {
+	out := make(map[string]bool, len(names))
+	for _, n := range names {
+		out[redactKey(n)] = true
+	}
+	return out
+}
+
+// redactKey normalizes a field name for matching: lowercase, without
+// underscores.
+func redactKey(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// logsPayloads reports whether messages of calls with ctx are logged.
+func (c *config) logsPayloads(ctx context.Context) bool {
+	return c.payloadBytes > 0 && jogger.FromContext(ctx).Core().Enabled(zapcore.DebugLevel)
+}
+
+// payloadFields returns msg under key, redacted and cut at the size cap,
+// with key_truncated when it was cut.
+func (c *config) payloadFields(key string, msg interface{}) []zap.Field {
+	b, err := marshalPayload(msg)
+	if err != nil {
+		return []zap.Field{zap.String(key+"_error", err.Error())}
+	}
+	b = c.redactJSON(b)
+	if len(b) <= c.payloadBytes {
+		return []zap.Field{zap.String(key, string(b))}
+	}
+	return []zap.Field{zap.String(key, string(b[:c.payloadBytes])), zap.Bool(key+"_truncated", true)}
+}
+
+// marshalPayload encodes msg as compact JSON. protojson output is compacted
+// since it varies its whitespace on purpose.
+func marshalPayload(msg interface{}) ([]byte, error) {
+	m, ok := msg.(proto.Message)
+	if !ok {
+		return json.Marshal(msg)
+	}
+	b, err := protojson.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := json.Compact(&out, b); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// redactJSON masks the redacted fields of the JSON document b.
+func (c *config) redactJSON(b []byte) []byte {
+	var doc interface{}
+	if json.Unmarshal(b, &doc) != nil || !c.redactValue(doc) {
+		return b
+	}
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return b
+	}
+	return out
+}
+
+// redactValue masks the redacted fields in v and reports whether there
+// were any.
+func (c *config) redactValue(v interface{}) bool {
+	changed := false
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			if c.redact[redactKey(k)] {
+				v[k] = redacted
+				changed = true
+			} else if c.redactValue(val) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, val := range v {
+			if c.redactValue(val) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// logStreamMessage logs the n-th message of a stream in direction.
+func (c *config) logStreamMessage(ctx context.Context, method, direction string, n int, msg interface{}) {
+	fields := []zap.Field{
+		zap.String("grpc.method", method),
+		zap.String("grpc.direction", direction),
+		zap.Int("grpc.message", n),
+	}
+	fields = append(fields, c.payloadFields("grpc.payload", msg)...)
+	jogger.FromContext(ctx).Debug("stream message", fields...)
+}
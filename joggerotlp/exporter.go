@@ -0,0 +1,463 @@
+// Package joggerotlp exports jogger entries as OTLP logs over HTTP. An
+// Exporter is a sink writer: add it with jogger.WithSink in JSON format and
+// it batches the entries, maps them to OTLP log records and posts them in
+// protobuf to the collector's /v1/logs endpoint.
+//
+//	exp, err := joggerotlp.New("http://collector:4318/v1/logs", joggerotlp.ServiceName("api"))
+//	err = jogger.Configure(jogger.WithSink(jogger.SinkConfig{Name: "otlp", Writer: exp, Format: jogger.FormatJSON}))
+//	defer exp.Close()
+package joggerotlp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultBatchSize    = 512
+	defaultBatchTimeout = time.Second
+	defaultQueueSize    = 2048
+	defaultMaxRetries   = 5
+	initialBackoff      = 500 * time.Millisecond
+	defaultMaxBackoff   = 30 * time.Second
+	defaultFlushTimeout = 30 * time.Second
+	exportTimeout       = 10 * time.Second
+)
+
+// ErrFlushTimeout is returned by Sync and Close when the queued entries
+// were not exported within the flush timeout, see FlushTimeout.
+var ErrFlushTimeout = errors.New("joggerotlp: flush timed out")
+
+// scopeName is the instrumentation scope of the exported records.
+const scopeName = "github.com/cheesycoffee/jogger"
+
+type config struct {
+	client       *http.Client
+	headers      map[string]string
+	service      string
+	batchSize    int
+	batchTimeout time.Duration
+	queueSize    int
+	maxRetries   int
+	maxBackoff   time.Duration
+	flushTimeout time.Duration
+	onError      func(error)
+}
+
+// An Option configures an Exporter.
+type Option func(*config)
+
+// HTTPClient sets the client used for exports, http.DefaultClient by
+// default.
+func HTTPClient(c *http.Client) Option {
+	return func(cfg *config) {
+		cfg.client = c
+	}
+}
+
+// Headers adds headers to every export request, such as authentication.
+func Headers(h map[string]string) Option {
+	return func(cfg *config) {
+		cfg.headers = h
+	}
+}
+
+// ServiceName sets the service.name resource attribute.
+func ServiceName(name string) Option {
+	return func(cfg *config) {
+		cfg.service = name
+	}
+}
+
+// BatchSize sets how many records trigger an export, 512 by default.
+func BatchSize(n int) Option {
+	return func(cfg *config) {
+		cfg.batchSize = n
+	}
+}
+
+// BatchTimeout sets how long records wait for a batch to fill before they
+// are exported anyway, one second by default.
+func BatchTimeout(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.batchTimeout = d
+	}
+}
+
+// QueueSize sets how many entries wait for export before new ones are
+// dropped, 2048 by default.
+func QueueSize(n int) Option {
+	return func(cfg *config) {
+		cfg.queueSize = n
+	}
+}
+
+// MaxRetries sets how often a failed export is retried, 5 by default.
+func MaxRetries(n int) Option {
+	return func(cfg *config) {
+		cfg.maxRetries = n
+	}
+}
+
+// MaxBackoff sets the longest wait between two attempts of an export, 30
+// seconds by default. A longer Retry-After from the collector is shortened
+// to it.
+func MaxBackoff(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.maxBackoff = d
+	}
+}
+
+// FlushTimeout sets how long Sync and Close wait for the queued entries to
+// be exported before returning ErrFlushTimeout, 30 seconds by default.
+// Close then returns while the export carries on in the background, and
+// gives up on it after one attempt.
+func FlushTimeout(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.flushTimeout = d
+	}
+}
+
+// ErrorHandler is called with every export that fails for good. It runs on
+// the export goroutine and must not log through jogger.
+func ErrorHandler(fn func(error)) Option {
+	return func(cfg *config) {
+		cfg.onError = fn
+	}
+}
+
+// Exporter batches JSON-encoded entries and exports them. Write never
+// blocks on the network: entries that do not fit the queue are dropped and
+// counted.
+type Exporter struct {
+	endpoint string
+	cfg      config
+	resource *resourcepb.Resource
+
+	queue   chan []byte
+	flushes chan chan error
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+	closing sync.RWMutex // held by Write while it queues, so none queues after Close
+
+	dropped  uint64
+	exported uint64
+}
+
+// New starts an Exporter posting to endpoint, the full URL of the logs
+// endpoint such as http://localhost:4318/v1/logs.
+func New(endpoint string, opts ...Option) (*Exporter, error) {
+	if endpoint == "" {
+		return nil, errors.New("joggerotlp: empty endpoint")
+	}
+	cfg := config{
+		client:       http.DefaultClient,
+		batchSize:    defaultBatchSize,
+		batchTimeout: defaultBatchTimeout,
+		queueSize:    defaultQueueSize,
+		maxRetries:   defaultMaxRetries,
+		maxBackoff:   defaultMaxBackoff,
+		flushTimeout: defaultFlushTimeout,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.batchSize <= 0 || cfg.queueSize <= 0 || cfg.batchTimeout <= 0 || cfg.maxRetries < 0 {
+		return nil, errors.New("joggerotlp: batch size, queue size and batch timeout must be positive")
+	}
+	if cfg.maxBackoff <= 0 || cfg.flushTimeout <= 0 {
+		return nil, errors.New("joggerotlp: max backoff and flush timeout must be positive")
+	}
+
+	e := &Exporter{
+		endpoint: endpoint,
+		cfg:      cfg,
+		resource: &resourcepb.Resource{},
+		queue:    make(chan []byte, cfg.queueSize),
+		flushes:  make(chan chan error),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	if cfg.service != "" {
+		e.resource.Attributes = []*commonpb.KeyValue{stringKV("service.name", cfg.service)}
+	}
+	go e.run()
+	return e, nil
+}
+
+// Write queues one encoded entry. It drops the entry when the queue is
+// full, counted in jogger.Stats().Dropped.Overflow too, or the exporter is
+// closed.
+func (e *Exporter) Write(p []byte) (int, error) {
+	e.closing.RLock()
+	defer e.closing.RUnlock()
+	select {
+	case <-e.done:
+		atomic.AddUint64(&e.dropped, 1)
+		return len(p), nil
+	default:
+	}
+	select {
+	case e.queue <- append([]byte(nil), p...):
+	default:
+		atomic.AddUint64(&e.dropped, 1)
+		jogger.CountOverflow(1)
+	}
+	return len(p), nil
+}
+
+// Sync exports every queued entry and returns the error of that export, or
+// ErrFlushTimeout when it takes longer than the flush timeout. jogger.Sync
+// and jogger.Shutdown call it.
+func (e *Exporter) Sync() error {
+	timeout, stop := e.flushDeadline()
+	defer stop()
+	return e.sync(timeout)
+}
+
+// flushDeadline returns a channel closed once the flush timeout elapsed,
+// and a function releasing its timer.
+func (e *Exporter) flushDeadline() (<-chan struct{}, func()) {
+	timeout := make(chan struct{})
+	timer := time.AfterFunc(e.cfg.flushTimeout, func() { close(timeout) })
+	return timeout, func() { timer.Stop() }
+}
+
+func (e *Exporter) sync(timeout <-chan struct{}) error {
+	res := make(chan error, 1)
+	select {
+	case e.flushes <- res:
+	case <-e.stopped:
+		return nil
+	case <-timeout:
+		return ErrFlushTimeout
+	}
+	select {
+	case err := <-res:
+		return err
+	case <-timeout:
+		return ErrFlushTimeout
+	}
+}
+
+// Close exports the queued entries and stops the exporter, waiting at most
+// the flush timeout. Later entries are dropped.
+func (e *Exporter) Close() error {
+	timeout, stop := e.flushDeadline()
+	defer stop()
+	err := e.sync(timeout)
+	e.closing.Lock()
+	e.once.Do(func() { close(e.done) })
+	e.closing.Unlock()
+	select {
+	case <-e.stopped:
+	case <-timeout:
+		err = ErrFlushTimeout
+	}
+	return err
+}
+
+// Dropped returns how many entries were dropped, because the queue was full
+// or their export failed for good.
+func (e *Exporter) Dropped() uint64 {
+	return atomic.LoadUint64(&e.dropped)
+}
+
+// Exported returns how many records were accepted by the collector.
+func (e *Exporter) Exported() uint64 {
+	return atomic.LoadUint64(&e.exported)
+}
+
+func (e *Exporter) run() {
+	defer close(e.stopped)
+
+	batch := make([]*logspb.LogRecord, 0, e.cfg.batchSize)
+	timer := time.NewTimer(e.cfg.batchTimeout)
+	defer timer.Stop()
+
+	add := func(entry []byte) {
+		if rec, err := toRecord(entry); err == nil {
+			batch = append(batch, rec)
+		} else {
+			atomic.AddUint64(&e.dropped, 1)
+			e.report(err)
+		}
+	}
+	flush := func() error {
+		err := e.export(batch)
+		batch = batch[:0]
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(e.cfg.batchTimeout)
+		return err
+	}
+	drain := func() {
+		for {
+			select {
+			case entry := <-e.queue:
+				add(entry)
+			default:
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case entry := <-e.queue:
+			add(entry)
+			if len(batch) >= e.cfg.batchSize {
+				flush()
+			}
+		case <-timer.C:
+			flush()
+		case res := <-e.flushes:
+			drain()
+			res <- flush()
+		case <-e.done:
+			drain()
+			flush()
+			return
+		}
+	}
+}
+
+// export posts records in requests of at most the batch size, and returns
+// the first error.
+func (e *Exporter) export(records []*logspb.LogRecord) error {
+	var first error
+	for len(records) > 0 {
+		n := len(records)
+		if n > e.cfg.batchSize {
+			n = e.cfg.batchSize
+		}
+		if err := e.exportBatch(records[:n]); err != nil && first == nil {
+			first = err
+		}
+		records = records[n:]
+	}
+	return first
+}
+
+// exportBatch posts one request, retrying with exponential backoff on
+// network errors and retryable statuses, honoring Retry-After up to the
+// max backoff.
+func (e *Exporter) exportBatch(records []*logspb.LogRecord) error {
+	body, err := proto.Marshal(&collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{{
+			Resource: e.resource,
+			ScopeLogs: []*logspb.ScopeLogs{{
+				Scope:      &commonpb.InstrumentationScope{Name: scopeName},
+				LogRecords: records,
+			}},
+		}},
+	})
+	if err != nil {
+		return e.fail(len(records), err)
+	}
+
+	backoff := initialBackoff
+	for attempt := 0; ; attempt++ {
+		wait, err := e.post(body)
+		if err == nil {
+			atomic.AddUint64(&e.exported, uint64(len(records)))
+			return nil
+		}
+		if wait < 0 || attempt >= e.cfg.maxRetries {
+			return e.fail(len(records), err)
+		}
+		if wait == 0 {
+			wait = backoff
+		} else if wait > e.cfg.maxBackoff {
+			wait = e.cfg.maxBackoff
+		}
+		select {
+		case <-time.After(wait):
+		case <-e.done:
+			return e.fail(len(records), err)
+		}
+		if backoff *= 2; backoff > e.cfg.maxBackoff {
+			backoff = e.cfg.maxBackoff
+		}
+	}
+}
+
+// post sends one request. On failure it returns how long to wait before
+// retrying: zero for the default backoff, the Retry-After delay when the
+// collector sent one, and a negative duration when retrying is pointless.
+func (e *Exporter) post(body []byte) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	for k, v := range e.cfg.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.cfg.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return 0, nil
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusBadGateway,
+		resp.StatusCode == http.StatusServiceUnavailable, resp.StatusCode == http.StatusGatewayTimeout:
+		return retryAfter(resp.Header.Get("Retry-After")), fmt.Errorf("joggerotlp: export failed with status %d", resp.StatusCode)
+	}
+	return -1, fmt.Errorf("joggerotlp: export rejected with status %d", resp.StatusCode)
+}
+
+// retryAfter parses a Retry-After header in seconds or as an HTTP date.
+func retryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if s, err := strconv.Atoi(v); err == nil && s >= 0 {
+		return time.Duration(s) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+func (e *Exporter) fail(n int, err error) error {
+	atomic.AddUint64(&e.dropped, uint64(n))
+	e.report(err)
+	return err
+}
+
+func (e *Exporter) report(err error) {
+	if e.cfg.onError != nil {
+		e.cfg.onError(err)
+	}
+}
@@ -0,0 +1,285 @@
+package joggerotlp_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"github.com/cheesycoffee/jogger/joggerotlp"
+	"github.com/google/uuid"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+// collector records the log records of every export it accepts.
+type collector struct {
+	mu       sync.Mutex
+	requests int
+	records  []*logspb.LogRecord
+	resource map[string]string
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	var req collogspb.ExportLogsServiceRequest
+	if r.Header.Get("Content-Type") != "application/x-protobuf" || proto.Unmarshal(body, &req) != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests++
+	for _, rl := range req.ResourceLogs {
+		c.resource = map[string]string{}
+		for _, kv := range rl.Resource.GetAttributes() {
+			c.resource[kv.Key] = kv.Value.GetStringValue()
+		}
+		for _, sl := range rl.ScopeLogs {
+			c.records = append(c.records, sl.LogRecords...)
+		}
+	}
+}
+
+func (c *collector) snapshot() (int, []*logspb.LogRecord) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.requests, append([]*logspb.LogRecord(nil), c.records...)
+}
+
+func configure(t *testing.T, exp *joggerotlp.Exporter) {
+	t.Helper()
+	if err := jogger.Configure(jogger.WithSink(jogger.SinkConfig{Name: "otlp", Writer: exp, Format: jogger.FormatJSON})); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		jogger.Configure()
+		exp.Close()
+	})
+}
+
+func attr(rec *logspb.LogRecord, key string) string {
+	for _, kv := range rec.Attributes {
+		if kv.Key == key {
+			return kv.Value.String()
+		}
+	}
+	return ""
+}
+
+func TestExporterMapsEntries(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+	exp, err := joggerotlp.New(srv.URL, joggerotlp.ServiceName("api"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	configure(t, exp)
+
+	rid := uuid.New()
+	ctx := jogger.WithRequestID(context.Background(), rid.String())
+	span, ctx := jogger.StartSpan(ctx, "work")
+	jogger.Warn(ctx, "slow cache", zap.Int("hits", 3))
+	span.Finish(nil)
+	if err := jogger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, records := c.snapshot()
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	rec := records[0]
+	if rec.Body.GetStringValue() != "slow cache" || rec.SeverityNumber != logspb.SeverityNumber_SEVERITY_NUMBER_WARN {
+		t.Errorf("unexpected record %v", rec)
+	}
+	if string(rec.TraceId) != string(rid[:]) || len(rec.SpanId) != 8 || rec.TimeUnixNano == 0 {
+		t.Errorf("expected the trace context from the request and span IDs, got %v", rec)
+	}
+	if records[1].Body.GetStringValue() != "span finished successfully" || string(records[1].SpanId) != string(rec.SpanId) {
+		t.Errorf("expected the span finish to share the span ID, got %v", records[1])
+	}
+	if attr(rec, "hits") != "int_value:3" {
+		t.Errorf("expected hits as an int attribute, got %q", attr(rec, "hits"))
+	}
+	if c.resource["service.name"] != "api" {
+		t.Errorf("expected the service name resource attribute, got %v", c.resource)
+	}
+}
+
+func TestExporterBatchesBySize(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+	exp, err := joggerotlp.New(srv.URL, joggerotlp.BatchSize(2), joggerotlp.BatchTimeout(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	configure(t, exp)
+
+	for i := 0; i < 4; i++ {
+		jogger.Info(context.Background(), "entry")
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		if n, _ := c.snapshot(); n == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			n, _ := c.snapshot()
+			t.Fatalf("expected two full batches without a flush, got %d requests", n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestExporterRetriesAfterRetryAfter(t *testing.T) {
+	c := &collector{}
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		c.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+	exp, err := joggerotlp.New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configure(t, exp)
+
+	jogger.Info(context.Background(), "retried")
+	if err := exp.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if _, records := c.snapshot(); len(records) != 1 || atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("expected the entry after one retry, got %d records in %d calls", len(records), calls)
+	}
+}
+
+func TestExporterDropsWhenQueueIsFull(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	exp, err := joggerotlp.New(srv.URL, joggerotlp.BatchSize(1), joggerotlp.QueueSize(2), joggerotlp.MaxRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer exp.Close()
+	defer close(release)
+	before := jogger.Stats().Dropped.Overflow
+
+	for i := 0; i < 10; i++ {
+		exp.Write([]byte(`{"level":"info","msg":"x"}`))
+	}
+	if exp.Dropped() == 0 {
+		t.Error("expected entries to be dropped while the collector stalls")
+	}
+	if got := jogger.Stats().Dropped.Overflow - before; got != exp.Dropped() {
+		t.Errorf("expected the %d dropped entries counted as overflow, got %d", exp.Dropped(), got)
+	}
+}
+
+func TestExporterRejectsPermanentFailures(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+	var reported int32
+	exp, err := joggerotlp.New(srv.URL, joggerotlp.ErrorHandler(func(error) { atomic.AddInt32(&reported, 1) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer exp.Close()
+
+	exp.Write([]byte(`{"level":"info","msg":"x"}`))
+	if err := exp.Sync(); err == nil {
+		t.Error("expected Sync to report the rejected export")
+	}
+	if exp.Dropped() != 1 || atomic.LoadInt32(&reported) != 1 {
+		t.Errorf("expected one dropped and reported entry, got %d and %d", exp.Dropped(), reported)
+	}
+}
+
+func TestExporterCapsRetryAfter(t *testing.T) {
+	c := &collector{}
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		c.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+	exp, err := joggerotlp.New(srv.URL, joggerotlp.MaxBackoff(10*time.Millisecond), joggerotlp.FlushTimeout(5*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer exp.Close()
+
+	exp.Write([]byte(`{"level":"info","msg":"x"}`))
+	if err := exp.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if _, records := c.snapshot(); len(records) != 1 {
+		t.Errorf("expected the entry exported after the capped wait, got %d records", len(records))
+	}
+}
+
+func TestExporterSyncAndCloseTimeOut(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+	exp, err := joggerotlp.New(srv.URL, joggerotlp.FlushTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp.Write([]byte(`{"level":"info","msg":"x"}`))
+	start := time.Now()
+	if err := exp.Sync(); err != joggerotlp.ErrFlushTimeout {
+		t.Errorf("expected Sync to time out, got %v", err)
+	}
+	if err := exp.Close(); err != joggerotlp.ErrFlushTimeout {
+		t.Errorf("expected Close to time out, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected Sync and Close bounded by the flush timeout, took %v", elapsed)
+	}
+}
+
+func TestExporterDropsAfterClose(t *testing.T) {
+	srv := httptest.NewServer(&collector{})
+	defer srv.Close()
+	exp, err := joggerotlp.New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exp.Close(); err != nil {
+		t.Fatal(err)
+	}
+	before := jogger.Stats().Dropped.Overflow
+
+	exp.Write([]byte(`{"level":"info","msg":"late"}`))
+	if exp.Dropped() != 1 || jogger.Stats().Dropped.Overflow != before {
+		t.Errorf("expected the late entry dropped and not counted as overflow, got %d dropped", exp.Dropped())
+	}
+}
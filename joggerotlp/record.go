@@ -0,0 +1,122 @@
+package joggerotlp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"github.com/google/uuid"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+)
+
+// Keys of the JSON encoder jogger configures, taken out of the attributes.
+const (
+	keyTime    = "ts"
+	keyLevel   = "level"
+	keyMessage = "msg"
+)
+
+// severities maps zap levels to OTLP severity numbers.
+var severities = map[string]logspb.SeverityNumber{
+	"debug":  logspb.SeverityNumber_SEVERITY_NUMBER_DEBUG,
+	"info":   logspb.SeverityNumber_SEVERITY_NUMBER_INFO,
+	"warn":   logspb.SeverityNumber_SEVERITY_NUMBER_WARN,
+	"error":  logspb.SeverityNumber_SEVERITY_NUMBER_ERROR,
+	"dpanic": logspb.SeverityNumber_SEVERITY_NUMBER_ERROR2,
+	"panic":  logspb.SeverityNumber_SEVERITY_NUMBER_FATAL,
+	"fatal":  logspb.SeverityNumber_SEVERITY_NUMBER_FATAL4,
+}
+
+// toRecord maps one JSON entry to a log record. The request ID, a UUID,
+// becomes the trace ID and the first half of the span ID the span ID, the
+// same mapping joggerce uses for traceparent, so logs link to the traces
+// built from them.
+func toRecord(entry []byte) (*logspb.LogRecord, error) {
+	dec := json.NewDecoder(bytes.NewReader(entry))
+	dec.UseNumber()
+	var m map[string]interface{}
+	if err := dec.Decode(&m); err != nil {
+		return nil, errors.New("joggerotlp: entry is not JSON; add the exporter with jogger.FormatJSON")
+	}
+
+	rec := &logspb.LogRecord{ObservedTimeUnixNano: uint64(time.Now().UnixNano())}
+	if ts, ok := m[keyTime].(string); ok {
+		if t, err := time.Parse("2006-01-02T15:04:05.000Z0700", ts); err == nil {
+			rec.TimeUnixNano = uint64(t.UnixNano())
+		}
+	}
+	if lvl, ok := m[keyLevel].(string); ok {
+		rec.SeverityText = lvl
+		rec.SeverityNumber = severities[lvl]
+	}
+	if msg, ok := m[keyMessage].(string); ok {
+		rec.Body = &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: msg}}
+	}
+	names := jogger.CurrentFieldNames()
+	if rid, ok := m[names.RequestID].(string); ok {
+		if id, err := uuid.Parse(rid); err == nil {
+			rec.TraceId = id[:]
+		}
+	}
+	// Span finishes carry the span ID in spanID, other entries logged
+	// within a span in span.
+	for _, key := range []string{names.SpanID, names.Span} {
+		if sid, ok := m[key].(string); ok {
+			if id, err := uuid.Parse(sid); err == nil {
+				rec.SpanId = id[:8]
+				break
+			}
+		}
+	}
+	delete(m, keyTime)
+	delete(m, keyLevel)
+	delete(m, keyMessage)
+	rec.Attributes = attributes(m)
+	return rec, nil
+}
+
+// attributes converts decoded fields, sorted by key so records are stable.
+func attributes(m map[string]interface{}) []*commonpb.KeyValue {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	kvs := make([]*commonpb.KeyValue, 0, len(keys))
+	for _, k := range keys {
+		kvs = append(kvs, &commonpb.KeyValue{Key: k, Value: anyValue(m[k])})
+	}
+	return kvs
+}
+
+func anyValue(v interface{}) *commonpb.AnyValue {
+	switch v := v.(type) {
+	case string:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}
+	case bool:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: v}}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: i}}
+		}
+		f, _ := v.Float64()
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: f}}
+	case []interface{}:
+		values := make([]*commonpb.AnyValue, len(v))
+		for i, e := range v {
+			values[i] = anyValue(e)
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{Values: values}}}
+	case map[string]interface{}:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: &commonpb.KeyValueList{Values: attributes(v)}}}
+	}
+	return &commonpb.AnyValue{}
+}
+
+func stringKV(k, v string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: k, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}}
+}
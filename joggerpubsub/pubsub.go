@@ -0,0 +1,67 @@
+// Package joggerpubsub carries jogger's request ID and correlation values
+// through Google Cloud Pub/Sub message attributes.
+package joggerpubsub
+
+import (
+	"context"
+
+	"cloud.google.com/go/pubsub/v2"
+	"github.com/cheesycoffee/jogger"
+)
+
+type config struct {
+	names map[string]string
+}
+
+// An Option configures the attribute helpers.
+type Option func(*config)
+
+// AttributeNames renames the attributes the request ID and propagated
+// correlation values are stored in, keyed by their header name such as
+// jogger.RequestIDHeader. See jogger.RenameKeys.
+func AttributeNames(names map[string]string) Option {
+	return func(c *config) {
+		c.names = names
+	}
+}
+
+func carrier(attrs map[string]string, opts []Option) jogger.Carrier {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return jogger.RenameKeys(jogger.MapCarrier(attrs), cfg.names)
+}
+
+// Inject adds the request ID and propagated correlation values of ctx to
+// msg's attributes before it is published.
+func Inject(ctx context.Context, msg *pubsub.Message, opts ...Option) {
+	if msg.Attributes == nil {
+		msg.Attributes = map[string]string{}
+	}
+	jogger.Inject(ctx, carrier(msg.Attributes, opts))
+}
+
+// Extract returns a copy of ctx carrying the request ID and correlation
+// values found in msg's attributes, with a new request ID when msg has none.
+func Extract(ctx context.Context, msg *pubsub.Message, opts ...Option) context.Context {
+	ctx = jogger.Extract(ctx, carrier(msg.Attributes, opts))
+	ctx = jogger.EnsureRequestID(ctx)
+	return ctx
+}
+
+// WrapReceive returns a callback for Subscriber.Receive that runs fn for
+// each message inside a "consume <subscription>" span, with the context from
+// Extract. The span is tagged with the delivery attempt when the
+// subscription has a dead letter policy, which is when Pub/Sub reports it.
+func WrapReceive(subscription string, fn func(context.Context, *pubsub.Message), opts ...Option) func(context.Context, *pubsub.Message) {
+	return func(ctx context.Context, msg *pubsub.Message) {
+		span, ctx := jogger.StartSpan(Extract(ctx, msg, opts...), "consume "+subscription)
+		defer span.Finish(nil)
+		if msg.DeliveryAttempt != nil {
+			span.SetTag("deliveryAttempt", *msg.DeliveryAttempt)
+		}
+		span.SetTag("messageID", msg.ID)
+		fn(ctx, msg)
+	}
+}
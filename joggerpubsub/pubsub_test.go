@@ -0,0 +1,56 @@
+package joggerpubsub_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/pubsub/v2"
+	"github.com/cheesycoffee/jogger"
+	"github.com/cheesycoffee/jogger/joggerpubsub"
+)
+
+func TestRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jogger.Configure(jogger.WithOutput(&buf), jogger.WithFormat(jogger.FormatJSON)); err != nil {
+		t.Fatal(err)
+	}
+	defer jogger.Configure()
+
+	msg := &pubsub.Message{ID: "m-1", Data: []byte("payload")}
+	joggerpubsub.Inject(jogger.WithRequestID(context.Background(), "req-pub"), msg)
+	attempt := 2
+	msg.DeliveryAttempt = &attempt
+
+	var handled string
+	joggerpubsub.WrapReceive("orders-sub", func(ctx context.Context, m *pubsub.Message) {
+		handled = jogger.RequestID(ctx)
+	})(context.Background(), msg)
+
+	if handled != "req-pub" {
+		t.Errorf("expected the published request ID in the handler, got %q", handled)
+	}
+	var e map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(buf.String())), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e["span"] != "consume orders-sub" || e["deliveryAttempt"] != float64(2) || e["messageID"] != "m-1" {
+		t.Errorf("unexpected consume span %v", e)
+	}
+}
+
+func TestAttributeNamesAndMissingRequestID(t *testing.T) {
+	names := joggerpubsub.AttributeNames(map[string]string{jogger.RequestIDHeader: "requestId"})
+
+	msg := &pubsub.Message{}
+	joggerpubsub.Inject(jogger.WithRequestID(context.Background(), "req-1"), msg, names)
+	if msg.Attributes["requestId"] != "req-1" {
+		t.Errorf("expected the request ID under the configured name, got %v", msg.Attributes)
+	}
+
+	if jogger.RequestID(joggerpubsub.Extract(context.Background(), &pubsub.Message{})) == "" {
+		t.Error("expected a generated request ID for a message without attributes")
+	}
+}
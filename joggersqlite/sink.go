@@ -0,0 +1,228 @@
+// Package joggersqlite writes jogger entries into a SQLite database, so a
+// debugging session can be queried instead of grepped. It uses the pure Go
+// modernc.org/sqlite driver and needs no cgo. Add a Sink with jogger.WithSink
+// in JSON format:
+//
+//	sink, err := joggersqlite.Open("debug.db")
+//	err = jogger.Configure(jogger.WithSink(jogger.SinkConfig{Name: "sqlite", Writer: sink, Format: jogger.FormatJSON}))
+//	defer sink.Close()
+//
+// The database runs in WAL mode, so sqlite3 or Tail can read it while the
+// program is writing.
+package joggersqlite
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+)
+
+const (
+	defaultBatchSize     = 100
+	defaultFlushInterval = 200 * time.Millisecond
+)
+
+const schema = `CREATE TABLE IF NOT EXISTS entries (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	ts TEXT NOT NULL,
+	level TEXT NOT NULL,
+	msg TEXT NOT NULL,
+	requestID TEXT NOT NULL DEFAULT '',
+	span TEXT NOT NULL DEFAULT '',
+	fields TEXT NOT NULL DEFAULT '{}'
+);
+CREATE INDEX IF NOT EXISTS entries_requestID ON entries (requestID);`
+
+type config struct {
+	batchSize     int
+	flushInterval time.Duration
+}
+
+// An Option configures a Sink.
+type Option func(*config)
+
+// BatchSize sets how many entries are inserted per transaction, 100 by
+// default.
+func BatchSize(n int) Option {
+	return func(c *config) {
+		c.batchSize = n
+	}
+}
+
+// FlushInterval sets how long entries wait for a batch to fill before they
+// are inserted anyway, 200 milliseconds by default.
+func FlushInterval(d time.Duration) Option {
+	return func(c *config) {
+		c.flushInterval = d
+	}
+}
+
+// Sink inserts JSON-encoded entries into the entries table of a database:
+// ts, level, msg, requestID, span and the remaining fields as a JSON
+// object.
+type Sink struct {
+	db  *sql.DB
+	cfg config
+
+	mu      sync.Mutex
+	pending [][]byte
+	lastErr error
+	timer   *time.Timer
+	closed  bool
+}
+
+// Open opens or creates the database at path and its entries table.
+func Open(path string, opts ...Option) (*Sink, error) {
+	cfg := config{batchSize: defaultBatchSize, flushInterval: defaultFlushInterval}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.batchSize <= 0 || cfg.flushInterval <= 0 {
+		return nil, errors.New("joggersqlite: batch size and flush interval must be positive")
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// One connection keeps the WAL setting and serializes the inserts.
+	db.SetMaxOpenConns(1)
+	for _, stmt := range []string{"PRAGMA journal_mode=WAL", "PRAGMA busy_timeout=5000", schema} {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return &Sink{db: db, cfg: cfg}, nil
+}
+
+// DB returns the database, for queries with Tail.
+func (s *Sink) DB() *sql.DB {
+	return s.db
+}
+
+// Write queues one entry. Entries are inserted when a batch is full, when
+// the flush interval elapses and on Sync.
+func (s *Sink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, errors.New("joggersqlite: sink closed")
+	}
+	s.pending = append(s.pending, append([]byte(nil), p...))
+	if len(s.pending) >= s.cfg.batchSize {
+		return len(p), s.flushLocked()
+	}
+	if s.timer == nil {
+		s.timer = time.AfterFunc(s.cfg.flushInterval, func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.timer = nil
+			s.lastErr = s.flushLocked()
+		})
+	}
+	return len(p), nil
+}
+
+// Sync inserts the queued entries. It also reports a failure of an earlier
+// timed insert.
+func (s *Sink) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.flushLocked()
+	if err == nil {
+		err = s.lastErr
+	}
+	s.lastErr = nil
+	return err
+}
+
+// Close inserts the queued entries and closes the database.
+func (s *Sink) Close() error {
+	err := s.Sync()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return err
+	}
+	s.closed = true
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	if cerr := s.db.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// flushLocked inserts the pending entries in one transaction. s.mu must be
+// held.
+func (s *Sink) flushLocked() error {
+	if len(s.pending) == 0 {
+		return nil
+	}
+	entries := s.pending
+	s.pending = nil
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(`INSERT INTO entries (ts, level, msg, requestID, span, fields) VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+	for _, entry := range entries {
+		r, err := toRow(entry)
+		if err != nil {
+			continue
+		}
+		if _, err := stmt.Exec(r.ts, r.level, r.msg, r.requestID, r.span, r.fields); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+type row struct {
+	ts, level, msg, requestID, span, fields string
+}
+
+// toRow splits the columns off a JSON entry. Entries logged within a span
+// carry its ID in span, span finishes in spanID; the span column holds the
+// ID either way.
+func toRow(entry []byte) (row, error) {
+	dec := json.NewDecoder(bytes.NewReader(entry))
+	dec.UseNumber()
+	var m map[string]interface{}
+	if err := dec.Decode(&m); err != nil {
+		return row{}, err
+	}
+	take := func(key string) string {
+		v, _ := m[key].(string)
+		delete(m, key)
+		return v
+	}
+	names := jogger.CurrentFieldNames()
+	r := row{ts: take("ts"), level: take("level"), msg: take("msg"), requestID: take(names.RequestID)}
+	if id, ok := m[names.SpanID].(string); ok {
+		r.span = id
+	} else {
+		r.span = take(names.Span)
+	}
+	fields, err := json.Marshal(m)
+	if err != nil {
+		return row{}, err
+	}
+	r.fields = string(fields)
+	return r, nil
+}
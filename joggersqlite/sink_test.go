@@ -0,0 +1,109 @@
+package joggersqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"github.com/cheesycoffee/jogger/joggersqlite"
+	"go.uber.org/zap"
+)
+
+func open(t *testing.T, opts ...joggersqlite.Option) (*joggersqlite.Sink, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "logs.db")
+	sink, err := joggersqlite.Open(path, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := jogger.Configure(jogger.WithSink(jogger.SinkConfig{Name: "sqlite", Writer: sink, Format: jogger.FormatJSON})); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		jogger.Configure()
+		sink.Close()
+	})
+	return sink, path
+}
+
+func TestSinkStoresEntries(t *testing.T) {
+	sink, _ := open(t)
+
+	ctx := jogger.WithRequestID(context.Background(), "req-1")
+	span, ctx := jogger.StartSpan(ctx, "checkout")
+	jogger.Warn(ctx, "card declined", zap.Int("attempt", 2))
+	span.Finish(nil)
+	jogger.Error(context.Background(), "unrelated", zap.Error(errors.New("boom")))
+	if err := jogger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := joggersqlite.Tail(sink.DB(), joggersqlite.Filter{RequestID: "req-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected the two entries of req-1, got %v", entries)
+	}
+	e := entries[0]
+	if e.Message != "card declined" || e.Level != "warn" || e.Time.IsZero() || e.Fields["attempt"] != float64(2) {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if e.Span == "" || entries[1].Span != e.Span {
+		t.Errorf("expected both entries in the span column of the same span, got %q and %q", e.Span, entries[1].Span)
+	}
+
+	entries, err = joggersqlite.Tail(sink.DB(), joggersqlite.Filter{Level: "error", Contains: "unrel"})
+	if err != nil || len(entries) != 1 || entries[0].Fields["error"] != "boom" {
+		t.Errorf("expected the error entry, got %v, %v", entries, err)
+	}
+}
+
+func TestSinkFlushesOnInterval(t *testing.T) {
+	_, path := open(t, joggersqlite.FlushInterval(10*time.Millisecond))
+
+	jogger.Info(context.Background(), "timed")
+
+	// A second connection reads while the sink keeps the database open,
+	// which WAL mode allows.
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		entries, err := joggersqlite.Tail(db, joggersqlite.Filter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) == 1 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the entry to be inserted without a Sync")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestTailLimit(t *testing.T) {
+	sink, _ := open(t, joggersqlite.BatchSize(2))
+
+	for _, msg := range []string{"a", "b", "c"} {
+		jogger.Info(context.Background(), msg)
+	}
+	jogger.Sync()
+
+	entries, err := joggersqlite.Tail(sink.DB(), joggersqlite.Filter{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Message != "b" || entries[1].Message != "c" {
+		t.Errorf("expected the two latest entries oldest first, got %v", entries)
+	}
+}
@@ -0,0 +1,90 @@
+package joggersqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+const defaultTailLimit = 100
+
+// Filter selects the entries Tail returns. Empty fields match everything.
+type Filter struct {
+	Level     string
+	RequestID string
+	Span      string
+	// Contains matches entries whose message contains it.
+	Contains string
+	// Limit is the number of entries returned, 100 by default.
+	Limit int
+}
+
+// Entry is one row of the entries table.
+type Entry struct {
+	ID        int64
+	Time      time.Time
+	Level     string
+	Message   string
+	RequestID string
+	Span      string
+	Fields    map[string]interface{}
+}
+
+// Tail returns the latest entries matching f, oldest first.
+func Tail(db *sql.DB, f Filter) ([]Entry, error) {
+	var where []string
+	var args []interface{}
+	add := func(cond string, arg interface{}) {
+		where = append(where, cond)
+		args = append(args, arg)
+	}
+	if f.Level != "" {
+		add("level = ?", f.Level)
+	}
+	if f.RequestID != "" {
+		add("requestID = ?", f.RequestID)
+	}
+	if f.Span != "" {
+		add("span = ?", f.Span)
+	}
+	if f.Contains != "" {
+		add("instr(msg, ?) > 0", f.Contains)
+	}
+	limit := f.Limit
+	if limit <= 0 {
+		limit = defaultTailLimit
+	}
+
+	query := "SELECT id, ts, level, msg, requestID, span, fields FROM entries"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	rows, err := db.Query(query, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		var ts, fields string
+		if err := rows.Scan(&e.ID, &ts, &e.Level, &e.Message, &e.RequestID, &e.Span, &fields); err != nil {
+			return nil, err
+		}
+		e.Time, _ = time.Parse("2006-01-02T15:04:05.000Z0700", ts)
+		if err := json.Unmarshal([]byte(fields), &e.Fields); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
@@ -0,0 +1,28 @@
+package joggertemporal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestReplayCoreDropsEntriesWhileReplaying(t *testing.T) {
+	var buf bytes.Buffer
+	replaying := true
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&buf), zapcore.DebugLevel)
+	l := zap.New(newReplayCore(core, func() bool { return replaying })).With(zap.String("workflowID", "wf"))
+
+	l.Info("during replay")
+	replaying = false
+	l.Info("live")
+
+	if strings.Contains(buf.String(), "during replay") {
+		t.Error("entries logged while replaying must be dropped")
+	}
+	if !strings.Contains(buf.String(), "live") {
+		t.Error("entries logged after replay must be written")
+	}
+}
@@ -0,0 +1,152 @@
+// Package joggertemporal adapts jogger to Temporal workers: a log.Logger
+// backed by jogger, replay-safe workflow loggers and a span per activity
+// execution.
+package joggertemporal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cheesycoffee/jogger"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/workflow"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// logger implements Temporal's log.Logger on top of the jogger base logger,
+// resolved on every call so it follows jogger.Configure.
+type logger struct {
+	fields []zap.Field
+}
+
+var (
+	_ log.Logger     = logger{}
+	_ log.WithLogger = logger{}
+)
+
+// NewLogger returns a log.Logger for client.Options.Logger that writes
+// through jogger. Key-value pairs become fields.
+func NewLogger() log.Logger {
+	return logger{}
+}
+
+func (l logger) Debug(msg string, keyvals ...interface{}) { l.log(zapcore.DebugLevel, msg, keyvals) }
+func (l logger) Info(msg string, keyvals ...interface{})  { l.log(zapcore.InfoLevel, msg, keyvals) }
+func (l logger) Warn(msg string, keyvals ...interface{})  { l.log(zapcore.WarnLevel, msg, keyvals) }
+func (l logger) Error(msg string, keyvals ...interface{}) { l.log(zapcore.ErrorLevel, msg, keyvals) }
+
+func (l logger) With(keyvals ...interface{}) log.Logger {
+	fields := append(append([]zap.Field(nil), l.fields...), keyvalFields(keyvals)...)
+	return logger{fields: fields}
+}
+
+func (l logger) log(lvl zapcore.Level, msg string, keyvals []interface{}) {
+	if ce := jogger.FromContext(nil).Check(lvl, msg); ce != nil {
+		ce.Write(append(l.fields, keyvalFields(keyvals)...)...)
+	}
+}
+
+// keyvalFields turns alternating keys and values into fields. A key without
+// a value is logged under "extra".
+func keyvalFields(keyvals []interface{}) []zap.Field {
+	fields := make([]zap.Field, 0, (len(keyvals)+1)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		if i+1 == len(keyvals) {
+			fields = append(fields, zap.Any("extra", keyvals[i]))
+			break
+		}
+		key, ok := keyvals[i].(string)
+		if !ok {
+			key = fmt.Sprint(keyvals[i])
+		}
+		fields = append(fields, zap.Any(key, keyvals[i+1]))
+	}
+	return fields
+}
+
+// Workflow returns a logger for workflow code tagged with the workflowID,
+// runID and workflowType of ctx. Entries are dropped while the workflow is
+// replaying history, so each is written once however often the code
+// reruns.
+func Workflow(ctx workflow.Context) *zap.Logger {
+	info := workflow.GetInfo(ctx)
+	return jogger.FromContext(nil).
+		WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+			return newReplayCore(c, func() bool { return workflow.IsReplaying(ctx) })
+		})).
+		With(
+			zap.String("workflowID", info.WorkflowExecution.ID),
+			zap.String("runID", info.WorkflowExecution.RunID),
+			zap.String("workflowType", info.WorkflowType.Name),
+		)
+}
+
+// Activity returns the context logger of an activity tagged with the
+// workflowID, runID, activityType and attempt of ctx.
+func Activity(ctx context.Context) *zap.Logger {
+	return jogger.FromContext(ctx).With(activityFields(activity.GetInfo(ctx))...)
+}
+
+func activityFields(info activity.Info) []zap.Field {
+	return []zap.Field{
+		zap.String("workflowID", info.WorkflowExecution.ID),
+		zap.String("runID", info.WorkflowExecution.RunID),
+		zap.String("activityType", info.ActivityType.Name),
+		zap.Int32("attempt", info.Attempt),
+	}
+}
+
+// replayCore drops entries while replaying reports true. It is checked per
+// entry because a workflow switches from replaying to live mid-execution.
+type replayCore struct {
+	zapcore.Core
+	replaying func() bool
+}
+
+func newReplayCore(c zapcore.Core, replaying func() bool) zapcore.Core {
+	return &replayCore{Core: c, replaying: replaying}
+}
+
+func (c *replayCore) With(fields []zapcore.Field) zapcore.Core {
+	return newReplayCore(c.Core.With(fields), c.replaying)
+}
+
+func (c *replayCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.replaying() {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// NewWorkerInterceptor returns a worker interceptor that runs each activity
+// execution inside a span named after the activity type and tagged like
+// Activity. Register it in worker.Options.Interceptors.
+func NewWorkerInterceptor() interceptor.WorkerInterceptor {
+	return &workerInterceptor{}
+}
+
+type workerInterceptor struct {
+	interceptor.WorkerInterceptorBase
+}
+
+func (w *workerInterceptor) InterceptActivity(ctx context.Context, next interceptor.ActivityInboundInterceptor) interceptor.ActivityInboundInterceptor {
+	return &activityInbound{ActivityInboundInterceptorBase: interceptor.ActivityInboundInterceptorBase{Next: next}}
+}
+
+type activityInbound struct {
+	interceptor.ActivityInboundInterceptorBase
+}
+
+func (a *activityInbound) ExecuteActivity(ctx context.Context, in *interceptor.ExecuteActivityInput) (res interface{}, err error) {
+	info := activity.GetInfo(ctx)
+	span, ctx := jogger.StartSpan(ctx, info.ActivityType.Name)
+	defer span.Finish(&err)
+	span.SetTag("workflowID", info.WorkflowExecution.ID)
+	span.SetTag("runID", info.WorkflowExecution.RunID)
+	span.SetTag("attempt", info.Attempt)
+
+	return a.Next.ExecuteActivity(ctx, in)
+}
@@ -0,0 +1,99 @@
+package joggertemporal_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"github.com/cheesycoffee/jogger/joggertemporal"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+)
+
+func capture(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	if err := jogger.Configure(jogger.WithOutput(buf), jogger.WithFormat(jogger.FormatJSON)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { jogger.Configure() })
+	return buf
+}
+
+func entries(t *testing.T, buf *bytes.Buffer) map[string]map[string]interface{} {
+	t.Helper()
+	byMsg := map[string]map[string]interface{}{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var e map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		byMsg[e["msg"].(string)] = e
+	}
+	return byMsg
+}
+
+func TestLoggerAdapter(t *testing.T) {
+	buf := capture(t)
+
+	var l log.Logger = joggertemporal.NewLogger()
+	l.(log.WithLogger).With("Namespace", "default").Warn("poll failed", "attempt", 2, "dangling")
+
+	e := entries(t, buf)["poll failed"]
+	if e["level"] != "warn" || e["Namespace"] != "default" || e["attempt"] != float64(2) || e["extra"] != "dangling" {
+		t.Errorf("unexpected entry %v", e)
+	}
+}
+
+func Greet(ctx context.Context, name string) (string, error) {
+	joggertemporal.Activity(ctx).Info("greeting")
+	return "hello " + name, nil
+}
+
+func GreetWorkflow(ctx workflow.Context, name string) (string, error) {
+	joggertemporal.Workflow(ctx).Info("workflow started")
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{StartToCloseTimeout: time.Minute})
+	var out string
+	err := workflow.ExecuteActivity(ctx, Greet, name).Get(ctx, &out)
+	return out, err
+}
+
+func TestWorkflowAndActivity(t *testing.T) {
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.SetWorkerOptions(worker.Options{
+		Interceptors: []interceptor.WorkerInterceptor{joggertemporal.NewWorkerInterceptor()},
+	})
+	env.RegisterWorkflow(GreetWorkflow)
+	env.RegisterActivity(Greet)
+
+	buf := capture(t)
+	env.ExecuteWorkflow(GreetWorkflow, "ann")
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatal(err)
+	}
+
+	byMsg := entries(t, buf)
+	wf := byMsg["workflow started"]
+	if wf == nil || wf["workflowType"] != "GreetWorkflow" || wf["workflowID"] == "" || wf["runID"] == "" {
+		t.Errorf("unexpected workflow entry %v", wf)
+	}
+	act := byMsg["greeting"]
+	if act == nil || act["activityType"] != "Greet" || act["attempt"] != float64(1) || act["workflowID"] != wf["workflowID"] {
+		t.Errorf("unexpected activity entry %v", act)
+	}
+	span := byMsg["span finished successfully"]
+	if span == nil || span["span"] != "Greet" || span["attempt"] != float64(1) || span["runID"] != wf["runID"] {
+		t.Errorf("unexpected activity span %v", span)
+	}
+	if act["span"] != span["spanID"] {
+		t.Errorf("activity logs should be inside the activity span: %v", act)
+	}
+}
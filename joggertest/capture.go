@@ -0,0 +1,135 @@
+package joggertest
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxCapturedEntries bounds the entries a RequestCapture keeps.
+const maxCapturedEntries = 1000
+
+type capturedEntry struct {
+	requestID string
+	line      string
+}
+
+// A RequestCapture keeps the entries logged with a request ID during a
+// test, see CaptureRequests.
+type RequestCapture struct {
+	t   testing.TB
+	enc zapcore.Encoder
+
+	mu      sync.Mutex
+	entries []capturedEntry // ring buffer, oldest at next once full
+	next    int
+	dropped int
+	owned   map[string]bool // IDs of Context, nil until it is called
+	ids     int
+}
+
+// CaptureRequests keeps the entries logged with a request ID by any jogger
+// instance until the end of t and, if t failed, prints them with t.Log,
+// grouped by request in the order they were logged. Passing tests print
+// nothing. Only the last 1000 entries are kept.
+//
+// Tests running in parallel see each other's entries; give the requests of
+// the test an ID from Context to keep only those.
+//
+//	func TestCheckout(t *testing.T) {
+//		t.Parallel()
+//		capture := joggertest.CaptureRequests(t)
+//		ctx := capture.Context(context.Background())
+//		...
+//	}
+func CaptureRequests(t testing.TB) *RequestCapture {
+	t.Helper()
+	c := &RequestCapture{
+		t:       t,
+		enc:     zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig()),
+		entries: make([]capturedEntry, 0, 64),
+	}
+	remove := jogger.Tap(c.record)
+	t.Cleanup(func() {
+		remove()
+		if t.Failed() {
+			c.dump()
+		}
+	})
+	return c
+}
+
+// Context returns ctx with a new request ID belonging to the capture. Once
+// Context has been called, the capture keeps only the requests it named.
+func (c *RequestCapture) Context(ctx context.Context) context.Context {
+	c.mu.Lock()
+	c.ids++
+	id := c.t.Name() + "#" + strconv.Itoa(c.ids)
+	if c.owned == nil {
+		c.owned = map[string]bool{}
+	}
+	c.owned[id] = true
+	c.mu.Unlock()
+	return jogger.WithRequestID(ctx, id)
+}
+
+func (c *RequestCapture) record(ent zapcore.Entry, fields []zapcore.Field) {
+	key := jogger.CurrentFieldNames().RequestID
+	requestID := ""
+	for _, f := range fields {
+		if f.Key == key && f.Type == zapcore.StringType {
+			requestID = f.String
+		}
+	}
+	if requestID == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.owned != nil && !c.owned[requestID] {
+		return
+	}
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return
+	}
+	e := capturedEntry{requestID: requestID, line: strings.TrimSuffix(buf.String(), "\n")}
+	buf.Free()
+	if len(c.entries) < maxCapturedEntries {
+		c.entries = append(c.entries, e)
+		return
+	}
+	c.entries[c.next] = e
+	c.next = (c.next + 1) % maxCapturedEntries
+	c.dropped++
+}
+
+// dump prints the kept entries, grouped by request.
+func (c *RequestCapture) dump() {
+	c.mu.Lock()
+	ordered := append(append([]capturedEntry(nil), c.entries[c.next:]...), c.entries[:c.next]...)
+	dropped := c.dropped
+	c.mu.Unlock()
+
+	var order []string
+	groups := map[string][]string{}
+	for _, e := range ordered {
+		if _, ok := groups[e.requestID]; !ok {
+			order = append(order, e.requestID)
+		}
+		groups[e.requestID] = append(groups[e.requestID], e.line)
+	}
+	if dropped > 0 {
+		c.t.Logf("jogger: %d older entries were not kept", dropped)
+	}
+	for _, id := range order {
+		c.t.Logf("jogger: request %s\n%s", id, strings.Join(groups[id], "\n"))
+	}
+}
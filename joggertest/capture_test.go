@@ -0,0 +1,113 @@
+package joggertest_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"github.com/cheesycoffee/jogger/joggertest"
+)
+
+// fakeT runs the cleanups of CaptureRequests on demand and records what they
+// log.
+type fakeT struct {
+	testing.TB
+	failed   bool
+	cleanups []func()
+	logs     []string
+}
+
+func (f *fakeT) Failed() bool      { return f.failed }
+func (f *fakeT) Cleanup(fn func()) { f.cleanups = append(f.cleanups, fn) }
+func (f *fakeT) Logf(format string, args ...interface{}) {
+	f.logs = append(f.logs, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeT) finish() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+}
+
+func quietOutput(t *testing.T) {
+	if err := jogger.Configure(jogger.WithOutput(ioutil.Discard)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { jogger.Configure() })
+}
+
+func TestCaptureRequestsDumpsOnFailure(t *testing.T) {
+	quietOutput(t)
+	ft := &fakeT{TB: t, failed: true}
+	joggertest.CaptureRequests(ft)
+
+	a := jogger.WithRequestID(context.Background(), "req-a")
+	b := jogger.WithRequestID(context.Background(), "req-b")
+	jogger.Info(a, "a started")
+	jogger.Info(b, "b started")
+	jogger.Error(a, "a failed")
+	jogger.Info(context.Background(), "no request")
+	ft.finish()
+
+	if len(ft.logs) != 2 {
+		t.Fatalf("expected one dump per request, got %q", ft.logs)
+	}
+	if !strings.HasPrefix(ft.logs[0], "jogger: request req-a\n") || !strings.Contains(ft.logs[0], "a started") || !strings.Contains(ft.logs[0], "a failed") {
+		t.Errorf("unexpected dump of req-a %q", ft.logs[0])
+	}
+	if strings.Index(ft.logs[0], "a started") > strings.Index(ft.logs[0], "a failed") {
+		t.Errorf("expected entries in logging order, got %q", ft.logs[0])
+	}
+	if !strings.Contains(ft.logs[1], "b started") || strings.Contains(ft.logs[1], "a failed") {
+		t.Errorf("unexpected dump of req-b %q", ft.logs[1])
+	}
+}
+
+func TestCaptureRequestsQuietOnSuccess(t *testing.T) {
+	quietOutput(t)
+	ft := &fakeT{TB: t}
+	joggertest.CaptureRequests(ft)
+
+	jogger.Info(jogger.WithRequestID(context.Background(), "req-ok"), "fine")
+	ft.finish()
+
+	if len(ft.logs) != 0 {
+		t.Errorf("expected nothing printed for a passing test, got %q", ft.logs)
+	}
+}
+
+func TestCaptureRequestsKeepsOwnRequests(t *testing.T) {
+	quietOutput(t)
+	ft := &fakeT{TB: t, failed: true}
+	capture := joggertest.CaptureRequests(ft)
+
+	jogger.Info(capture.Context(context.Background()), "mine")
+	jogger.Info(jogger.WithRequestID(context.Background(), "req-other"), "another test's")
+	ft.finish()
+
+	if len(ft.logs) != 1 || !strings.Contains(ft.logs[0], "mine") {
+		t.Errorf("expected only the capture's own request, got %q", ft.logs)
+	}
+}
+
+func TestCaptureRequestsBounded(t *testing.T) {
+	quietOutput(t)
+	ft := &fakeT{TB: t, failed: true}
+	joggertest.CaptureRequests(ft)
+
+	ctx := jogger.WithRequestID(context.Background(), "req-busy")
+	for i := 0; i < 1005; i++ {
+		jogger.Info(ctx, fmt.Sprintf("entry %d", i))
+	}
+	ft.finish()
+
+	if len(ft.logs) != 2 || ft.logs[0] != "jogger: 5 older entries were not kept" {
+		t.Fatalf("expected the oldest entries dropped, got %d logs", len(ft.logs))
+	}
+	if strings.Contains(ft.logs[1], "entry 4\t") || !strings.Contains(ft.logs[1], "entry 1004") {
+		t.Error("expected the most recent entries kept")
+	}
+}
@@ -0,0 +1,242 @@
+package joggertest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of joggertest.Golden")
+
+// A Normalizer replaces the volatile value of a field in the entries
+// compared by Golden. It gets every field, nested ones by their own key,
+// and returns value unchanged for those it does not handle.
+type Normalizer func(key string, value interface{}) interface{}
+
+var uuidPattern = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+
+// ReplaceUUIDs replaces the UUIDs in string values, such as span IDs and
+// generated request IDs, with "<uuid>".
+func ReplaceUUIDs(_ string, value interface{}) interface{} {
+	if s, ok := value.(string); ok {
+		return uuidPattern.ReplaceAllString(s, "<uuid>")
+	}
+	return value
+}
+
+// durationKeys are the fields jogger reports time measurements in.
+var durationKeys = map[string]bool{
+	"duration": true, "self_time": true, "elapsed": true, "handler_ms": true,
+	"queue_wait_ms": true, "deadline_remaining_ms": true, "clock_skew_ms": true,
+}
+
+// ZeroDurations sets the durations jogger reports, such as duration and
+// self_time, to 0.
+func ZeroDurations(key string, value interface{}) interface{} {
+	if _, ok := value.(float64); ok && (durationKeys[key] || key == jogger.CurrentFieldNames().Duration) {
+		return 0
+	}
+	return value
+}
+
+// volatileFields hides the timestamps and positions of entries.
+func volatileFields(key string, value interface{}) interface{} {
+	switch key {
+	case "ts", "span_start", "span_end", "caller", "stacktrace", "goroutine":
+		return "<" + key + ">"
+	}
+	return value
+}
+
+// ReplaceField returns a Normalizer setting the fields named key to value.
+func ReplaceField(key string, value interface{}) Normalizer {
+	return func(k string, v interface{}) interface{} {
+		if k == key {
+			return value
+		}
+		return v
+	}
+}
+
+type goldenConfig struct {
+	normalizers []Normalizer
+}
+
+// A GoldenOption configures Golden.
+type GoldenOption func(*goldenConfig)
+
+// WithNormalizer adds n to the normalizers of Golden, which by default hide
+// timestamps, callers, stack traces and goroutine IDs and apply
+// ReplaceUUIDs and ZeroDurations. Added normalizers run after those.
+func WithNormalizer(n Normalizer) GoldenOption {
+	return func(c *goldenConfig) {
+		c.normalizers = append(c.normalizers, n)
+	}
+}
+
+// Golden runs a scenario and compares the entries it logs with the golden
+// file testdata/<name>.json, failing t with a diff when they differ, so
+// that a renamed field or a changed level is noticed before it breaks a
+// dashboard. run gets a context with a request ID of its own; the entries
+// logged with it or contexts derived from it, at or above the level of the
+// default instance, are compared. Volatile values are normalized first,
+// see WithNormalizer, and the entries are written as JSON with sorted
+// keys. Run the tests with -update to write the golden files.
+func Golden(t testing.TB, name string, run func(ctx context.Context), opts ...GoldenOption) {
+	t.Helper()
+	cfg := goldenConfig{normalizers: []Normalizer{volatileFields, ReplaceUUIDs, ZeroDurations}}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	requestID := "golden-" + name
+	var (
+		mu      sync.Mutex
+		entries []map[string]interface{}
+	)
+	entryEnc := zapcore.NewJSONEncoder(goldenEncoderConfig())
+	remove := jogger.Tap(func(ent zapcore.Entry, fields []zapcore.Field) {
+		if !hasRequestID(fields, jogger.CurrentFieldNames().RequestID, requestID) {
+			return
+		}
+		buf, err := entryEnc.EncodeEntry(ent, fields)
+		if err != nil {
+			return
+		}
+		var e map[string]interface{}
+		err = json.Unmarshal(buf.Bytes(), &e)
+		buf.Free()
+		if err != nil {
+			return
+		}
+		mu.Lock()
+		entries = append(entries, e)
+		mu.Unlock()
+	})
+	run(jogger.WithRequestID(context.Background(), requestID))
+	remove()
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, e := range entries {
+		normalizeMap(e, cfg.normalizers)
+	}
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(entries); err != nil {
+		t.Fatalf("joggertest: encoding entries: %v", err)
+	}
+	got := out.Bytes()
+
+	path := filepath.Join("testdata", name+".json")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("joggertest: %v", err)
+		}
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("joggertest: %v", err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Errorf("joggertest: %v; run the test with -update to create it", err)
+		return
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("joggertest: entries differ from %s (-want +got):\n%s", path, lineDiff(string(want), string(got)))
+	}
+}
+
+func hasRequestID(fields []zapcore.Field, key, requestID string) bool {
+	for _, f := range fields {
+		if f.Key == key && f.Type == zapcore.StringType && f.String == requestID {
+			return true
+		}
+	}
+	return false
+}
+
+// goldenEncoderConfig matches the JSON format of jogger.
+func goldenEncoderConfig() zapcore.EncoderConfig {
+	cfg := zap.NewProductionEncoderConfig()
+	cfg.EncodeTime = zapcore.ISO8601TimeEncoder
+	return cfg
+}
+
+func normalizeMap(m map[string]interface{}, normalizers []Normalizer) {
+	for k, v := range m {
+		m[k] = normalizeValue(k, v, normalizers)
+	}
+}
+
+func normalizeValue(key string, v interface{}, normalizers []Normalizer) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		normalizeMap(v, normalizers)
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeValue(key, item, normalizers)
+		}
+		return v
+	}
+	for _, n := range normalizers {
+		v = n(key, v)
+	}
+	return v
+}
+
+// lineDiff returns the lines of want and got that differ, prefixed with "-"
+// and "+", around their longest common subsequence.
+func lineDiff(want, got string) string {
+	a := strings.Split(strings.TrimSuffix(want, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var out strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			fmt.Fprintf(&out, "  %s\n", a[i])
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			fmt.Fprintf(&out, "+ %s\n", b[j])
+			j++
+		default:
+			fmt.Fprintf(&out, "- %s\n", a[i])
+			i++
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,59 @@
+package joggertest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"github.com/cheesycoffee/jogger/joggertest"
+	"go.uber.org/zap"
+)
+
+// goldenT records the errors of Golden instead of failing the test.
+type goldenT struct {
+	testing.TB
+	errors []string
+}
+
+func (g *goldenT) Errorf(format string, args ...interface{}) {
+	g.errors = append(g.errors, fmt.Sprintf(format, args...))
+}
+
+func checkout(ctx context.Context) {
+	jogger.Info(ctx, "checkout started", zap.Int("items", 2))
+	span, ctx := jogger.StartSpan(ctx, "payment.charge")
+	span.SetTag("provider", "acme")
+	err := errors.New("card declined")
+	span.Finish(&err)
+	jogger.Warn(ctx, "checkout failed", zap.String("reason", "payment"))
+}
+
+func TestGolden(t *testing.T) {
+	quietOutput(t)
+	joggertest.Golden(t, "checkout", checkout)
+}
+
+func TestGoldenReportsChanges(t *testing.T) {
+	quietOutput(t)
+	gt := &goldenT{TB: t}
+	joggertest.Golden(gt, "checkout", func(ctx context.Context) {
+		jogger.Info(ctx, "checkout started", zap.Int("item_count", 2))
+	})
+
+	if len(gt.errors) != 1 || !strings.Contains(gt.errors[0], `-     "items": 2,`) || !strings.Contains(gt.errors[0], `+     "item_count": 2,`) {
+		t.Errorf("expected a diff naming the renamed field, got %q", gt.errors)
+	}
+}
+
+func TestGoldenMissingFile(t *testing.T) {
+	quietOutput(t)
+	gt := &goldenT{TB: t}
+	joggertest.Golden(gt, "does-not-exist", func(ctx context.Context) {})
+
+	if len(gt.errors) != 1 || !strings.Contains(gt.errors[0], "-update") {
+		t.Errorf("expected a hint to run with -update, got %q", gt.errors)
+	}
+}
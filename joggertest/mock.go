@@ -0,0 +1,111 @@
+// Package joggertest provides helpers for testing code that logs through
+// jogger.
+package joggertest
+
+import (
+	"sync"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// A Call is one logging call recorded by a MockLogger.
+type Call struct {
+	Level   zapcore.Level
+	Logger  string // the name set with Named, "" if none
+	Message string
+	// Fields holds the fields added with With followed by those of the
+	// call.
+	Fields []zap.Field
+}
+
+// Field returns the value of the field named key, as zap's map encoder
+// reports it.
+func (c Call) Field(key string) (interface{}, bool) {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.Fields {
+		f.AddTo(enc)
+	}
+	v, ok := enc.Fields[key]
+	return v, ok
+}
+
+type recorder struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+// MockLogger is a jogger.Logger that records its calls instead of writing
+// them. Loggers derived with With and Named record into the same list. It
+// is safe for concurrent use.
+//
+//	mock := joggertest.NewMockLogger()
+//	ctx := jogger.WithLogger(context.Background(), mock)
+//	svc.Do(ctx) // logs with jogger.L(ctx)
+//	calls := mock.Calls()
+type MockLogger struct {
+	rec    *recorder
+	name   string
+	fields []zap.Field
+}
+
+var _ jogger.Logger = (*MockLogger)(nil)
+
+// NewMockLogger returns an empty MockLogger.
+func NewMockLogger() *MockLogger {
+	return &MockLogger{rec: &recorder{}}
+}
+
+func (m *MockLogger) log(lvl zapcore.Level, msg string, fields []zap.Field) {
+	all := make([]zap.Field, 0, len(m.fields)+len(fields))
+	all = append(append(all, m.fields...), fields...)
+	m.rec.mu.Lock()
+	defer m.rec.mu.Unlock()
+	m.rec.calls = append(m.rec.calls, Call{Level: lvl, Logger: m.name, Message: msg, Fields: all})
+}
+
+func (m *MockLogger) Debug(msg string, fields ...zap.Field) { m.log(zapcore.DebugLevel, msg, fields) }
+func (m *MockLogger) Info(msg string, fields ...zap.Field)  { m.log(zapcore.InfoLevel, msg, fields) }
+func (m *MockLogger) Warn(msg string, fields ...zap.Field)  { m.log(zapcore.WarnLevel, msg, fields) }
+func (m *MockLogger) Error(msg string, fields ...zap.Field) { m.log(zapcore.ErrorLevel, msg, fields) }
+
+// With returns a logger recording fields with every call.
+func (m *MockLogger) With(fields ...zap.Field) jogger.Logger {
+	all := make([]zap.Field, 0, len(m.fields)+len(fields))
+	return &MockLogger{rec: m.rec, name: m.name, fields: append(append(all, m.fields...), fields...)}
+}
+
+// Named returns a logger recording name, joined to the current one with a
+// dot like zap does.
+func (m *MockLogger) Named(name string) jogger.Logger {
+	if m.name != "" {
+		name = m.name + "." + name
+	}
+	return &MockLogger{rec: m.rec, name: name, fields: m.fields}
+}
+
+// Calls returns a copy of the recorded calls, oldest first.
+func (m *MockLogger) Calls() []Call {
+	m.rec.mu.Lock()
+	defer m.rec.mu.Unlock()
+	return append([]Call(nil), m.rec.calls...)
+}
+
+// Messages returns the messages of the recorded calls at lvl.
+func (m *MockLogger) Messages(lvl zapcore.Level) []string {
+	var msgs []string
+	for _, c := range m.Calls() {
+		if c.Level == lvl {
+			msgs = append(msgs, c.Message)
+		}
+	}
+	return msgs
+}
+
+// Reset forgets the recorded calls.
+func (m *MockLogger) Reset() {
+	m.rec.mu.Lock()
+	defer m.rec.mu.Unlock()
+	m.rec.calls = nil
+}
@@ -0,0 +1,47 @@
+package joggertest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"github.com/cheesycoffee/jogger/joggertest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func charge(ctx context.Context, amount int) {
+	l := jogger.L(ctx).Named("billing").With(zap.Int("amount", amount))
+	if amount <= 0 {
+		l.Warn("rejected charge")
+		return
+	}
+	l.Info("charged")
+}
+
+func TestMockLogger(t *testing.T) {
+	mock := joggertest.NewMockLogger()
+	ctx := jogger.WithLogger(context.Background(), mock)
+
+	charge(ctx, 0)
+	charge(ctx, 5)
+
+	calls := mock.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("expected two calls, got %v", calls)
+	}
+	if c := calls[0]; c.Level != zapcore.WarnLevel || c.Message != "rejected charge" || c.Logger != "billing" {
+		t.Errorf("unexpected first call %+v", c)
+	}
+	if v, ok := calls[1].Field("amount"); !ok || v != int64(5) {
+		t.Errorf("expected the With field on the call, got %v", calls[1].Fields)
+	}
+	if msgs := mock.Messages(zapcore.InfoLevel); len(msgs) != 1 || msgs[0] != "charged" {
+		t.Errorf("unexpected Info messages %v", msgs)
+	}
+
+	mock.Reset()
+	if len(mock.Calls()) != 0 {
+		t.Error("expected Reset to forget the calls")
+	}
+}
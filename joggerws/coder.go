@@ -0,0 +1,69 @@
+package joggerws
+
+import (
+	"context"
+	"errors"
+
+	"github.com/coder/websocket"
+)
+
+// Conn wraps a github.com/coder/websocket connection. Messages going through
+// Read and Write are counted; the streaming Reader and Writer methods of the
+// embedded connection are not.
+type Conn struct {
+	*websocket.Conn
+	t *tracker
+}
+
+// WrapConn starts logging conn, an accepted or dialed connection, in a span
+// derived from ctx. The connection is reported closed when Close or
+// CloseNow is called, or when Read returns the peer's close frame or fails.
+func WrapConn(ctx context.Context, conn *websocket.Conn, opts ...Option) *Conn {
+	return &Conn{Conn: conn, t: newTracker(ctx, conn.Subprotocol(), opts)}
+}
+
+// Context returns the connection span's context, for logging per-message
+// work with the connection's spanID.
+func (c *Conn) Context() context.Context {
+	return c.t.ctx
+}
+
+// Read reads a data message and counts it.
+func (c *Conn) Read(ctx context.Context) (websocket.MessageType, []byte, error) {
+	typ, p, err := c.Conn.Read(ctx)
+	if err != nil {
+		var ce websocket.CloseError
+		if errors.As(err, &ce) {
+			c.t.closed(int(ce.Code), ce.Reason)
+		} else {
+			c.t.closed(closeAbnormal, err.Error())
+		}
+		return typ, p, err
+	}
+	c.t.received(len(p))
+	return typ, p, nil
+}
+
+// Write writes a data message and counts it once written.
+func (c *Conn) Write(ctx context.Context, typ websocket.MessageType, p []byte) error {
+	if err := c.Conn.Write(ctx, typ, p); err != nil {
+		return err
+	}
+	c.t.sent(len(p))
+	return nil
+}
+
+// Close closes the connection with code and reason and logs the closure.
+func (c *Conn) Close(code websocket.StatusCode, reason string) error {
+	err := c.Conn.Close(code, reason)
+	c.t.closed(int(code), reason)
+	return err
+}
+
+// CloseNow closes the connection without a close handshake, which is
+// logged as an abnormal closure.
+func (c *Conn) CloseNow() error {
+	err := c.Conn.CloseNow()
+	c.t.closed(closeAbnormal, "")
+	return err
+}
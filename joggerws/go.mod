@@ -0,0 +1,17 @@
+module github.com/cheesycoffee/jogger/joggerws
+
+go 1.26.0
+
+require (
+	github.com/cheesycoffee/jogger v0.0.0
+	github.com/coder/websocket v1.8.15
+	github.com/gorilla/websocket v1.5.3
+	go.uber.org/zap v1.27.0
+)
+
+require (
+	github.com/google/uuid v1.6.0 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+)
+
+replace github.com/cheesycoffee/jogger => ../
@@ -0,0 +1,85 @@
+package joggerws
+
+import (
+	"context"
+	"encoding/binary"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// GorillaConn wraps a github.com/gorilla/websocket connection. Text and
+// binary messages going through ReadMessage and WriteMessage are counted;
+// NextReader, NextWriter and the JSON helpers of the embedded connection
+// are not.
+type GorillaConn struct {
+	*websocket.Conn
+	t *tracker
+
+	mu          sync.Mutex
+	closeCode   int
+	closeReason string
+}
+
+// WrapGorillaConn starts logging conn, an upgraded or dialed connection, in
+// a span derived from ctx. A close frame written with WriteMessage sets the
+// code and reason logged by Close; a connection closed without one is
+// logged as an abnormal closure, as is a failed ReadMessage.
+func WrapGorillaConn(ctx context.Context, conn *websocket.Conn, opts ...Option) *GorillaConn {
+	return &GorillaConn{
+		Conn:      conn,
+		t:         newTracker(ctx, conn.Subprotocol(), opts),
+		closeCode: closeAbnormal,
+	}
+}
+
+// Context returns the connection span's context, for logging per-message
+// work with the connection's spanID.
+func (c *GorillaConn) Context() context.Context {
+	return c.t.ctx
+}
+
+// ReadMessage reads a data message and counts it.
+func (c *GorillaConn) ReadMessage() (int, []byte, error) {
+	typ, p, err := c.Conn.ReadMessage()
+	if err != nil {
+		if ce, ok := err.(*websocket.CloseError); ok {
+			c.t.closed(ce.Code, ce.Text)
+		} else {
+			c.t.closed(closeAbnormal, err.Error())
+		}
+		return typ, p, err
+	}
+	c.t.received(len(p))
+	return typ, p, nil
+}
+
+// WriteMessage writes a message, counting data messages and remembering
+// the code and reason of a close message.
+func (c *GorillaConn) WriteMessage(typ int, data []byte) error {
+	if err := c.Conn.WriteMessage(typ, data); err != nil {
+		return err
+	}
+	switch typ {
+	case websocket.TextMessage, websocket.BinaryMessage:
+		c.t.sent(len(data))
+	case websocket.CloseMessage:
+		c.mu.Lock()
+		c.closeCode, c.closeReason = websocket.CloseNoStatusReceived, ""
+		if len(data) >= 2 {
+			c.closeCode, c.closeReason = int(binary.BigEndian.Uint16(data)), string(data[2:])
+		}
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+// Close closes the underlying network connection and logs the closure.
+func (c *GorillaConn) Close() error {
+	err := c.Conn.Close()
+	c.mu.Lock()
+	code, reason := c.closeCode, c.closeReason
+	c.mu.Unlock()
+	c.t.closed(code, reason)
+	return err
+}
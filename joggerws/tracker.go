@@ -0,0 +1,116 @@
+// Package joggerws logs WebSocket connections as a whole: one span for the
+// connection lifetime, periodic traffic summaries and a close entry, rather
+// than an entry per frame. Adapters are provided for github.com/coder/websocket
+// (formerly nhooyr.io/websocket) and github.com/gorilla/websocket.
+package joggerws
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+)
+
+const defaultHeartbeatInterval = time.Minute
+
+// Close codes from RFC 6455 that end a connection normally.
+const (
+	closeNormal    = 1000
+	closeGoingAway = 1001
+	// closeAbnormal is reported when the connection dropped without a
+	// close frame.
+	closeAbnormal = 1006
+)
+
+type config struct {
+	heartbeat time.Duration
+}
+
+// An Option configures a wrapped connection.
+type Option func(*config)
+
+// HeartbeatInterval sets how often the traffic summary is logged, one
+// minute by default. Zero or less turns it off.
+func HeartbeatInterval(d time.Duration) Option {
+	return func(c *config) {
+		c.heartbeat = d
+	}
+}
+
+// tracker counts a connection's traffic and logs its lifetime. The span it
+// starts gives the connection's entries a shared spanID; the close entry
+// takes the place of the span's finish entry so that its level follows the
+// close code and not the connection's, necessarily long, duration.
+type tracker struct {
+	ctx    context.Context
+	logger *zap.Logger
+	start  time.Time
+	stop   func()
+
+	messagesIn, messagesOut int64
+	bytesIn, bytesOut       int64
+
+	once sync.Once
+}
+
+func newTracker(ctx context.Context, subprotocol string, opts []Option) *tracker {
+	cfg := config{heartbeat: defaultHeartbeatInterval}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	_, ctx = jogger.StartSpan(ctx, "websocket")
+	t := &tracker{
+		ctx:    ctx,
+		logger: jogger.FromContext(ctx).With(zap.String("subprotocol", subprotocol)),
+		start:  time.Now(),
+		stop:   func() {},
+	}
+	t.logger.Info("websocket opened")
+	if cfg.heartbeat > 0 {
+		t.stop = jogger.Heartbeat(ctx, cfg.heartbeat, "websocket active", t.trafficFields)
+	}
+	return t
+}
+
+func (t *tracker) received(n int) {
+	atomic.AddInt64(&t.messagesIn, 1)
+	atomic.AddInt64(&t.bytesIn, int64(n))
+}
+
+func (t *tracker) sent(n int) {
+	atomic.AddInt64(&t.messagesOut, 1)
+	atomic.AddInt64(&t.bytesOut, int64(n))
+}
+
+func (t *tracker) trafficFields() []zap.Field {
+	return []zap.Field{
+		zap.Int64("messagesIn", atomic.LoadInt64(&t.messagesIn)),
+		zap.Int64("messagesOut", atomic.LoadInt64(&t.messagesOut)),
+		zap.Int64("bytesIn", atomic.LoadInt64(&t.bytesIn)),
+		zap.Int64("bytesOut", atomic.LoadInt64(&t.bytesOut)),
+	}
+}
+
+// closed logs the close entry once, at Info for normal closures and Warn
+// otherwise.
+func (t *tracker) closed(code int, reason string) {
+	t.once.Do(func() {
+		t.stop()
+		fields := append(t.trafficFields(),
+			zap.Duration(jogger.CurrentFieldNames().Duration, time.Since(t.start)),
+			zap.Int("closeCode", code),
+		)
+		if reason != "" {
+			fields = append(fields, zap.String("closeReason", reason))
+		}
+		if code == closeNormal || code == closeGoingAway {
+			t.logger.Info("websocket closed", fields...)
+		} else {
+			t.logger.Warn("websocket closed", fields...)
+		}
+	})
+}
@@ -0,0 +1,242 @@
+package joggerws_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"github.com/cheesycoffee/jogger/joggerws"
+	"github.com/coder/websocket"
+	gorilla "github.com/gorilla/websocket"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) entries(t *testing.T) []map[string]interface{} {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		var e map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// find returns the entries logged with msg.
+func (b *syncBuffer) find(t *testing.T, msg string) []map[string]interface{} {
+	t.Helper()
+	var out []map[string]interface{}
+	for _, e := range b.entries(t) {
+		if e["msg"] == msg {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func configure(t *testing.T) *syncBuffer {
+	t.Helper()
+	buf := &syncBuffer{}
+	if err := jogger.Configure(jogger.WithOutput(buf), jogger.WithFormat(jogger.FormatJSON)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { jogger.Configure() })
+	return buf
+}
+
+// echoServer accepts connections with coder/websocket and echoes messages
+// until Read fails. done is closed when the handler returns.
+func echoServer(t *testing.T, opts ...joggerws.Option) (url string, done chan struct{}) {
+	t.Helper()
+	done = make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		raw, err := websocket.Accept(w, r, &websocket.AcceptOptions{Subprotocols: []string{"chat"}})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn := joggerws.WrapConn(jogger.WithRequestID(r.Context(), "req-ws"), raw, opts...)
+		for {
+			typ, p, err := conn.Read(context.Background())
+			if err != nil {
+				return
+			}
+			if err := conn.Write(context.Background(), typ, p); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http"), done
+}
+
+func dial(t *testing.T, url string) *websocket.Conn {
+	t.Helper()
+	c, _, err := websocket.Dial(context.Background(), url, &websocket.DialOptions{Subprotocols: []string{"chat"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range []string{"hello", "world!"} {
+		if err := c.Write(context.Background(), websocket.MessageText, []byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := c.Read(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return c
+}
+
+func TestWrapConnNormalClose(t *testing.T) {
+	buf := configure(t)
+	url, done := echoServer(t)
+
+	c := dial(t, url)
+	c.Close(websocket.StatusNormalClosure, "bye")
+	<-done
+
+	closed := buf.find(t, "websocket closed")
+	if len(closed) != 1 {
+		t.Fatalf("expected one close entry, got %v", buf.entries(t))
+	}
+	e := closed[0]
+	if e["level"] != "info" || e["closeCode"] != float64(1000) || e["closeReason"] != "bye" {
+		t.Errorf("unexpected close entry %v", e)
+	}
+	if e["subprotocol"] != "chat" || e["requestID"] != "req-ws" {
+		t.Errorf("expected the subprotocol and request ID, got %v", e)
+	}
+	if e["messagesIn"] != float64(2) || e["messagesOut"] != float64(2) || e["bytesIn"] != float64(11) || e["bytesOut"] != float64(11) {
+		t.Errorf("unexpected counters %v", e)
+	}
+	if open := buf.find(t, "websocket opened"); len(open) != 1 || open[0]["span"] != e["span"] {
+		t.Errorf("expected the open and close entries in the same span, got %v", buf.entries(t))
+	}
+}
+
+func TestWrapConnAbnormalClose(t *testing.T) {
+	buf := configure(t)
+	url, done := echoServer(t)
+
+	c := dial(t, url)
+	c.CloseNow()
+	<-done
+
+	closed := buf.find(t, "websocket closed")
+	if len(closed) != 1 || closed[0]["level"] != "warn" || closed[0]["closeCode"] != float64(1006) {
+		t.Errorf("expected an abnormal closure at Warn, got %v", closed)
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	buf := configure(t)
+	url, done := echoServer(t, joggerws.HeartbeatInterval(10*time.Millisecond))
+
+	c := dial(t, url)
+	time.Sleep(50 * time.Millisecond)
+	c.Close(websocket.StatusGoingAway, "")
+	<-done
+
+	beats := buf.find(t, "websocket active")
+	if len(beats) == 0 {
+		t.Fatalf("expected heartbeat entries, got %v", buf.entries(t))
+	}
+	if beats[len(beats)-1]["messagesIn"] != float64(2) {
+		t.Errorf("expected the heartbeat to report traffic, got %v", beats[len(beats)-1])
+	}
+	if closed := buf.find(t, "websocket closed"); len(closed) != 1 || closed[0]["level"] != "info" {
+		t.Errorf("expected going away to be a normal closure, got %v", closed)
+	}
+}
+
+func gorillaServer(t *testing.T, closeFrame bool) (url string, done chan struct{}) {
+	t.Helper()
+	done = make(chan struct{})
+	upgrader := gorilla.Upgrader{Subprotocols: []string{"chat"}}
+	srv := httptest.NewServer(jogger.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		raw, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn := joggerws.WrapGorillaConn(r.Context(), raw)
+		defer conn.Close()
+		typ, p, err := conn.ReadMessage()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.WriteMessage(typ, p)
+		if closeFrame {
+			conn.WriteMessage(gorilla.CloseMessage, gorilla.FormatCloseMessage(gorilla.CloseNormalClosure, "done"))
+		}
+	})))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http"), done
+}
+
+func TestWrapGorillaConn(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		closeFrame bool
+		level      string
+		code       float64
+	}{
+		{"close frame", true, "info", 1000},
+		{"no close frame", false, "warn", 1006},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := configure(t)
+			url, done := gorillaServer(t, tc.closeFrame)
+
+			c, _, err := (&gorilla.Dialer{Subprotocols: []string{"chat"}}).Dial(url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+			if err := c.WriteMessage(gorilla.TextMessage, []byte("ping")); err != nil {
+				t.Fatal(err)
+			}
+			c.ReadMessage()
+			<-done
+
+			closed := buf.find(t, "websocket closed")
+			if len(closed) != 1 {
+				t.Fatalf("expected one close entry, got %v", buf.entries(t))
+			}
+			e := closed[0]
+			if e["level"] != tc.level || e["closeCode"] != tc.code || e["subprotocol"] != "chat" {
+				t.Errorf("unexpected close entry %v", e)
+			}
+			if e["messagesIn"] != float64(1) || e["bytesOut"] != float64(4) {
+				t.Errorf("unexpected counters %v", e)
+			}
+			if e["requestID"] == nil {
+				t.Errorf("expected the middleware's request ID, got %v", e)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package jogger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxLateTags bounds the tags a span buffers after Finish.
+const maxLateTags = 64
+
+// lateTagWarned holds the span names a dropped late tag was reported for.
+var lateTagWarned sync.Map
+
+// lateTag handles a tag set after Finish. Within the span's LateTagGrace it
+// is buffered for the "span tags (late)" entry. Otherwise it is dropped and
+// counted in Stats().LateTags, with one warning per span name, and a DPanic
+// in development mode. s.mu must be held.
+func (s *Span) lateTag(f zap.Field) {
+	grace := s.settings.lateTagGrace
+	if grace > 0 && time.Since(s.finishedAt) <= grace && len(s.late) < maxLateTags {
+		s.late = append(s.late, f)
+		if s.lateTimer == nil {
+			s.lateTimer = time.AfterFunc(time.Until(s.finishedAt.Add(grace)), s.flushLateTags)
+		}
+		return
+	}
+
+	atomic.AddUint64(&stats.lateTags, 1)
+	if s.strict != nil {
+		s.strict.misuse("jogger: SetTag after Finish", zap.String("span", s.name), zap.String("tag", f.Key))
+	}
+	if _, warned := lateTagWarned.LoadOrStore(s.name, struct{}{}); !warned {
+		s.logger.Warn("jogger: SetTag after Finish, tag dropped", zap.String("tag", f.Key))
+	}
+}
+
+func (s *Span) flushLateTags() {
+	s.mu.Lock()
+	late := s.late
+	s.late = nil
+	s.mu.Unlock()
+	if len(late) > 0 {
+		s.logger.Info("span tags (late)", late...)
+	}
+}
@@ -0,0 +1,69 @@
+package jogger_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+)
+
+func TestLateTagDropped(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	jogger.ResetStats()
+	// Warnings are once per name for the life of the process.
+	name := fmt.Sprintf("late.dropped.%d", time.Now().UnixNano())
+
+	for i := 0; i < 2; i++ {
+		span, _ := jogger.StartSpan(context.Background(), name)
+		span.Finish(nil)
+		span.SetTag("rows", 3)
+	}
+
+	var warnings int
+	for _, e := range decodeEntries(t, buf) {
+		if e["msg"] == "jogger: SetTag after Finish, tag dropped" {
+			warnings++
+			if e["tag"] != "rows" || e["span"] != name {
+				t.Errorf("unexpected warning %v", e)
+			}
+		}
+		if _, ok := e["rows"]; ok {
+			t.Errorf("expected the late tag to be dropped, got %v", e)
+		}
+	}
+	if warnings != 1 {
+		t.Errorf("expected one warning per span name, got %d", warnings)
+	}
+	if n := jogger.Stats().LateTags; n != 2 {
+		t.Errorf("expected two late tags counted, got %d", n)
+	}
+}
+
+func TestLateTagGrace(t *testing.T) {
+	buf := configureSyncBuffer(t)
+	jogger.ResetStats()
+
+	span, _ := jogger.StartSpan(context.Background(), "late.kept", jogger.LateTagGrace(20*time.Millisecond))
+	span.Finish(nil)
+	span.SetTag("rows", 3)
+	span.SetTag("cached", true)
+	time.Sleep(60 * time.Millisecond)
+	span.SetTag("afterGrace", 1)
+
+	entries := decodeEntries(t, bytes.NewBufferString(buf.String()))
+	var late map[string]interface{}
+	for _, e := range entries {
+		if e["msg"] == "span tags (late)" {
+			late = e
+		}
+	}
+	if late == nil || late["rows"] != float64(3) || late["cached"] != true || late["spanID"] != entries[0]["spanID"] {
+		t.Fatalf("expected an amended entry with both tags and the span ID, got %v", entries)
+	}
+	if n := jogger.Stats().LateTags; n != 1 {
+		t.Errorf("expected the tag after the grace window to be dropped, got %d", n)
+	}
+}
@@ -0,0 +1,52 @@
+package jogger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// lazyValue defers fn until the encoder asks for the value. zap encodes
+// reflected fields with encoding/json on both the console and JSON paths,
+// so MarshalJSON is only reached for entries that are actually written.
+type lazyValue func() interface{}
+
+func (fn lazyValue) MarshalJSON() (b []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("jogger: lazy field panicked: %v", r)
+		}
+	}()
+	return json.Marshal(fn())
+}
+
+// Lazy returns a field whose value is computed by fn only if the entry is
+// encoded, so expensive values cost nothing when the level suppresses them.
+//
+// fn runs while the entry is encoded, which may be on another goroutine if
+// an asynchronous sink is in use, and may run more than once if the entry
+// goes to several sinks. It must not read request state that can change
+// after the log call returns.
+func Lazy(key string, fn func() interface{}) zap.Field {
+	if fn == nil {
+		return zap.Skip()
+	}
+	return zap.Reflect(key, lazyValue(fn))
+}
+
+// DebugIf logs at Debug level only when cond is true.
+func DebugIf(ctx context.Context, cond bool, msg string, fields ...zap.Field) {
+	if cond {
+		logAt(ctx, nil, zapcore.DebugLevel, msg, fields)
+	}
+}
+
+// InfoIf logs at Info level only when cond is true.
+func InfoIf(ctx context.Context, cond bool, msg string, fields ...zap.Field) {
+	if cond {
+		logAt(ctx, nil, zapcore.InfoLevel, msg, fields)
+	}
+}
@@ -0,0 +1,80 @@
+package jogger_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLazyNotEvaluatedWhenSuppressed(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithLevel(zapcore.InfoLevel))
+
+	calls := 0
+	jogger.Debug(context.Background(), "suppressed", jogger.Lazy("state", func() interface{} {
+		calls++
+		return map[string]int{"n": 1}
+	}))
+
+	if calls != 0 {
+		t.Errorf("expected lazy field not to be evaluated, got %d calls", calls)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no output, got %s", buf.String())
+	}
+}
+
+func TestLazyEvaluatedWhenWritten(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	calls := 0
+	jogger.Info(context.Background(), "written", jogger.Lazy("state", func() interface{} {
+		calls++
+		return map[string]int{"n": 1}
+	}))
+
+	if calls != 1 {
+		t.Errorf("expected one evaluation, got %d", calls)
+	}
+	state, ok := decodeEntries(t, buf)[0]["state"].(map[string]interface{})
+	if !ok || state["n"] != float64(1) {
+		t.Errorf("expected structured lazy value, got %s", buf.String())
+	}
+}
+
+func TestLazyConsoleAndPanics(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatConsole))
+
+	jogger.Info(context.Background(), "console",
+		jogger.Lazy("ok", func() interface{} { return "fine" }),
+		jogger.Lazy("bad", func() interface{} { panic("boom") }),
+	)
+
+	out := buf.String()
+	if !strings.Contains(out, `"ok": "fine"`) {
+		t.Errorf("expected lazy value in console output: %s", out)
+	}
+	if !strings.Contains(out, "lazy field panicked: boom") {
+		t.Errorf("expected panic to be reported as a field error: %s", out)
+	}
+}
+
+func TestInfoIfAndDebugIf(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithLevel(zapcore.DebugLevel))
+
+	ctx := context.Background()
+	jogger.InfoIf(ctx, false, "skipped info")
+	jogger.DebugIf(ctx, false, "skipped debug")
+	jogger.InfoIf(ctx, true, "kept info")
+	jogger.DebugIf(ctx, true, "kept debug")
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d: %s", len(entries), buf.String())
+	}
+	if entries[0]["msg"] != "kept info" || entries[1]["msg"] != "kept debug" {
+		t.Errorf("unexpected entries %v", entries)
+	}
+}
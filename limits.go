@@ -0,0 +1,202 @@
+package jogger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// WithMaxFieldLength truncates string and byte slice field values longer
+// than n bytes. Truncated strings end with "…(truncated, was N bytes)" and
+// the entry gets a truncated_fields count. Zero disables truncation.
+func WithMaxFieldLength(n int) Option {
+	return func(c *config) error {
+		if n < 0 {
+			return errors.New("jogger: max field length must not be negative")
+		}
+		c.maxFieldLength = n
+		return nil
+	}
+}
+
+// WithMaxEntrySize caps the encoded size of an entry at n bytes. When an
+// entry would be larger, its own fields are dropped largest first and their
+// keys are listed in a dropped_fields field. Fields added with With are
+// never dropped. Measuring costs one extra encoding per entry. Zero
+// disables the cap.
+func WithMaxEntrySize(n int) Option {
+	return func(c *config) error {
+		if n < 0 {
+			return errors.New("jogger: max entry size must not be negative")
+		}
+		c.maxEntrySize = n
+		return nil
+	}
+}
+
+// truncateCore shortens long string and byte values before they reach the
+// encoder.
+type truncateCore struct {
+	zapcore.Core
+	max       int
+	truncated int
+}
+
+func newTruncateCore(c zapcore.Core, max int) zapcore.Core {
+	return &truncateCore{Core: c, max: max}
+}
+
+func (c *truncateCore) With(fields []zapcore.Field) zapcore.Core {
+	fields, n := truncateFields(fields, c.max)
+	return &truncateCore{Core: c.Core.With(fields), max: c.max, truncated: c.truncated + n}
+}
+
+func (c *truncateCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *truncateCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	fields, n := truncateFields(fields, c.max)
+	if n += c.truncated; n > 0 {
+		fields = append(fields, zap.Int("truncated_fields", n))
+	}
+	return c.Core.Write(ent, fields)
+}
+
+// truncateFields returns fields with long values shortened and how many were
+// changed. The input slice is copied before any change, since it belongs to
+// the caller.
+func truncateFields(fields []zapcore.Field, max int) ([]zapcore.Field, int) {
+	n := 0
+	copied := false
+	for i, f := range fields {
+		short, ok := truncateField(f, max)
+		if !ok {
+			continue
+		}
+		if !copied {
+			fields = append([]zapcore.Field(nil), fields...)
+			copied = true
+		}
+		fields[i] = short
+		n++
+	}
+	return fields, n
+}
+
+func truncateField(f zapcore.Field, max int) (zapcore.Field, bool) {
+	switch f.Type {
+	case zapcore.StringType:
+		if len(f.String) > max {
+			return zap.String(f.Key, truncateString(f.String, max)), true
+		}
+	case zapcore.ByteStringType:
+		if b, ok := f.Interface.([]byte); ok && len(b) > max {
+			return zap.ByteString(f.Key, []byte(truncateString(string(b), max))), true
+		}
+	case zapcore.BinaryType:
+		// Binary values are base64 encoded, so a text suffix would be
+		// unreadable; they are cut and only counted.
+		if b, ok := f.Interface.([]byte); ok && len(b) > max {
+			return zap.Binary(f.Key, b[:max]), true
+		}
+	}
+	return f, false
+}
+
+// truncateString cuts s to at most max bytes without splitting a UTF-8
+// sequence and notes the original length.
+func truncateString(s string, max int) string {
+	cut := max
+	for cut > 0 && cut < len(s) && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + fmt.Sprintf("…(truncated, was %d bytes)", len(s))
+}
+
+// entrySizeCore drops fields from entries whose encoding exceeds max bytes.
+// It keeps its own encoder clone carrying the same context fields as the
+// wrapped core so it can measure entries exactly as they will be written.
+type entrySizeCore struct {
+	zapcore.Core
+	enc zapcore.Encoder
+	max int
+}
+
+func newEntrySizeCore(c zapcore.Core, enc zapcore.Encoder, max int) zapcore.Core {
+	return &entrySizeCore{Core: c, enc: enc.Clone(), max: max}
+}
+
+func (c *entrySizeCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return &entrySizeCore{Core: c.Core.With(fields), enc: enc, max: c.max}
+}
+
+func (c *entrySizeCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *entrySizeCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if c.encodedSize(ent, fields) > c.max {
+		fields = c.shrink(ent, fields)
+	}
+	return c.Core.Write(ent, fields)
+}
+
+func (c *entrySizeCore) encodedSize(ent zapcore.Entry, fields []zapcore.Field) int {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return 0
+	}
+	n := buf.Len()
+	buf.Free()
+	return n
+}
+
+// shrink drops the largest fields until the entry, including the
+// dropped_fields note, fits.
+func (c *entrySizeCore) shrink(ent zapcore.Entry, fields []zapcore.Field) []zapcore.Field {
+	base := c.encodedSize(ent, nil)
+	sizes := make([]int, len(fields))
+	order := make([]int, len(fields))
+	for i := range fields {
+		sizes[i] = c.encodedSize(ent, fields[i:i+1]) - base
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return sizes[order[a]] > sizes[order[b]] })
+
+	dropped := make(map[int]bool, len(fields))
+	var kept []zapcore.Field
+	for _, i := range order {
+		dropped[i] = true
+
+		kept = kept[:0]
+		var keys []string
+		for j, f := range fields {
+			if dropped[j] {
+				keys = append(keys, f.Key)
+				continue
+			}
+			kept = append(kept, f)
+		}
+		kept = append(kept, zap.Strings("dropped_fields", keys))
+
+		if c.encodedSize(ent, kept) <= c.max {
+			break
+		}
+	}
+	return kept
+}
@@ -0,0 +1,160 @@
+package jogger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+)
+
+func configureBuffer(t *testing.T, opts ...jogger.Option) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	opts = append([]jogger.Option{jogger.WithOutput(&buf)}, opts...)
+	if err := jogger.Configure(opts...); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := jogger.Configure(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	return &buf
+}
+
+func decodeEntries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("invalid JSON entry %q: %v", line, err)
+		}
+		entries = append(entries, m)
+	}
+	return entries
+}
+
+func TestMaxFieldLengthJSON(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithMaxFieldLength(8))
+
+	jogger.Info(context.Background(), "big body",
+		zap.String("body", strings.Repeat("a", 100)),
+		zap.ByteString("raw", []byte(strings.Repeat("b", 20))),
+		zap.String("short", "ok"),
+	)
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if got := e["body"]; got != "aaaaaaaa…(truncated, was 100 bytes)" {
+		t.Errorf("unexpected truncated body %q", got)
+	}
+	if got := e["raw"]; got != "bbbbbbbb…(truncated, was 20 bytes)" {
+		t.Errorf("unexpected truncated bytes %q", got)
+	}
+	if got := e["short"]; got != "ok" {
+		t.Errorf("short field must be untouched, got %q", got)
+	}
+	if got := e["truncated_fields"]; got != float64(2) {
+		t.Errorf("expected truncated_fields 2, got %v", got)
+	}
+}
+
+func TestMaxFieldLengthKeepsRunesWhole(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithMaxFieldLength(2))
+
+	jogger.Info(context.Background(), "runes", zap.String("s", "héllo wörld"))
+
+	e := decodeEntries(t, buf)[0]
+	if got := e["s"]; got != "h…(truncated, was 13 bytes)" {
+		t.Errorf("unexpected truncation %q", got)
+	}
+}
+
+func TestMaxFieldLengthConsole(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatConsole), jogger.WithMaxFieldLength(5))
+
+	logger := jogger.FromContext(context.Background()).With(zap.String("ctx", "0123456789"))
+	logger.Info("console", zap.String("body", strings.Repeat("x", 50)))
+
+	out := buf.String()
+	if strings.Contains(out, strings.Repeat("x", 6)) || strings.Contains(out, "0123456789") {
+		t.Errorf("expected long values to be truncated: %s", out)
+	}
+	if !strings.Contains(out, "(truncated, was 50 bytes)") || !strings.Contains(out, `"truncated_fields": 2`) {
+		t.Errorf("expected truncation markers in console output: %s", out)
+	}
+}
+
+func TestMaxEntrySizeDropsLargestFields(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithMaxEntrySize(300))
+
+	jogger.Info(context.Background(), "oversized",
+		zap.String("small", "keep me"),
+		zap.String("medium", strings.Repeat("m", 150)),
+		zap.String("huge", strings.Repeat("h", 500)),
+	)
+
+	line := strings.TrimSpace(buf.String())
+	if len(line) > 300 {
+		t.Errorf("entry of %d bytes exceeds the cap: %s", len(line), line)
+	}
+	e := decodeEntries(t, buf)[0]
+	if _, ok := e["huge"]; ok {
+		t.Error("expected the largest field to be dropped")
+	}
+	if e["small"] != "keep me" || e["medium"] == nil {
+		t.Errorf("expected smaller fields to survive: %v", e)
+	}
+	dropped, _ := e["dropped_fields"].([]interface{})
+	if len(dropped) != 1 || dropped[0] != "huge" {
+		t.Errorf("expected dropped_fields [huge], got %v", e["dropped_fields"])
+	}
+}
+
+func TestMaxEntrySizeConsole(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatConsole), jogger.WithMaxEntrySize(200))
+
+	jogger.Info(context.Background(), "oversized",
+		zap.String("a", strings.Repeat("a", 120)),
+		zap.String("b", strings.Repeat("b", 120)),
+	)
+
+	out := strings.TrimSpace(buf.String())
+	if len(out) > 200 {
+		t.Errorf("entry of %d bytes exceeds the cap: %s", len(out), out)
+	}
+	if !strings.Contains(out, `"dropped_fields": ["a"]`) && !strings.Contains(out, `"dropped_fields": ["a", "b"]`) {
+		t.Errorf("expected dropped_fields note: %s", out)
+	}
+}
+
+func TestMaxEntrySizeLeavesSmallEntries(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithMaxEntrySize(1024))
+
+	jogger.Info(context.Background(), "small", zap.String("k", "v"))
+
+	e := decodeEntries(t, buf)[0]
+	if _, ok := e["dropped_fields"]; ok {
+		t.Errorf("small entry must not drop fields: %v", e)
+	}
+}
+
+func TestConfigureRejectsInvalidOptions(t *testing.T) {
+	if err := jogger.Configure(jogger.WithMaxFieldLength(-1)); err == nil {
+		t.Error("expected error for negative max field length")
+	}
+	if err := jogger.Configure(jogger.WithFormat("xml")); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
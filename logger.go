@@ -0,0 +1,56 @@
+package jogger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// Logger is the subset of *zap.Logger that code logging through jogger
+// usually needs. Depending on it rather than on *zap.Logger lets tests pass
+// a joggertest.MockLogger and keeps the backend replaceable.
+type Logger interface {
+	Debug(msg string, fields ...zap.Field)
+	Info(msg string, fields ...zap.Field)
+	Warn(msg string, fields ...zap.Field)
+	Error(msg string, fields ...zap.Field)
+	With(fields ...zap.Field) Logger
+	Named(name string) Logger
+}
+
+var loggerIfaceKey = &contextKey{"loggerIface"}
+
+// zapLogger adapts *zap.Logger to Logger.
+type zapLogger struct {
+	*zap.Logger
+}
+
+func (l zapLogger) With(fields ...zap.Field) Logger {
+	return zapLogger{l.Logger.With(fields...)}
+}
+
+func (l zapLogger) Named(name string) Logger {
+	return zapLogger{l.Logger.Named(name)}
+}
+
+// NewLogger returns l as a Logger.
+func NewLogger(l *zap.Logger) Logger {
+	return zapLogger{l}
+}
+
+// WithLogger returns a copy of ctx whose L returns l, such as a mock in
+// tests. FromContext and the package-level logging functions are not
+// affected.
+func WithLogger(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(orBackground(ctx), loggerIfaceKey, l)
+}
+
+// L returns the Logger of ctx: the one set with WithLogger, or the logger
+// of FromContext with the correlation fields of ctx.
+func L(ctx context.Context) Logger {
+	ctx = orBackground(ctx)
+	if l, ok := ctx.Value(loggerIfaceKey).(Logger); ok {
+		return l
+	}
+	return zapLogger{FromContext(ctx)}
+}
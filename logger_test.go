@@ -0,0 +1,25 @@
+package jogger_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+)
+
+func TestL(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	ctx := jogger.WithRequestID(context.Background(), "req-1")
+
+	jogger.L(ctx).Named("api").With(zap.String("k", "v")).Warn("through the interface")
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 1 {
+		t.Fatalf("expected one entry, got %v", entries)
+	}
+	e := entries[0]
+	if e["msg"] != "through the interface" || e["requestID"] != "req-1" || e["k"] != "v" || e["logger"] != "api" {
+		t.Errorf("expected the correlation fields, name and field, got %v", e)
+	}
+}
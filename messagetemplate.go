@@ -0,0 +1,95 @@
+package jogger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// WithMessageTemplates makes Debug, Info, Warn and Error, on the package
+// and on instances, fill {key} placeholders in their message from the
+// field named key, as in "user {user_id} exceeded quota {quota}". The
+// fields are still written as fields. A placeholder without a field is
+// written as {key:missing}. Catalog messages, see RegisterMessage, are
+// always filled in. Without this option messages are written as is, so
+// messages quoting routes such as /users/{id} keep their braces.
+func WithMessageTemplates() Option {
+	return func(c *config) error {
+		c.messageTemplates = true
+		return nil
+	}
+}
+
+// writeEntry writes ce with fields, filling the placeholders of its
+// message if WithMessageTemplates is set. It is called once the entry is
+// known to be written, so disabled entries are not rendered.
+func writeEntry(ctx context.Context, ce *zapcore.CheckedEntry, fields []zap.Field) {
+	if outputFor(orBackground(ctx)).cfg.messageTemplates {
+		ce.Message = interpolate(ce.Message, fields)
+	}
+	ce.Write(fields...)
+}
+
+// interpolate replaces each {key} of msg, key being letters, digits and
+// _ . -, with the value of the last field named key. Anything else in
+// braces is kept as is. msg is scanned once and values are copied without
+// being scanned, so braces in a value are never substituted.
+func interpolate(msg string, fields []zap.Field) string {
+	if strings.IndexByte(msg, '{') < 0 {
+		return msg
+	}
+	var b strings.Builder
+	b.Grow(len(msg))
+	for {
+		open := strings.IndexByte(msg, '{')
+		if open < 0 {
+			break
+		}
+		end := open + 1
+		for end < len(msg) && placeholderByte(msg[end]) {
+			end++
+		}
+		if end == open+1 || end == len(msg) || msg[end] != '}' {
+			b.WriteString(msg[:end])
+			msg = msg[end:]
+			continue
+		}
+		b.WriteString(msg[:open])
+		key := msg[open+1 : end]
+		if v, ok := fieldText(key, fields); ok {
+			b.WriteString(v)
+		} else {
+			b.WriteString("{" + key + ":missing}")
+		}
+		msg = msg[end+1:]
+	}
+	b.WriteString(msg)
+	return b.String()
+}
+
+func placeholderByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.' || c == '-'
+}
+
+// fieldText returns the value of the last field named key as text, the way
+// the map encoder sees it.
+func fieldText(key string, fields []zap.Field) (string, bool) {
+	for i := len(fields) - 1; i >= 0; i-- {
+		f := fields[i]
+		if f.Key != key {
+			continue
+		}
+		if f.Type == zapcore.StringType {
+			return f.String, true
+		}
+		enc := zapcore.NewMapObjectEncoder()
+		f.AddTo(enc)
+		if v, ok := enc.Fields[key]; ok {
+			return fmt.Sprint(v), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,91 @@
+package jogger_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestMessageTemplates(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithMessageTemplates())
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		msg    string
+		fields []zap.Field
+		want   string
+	}{
+		{"user {user_id} exceeded quota {quota}", []zap.Field{zap.String("user_id", "u-1"), zap.Int("quota", 100)}, "user u-1 exceeded quota 100"},
+		{"took {elapsed}, failed with {error}", []zap.Field{zap.Duration("elapsed", 1500*time.Millisecond), zap.Error(errors.New("boom"))}, "took 1.5s, failed with boom"},
+		{"user {user_id} not found", nil, "user {user_id:missing} not found"},
+		{"name {name}", []zap.Field{zap.String("name", "{name} {other}")}, "name {name} {other}"},
+		{"GET /users/{id", []zap.Field{zap.String("id", "7")}, "GET /users/{id"},
+		{"{} { id } {{id}}", []zap.Field{zap.String("id", "7")}, "{} { id } {7}"},
+		{"retry {n}", []zap.Field{zap.Int("n", 1), zap.Int("n", 2)}, "retry 2"},
+	} {
+		jogger.Info(ctx, tc.msg, tc.fields...)
+		entries := decodeEntries(t, buf)
+		buf.Reset()
+		if len(entries) != 1 {
+			t.Fatalf("%q: expected 1 entry, got %d", tc.msg, len(entries))
+		}
+		if got := entries[0]["msg"]; got != tc.want {
+			t.Errorf("%q: expected message %q, got %q", tc.msg, tc.want, got)
+		}
+	}
+}
+
+func TestMessageTemplatesKeepFields(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithMessageTemplates())
+
+	jogger.Warn(context.Background(), "user {user_id} exceeded quota {quota}", zap.String("user_id", "u-1"), zap.Int("quota", 100))
+
+	e := decodeEntries(t, buf)[0]
+	if e["user_id"] != "u-1" || e["quota"] != float64(100) {
+		t.Errorf("expected the fields written structurally, got %v", e)
+	}
+}
+
+func TestMessageTemplatesOffByDefault(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	jogger.Info(context.Background(), "GET /users/{id}", zap.String("id", "7"))
+
+	if got := decodeEntries(t, buf)[0]["msg"]; got != "GET /users/{id}" {
+		t.Errorf("expected the message as is, got %q", got)
+	}
+}
+
+func TestMessageTemplatesOnInstance(t *testing.T) {
+	var buf bytes.Buffer
+	j, err := jogger.New(jogger.WithOutput(&buf), jogger.WithFormat(jogger.FormatJSON), jogger.WithLevel(zapcore.DebugLevel), jogger.WithMessageTemplates())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j.Debug(context.Background(), "cache {cache} warmed", zap.String("cache", "users"))
+
+	if got := decodeEntries(t, &buf)[0]["msg"]; got != "cache users warmed" {
+		t.Errorf("expected the placeholder filled, got %q", got)
+	}
+}
+
+func TestLogInterpolatesCatalogMessage(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	if err := jogger.RegisterMessage("QUOTA-001", "user {user_id} exceeded quota {quota}", zapcore.WarnLevel); err != nil {
+		t.Fatal(err)
+	}
+
+	jogger.Log(context.Background(), "QUOTA-001", zap.String("user_id", "u-1"))
+
+	e := decodeEntries(t, buf)[0]
+	if e["msg"] != "user u-1 exceeded quota {quota:missing}" || e["code"] != "QUOTA-001" || e["user_id"] != "u-1" {
+		t.Errorf("unexpected entry %v", e)
+	}
+}
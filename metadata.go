@@ -0,0 +1,96 @@
+package jogger
+
+import (
+	"errors"
+	"os"
+	"runtime/debug"
+
+	"go.uber.org/zap"
+)
+
+// WithHostInfo adds hostname and pid fields to every entry.
+func WithHostInfo() Option {
+	return func(c *config) error {
+		if host, err := os.Hostname(); err == nil && host != "" {
+			c.fields = append(c.fields, zap.String("hostname", host))
+		}
+		c.fields = append(c.fields, zap.Int("pid", os.Getpid()))
+		return nil
+	}
+}
+
+// kubernetesEnv maps the conventional downward API variables to field keys.
+var kubernetesEnv = []struct {
+	env string
+	key string
+}{
+	{"POD_NAME", "k8s.pod"},
+	{"POD_NAMESPACE", "k8s.namespace"},
+	{"NODE_NAME", "k8s.node"},
+}
+
+// WithKubernetesInfo adds k8s.pod, k8s.namespace and k8s.node fields from
+// the POD_NAME, POD_NAMESPACE and NODE_NAME environment variables. Unset
+// variables are skipped.
+func WithKubernetesInfo() Option {
+	return func(c *config) error {
+		for _, kv := range kubernetesEnv {
+			if v := os.Getenv(kv.env); v != "" {
+				c.fields = append(c.fields, zap.String(kv.key, v))
+			}
+		}
+		return nil
+	}
+}
+
+// WithSchemaVersion adds a schema field with v to every entry, so the
+// pipeline knows which field names an entry uses.
+func WithSchemaVersion(v string) Option {
+	return func(c *config) error {
+		if v == "" {
+			return errors.New("jogger: empty schema version")
+		}
+		c.fields = append(c.fields, zap.String("schema", v))
+		return nil
+	}
+}
+
+var readBuildInfo = debug.ReadBuildInfo
+
+// WithBuildInfo adds build.version, build.revision, build.time and
+// build.dirty fields describing the running binary. It does nothing when the
+// binary carries no build information, for example under go run.
+func WithBuildInfo() Option {
+	return func(c *config) error {
+		bi, ok := readBuildInfo()
+		if !ok || bi == nil {
+			return nil
+		}
+		c.fields = append(c.fields, buildInfoFields(bi)...)
+		return nil
+	}
+}
+
+const shortRevisionLength = 12
+
+func buildInfoFields(bi *debug.BuildInfo) []zap.Field {
+	var fields []zap.Field
+	if bi.Main.Version != "" {
+		fields = append(fields, zap.String("build.version", bi.Main.Version))
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			rev := s.Value
+			if len(rev) > shortRevisionLength {
+				rev = rev[:shortRevisionLength]
+			}
+			fields = append(fields, zap.String("build.revision", rev))
+		case "vcs.time":
+			fields = append(fields, zap.String("build.time", s.Value))
+		case "vcs.modified":
+			fields = append(fields, zap.Bool("build.dirty", s.Value == "true"))
+		}
+	}
+	return fields
+}
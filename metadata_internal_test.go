@@ -0,0 +1,63 @@
+package jogger
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime/debug"
+	"testing"
+)
+
+func configureWithBuildInfo(t *testing.T, bi *debug.BuildInfo, ok bool) map[string]interface{} {
+	t.Helper()
+	prev := readBuildInfo
+	readBuildInfo = func() (*debug.BuildInfo, bool) { return bi, ok }
+	defer func() { readBuildInfo = prev }()
+
+	var buf bytes.Buffer
+	if err := Configure(WithOutput(&buf), WithFormat(FormatJSON), WithBuildInfo()); err != nil {
+		t.Fatal(err)
+	}
+	defer Configure()
+
+	baseLogger().Info("build info")
+
+	var e map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &e); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	return e
+}
+
+func TestWithBuildInfo(t *testing.T) {
+	bi := &debug.BuildInfo{
+		Main: debug.Module{Path: "example.com/app", Version: "v1.4.2"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs", Value: "git"},
+			{Key: "vcs.revision", Value: "45ce62e1f0a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5"},
+			{Key: "vcs.time", Value: "2026-10-01T12:00:00Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+
+	e := configureWithBuildInfo(t, bi, true)
+	want := map[string]interface{}{
+		"build.version":  "v1.4.2",
+		"build.revision": "45ce62e1f0a9",
+		"build.time":     "2026-10-01T12:00:00Z",
+		"build.dirty":    true,
+	}
+	for k, v := range want {
+		if e[k] != v {
+			t.Errorf("expected %s=%v, got %v", k, v, e[k])
+		}
+	}
+}
+
+func TestWithBuildInfoUnavailable(t *testing.T) {
+	e := configureWithBuildInfo(t, nil, false)
+	for _, k := range []string{"build.version", "build.revision", "build.time", "build.dirty"} {
+		if _, ok := e[k]; ok {
+			t.Errorf("expected no %s without build info", k)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package jogger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+)
+
+func TestWithHostInfo(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithHostInfo())
+
+	jogger.Info(context.Background(), "host info")
+	span, _ := jogger.StartSpan(context.Background(), "host-span")
+	span.Finish(nil)
+
+	host, _ := os.Hostname()
+	for _, e := range decodeEntries(t, buf) {
+		if e["hostname"] != host {
+			t.Errorf("expected hostname %q, got %v", host, e["hostname"])
+		}
+		if e["pid"] != float64(os.Getpid()) {
+			t.Errorf("expected pid %d, got %v", os.Getpid(), e["pid"])
+		}
+	}
+}
+
+func TestWithKubernetesInfo(t *testing.T) {
+	os.Setenv("POD_NAME", "api-7d9f")
+	os.Setenv("POD_NAMESPACE", "payments")
+	os.Unsetenv("NODE_NAME")
+	defer os.Unsetenv("POD_NAME")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithKubernetesInfo())
+
+	// Values are read at configure time, not per entry.
+	os.Setenv("POD_NAME", "changed")
+
+	span, _ := jogger.StartSpan(context.Background(), "k8s-span")
+	span.Finish(nil)
+
+	e := decodeEntries(t, buf)[0]
+	if e["k8s.pod"] != "api-7d9f" {
+		t.Errorf("expected k8s.pod api-7d9f, got %v", e["k8s.pod"])
+	}
+	if e["k8s.namespace"] != "payments" {
+		t.Errorf("expected k8s.namespace payments, got %v", e["k8s.namespace"])
+	}
+	if _, ok := e["k8s.node"]; ok {
+		t.Error("expected k8s.node to be skipped when NODE_NAME is unset")
+	}
+}
+
+func TestWithSchemaVersion(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithSchemaVersion("2"))
+
+	jogger.Info(context.Background(), "versioned")
+
+	if entries := decodeEntries(t, buf); len(entries) != 1 || entries[0]["schema"] != "2" {
+		t.Errorf("expected schema 2, got %v", entries)
+	}
+	if err := jogger.Configure(jogger.WithSchemaVersion("")); err == nil {
+		t.Error("expected an error for an empty version")
+	}
+}
+
+func TestWithSinkFields(t *testing.T) {
+	var audit bytes.Buffer
+	main := configureBuffer(t,
+		jogger.WithFormat(jogger.FormatJSON),
+		jogger.WithAuditOutput(&audit, jogger.AuditAndMain),
+		jogger.WithSinkFields("audit", zap.String("stream", "audit")),
+		jogger.WithSinkFields("writer", zap.String("stream", "app")),
+	)
+
+	jogger.Audit(context.Background(), "login", zap.String("actor", "a"), zap.String("target", "b"), zap.String("outcome", "ok"))
+
+	if entries := decodeEntries(t, &audit); len(entries) != 1 || entries[0]["stream"] != "audit" {
+		t.Errorf("expected stream=audit on the audit output, got %v", entries)
+	}
+	if entries := decodeEntries(t, main); len(entries) != 1 || entries[0]["stream"] != "app" {
+		t.Errorf("expected only stream=app on the main output, got %v", entries)
+	}
+
+	if err := jogger.Configure(jogger.WithSinkFields("loki", zap.String("stream", "x"))); err == nil {
+		t.Error("expected an error for an unknown sink")
+	}
+}
+
+func TestWithBuildInfoBinary(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a binary")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go tool not available")
+	}
+	if _, err := os.Stat(".git"); err != nil {
+		t.Skip("not a git checkout")
+	}
+
+	bin := filepath.Join(t.TempDir(), "buildinfo")
+	if out, err := exec.Command("go", "build", "-buildvcs=true", "-o", bin, "./testdata/buildinfo").CombinedOutput(); err != nil {
+		t.Fatalf("build failed: %v\n%s", err, out)
+	}
+	out, err := exec.Command(bin).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var e map[string]interface{}
+	if err := json.Unmarshal(out, &e); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	for _, k := range []string{"build.revision", "build.time", "build.dirty"} {
+		if _, ok := e[k]; !ok {
+			t.Errorf("expected %s in %s", k, out)
+		}
+	}
+}
@@ -0,0 +1,522 @@
+package jogger
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const defaultMessageTemplate = "{method} {route} -> {status}"
+
+type middlewareConfig struct {
+	message        []templatePart
+	route          func(*http.Request) string
+	trustedProxies []netip.Prefix
+	userAgent      bool
+	referer        bool
+	requestBody    *bodyCapture
+	responseBody   *bodyCapture
+	echoHeader     string
+	slow           time.Duration
+	buckets        latencyBuckets
+	spanTree       bool
+	routeMetadata  func(*http.Request) []zap.Field
+	rateLimit      RateLimitInfoProvider
+	queueHeader    string
+	queueFormat    QueueTimeFormat
+}
+
+// A MiddlewareOption configures Middleware.
+type MiddlewareOption func(*middlewareConfig)
+
+// MessageTemplate sets the access log message. The placeholders {method},
+// {route}, {path}, {status} and {remote_ip} are replaced with the request's
+// values; anything else is copied as is. The default is
+// "{method} {route} -> {status}".
+func MessageTemplate(tmpl string) MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.message = parseTemplate(tmpl)
+	}
+}
+
+// RouteFunc sets how the {route} placeholder and route field are derived,
+// for example from a router's matched pattern. The default is the URL path.
+func RouteFunc(fn func(*http.Request) string) MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.route = fn
+	}
+}
+
+// RouteMetadata adds the fields fn returns for a request, such as the
+// owning team or criticality tier of its route, to the request context with
+// WithFields, so the access log, spans and every log further down carry
+// them. fn is called once per request, before the handler, so with a
+// router that resolves routes while serving, mount Middleware per route or
+// have fn match the route itself. MetadataByRoute looks the fields up by
+// route pattern.
+func RouteMetadata(fn func(*http.Request) []zap.Field) MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.routeMetadata = fn
+	}
+}
+
+// MetadataByRoute returns a RouteMetadata function looking up the fields of
+// a request in metadata by the route pattern that pattern returns for it,
+// for instance with chi:
+//
+//	jogger.MetadataByRoute(func(r *http.Request) string {
+//		rctx := chi.NewRouteContext()
+//		mux.Match(rctx, r.Method, r.URL.Path)
+//		return rctx.RoutePattern()
+//	}, map[string][]zap.Field{
+//		"/orders/{id}": {zap.String("team", "checkout"), zap.String("tier", "1")},
+//	})
+func MetadataByRoute(pattern func(*http.Request) string, metadata map[string][]zap.Field) func(*http.Request) []zap.Field {
+	return func(r *http.Request) []zap.Field {
+		return metadata[pattern(r)]
+	}
+}
+
+// TrustedProxies lists the networks whose X-Forwarded-For header is
+// believed. The client IP is the rightmost X-Forwarded-For address that is
+// not a trusted proxy. Without trusted proxies, or when the header is
+// malformed, the connection's remote address is used. IPv4-mapped IPv6
+// addresses match the IPv4 networks.
+func TrustedProxies(networks ...netip.Prefix) MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.trustedProxies = networks
+	}
+}
+
+// LogUserAgent toggles the user_agent field, on by default.
+func LogUserAgent(on bool) MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.userAgent = on
+	}
+}
+
+// LogReferer toggles the referer field, off by default.
+func LogReferer(on bool) MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.referer = on
+	}
+}
+
+// EchoRequestID sets the response header the resolved request ID is written
+// to, whether it came from the request or was generated, so clients can
+// quote it. The default is X-Request-ID; an empty name turns echoing off.
+func EchoRequestID(header string) MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.echoHeader = header
+	}
+}
+
+// SlowRequestThreshold escalates the access log of successful requests that
+// take longer than d to Warn. It is off by default, and never applies to
+// streamed responses, which are meant to stay open.
+func SlowRequestThreshold(d time.Duration) MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.slow = d
+	}
+}
+
+// LatencyBuckets sets the boundaries of the latency_bucket field, which lets
+// log-based dashboards approximate a latency histogram. The default
+// boundaries 10ms, 100ms and 1s give the buckets "lt_10ms", "10ms_100ms",
+// "100ms_1s" and "gt_1s".
+func LatencyBuckets(bounds ...time.Duration) MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.buckets = newLatencyBuckets(bounds)
+	}
+}
+
+// SpanTree toggles logging a "span tree" entry with the spans of each
+// request before its access log, off by default. See EmitSpanTree. It is
+// skipped for requests left out by WithSampling.
+func SpanTree(on bool) MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.spanTree = on
+	}
+}
+
+// Middleware returns HTTP middleware that puts the request ID and propagated
+// correlation values from the request headers into the request context,
+// generating a request ID when there is none, and writes one access log
+// entry per request. The entry is logged at Error for 5xx responses, Warn
+// for 4xx and slow requests and Info otherwise; Info entries of requests
+// left out by WithSampling are skipped.
+//
+// A response the handler flushes, such as Server-Sent Events, is treated as
+// a stream: a "stream started" entry is logged on the first flush, and the
+// access log is tagged with streamed=true and the number of flushed chunks.
+func Middleware(opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	cfg := &middlewareConfig{
+		message:    parseTemplate(defaultMessageTemplate),
+		route:      func(r *http.Request) string { return r.URL.Path },
+		userAgent:  true,
+		echoHeader: RequestIDHeader,
+		buckets:    defaultLatencyBuckets,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			defer ClearGoroutineContext()
+
+			ctx := StartTailSampling(EnsureRequestID(Extract(r.Context(), HeaderCarrier(r.Header))))
+			ctx = context.WithValue(ctx, routeFuncKey, cfg.route)
+			if cfg.routeMetadata != nil {
+				if fields := cfg.routeMetadata(r); len(fields) > 0 {
+					ctx = WithFields(ctx, fields...)
+				}
+			}
+			if cfg.spanTree {
+				ctx = CollectSpanTree(ctx)
+			}
+			if cfg.echoHeader != "" {
+				w.Header().Set(cfg.echoHeader, RequestID(ctx))
+			}
+
+			var extra []zap.Field
+			debug := FromContext(ctx).Core().Enabled(zapcore.DebugLevel)
+			if debug && cfg.requestBody.allows(r.Header.Get("Content-Type")) {
+				extra = cfg.requestBody.captureRequestBody(r)
+			}
+
+			body := &countingReader{ReadCloser: r.Body}
+			if r.Body != nil {
+				r.Body = body
+			}
+			rec := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+			if debug && cfg.responseBody != nil {
+				rec.tee = &bodyTee{capture: cfg.responseBody}
+			}
+
+			r = r.WithContext(ctx)
+			rec.onStream = func() {
+				FromContext(ctx).Info("stream started",
+					zap.String("method", r.Method),
+					zap.String("route", cfg.route(r)),
+					zap.Int("status", rec.statusCode),
+					zap.Duration("elapsed", time.Since(start)),
+				)
+			}
+			next.ServeHTTP(rec.writer(), r)
+			FinishTailSampling(ctx, !rec.hijacked && rec.statusCode >= 500)
+			if cfg.spanTree && IsSampled(ctx) {
+				EmitSpanTree(ctx)
+			}
+
+			elapsed := time.Since(start)
+			extra = append(extra, rec.tee.fields()...)
+			if cfg.queueHeader != "" {
+				extra = append(extra, cfg.queueTimeFields(r, start, elapsed)...)
+			}
+			cfg.logAccess(r, rec, body.n, elapsed, extra)
+		})
+	}
+}
+
+func (c *middlewareConfig) logAccess(r *http.Request, rec *responseRecorder, bytesIn int64, elapsed time.Duration, extra []zap.Field) {
+	var rateLimit []zap.Field
+	throttled := false
+	if c.rateLimit != nil {
+		rateLimit, throttled = rateLimitFields(c.rateLimit, r)
+	}
+
+	lvl := zapcore.InfoLevel
+	switch {
+	case rec.hijacked:
+	case rec.statusCode >= 500:
+		lvl = zapcore.ErrorLevel
+	case rec.statusCode >= 400, throttled:
+		lvl = zapcore.WarnLevel
+	case c.slow > 0 && elapsed > c.slow && rec.chunks == 0:
+		lvl = zapcore.WarnLevel
+	}
+
+	logger := FromContext(r.Context())
+	if lvl == zapcore.InfoLevel && !IsSampled(r.Context()) {
+		if logger.Core().Enabled(lvl) {
+			countDrop(dropSampling)
+		}
+		return
+	}
+	ce := logger.Check(lvl, "")
+	if ce == nil {
+		return
+	}
+
+	vals := accessValues{
+		method:   r.Method,
+		route:    c.route(r),
+		path:     r.URL.Path,
+		status:   rec.statusCode,
+		hijacked: rec.hijacked,
+		remoteIP: clientIP(r, c.trustedProxies),
+	}
+	ce.Message = renderTemplate(c.message, vals)
+
+	fields := []zap.Field{
+		zap.String("method", vals.method),
+		zap.String("route", vals.route),
+		zap.String("path", vals.path),
+	}
+	// A hijacked connection's status and response bytes are unknown to
+	// net/http, so they are left out rather than reported as zeros.
+	if rec.hijacked {
+		fields = append(fields, zap.Bool("hijacked", true))
+	} else {
+		fields = append(fields, zap.Int("status", vals.status))
+	}
+	fields = append(fields,
+		zap.String("remote_ip", vals.remoteIP),
+		zap.Int64("bytes_in", bytesIn),
+	)
+	if !rec.hijacked {
+		fields = append(fields, zap.Int64("bytes_out", rec.bytes))
+	}
+	fields = append(fields,
+		zap.Duration(outputFor(r.Context()).cfg.fieldNames.Duration, elapsed),
+		zap.String("latency_bucket", c.buckets.name(elapsed)),
+	)
+	if rec.chunks > 0 {
+		fields = append(fields, zap.Bool("streamed", true), zap.Int64("chunks", rec.chunks))
+	}
+	if c.userAgent {
+		fields = append(fields, zap.String("user_agent", r.UserAgent()))
+	}
+	if c.referer {
+		fields = append(fields, zap.String("referer", r.Referer()))
+	}
+	fields = append(fields, rateLimit...)
+	ce.Write(append(fields, extra...)...)
+}
+
+var defaultLatencyBuckets = newLatencyBuckets([]time.Duration{
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+})
+
+// latencyBuckets holds sorted boundaries and the precomputed name of the
+// bucket below each one, plus the bucket above the last.
+type latencyBuckets struct {
+	bounds []time.Duration
+	names  []string
+}
+
+func newLatencyBuckets(bounds []time.Duration) latencyBuckets {
+	sorted := make([]time.Duration, 0, len(bounds))
+	for _, b := range bounds {
+		if b > 0 {
+			sorted = append(sorted, b)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var lb latencyBuckets
+	for i, b := range sorted {
+		if i > 0 && b == sorted[i-1] {
+			continue
+		}
+		if len(lb.bounds) == 0 {
+			lb.names = append(lb.names, "lt_"+b.String())
+		} else {
+			lb.names = append(lb.names, lb.bounds[len(lb.bounds)-1].String()+"_"+b.String())
+		}
+		lb.bounds = append(lb.bounds, b)
+	}
+	if n := len(lb.bounds); n > 0 {
+		lb.names = append(lb.names, "gt_"+lb.bounds[n-1].String())
+	} else {
+		lb.names = append(lb.names, "all")
+	}
+	return lb
+}
+
+// name returns the bucket d falls in. A duration equal to a boundary
+// belongs to the bucket above it.
+func (lb latencyBuckets) name(d time.Duration) string {
+	i := sort.Search(len(lb.bounds), func(i int) bool { return d < lb.bounds[i] })
+	return lb.names[i]
+}
+
+// responseRecorder wraps http.ResponseWriter to capture the status code,
+// the number of body bytes written and the number of flushes. Handlers are
+// given its writer, which exposes the optional interfaces the wrapped
+// writer implements.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	bytes       int64
+	wroteHeader bool
+	tee         *bodyTee
+	chunks      int64
+	hijacked    bool
+	onStream    func()
+}
+
+func (rr *responseRecorder) WriteHeader(code int) {
+	if !rr.wroteHeader {
+		rr.statusCode = code
+		rr.wroteHeader = true
+	}
+	rr.ResponseWriter.WriteHeader(code)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.wroteHeader = true
+	n, err := rr.ResponseWriter.Write(b)
+	rr.bytes += int64(n)
+	if rr.tee != nil {
+		rr.tee.write(rr.Header(), b[:n])
+	}
+	return n, err
+}
+
+// countingReader counts the request body bytes the handler reads.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// clientIP returns the address of the client that sent r, honoring
+// X-Forwarded-For only when the connection comes from a trusted proxy.
+func clientIP(r *http.Request, trusted []netip.Prefix) string {
+	remote := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	if len(trusted) == 0 {
+		return remote
+	}
+	if ip, err := netip.ParseAddr(remote); err != nil || !isTrusted(ip, trusted) {
+		return remote
+	}
+
+	xff := r.Header["X-Forwarded-For"]
+	if len(xff) == 0 {
+		return remote
+	}
+	hops := strings.Split(strings.Join(xff, ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			return remote
+		}
+		if !isTrusted(ip, trusted) {
+			return ip.Unmap().String()
+		}
+	}
+	return remote
+}
+
+func isTrusted(ip netip.Addr, trusted []netip.Prefix) bool {
+	ip = ip.Unmap().WithZone("")
+	for _, n := range trusted {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+type accessValues struct {
+	method   string
+	route    string
+	path     string
+	status   int
+	hijacked bool
+	remoteIP string
+}
+
+// templatePart is either literal text or, when placeholder is set, the name
+// of a value to substitute.
+type templatePart struct {
+	text        string
+	placeholder bool
+}
+
+// parseTemplate splits tmpl once at construction so rendering is a single
+// pass over precomputed parts.
+func parseTemplate(tmpl string) []templatePart {
+	var parts []templatePart
+	for len(tmpl) > 0 {
+		open := strings.IndexByte(tmpl, '{')
+		if open < 0 {
+			parts = append(parts, templatePart{text: tmpl})
+			break
+		}
+		end := strings.IndexByte(tmpl[open:], '}')
+		if end < 0 {
+			parts = append(parts, templatePart{text: tmpl})
+			break
+		}
+		end += open
+		if open > 0 {
+			parts = append(parts, templatePart{text: tmpl[:open]})
+		}
+		name := tmpl[open+1 : end]
+		if isAccessPlaceholder(name) {
+			parts = append(parts, templatePart{text: name, placeholder: true})
+		} else {
+			parts = append(parts, templatePart{text: tmpl[open : end+1]})
+		}
+		tmpl = tmpl[end+1:]
+	}
+	return parts
+}
+
+func isAccessPlaceholder(name string) bool {
+	switch name {
+	case "method", "route", "path", "status", "remote_ip":
+		return true
+	}
+	return false
+}
+
+func renderTemplate(parts []templatePart, v accessValues) string {
+	var b strings.Builder
+	for _, p := range parts {
+		if !p.placeholder {
+			b.WriteString(p.text)
+			continue
+		}
+		switch p.text {
+		case "method":
+			b.WriteString(v.method)
+		case "route":
+			b.WriteString(v.route)
+		case "path":
+			b.WriteString(v.path)
+		case "status":
+			if v.hijacked {
+				b.WriteString("hijacked")
+			} else {
+				b.WriteString(strconv.Itoa(v.status))
+			}
+		case "remote_ip":
+			b.WriteString(v.remoteIP)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,45 @@
+package jogger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyBucketNames(t *testing.T) {
+	cases := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "lt_10ms"},
+		{9 * time.Millisecond, "lt_10ms"},
+		{10 * time.Millisecond, "10ms_100ms"},
+		{99 * time.Millisecond, "10ms_100ms"},
+		{100 * time.Millisecond, "100ms_1s"},
+		{999 * time.Millisecond, "100ms_1s"},
+		{time.Second, "gt_1s"},
+		{time.Hour, "gt_1s"},
+	}
+	for _, c := range cases {
+		if got := defaultLatencyBuckets.name(c.d); got != c.want {
+			t.Errorf("%v: expected %q, got %q", c.d, c.want, got)
+		}
+	}
+}
+
+func TestCustomLatencyBuckets(t *testing.T) {
+	lb := newLatencyBuckets([]time.Duration{5 * time.Second, 50 * time.Millisecond, 5 * time.Second, 0})
+
+	if len(lb.names) != 3 {
+		t.Fatalf("expected unsorted, duplicate and zero bounds to be cleaned up, got %v", lb.names)
+	}
+	cases := map[time.Duration]string{
+		time.Millisecond:       "lt_50ms",
+		500 * time.Millisecond: "50ms_5s",
+		time.Minute:            "gt_5s",
+	}
+	for d, want := range cases {
+		if got := lb.name(d); got != want {
+			t.Errorf("%v: expected %q, got %q", d, want, got)
+		}
+	}
+}
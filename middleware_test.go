@@ -0,0 +1,499 @@
+package jogger_test
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func serve(t *testing.T, h http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	return rec
+}
+
+func TestMiddlewareAccessLog(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	var handlerRequestID string
+	h := jogger.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerRequestID = jogger.RequestID(r.Context())
+		ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, "created")
+	}))
+
+	r := httptest.NewRequest("POST", "/v1/users", strings.NewReader(`{"name":"ann"}`))
+	r.Header.Set("X-Request-ID", "req-mw")
+	r.Header.Set("User-Agent", "curl/8.0")
+	r.RemoteAddr = "203.0.113.7:5123"
+	serve(t, h, r)
+
+	if handlerRequestID != "req-mw" {
+		t.Errorf("expected handler to see the inbound request ID, got %q", handlerRequestID)
+	}
+	e := decodeEntries(t, buf)[0]
+	want := map[string]interface{}{
+		"msg":        "POST /v1/users -> 201",
+		"level":      "info",
+		"requestID":  "req-mw",
+		"status":     float64(201),
+		"remote_ip":  "203.0.113.7",
+		"user_agent": "curl/8.0",
+		"bytes_in":   float64(14),
+		"bytes_out":  float64(7),
+	}
+	for k, v := range want {
+		if e[k] != v {
+			t.Errorf("expected %s=%v, got %v", k, v, e[k])
+		}
+	}
+	if _, ok := e["referer"]; ok {
+		t.Error("expected referer to be off by default")
+	}
+}
+
+func TestMiddlewareGeneratesRequestID(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	h := jogger.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	serve(t, h, httptest.NewRequest("GET", "/", nil))
+
+	if rid, _ := decodeEntries(t, buf)[0]["requestID"].(string); len(rid) != 36 {
+		t.Errorf("expected a generated UUID request ID, got %q", rid)
+	}
+}
+
+func TestMiddlewareStatusLevels(t *testing.T) {
+	cases := map[int]string{200: "info", 404: "warn", 503: "error"}
+	for status, level := range cases {
+		buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+		h := jogger.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+		serve(t, h, httptest.NewRequest("GET", "/status", nil))
+		if got := decodeEntries(t, buf)[0]["level"]; got != level {
+			t.Errorf("status %d: expected %s, got %v", status, level, got)
+		}
+	}
+}
+
+func TestMiddlewareMessageTemplateAndToggles(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	h := jogger.Middleware(
+		jogger.MessageTemplate("{status} {method} {route} from {remote_ip} {unknown}"),
+		jogger.RouteFunc(func(r *http.Request) string { return "/users/{id}" }),
+		jogger.LogUserAgent(false),
+		jogger.LogReferer(true),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	r := httptest.NewRequest("GET", "/users/42", nil)
+	r.RemoteAddr = "198.51.100.1:1234"
+	r.Header.Set("Referer", "https://example.com/")
+	serve(t, h, r)
+
+	e := decodeEntries(t, buf)[0]
+	if e["msg"] != "200 GET /users/{id} from 198.51.100.1 {unknown}" {
+		t.Errorf("unexpected message %q", e["msg"])
+	}
+	if e["route"] != "/users/{id}" || e["path"] != "/users/42" {
+		t.Errorf("unexpected route/path %v %v", e["route"], e["path"])
+	}
+	if _, ok := e["user_agent"]; ok {
+		t.Error("expected user_agent to be disabled")
+	}
+	if e["referer"] != "https://example.com/" {
+		t.Errorf("expected referer, got %v", e["referer"])
+	}
+}
+
+func TestMiddlewareClientIP(t *testing.T) {
+	cases := []struct {
+		name   string
+		remote string
+		xff    string
+		want   string
+	}{
+		{"untrusted peer ignores header", "203.0.113.9:1", "1.2.3.4", "203.0.113.9"},
+		{"trusted peer uses header", "10.0.0.5:1", "198.51.100.20", "198.51.100.20"},
+		{"skips trusted hops", "10.0.0.5:1", "198.51.100.20, 10.0.0.9", "198.51.100.20"},
+		{"rightmost untrusted wins", "10.0.0.5:1", "6.6.6.6, 198.51.100.20", "198.51.100.20"},
+		{"malformed header", "10.0.0.5:1", "198.51.100.20, not-an-ip", "10.0.0.5"},
+		{"all hops trusted", "10.0.0.5:1", "10.0.0.7", "10.0.0.5"},
+		{"mapped trusted peer", "[::ffff:10.0.0.5]:1", "::ffff:198.51.100.20", "198.51.100.20"},
+	}
+	for _, c := range cases {
+		buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+		h := jogger.Middleware(jogger.TrustedProxies(netip.MustParsePrefix("10.0.0.0/8")))(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = c.remote
+		r.Header.Set("X-Forwarded-For", c.xff)
+		serve(t, h, r)
+
+		if got := decodeEntries(t, buf)[0]["remote_ip"]; got != c.want {
+			t.Errorf("%s: expected %s, got %v", c.name, c.want, got)
+		}
+	}
+}
+
+func bodyEchoHandler(t *testing.T, contentType string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write(body)
+	})
+}
+
+func TestMiddlewareCapturesBodiesAtDebug(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithLevel(zapcore.DebugLevel))
+
+	h := jogger.Middleware(
+		jogger.CaptureRequestBody(8, nil),
+		jogger.CaptureResponseBody(64, []string{"application/json"}),
+	)(bodyEchoHandler(t, "application/json"))
+
+	payload := `{"name":"ann","role":"admin"}`
+	r := httptest.NewRequest("POST", "/users", strings.NewReader(payload))
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	rec := serve(t, h, r)
+
+	if rec.Body.String() != payload {
+		t.Fatalf("handler must read the full body, echoed %q", rec.Body.String())
+	}
+	e := decodeEntries(t, buf)[0]
+	if e["request_body"] != `{"name":` || e["request_body_truncated"] != true {
+		t.Errorf("unexpected request body capture %v / %v", e["request_body"], e["request_body_truncated"])
+	}
+	if e["response_body"] != payload || e["response_body_truncated"] != false {
+		t.Errorf("unexpected response body capture %v / %v", e["response_body"], e["response_body_truncated"])
+	}
+	if e["bytes_in"] != float64(len(payload)) {
+		t.Errorf("expected bytes_in %d, got %v", len(payload), e["bytes_in"])
+	}
+}
+
+func TestMiddlewareSkipsBodiesAboveDebug(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	h := jogger.Middleware(jogger.CaptureRequestBody(64, nil), jogger.CaptureResponseBody(64, nil))(bodyEchoHandler(t, "text/plain"))
+	serve(t, h, httptest.NewRequest("POST", "/", strings.NewReader("hello")))
+
+	e := decodeEntries(t, buf)[0]
+	if _, ok := e["request_body"]; ok {
+		t.Error("request body must not be captured at info level")
+	}
+	if _, ok := e["response_body"]; ok {
+		t.Error("response body must not be captured at info level")
+	}
+}
+
+func TestMiddlewareCapturesBodiesForDebugRequest(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	jogger.EnableRequestDebug("req-body-debug")
+	defer jogger.DisableRequestDebug("req-body-debug")
+
+	h := jogger.Middleware(jogger.CaptureRequestBody(64, nil))(bodyEchoHandler(t, "text/plain"))
+	r := httptest.NewRequest("POST", "/", strings.NewReader("flagged"))
+	r.Header.Set("X-Request-ID", "req-body-debug")
+	serve(t, h, r)
+
+	if e := decodeEntries(t, buf)[0]; e["request_body"] != "flagged" {
+		t.Errorf("expected body capture for a debug-flagged request, got %v", e["request_body"])
+	}
+}
+
+func TestMiddlewareNeverCapturesExcludedTypes(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithLevel(zapcore.DebugLevel))
+
+	h := jogger.Middleware(
+		jogger.CaptureRequestBody(64, []string{"multipart/*", "text/*"}),
+		jogger.CaptureResponseBody(64, nil),
+	)(bodyEchoHandler(t, "application/octet-stream"))
+
+	r := httptest.NewRequest("POST", "/upload", strings.NewReader("--boundary"))
+	r.Header.Set("Content-Type", "multipart/form-data; boundary=boundary")
+	serve(t, h, r)
+
+	e := decodeEntries(t, buf)[0]
+	if _, ok := e["request_body"]; ok {
+		t.Error("multipart bodies must never be captured")
+	}
+	if _, ok := e["response_body"]; ok {
+		t.Error("octet-stream bodies must never be captured")
+	}
+}
+
+func TestMiddlewareEchoesRequestID(t *testing.T) {
+	configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	h := jogger.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Correlation-ID", "inherited")
+	if got := serve(t, h, r).Header().Get("X-Request-ID"); got != "inherited" {
+		t.Errorf("expected inherited request ID echoed, got %q", got)
+	}
+	if got := serve(t, h, httptest.NewRequest("GET", "/", nil)).Header().Get("X-Request-ID"); got == "" {
+		t.Error("expected generated request ID echoed")
+	}
+}
+
+func TestMiddlewareEchoRequestIDOption(t *testing.T) {
+	configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Request-ID", "abc")
+	rec := serve(t, jogger.Middleware(jogger.EchoRequestID("Request-Id"))(ok), r)
+	if rec.Header().Get("Request-Id") != "abc" || rec.Header().Get("X-Request-ID") != "" {
+		t.Errorf("expected request ID only in the configured header, got %v", rec.Header())
+	}
+
+	rec = serve(t, jogger.Middleware(jogger.EchoRequestID(""))(ok), r)
+	if rec.Header().Get("X-Request-ID") != "" {
+		t.Error("expected echoing to be disabled")
+	}
+}
+
+func TestMiddlewareSlowRequestThreshold(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	slow := jogger.Middleware(
+		jogger.SlowRequestThreshold(time.Millisecond),
+		jogger.LatencyBuckets(time.Millisecond, time.Minute),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+	}))
+	serve(t, slow, httptest.NewRequest("GET", "/slow", nil))
+	serve(t, jogger.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})), httptest.NewRequest("GET", "/fast", nil))
+
+	entries := decodeEntries(t, buf)
+	if entries[0]["level"] != "warn" || entries[0]["latency_bucket"] != "1ms_1m0s" {
+		t.Errorf("expected slow request at warn in the 1ms_1m0s bucket, got %v", entries[0])
+	}
+	if entries[1]["level"] != "info" || entries[1]["latency_bucket"] != "lt_10ms" {
+		t.Errorf("expected fast request at info in the default first bucket, got %v", entries[1])
+	}
+}
+
+func TestMiddlewareStreamedResponse(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	h := jogger.Middleware(jogger.SlowRequestThreshold(time.Millisecond))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; i < 3; i++ {
+			io.WriteString(w, "data: tick\n\n")
+			w.(http.Flusher).Flush()
+			time.Sleep(time.Millisecond)
+		}
+	}))
+	rec := serve(t, h, httptest.NewRequest("GET", "/events", nil))
+	if !rec.Flushed {
+		t.Error("expected the flushes to reach the underlying writer")
+	}
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("expected stream start and access entries, got %v", entries)
+	}
+	if entries[0]["msg"] != "stream started" || entries[0]["route"] != "/events" || entries[0]["requestID"] != entries[1]["requestID"] {
+		t.Errorf("unexpected stream start entry %v", entries[0])
+	}
+	access := entries[1]
+	if access["level"] != "info" || access["streamed"] != true || access["chunks"] != float64(3) || access["bytes_out"] != float64(36) {
+		t.Errorf("expected a streamed access log at info, got %v", access)
+	}
+}
+
+type readerFromRecorder struct {
+	*httptest.ResponseRecorder
+	readFrom bool
+}
+
+func (w *readerFromRecorder) ReadFrom(src io.Reader) (int64, error) {
+	w.readFrom = true
+	return io.Copy(w.ResponseRecorder, src)
+}
+
+func TestMiddlewareKeepsOptionalInterfaces(t *testing.T) {
+	configureBuffer(t)
+
+	var isHijacker, isPusher bool
+	h := jogger.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, isHijacker = w.(http.Hijacker)
+		_, isPusher = w.(http.Pusher)
+		// Hide strings.Reader.WriteTo so io.Copy goes through ReadFrom.
+		io.Copy(w, struct{ io.Reader }{strings.NewReader("file contents")})
+		w.(http.Flusher).Flush()
+	}))
+	w := &readerFromRecorder{ResponseRecorder: httptest.NewRecorder()}
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/file", nil))
+
+	if !w.readFrom || w.Body.String() != "file contents" {
+		t.Errorf("expected io.Copy to use the underlying ReadFrom, got %v %q", w.readFrom, w.Body)
+	}
+	if !w.Flushed {
+		t.Error("expected Flush to reach the underlying writer")
+	}
+	if isHijacker || isPusher {
+		t.Errorf("expected only the underlying writer's interfaces, got Hijacker=%v Pusher=%v", isHijacker, isPusher)
+	}
+}
+
+// upgrade performs a bare WebSocket-style handshake over a hijacked
+// connection, as upgraders do after checking for http.Hijacker.
+func upgrade(w http.ResponseWriter, r *http.Request) {
+	h, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "cannot hijack", http.StatusInternalServerError)
+		return
+	}
+	conn, rw, err := h.Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+	rw.Flush()
+}
+
+func TestMiddlewareHijackedConnection(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	done := make(chan struct{})
+	h := jogger.Middleware()(http.HandlerFunc(upgrade))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done) // after the access log is written
+		h.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	io.WriteString(conn, "GET /ws HTTP/1.1\r\nHost: example.com\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected the upgrade to succeed through the middleware, got %d", resp.StatusCode)
+	}
+	<-done
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 1 {
+		t.Fatalf("expected one access entry, got %v", entries)
+	}
+	e := entries[0]
+	if e["msg"] != "GET /ws -> hijacked" || e["hijacked"] != true || e["level"] != "info" {
+		t.Errorf("unexpected hijacked access entry %v", e)
+	}
+	if _, ok := e["status"]; ok {
+		t.Errorf("expected no status for a hijacked connection, got %v", e)
+	}
+	if _, ok := e["bytes_out"]; ok {
+		t.Errorf("expected no bytes_out for a hijacked connection, got %v", e)
+	}
+}
+
+func TestMiddlewareRouteMetadata(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	metadata := jogger.MetadataByRoute(func(r *http.Request) string {
+		if strings.HasPrefix(r.URL.Path, "/orders/") {
+			return "/orders/{id}"
+		}
+		return r.URL.Path
+	}, map[string][]zap.Field{
+		"/orders/{id}": {zap.String("team", "checkout"), zap.String("tier", "1")},
+	})
+	h := jogger.Middleware(jogger.RouteMetadata(metadata))(jogger.Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jogger.Info(r.Context(), "handling")
+	})))
+
+	serve(t, h, httptest.NewRequest(http.MethodGet, "/orders/7", nil))
+	serve(t, h, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 6 {
+		t.Fatalf("expected a handler, span and access entry per request, got %v", entries)
+	}
+	for i, e := range entries {
+		team := e["team"]
+		if i < 3 && (team != "checkout" || e["tier"] != "1") {
+			t.Errorf("expected the route metadata on %q, got %v", e["msg"], e)
+		}
+		if i >= 3 && team != nil {
+			t.Errorf("expected no metadata for an unknown route, got %v", e)
+		}
+	}
+}
+
+func TestMiddlewareRateLimit(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	reset := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := jogger.RateLimitInfoFunc(func(r *http.Request) (jogger.RateLimitInfo, bool) {
+		switch r.URL.Path {
+		case "/throttled":
+			return jogger.RateLimitInfo{Client: "key-1", Limit: 10, Remaining: 0, Reset: reset, Throttled: true}, true
+		case "/ok":
+			return jogger.RateLimitInfo{Client: "key-2", Limit: 10, Remaining: 9}, true
+		case "/panic":
+			panic("limiter broke")
+		}
+		return jogger.RateLimitInfo{}, false
+	})
+	var internal []error
+	jogger.SetInternalErrorHandler(func(err error) { internal = append(internal, err) })
+	defer jogger.SetInternalErrorHandler(nil)
+
+	h := jogger.Middleware(jogger.RateLimit(limiter))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/throttled" {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	for _, path := range []string{"/throttled", "/ok", "/panic", "/other"} {
+		serve(t, h, httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 4 {
+		t.Fatalf("expected four access entries, got %v", entries)
+	}
+	if e := entries[0]; e["level"] != "warn" || e["ratelimit.client"] != "key-1" || e["ratelimit.throttled"] != true ||
+		e["ratelimit.remaining"] != float64(0) || e["ratelimit.reset"] != "2030-01-01T00:00:00.000Z" {
+		t.Errorf("unexpected throttled entry %v", e)
+	}
+	if e := entries[1]; e["level"] != "info" || e["ratelimit.limit"] != float64(10) || e["ratelimit.remaining"] != float64(9) || e["ratelimit.throttled"] != false {
+		t.Errorf("unexpected allowed entry %v", e)
+	}
+	for _, e := range entries[2:] {
+		if e["status"] != float64(200) || e["ratelimit.limit"] != nil {
+			t.Errorf("expected the request unaffected and without rate limit fields, got %v", e)
+		}
+	}
+	if len(internal) != 1 || !strings.Contains(internal[0].Error(), "limiter broke") {
+		t.Errorf("expected the panic reported, got %v", internal)
+	}
+}
@@ -0,0 +1,369 @@
+package jogger
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// Framing selects how entries are delimited on a network output.
+type Framing int
+
+const (
+	// FrameNewline ends every entry with a newline, as the encoders do.
+	FrameNewline Framing = iota
+	// FrameLengthPrefix precedes every entry with its length as a 4-byte
+	// big-endian integer.
+	FrameLengthPrefix
+)
+
+// OverflowPolicy selects which entry a full network buffer drops.
+type OverflowPolicy int
+
+const (
+	// DropNewest drops the entry being written.
+	DropNewest OverflowPolicy = iota
+	// DropOldest drops the oldest buffered entry to make room.
+	DropOldest
+)
+
+const (
+	defaultNetworkBuffer       = 1000
+	defaultNetworkWriteTimeout = time.Second
+	defaultNetworkMinBackoff   = 100 * time.Millisecond
+	defaultNetworkMaxBackoff   = 30 * time.Second
+)
+
+var errNetworkBufferFull = errors.New("jogger: network output buffer full, entry dropped")
+
+type networkConfig struct {
+	buffer       int
+	overflow     OverflowPolicy
+	framing      Framing
+	writeTimeout time.Duration
+	synchronous  bool
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+}
+
+// A NetworkOption configures WithNetworkOutput.
+type NetworkOption func(*networkConfig)
+
+// NetworkBuffer sets how many entries are held while the relay is
+// unreachable, 1000 by default.
+func NetworkBuffer(n int) NetworkOption {
+	return func(c *networkConfig) {
+		c.buffer = n
+	}
+}
+
+// NetworkOverflow sets which entry a full buffer drops, DropNewest by
+// default.
+func NetworkOverflow(p OverflowPolicy) NetworkOption {
+	return func(c *networkConfig) {
+		c.overflow = p
+	}
+}
+
+// NetworkFraming sets how entries are delimited, FrameNewline by default.
+func NetworkFraming(f Framing) NetworkOption {
+	return func(c *networkConfig) {
+		c.framing = f
+	}
+}
+
+// NetworkWriteTimeout bounds every dial and write, one second by default.
+func NetworkWriteTimeout(d time.Duration) NetworkOption {
+	return func(c *networkConfig) {
+		c.writeTimeout = d
+	}
+}
+
+// NetworkSynchronous writes entries from the logging goroutine, each
+// bounded by the write timeout, instead of from a background goroutine.
+func NetworkSynchronous(on bool) NetworkOption {
+	return func(c *networkConfig) {
+		c.synchronous = on
+	}
+}
+
+// NetworkBackoff sets the delays between reconnection attempts, doubling
+// from min to max, 100 milliseconds to 30 seconds by default.
+func NetworkBackoff(min, max time.Duration) NetworkOption {
+	return func(c *networkConfig) {
+		c.minBackoff, c.maxBackoff = min, max
+	}
+}
+
+// WithNetworkOutput writes entries to a relay over network, "tcp" or
+// "udp", at addr instead of stdout. The connection is made on the first
+// entry and remade with exponential backoff when it fails; meanwhile a
+// bounded number of entries is buffered. Writing never blocks for longer
+// than the write timeout.
+func WithNetworkOutput(network, addr string, opts ...NetworkOption) Option {
+	return func(c *config) error {
+		w, err := newNetworkWriter(network, addr, opts)
+		if err != nil {
+			return err
+		}
+		c.writer = w
+		c.owned = append(c.owned, w)
+		return nil
+	}
+}
+
+// networkConn is a connection remade with backoff. It is used by one
+// goroutine at a time: the logging goroutines under networkWriter.mu in
+// synchronous mode, the background goroutine otherwise.
+type networkConn struct {
+	network string
+	addr    string
+	cfg     networkConfig
+	conn    net.Conn
+	backoff time.Duration
+	retryAt time.Time
+	lastErr error
+}
+
+// write connects if the backoff allows it and writes entries in order,
+// returning how many were written.
+func (c *networkConn) write(entries [][]byte) (int, error) {
+	for i, entry := range entries {
+		if c.conn == nil {
+			if time.Now().Before(c.retryAt) {
+				return i, c.lastErr
+			}
+			conn, err := net.DialTimeout(c.network, c.addr, c.cfg.writeTimeout)
+			if err != nil {
+				c.fail(err)
+				return i, err
+			}
+			c.conn = conn
+			c.backoff = c.cfg.minBackoff
+		}
+		c.conn.SetWriteDeadline(time.Now().Add(c.cfg.writeTimeout))
+		if _, err := c.conn.Write(entry); err != nil {
+			c.fail(err)
+			return i, err
+		}
+	}
+	c.lastErr = nil
+	return len(entries), nil
+}
+
+// fail drops the connection and schedules the next attempt.
+func (c *networkConn) fail(err error) {
+	c.close()
+	c.lastErr = err
+	c.retryAt = time.Now().Add(c.backoff)
+	if c.backoff *= 2; c.backoff > c.cfg.maxBackoff {
+		c.backoff = c.cfg.maxBackoff
+	}
+}
+
+func (c *networkConn) close() {
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn = nil
+	}
+}
+
+// networkWriter is a WriteSyncer over a networkConn with a bounded buffer
+// of entries not written yet.
+type networkWriter struct {
+	cfg networkConfig
+	c   *networkConn
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	pending  [][]byte
+	inflight int
+	lastErr  error
+	started  bool
+	closed   bool
+	stopping chan struct{}
+}
+
+func newNetworkWriter(network, addr string, opts []NetworkOption) (*networkWriter, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6", "unix":
+	default:
+		return nil, fmt.Errorf("jogger: unsupported network %q", network)
+	}
+	if addr == "" {
+		return nil, errors.New("jogger: empty network address")
+	}
+	cfg := networkConfig{
+		buffer:       defaultNetworkBuffer,
+		writeTimeout: defaultNetworkWriteTimeout,
+		minBackoff:   defaultNetworkMinBackoff,
+		maxBackoff:   defaultNetworkMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.buffer <= 0 || cfg.writeTimeout <= 0 || cfg.minBackoff <= 0 || cfg.maxBackoff < cfg.minBackoff {
+		return nil, errors.New("jogger: network buffer, write timeout and backoff must be positive")
+	}
+	if cfg.overflow != DropNewest && cfg.overflow != DropOldest {
+		return nil, fmt.Errorf("jogger: unknown overflow policy %d", cfg.overflow)
+	}
+	if cfg.framing != FrameNewline && cfg.framing != FrameLengthPrefix {
+		return nil, fmt.Errorf("jogger: unknown framing %d", cfg.framing)
+	}
+	w := &networkWriter{
+		cfg:      cfg,
+		c:        &networkConn{network: network, addr: addr, cfg: cfg, backoff: cfg.minBackoff},
+		stopping: make(chan struct{}),
+	}
+	w.cond = sync.NewCond(&w.mu)
+	return w, nil
+}
+
+func (w *networkWriter) String() string {
+	return w.c.network + "://" + w.c.addr
+}
+
+func (w *networkWriter) frame(p []byte) []byte {
+	if w.cfg.framing == FrameLengthPrefix {
+		b := make([]byte, 4+len(p))
+		binary.BigEndian.PutUint32(b, uint32(len(p)))
+		copy(b[4:], p)
+		return b
+	}
+	return append([]byte(nil), p...)
+}
+
+func (w *networkWriter) Write(p []byte) (int, error) {
+	entry := w.frame(p)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, errors.New("jogger: network output closed")
+	}
+	err := w.enqueue(entry)
+	if w.cfg.synchronous {
+		w.flushLocked()
+		return len(p), err
+	}
+	if !w.started {
+		w.started = true
+		go w.run()
+	}
+	w.cond.Broadcast()
+	return len(p), err
+}
+
+// enqueue buffers entry under the overflow policy. w.mu must be held.
+func (w *networkWriter) enqueue(entry []byte) error {
+	if len(w.pending) < w.cfg.buffer {
+		w.pending = append(w.pending, entry)
+		return nil
+	}
+	if w.cfg.overflow == DropOldest {
+		copy(w.pending, w.pending[1:])
+		w.pending[len(w.pending)-1] = entry
+	}
+	countDrop(dropOverflow)
+	return errNetworkBufferFull
+}
+
+// flushLocked writes the pending entries from the calling goroutine, in
+// synchronous mode. w.mu must be held.
+func (w *networkWriter) flushLocked() {
+	n, err := w.c.write(w.pending)
+	w.pending = w.pending[n:]
+	w.lastErr = err
+}
+
+// run writes pending entries in the background, outside w.mu, waiting out
+// the backoff between failed attempts.
+func (w *networkWriter) run() {
+	defer w.c.close()
+	for {
+		w.mu.Lock()
+		for len(w.pending) == 0 && !w.closed {
+			w.cond.Wait()
+		}
+		if w.closed {
+			w.mu.Unlock()
+			return
+		}
+		batch := w.pending
+		w.pending = nil
+		w.inflight = len(batch)
+		w.mu.Unlock()
+
+		n, err := w.c.write(batch)
+
+		w.mu.Lock()
+		if rest := batch[n:]; len(rest) > 0 {
+			w.pending = append(rest, w.pending...)
+			if extra := len(w.pending) - w.cfg.buffer; extra > 0 {
+				w.pending = w.pending[extra:]
+			}
+		}
+		w.inflight = 0
+		w.lastErr = err
+		w.cond.Broadcast()
+		w.mu.Unlock()
+
+		if err != nil {
+			select {
+			case <-time.After(time.Until(w.c.retryAt)):
+			case <-w.stopping:
+			}
+		}
+	}
+}
+
+// Sync writes the pending entries, waiting for at most the write timeout,
+// and reports the last connection error if some remain.
+func (w *networkWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cfg.synchronous {
+		w.flushLocked()
+	} else if w.started {
+		expired := false
+		timer := time.AfterFunc(w.cfg.writeTimeout, func() {
+			w.mu.Lock()
+			expired = true
+			w.cond.Broadcast()
+			w.mu.Unlock()
+		})
+		defer timer.Stop()
+		for (len(w.pending) > 0 || w.inflight > 0) && !expired && !w.closed {
+			w.cond.Wait()
+		}
+	}
+	if len(w.pending) > 0 {
+		if w.lastErr != nil {
+			return w.lastErr
+		}
+		return fmt.Errorf("jogger: %d entries not yet written to %s", len(w.pending), w)
+	}
+	return nil
+}
+
+// Close writes what it can within the write timeout and closes the
+// connection.
+func (w *networkWriter) Close() error {
+	err := w.Sync()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	close(w.stopping)
+	w.cond.Broadcast()
+	if !w.started {
+		w.c.close()
+	}
+	return err
+}
@@ -0,0 +1,186 @@
+package jogger_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+)
+
+func listen(t *testing.T) net.Listener {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	return ln
+}
+
+func configureNetwork(t *testing.T, addr string, opts ...jogger.NetworkOption) {
+	t.Helper()
+	if err := jogger.Configure(jogger.WithFormat(jogger.FormatJSON), jogger.WithNetworkOutput("tcp", addr, opts...)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { jogger.Configure() })
+}
+
+func accept(t *testing.T, ln net.Listener) net.Conn {
+	t.Helper()
+	ln.(*net.TCPListener).SetDeadline(time.Now().Add(5 * time.Second))
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return conn
+}
+
+func readMessage(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+	line, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &e); err != nil {
+		t.Fatalf("invalid entry %q: %v", line, err)
+	}
+	msg, _ := e["msg"].(string)
+	return msg
+}
+
+func TestNetworkOutputReconnects(t *testing.T) {
+	ln := listen(t)
+	configureNetwork(t, ln.Addr().String(), jogger.NetworkBackoff(time.Millisecond, 10*time.Millisecond))
+
+	jogger.Info(context.Background(), "first")
+	conn := accept(t, ln)
+	if msg := readMessage(t, bufio.NewReader(conn)); msg != "first" {
+		t.Fatalf("expected the first entry, got %q", msg)
+	}
+	conn.Close()
+
+	// Writes to the closed connection fail once the peer's reset arrives;
+	// keep logging until the writer has reconnected.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(5 * time.Millisecond):
+				jogger.Info(context.Background(), "after reconnect")
+			}
+		}
+	}()
+	conn = accept(t, ln)
+	defer conn.Close()
+	if msg := readMessage(t, bufio.NewReader(conn)); msg != "after reconnect" {
+		t.Errorf("expected entries on the new connection, got %q", msg)
+	}
+}
+
+func TestNetworkOutputBuffersWhileDisconnected(t *testing.T) {
+	ln := listen(t)
+	addr := ln.Addr().String()
+	ln.Close()
+	configureNetwork(t, addr,
+		jogger.NetworkSynchronous(true),
+		jogger.NetworkBuffer(2),
+		jogger.NetworkOverflow(jogger.DropOldest),
+		jogger.NetworkBackoff(time.Millisecond, time.Millisecond),
+	)
+	before := jogger.Stats().Dropped.Overflow
+
+	for _, msg := range []string{"one", "two", "three"} {
+		jogger.Info(context.Background(), msg)
+	}
+	if got := jogger.Stats().Dropped.Overflow - before; got != 1 {
+		t.Errorf("expected one overflowed entry counted, got %d", got)
+	}
+	if err := jogger.Sync(); err == nil {
+		t.Error("expected Sync to report the unreachable relay")
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("cannot listen on %s again: %v", addr, err)
+	}
+	defer ln.Close()
+	time.Sleep(5 * time.Millisecond)
+	if err := jogger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	conn := accept(t, ln)
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	if a, b := readMessage(t, r), readMessage(t, r); a != "two" || b != "three" {
+		t.Errorf("expected the two newest entries, got %q and %q", a, b)
+	}
+}
+
+func TestNetworkOutputLengthPrefix(t *testing.T) {
+	ln := listen(t)
+	configureNetwork(t, ln.Addr().String(), jogger.NetworkFraming(jogger.FrameLengthPrefix))
+
+	jogger.Info(context.Background(), "framed")
+	conn := accept(t, ln)
+	defer conn.Close()
+
+	var size uint32
+	if err := binary.Read(conn, binary.BigEndian, &size); err != nil {
+		t.Fatal(err)
+	}
+	entry := make([]byte, size)
+	if _, err := io.ReadFull(conn, entry); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(entry), `"msg":"framed"`) {
+		t.Errorf("unexpected framed entry %q", entry)
+	}
+}
+
+func TestNetworkOutputSynchronousWriteTimeout(t *testing.T) {
+	ln := listen(t)
+	configureNetwork(t, ln.Addr().String(), jogger.NetworkSynchronous(true), jogger.NetworkWriteTimeout(50*time.Millisecond))
+
+	// The relay accepts but never reads, so the socket buffers fill up.
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			defer conn.Close()
+			time.Sleep(5 * time.Second)
+		}
+	}()
+
+	payload := strings.Repeat("x", 4<<20)
+	start := time.Now()
+	for i := 0; i < 8; i++ {
+		jogger.Info(context.Background(), "big", zap.String("payload", payload))
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("expected the write timeout to bound logging against a stalled relay, took %v", elapsed)
+	}
+}
+
+func TestWithNetworkOutputRejectsInvalidSettings(t *testing.T) {
+	for name, opt := range map[string]jogger.Option{
+		"network":  jogger.WithNetworkOutput("ipx", "localhost:1"),
+		"address":  jogger.WithNetworkOutput("tcp", ""),
+		"buffer":   jogger.WithNetworkOutput("tcp", "localhost:1", jogger.NetworkBuffer(0)),
+		"overflow": jogger.WithNetworkOutput("tcp", "localhost:1", jogger.NetworkOverflow(jogger.OverflowPolicy(9))),
+	} {
+		if err := jogger.Configure(opt); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
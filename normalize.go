@@ -0,0 +1,156 @@
+package jogger
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	normalizerMu sync.Mutex
+	normalizers  atomic.Value // map[string]func(zap.Field) zap.Field, replaced on every change
+)
+
+func init() {
+	normalizers.Store(map[string]func(zap.Field) zap.Field{})
+}
+
+// RegisterFieldNormalizer makes fields named key pass through fn before
+// they are written, for a log schema requiring canonical values, such as
+// NormalizeMethod for method. It applies to the fields of every instance,
+// including those of With and the ones jogger adds. fn must not change the
+// key. A nil fn removes the registration. It is safe to call while logging;
+// without registrations, fields are not looked at.
+func RegisterFieldNormalizer(key string, fn func(zap.Field) zap.Field) {
+	normalizerMu.Lock()
+	defer normalizerMu.Unlock()
+
+	cur := normalizers.Load().(map[string]func(zap.Field) zap.Field)
+	next := make(map[string]func(zap.Field) zap.Field, len(cur)+1)
+	for k, v := range cur {
+		next[k] = v
+	}
+	if fn != nil {
+		next[key] = fn
+	} else {
+		delete(next, key)
+	}
+	normalizers.Store(next)
+}
+
+// NormalizeMethod upper-cases an HTTP method, so "get" is written as "GET".
+func NormalizeMethod(f zap.Field) zap.Field {
+	if f.Type == zapcore.StringType {
+		f.String = strings.ToUpper(f.String)
+	}
+	return f
+}
+
+// levelAliases maps level spellings of other libraries to zap's.
+var levelAliases = map[string]string{
+	"warning":     "warn",
+	"err":         "error",
+	"information": "info",
+	"trace":       "debug",
+	"critical":    "fatal",
+}
+
+// NormalizeLevel writes a level-like string the way zap names levels: "WARN"
+// and "Warning" become "warn". Values that are not a level are left as they
+// are.
+func NormalizeLevel(f zap.Field) zap.Field {
+	if f.Type != zapcore.StringType {
+		return f
+	}
+	s := strings.ToLower(f.String)
+	if alias, ok := levelAliases[s]; ok {
+		s = alias
+	}
+	var lvl zapcore.Level
+	if lvl.UnmarshalText([]byte(s)) == nil {
+		f.String = lvl.String()
+	}
+	return f
+}
+
+// NormalizeInt turns a string holding an integer, such as a status code
+// read from a header, into an integer field.
+func NormalizeInt(f zap.Field) zap.Field {
+	if f.Type != zapcore.StringType {
+		return f
+	}
+	if n, err := strconv.ParseInt(strings.TrimSpace(f.String), 10, 64); err == nil {
+		return zap.Int64(f.Key, n)
+	}
+	return f
+}
+
+// NormalizeEnum returns a normalizer for a string field with a fixed set of
+// values: a value matching one of them regardless of case is written as
+// given here, and any other value as "invalid:" followed by the value, so
+// it stands out in queries.
+func NormalizeEnum(values ...string) func(zap.Field) zap.Field {
+	canonical := make(map[string]string, len(values))
+	for _, v := range values {
+		canonical[strings.ToLower(v)] = v
+	}
+	return func(f zap.Field) zap.Field {
+		if f.Type != zapcore.StringType {
+			return f
+		}
+		if v, ok := canonical[strings.ToLower(f.String)]; ok {
+			f.String = v
+		} else {
+			f.String = "invalid:" + f.String
+		}
+		return f
+	}
+}
+
+// normalizeFields applies the registered normalizers to fields, copying
+// them on the first change.
+func normalizeFields(fields []zapcore.Field) []zapcore.Field {
+	reg := normalizers.Load().(map[string]func(zap.Field) zap.Field)
+	if len(reg) == 0 {
+		return fields
+	}
+	copied := false
+	for i, f := range fields {
+		fn, ok := reg[f.Key]
+		if !ok {
+			continue
+		}
+		if !copied {
+			fields = append([]zapcore.Field(nil), fields...)
+			copied = true
+		}
+		fields[i] = fn(f)
+		fields[i].Key = f.Key
+	}
+	return fields
+}
+
+// normalizeCore applies the field normalizers. It sits inside the other
+// wrappers, so it sees the fields they add as well.
+type normalizeCore struct {
+	zapcore.Core
+}
+
+func (c normalizeCore) With(fields []zapcore.Field) zapcore.Core {
+	return normalizeCore{c.Core.With(normalizeFields(fields))}
+}
+
+func (c normalizeCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c normalizeCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, normalizeFields(fields))
+}
@@ -0,0 +1,62 @@
+package jogger_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+)
+
+func TestRegisterFieldNormalizer(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	jogger.RegisterFieldNormalizer("method", jogger.NormalizeMethod)
+	jogger.RegisterFieldNormalizer("status", jogger.NormalizeInt)
+	jogger.RegisterFieldNormalizer("severity", jogger.NormalizeLevel)
+	jogger.RegisterFieldNormalizer("country", jogger.NormalizeEnum("DE", "FR", "US"))
+	defer func() {
+		for _, key := range []string{"method", "status", "severity", "country"} {
+			jogger.RegisterFieldNormalizer(key, nil)
+		}
+	}()
+
+	ctx := jogger.WithFields(context.Background(), zap.String("country", "fr"))
+	jogger.Info(ctx, "request",
+		zap.String("method", "get"),
+		zap.String("status", "404"),
+		zap.String("severity", "WARNING"),
+	)
+	jogger.Info(context.Background(), "unknown", zap.String("country", "xx"), zap.String("status", "n/a"))
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	e := entries[0]
+	if e["method"] != "GET" {
+		t.Errorf("expected method GET, got %v", e["method"])
+	}
+	if e["status"] != float64(404) {
+		t.Errorf("expected status as a number, got %#v", e["status"])
+	}
+	if e["severity"] != "warn" {
+		t.Errorf("expected severity warn, got %v", e["severity"])
+	}
+	if e["country"] != "FR" {
+		t.Errorf("expected the country of WithFields normalized, got %v", e["country"])
+	}
+	if e := entries[1]; e["country"] != "invalid:xx" || e["status"] != "n/a" {
+		t.Errorf("expected an invalid country flagged and a non-numeric status kept, got %v", e)
+	}
+}
+
+func TestRegisterFieldNormalizerRemoved(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	jogger.RegisterFieldNormalizer("method", jogger.NormalizeMethod)
+	jogger.RegisterFieldNormalizer("method", nil)
+
+	jogger.Info(context.Background(), "request", zap.String("method", "get"))
+	if e := decodeEntries(t, buf)[0]; e["method"] != "get" {
+		t.Errorf("expected the method as logged, got %v", e["method"])
+	}
+}
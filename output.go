@@ -0,0 +1,298 @@
+package jogger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// output is an immutable set of loggers built from one configuration.
+// Reconfiguring builds a new output and swaps it in atomically.
+type output struct {
+	cfg     config
+	sinks   []string
+	built   []sink // resolved sinks, for DumpConfig
+	summary *exitSummary
+	budget  *budgetTracker
+	base    *zap.Logger
+	debug   *zap.Logger
+	audit   auditLoggers
+	events  *zap.Logger
+	dedup   *deduper
+}
+
+func init() {
+	cfg := defaultConfig()
+	level.SetLevel(cfg.level)
+
+	o, err := newOutput(cfg, level)
+	if err != nil {
+		cfg.format = FormatConsole
+		o, _ = newOutput(cfg, level)
+	}
+	std.out.Store(o)
+}
+
+// newOutput builds the output of cfg, its base logger filtered by lvl.
+func newOutput(cfg config, lvl zap.AtomicLevel) (*output, error) {
+	sinks, err := buildSinks(cfg)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(sinks)+1)
+	for _, s := range sinks {
+		names = append(names, s.name)
+	}
+	if cfg.auditWriter != nil {
+		names = append(names, auditSinkName)
+	}
+	if cfg.eventSink != nil {
+		names = append(names, cfg.eventSink.Name)
+	}
+	for name := range cfg.sinkFields {
+		if !containsString(names, name) {
+			return nil, fmt.Errorf("jogger: fields for unknown sink %q", name)
+		}
+	}
+
+	errOut := zap.ErrorOutput(internalErrorOutput{})
+	opts := []zap.Option{errOut}
+	if cfg.development {
+		opts = append(opts, zap.Development())
+	}
+	opts = append(opts, cfg.zapOptions...)
+	dedup := newDeduper(cfg.dedupWindow, cfg.dedupScope, cfg.fieldNames.RequestID)
+	audit, err := newAuditLoggers(cfg, sinks, errOut)
+	if err != nil {
+		return nil, err
+	}
+	events, err := newEventLogger(cfg, sinks, errOut)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := newExitSummary(cfg.exitSummary)
+	base := newCore(cfg, sinks, func(s sink) zapcore.LevelEnabler { return s.enabler(lvl) }, dedup, summary)
+	debug := newCore(cfg, sinks, func(s sink) zapcore.LevelEnabler { return s.enabler(zapcore.DebugLevel) }, dedup, summary)
+	if cfg.cardinalityMax > 0 {
+		guard := newCardinalityGuard(cfg.cardinalityKeys, cfg.cardinalityMax, cfg.cardinalityReset)
+		base, debug = newCardinalityCore(base, guard), newCardinalityCore(debug, guard)
+	}
+	base, debug = newCrashCore(base), newCrashCore(debug)
+	for _, wrap := range cfg.coreWrappers {
+		base, debug = wrap(base), wrap(debug)
+	}
+	return &output{
+		cfg:     cfg,
+		sinks:   names,
+		built:   sinks,
+		summary: summary,
+		budget:  newBudgetTracker(cfg.errorBudget),
+		base:    zap.New(base, opts...).With(cfg.fields...),
+		debug:   zap.New(debug, opts...).With(cfg.fields...),
+		audit:   audit,
+		events:  events,
+		dedup:   dedup,
+	}, nil
+}
+
+// newCore assembles the core writing to sinks, each enabled by enab.
+// Wrappers are applied inside out: fingerprints are taken from the raw
+// message, truncation sees already escaped values, the entry size cap sees
+// already truncated fields, and the stats core counts only what was
+// actually written, which excludes entries suppressed by deduplication.
+// Fingerprinting, stats and deduplication wrap the tee of all sinks, so
+// they see each entry once, and the goroutine ID is added outside them.
+// Field normalizers run inside them, after every field is added.
+func newCore(cfg config, sinks []sink, enab func(sink) zapcore.LevelEnabler, dedup *deduper, summary *exitSummary) zapcore.Core {
+	cores := make([]zapcore.Core, 0, len(sinks))
+	for _, s := range sinks {
+		core := s.ioCore(enab(s))
+		if cfg.maxEntrySize > 0 {
+			core = newEntrySizeCore(core, s.enc, cfg.maxEntrySize)
+		}
+		if cfg.maxFieldLength > 0 {
+			core = newTruncateCore(core, cfg.maxFieldLength)
+		}
+		if escape := cfg.escapingEnabled(s.format); escape || cfg.stripANSI {
+			core = newEscapeCore(core, escape, cfg.stripANSI)
+		}
+		core = sinkCore{core}
+		if s.route != nil {
+			core = &routeCore{Core: core, pred: s.route}
+		}
+		cores = append(cores, core)
+	}
+	core := cores[0]
+	if len(cores) > 1 {
+		core = zapcore.NewTee(cores...)
+	}
+	if cfg.stack != nil {
+		core = stackCore{core, cfg.stack}
+	}
+	core = normalizeCore{core}
+	core = &suppressCore{Core: newDedupCore(newStatsCore(newFingerprintCore(core, summary)), dedup)}
+	if cfg.goroutineID {
+		core = goroutineCore{core}
+	}
+	return core
+}
+
+// sinkCore checks the sink level again on Write, since the wrappers around
+// the tee add themselves to checked entries and write to every sink.
+type sinkCore struct {
+	zapcore.Core
+}
+
+func (c sinkCore) With(fields []zapcore.Field) zapcore.Core {
+	return sinkCore{c.Core.With(fields)}
+}
+
+func (c sinkCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if !c.Enabled(ent.Level) {
+		return nil
+	}
+	return c.Core.Write(ent, fields)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sinkName(w io.Writer) string {
+	switch w {
+	case os.Stdout:
+		return "stdout"
+	case os.Stderr:
+		return "stderr"
+	}
+	switch w := w.(type) {
+	case *os.File:
+		return w.Name()
+	case *networkWriter:
+		return w.String()
+	}
+	return "writer"
+}
+
+func newEncoder(format string) (zapcore.Encoder, error) {
+	encoderCfg := zap.NewProductionEncoderConfig()
+	encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	switch format {
+	case FormatConsole:
+		encoderCfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		return &prefixEncoder{Encoder: zapcore.NewConsoleEncoder(encoderCfg)}, nil
+	case FormatJSON:
+		return zapcore.NewJSONEncoder(encoderCfg), nil
+	}
+	return nil, fmt.Errorf("jogger: unknown format %q", format)
+}
+
+// currentOutput returns the output of the default instance.
+func currentOutput() *output {
+	return std.output()
+}
+
+// outputFor returns the output of the instance ctx was bound to, see
+// Jogger.WithContext, or of the default instance.
+func outputFor(ctx context.Context) *output {
+	s := scopeOf(ctx)
+	return s.output()
+}
+
+// swap replaces the output of j and releases the old one.
+func (j *Jogger) swap(o *output) {
+	old, _ := j.out.Load().(*output)
+	j.out.Store(o)
+	if o.summary != nil {
+		setSpanHook(j.summaryHook(), o.summary.observeSpan)
+	} else if old != nil && old.summary != nil {
+		setSpanHook(j.summaryHook(), nil)
+	}
+	if o.budget != nil {
+		setSpanHook(j.budgetHook(), o.budget.observeSpan)
+		o.budget.run(o.base)
+	} else if old != nil && old.budget != nil {
+		setSpanHook(j.budgetHook(), nil)
+	}
+	if o.cfg.development {
+		atomic.AddInt32(&devOutputs, 1)
+	}
+	if old == nil {
+		return
+	}
+	if old.cfg.development {
+		atomic.AddInt32(&devOutputs, -1)
+	}
+	if old.budget != nil {
+		old.budget.close()
+	}
+	old.dedup.flush()
+	_ = old.sync()
+	for _, c := range old.cfg.owned {
+		if !o.owns(c) {
+			_ = c.Close()
+		}
+	}
+}
+
+// owns reports whether c is a writer the configuration of o opened, such as
+// a network output, which stays open while it is in use.
+func (o *output) owns(c io.Closer) bool {
+	for _, owned := range o.cfg.owned {
+		if owned == c {
+			return true
+		}
+	}
+	return false
+}
+
+// Sync flushes every sink, the audit output included.
+func Sync() error {
+	return std.Sync()
+}
+
+// Shutdown writes the entries jogger holds back, such as deduplication
+// counts, and the trace events file, and syncs the output. Call it before
+// the process exits.
+func Shutdown() error {
+	err := writeTraceEvents()
+	if serr := std.Shutdown(); err == nil {
+		err = serr
+	}
+	return err
+}
+
+// sync syncs the main, the audit and the event output.
+func (o *output) sync() error {
+	err := o.base.Sync()
+	if aerr := o.audit.all.Sync(); err == nil {
+		err = aerr
+	}
+	if eerr := o.events.Sync(); err == nil {
+		err = eerr
+	}
+	return err
+}
+
+func baseLogger() *zap.Logger {
+	return currentOutput().base
+}
+
+func (o *output) loggerFor(requestID string) *zap.Logger {
+	if RequestDebugEnabled(requestID) || incidentDebug(requestID) {
+		return o.debug
+	}
+	return o.base
+}
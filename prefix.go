@@ -0,0 +1,76 @@
+package jogger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// prefixKey is the field WithPrefix adds. Console sinks turn it into a
+// message prefix, JSON sinks keep it as a field.
+const prefixKey = "prefix"
+
+// prefixValue is the value of the prefix field. Being unexported, it tells
+// the field jogger adds apart from fields named prefix that code logs
+// itself, which are left alone.
+type prefixValue string
+
+// WithPrefix returns a copy of ctx whose entries start with "[prefix] " on
+// console sinks, for readability during local development, as in
+// "[cache] miss". Nested prefixes are joined, as in "[api][cache] miss".
+// JSON sinks get a prefix field instead and keep the message untouched.
+func WithPrefix(ctx context.Context, prefix string) context.Context {
+	ctx = orBackground(ctx)
+	if prefix == "" {
+		return ctx
+	}
+	s := scopeOf(ctx)
+	s.prefix += "[" + prefix + "]"
+	return withScope(ctx, s)
+}
+
+// prefixEncoder is the console encoder, moving the prefix field in front
+// of the message.
+type prefixEncoder struct {
+	zapcore.Encoder
+	prefix string
+}
+
+// AddReflected captures the prefix field when a logger is built With it.
+func (e *prefixEncoder) AddReflected(key string, v interface{}) error {
+	if p, ok := v.(prefixValue); ok {
+		e.prefix = string(p)
+		return nil
+	}
+	return e.Encoder.AddReflected(key, v)
+}
+
+func (e *prefixEncoder) Clone() zapcore.Encoder {
+	return &prefixEncoder{Encoder: e.Encoder.Clone(), prefix: e.prefix}
+}
+
+func (e *prefixEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	prefix := e.prefix
+	for i, f := range fields {
+		if p, ok := f.Interface.(prefixValue); ok && f.Type == zapcore.ReflectType {
+			prefix = string(p)
+			fields = append(fields[:i:i], fields[i+1:]...)
+			break
+		}
+	}
+	if prefix != "" {
+		// The message was escaped before it got here, the prefix was not.
+		ent.Message = escapeControl(prefix) + " " + ent.Message
+	}
+	return e.Encoder.EncodeEntry(ent, fields)
+}
+
+// prefixField appends the prefix field of s.
+func (s *Scope) prefixField(fields []zap.Field) []zap.Field {
+	if s.prefix == "" {
+		return fields
+	}
+	return append(fields, zap.Reflect(prefixKey, prefixValue(s.prefix)))
+}
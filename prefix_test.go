@@ -0,0 +1,70 @@
+package jogger_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+)
+
+func TestWithPrefixConsole(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatConsole))
+
+	ctx := jogger.WithPrefix(context.Background(), "api")
+	jogger.Info(ctx, "request")
+	ctx = jogger.WithPrefix(ctx, "cache")
+	jogger.Info(ctx, "miss")
+	span, _ := jogger.StartSpan(ctx, "cache.get")
+	span.Finish(nil)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected three lines, got %q", buf.String())
+	}
+	for i, want := range []string{"[api] request", "[api][cache] miss", "[api][cache] span finished"} {
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("expected %q in %q", want, lines[i])
+		}
+		if strings.Contains(lines[i], `"prefix"`) {
+			t.Errorf("expected no prefix field on the console, got %q", lines[i])
+		}
+	}
+}
+
+func TestWithPrefixJSON(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	ctx := jogger.WithPrefix(jogger.WithPrefix(context.Background(), "api"), "cache")
+	jogger.Info(ctx, "miss")
+
+	e := decodeEntries(t, buf)[0]
+	if e["msg"] != "miss" || e["prefix"] != "[api][cache]" {
+		t.Errorf("expected a clean message and a prefix field, got %v", e)
+	}
+}
+
+func TestUserPrefixFieldKept(t *testing.T) {
+	for _, format := range []string{jogger.FormatConsole, jogger.FormatJSON} {
+		buf := configureBuffer(t, jogger.WithFormat(format))
+
+		jogger.Info(context.Background(), "listing objects", zap.String("prefix", "users/"))
+
+		out := buf.String()
+		if strings.Contains(out, "users/ listing objects") || !strings.Contains(out, "users/") {
+			t.Errorf("%s: expected the prefix field kept as a field, got %q", format, out)
+		}
+	}
+}
+
+func TestWithPrefixEscaped(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatConsole))
+
+	ctx := jogger.WithPrefix(context.Background(), "api\n2026-01-01T00:00:00.000Z\tERROR\tforged")
+	jogger.Info(ctx, "request")
+
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 1 {
+		t.Errorf("expected one line, got %q", buf.String())
+	}
+}
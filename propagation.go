@@ -0,0 +1,129 @@
+package jogger
+
+import (
+	"context"
+	"net/http"
+)
+
+// RequestIDHeader is the header Inject writes and Extract reads the request
+// ID from.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDHeaders are checked by Extract in order.
+var requestIDHeaders = []string{RequestIDHeader, "X-Correlation-ID"}
+
+// Carrier is the transport a request's correlation values travel in, such as
+// HTTP headers, gRPC metadata or message queue headers.
+type Carrier interface {
+	Get(key string) string
+	Set(key, value string)
+}
+
+// HeaderCarrier adapts http.Header to Carrier.
+type HeaderCarrier http.Header
+
+func (c HeaderCarrier) Get(key string) string {
+	return http.Header(c).Get(key)
+}
+
+func (c HeaderCarrier) Set(key, value string) {
+	http.Header(c).Set(key, value)
+}
+
+// MapCarrier adapts a string map, such as message attributes, to Carrier.
+type MapCarrier map[string]string
+
+func (c MapCarrier) Get(key string) string {
+	return c[key]
+}
+
+func (c MapCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+// Inject writes the request ID, the sampling decision, the baggage and every
+// correlation key registered with PropagateAs from ctx into c.
+func Inject(ctx context.Context, c Carrier) {
+	ctx = orBackground(ctx)
+	if rid := RequestID(ctx); rid != "" {
+		c.Set(RequestIDHeader, rid)
+	}
+	if sampled := scopeOf(ctx).Sampled; sampled != nil {
+		c.Set(SampledHeader, formatSampled(*sampled))
+	}
+	if b := baggageFrom(ctx); b != nil {
+		c.Set(BaggageHeader, formatBaggage(b))
+	}
+	s := scopeOf(ctx)
+	for _, k := range registeredCorrelationKeys() {
+		if k.header == "" {
+			continue
+		}
+		if v, ok := k.value(ctx, &s).(string); ok && v != "" {
+			c.Set(k.header, v)
+		}
+	}
+}
+
+// Extract returns a copy of ctx carrying the request ID, the sampling
+// decision, the baggage and the registered correlation values found in c.
+// Correlation values are cleaned with SanitizeID, like the request ID.
+func Extract(ctx context.Context, c Carrier) context.Context {
+	ctx = orBackground(ctx)
+	for _, h := range requestIDHeaders {
+		if rid := c.Get(h); rid != "" {
+			ctx = WithRequestID(ctx, rid)
+			break
+		}
+	}
+	if sampled, ok := parseSampled(c.Get(SampledHeader)); ok {
+		ctx = withSampled(ctx, sampled)
+	}
+	if h := c.Get(BaggageHeader); h != "" {
+		ctx = parseBaggage(ctx, h)
+	}
+	for _, k := range registeredCorrelationKeys() {
+		if k.header == "" {
+			continue
+		}
+		v := SanitizeID(c.Get(k.header))
+		switch {
+		case v == "":
+		case k.scoped:
+			ctx = withScopedValue(ctx, k.key, v)
+		default:
+			ctx = context.WithValue(ctx, k.key, v)
+		}
+	}
+	return ctx
+}
+
+// renamedCarrier stores keys under other names.
+type renamedCarrier struct {
+	Carrier
+	names map[string]string
+}
+
+// RenameKeys returns a Carrier that reads and writes the keys Inject and
+// Extract use, such as RequestIDHeader, under the names given in names, for
+// transports with their own naming rules. Keys not in names pass through.
+func RenameKeys(c Carrier, names map[string]string) Carrier {
+	if len(names) == 0 {
+		return c
+	}
+	return renamedCarrier{Carrier: c, names: names}
+}
+
+func (c renamedCarrier) Get(key string) string {
+	if name, ok := c.names[key]; ok {
+		key = name
+	}
+	return c.Carrier.Get(key)
+}
+
+func (c renamedCarrier) Set(key, value string) {
+	if name, ok := c.names[key]; ok {
+		key = name
+	}
+	c.Carrier.Set(key, value)
+}
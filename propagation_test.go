@@ -0,0 +1,96 @@
+package jogger_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+)
+
+func TestInjectExtractHeaders(t *testing.T) {
+	upstream := jogger.WithRequestID(context.Background(), "req-upstream")
+	upstream = context.WithValue(upstream, sessionKey, "sess-up")
+	upstream = jogger.WithUserID(upstream, "user-not-propagated")
+
+	h := http.Header{}
+	jogger.Inject(upstream, jogger.HeaderCarrier(h))
+
+	if got := h.Get(jogger.RequestIDHeader); got != "req-upstream" {
+		t.Errorf("expected request ID header, got %q", got)
+	}
+	if got := h.Get("X-Session-ID"); got != "sess-up" {
+		t.Errorf("expected session header, got %q", got)
+	}
+	if len(h) != 2 {
+		t.Errorf("expected only propagated keys to be injected, got %v", h)
+	}
+
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	downstream := jogger.Extract(context.Background(), jogger.HeaderCarrier(h))
+	jogger.Info(downstream, "downstream")
+
+	e := decodeEntries(t, buf)[0]
+	if e["requestID"] != "req-upstream" || e["sessionID"] != "sess-up" {
+		t.Errorf("expected upstream correlation in downstream logs, got %v", e)
+	}
+	if _, ok := e["userID"]; ok {
+		t.Errorf("userID is not propagated by default, got %v", e)
+	}
+}
+
+func TestExtractFallbackHeaderAndMapCarrier(t *testing.T) {
+	c := jogger.MapCarrier{"X-Correlation-ID": "corr-1"}
+	ctx := jogger.Extract(context.Background(), c)
+	if got := jogger.RequestID(ctx); got != "corr-1" {
+		t.Errorf("expected request ID from X-Correlation-ID, got %q", got)
+	}
+
+	out := jogger.MapCarrier{}
+	jogger.Inject(ctx, out)
+	if out[jogger.RequestIDHeader] != "corr-1" {
+		t.Errorf("expected request ID to be injected into map carrier, got %v", out)
+	}
+}
+
+func TestExtractSanitizesCorrelationValues(t *testing.T) {
+	buf := configureBuffer(t)
+	c := jogger.MapCarrier{"X-Session-ID": "sess-1\r\nINFO\tforged entry"}
+
+	ctx := jogger.Extract(context.Background(), c)
+	if got, _ := ctx.Value(sessionKey).(string); got != "sess-1INFOforged entry" {
+		t.Errorf("expected the control characters removed, got %q", got)
+	}
+	jogger.Info(ctx, "extracted")
+	if out := buf.String(); strings.Count(out, "\n") != 1 {
+		t.Errorf("expected a single console line, got %q", out)
+	}
+
+	ctx = jogger.Extract(context.Background(), jogger.MapCarrier{"X-Session-ID": "\r\n"})
+	if _, ok := ctx.Value(sessionKey).(string); ok {
+		t.Error("expected a value of only control characters to be ignored")
+	}
+}
+
+func TestExtractWithoutValues(t *testing.T) {
+	ctx := jogger.Extract(context.Background(), jogger.HeaderCarrier(http.Header{}))
+	if jogger.RequestID(ctx) != "" {
+		t.Error("expected no request ID")
+	}
+}
+
+func TestRenameKeys(t *testing.T) {
+	attrs := jogger.MapCarrier{}
+	names := map[string]string{jogger.RequestIDHeader: "request_id"}
+
+	ctx := context.WithValue(jogger.WithRequestID(context.Background(), "req-1"), sessionKey, "sess-1")
+	jogger.Inject(ctx, jogger.RenameKeys(attrs, names))
+
+	if attrs["request_id"] != "req-1" || attrs["X-Session-ID"] != "sess-1" || len(attrs) != 2 {
+		t.Errorf("expected the request ID renamed and other keys unchanged, got %v", attrs)
+	}
+	if got := jogger.RequestID(jogger.Extract(context.Background(), jogger.RenameKeys(attrs, names))); got != "req-1" {
+		t.Errorf("expected the renamed request ID to be extracted, got %q", got)
+	}
+}
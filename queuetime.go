@@ -0,0 +1,92 @@
+package jogger
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// QueueTimeFormat is the format of the header of QueueTimeHeader.
+type QueueTimeFormat int
+
+const (
+	// QueueTimeAuto guesses the unit of an epoch timestamp from its
+	// magnitude: seconds, milliseconds, microseconds or nanoseconds.
+	QueueTimeAuto QueueTimeFormat = iota
+	// QueueTimeNginx is seconds with a fractional part, as nginx writes
+	// "t=${msec}".
+	QueueTimeNginx
+	// QueueTimeMillis is epoch milliseconds.
+	QueueTimeMillis
+	// QueueTimeMicros is epoch microseconds.
+	QueueTimeMicros
+)
+
+// maxQueueWait bounds plausible queue waits; longer ones come from a header
+// in another unit or format and are ignored.
+const maxQueueWait = 24 * time.Hour
+
+// QueueTimeHeader reads the time a proxy received the request from the
+// header name, such as X-Request-Start, and adds to the access log how long
+// the request waited before the middleware saw it, queue_wait_ms, next to
+// how long it took from there, handler_ms. Values may carry a "t=" prefix.
+// Values that do not parse, or lie more than a day in the past, are
+// ignored; values in the future, from a proxy whose clock is ahead, count
+// as no wait.
+func QueueTimeHeader(name string, format QueueTimeFormat) MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.queueHeader = name
+		c.queueFormat = format
+	}
+}
+
+// parseQueueTime parses a header value of QueueTimeHeader.
+func parseQueueTime(v string, format QueueTimeFormat) (time.Time, bool) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "t=")
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}, false
+	}
+	var unit float64
+	switch format {
+	case QueueTimeNginx:
+		unit = 1e9
+	case QueueTimeMillis:
+		unit = 1e6
+	case QueueTimeMicros:
+		unit = 1e3
+	default:
+		switch {
+		case n < 1e11:
+			unit = 1e9
+		case n < 1e14:
+			unit = 1e6
+		case n < 1e17:
+			unit = 1e3
+		default:
+			unit = 1
+		}
+	}
+	return time.Unix(0, int64(n*unit)), true
+}
+
+// queueTimeFields returns queue_wait_ms and handler_ms for a request the
+// middleware saw at start and that took elapsed from there.
+func (c *middlewareConfig) queueTimeFields(r *http.Request, start time.Time, elapsed time.Duration) []zap.Field {
+	fields := []zap.Field{zap.Float64("handler_ms", float64(elapsed)/float64(time.Millisecond))}
+	received, ok := parseQueueTime(r.Header.Get(c.queueHeader), c.queueFormat)
+	if !ok {
+		return fields
+	}
+	wait := start.Sub(received)
+	if wait > maxQueueWait {
+		return fields
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return append(fields, zap.Float64("queue_wait_ms", float64(wait)/float64(time.Millisecond)))
+}
@@ -0,0 +1,64 @@
+package jogger_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+)
+
+func TestMiddlewareQueueTime(t *testing.T) {
+	queued := time.Now().Add(-250 * time.Millisecond)
+	cases := []struct {
+		name   string
+		format jogger.QueueTimeFormat
+		header string
+		wait   bool
+	}{
+		{"nginx", jogger.QueueTimeNginx, "t=" + strconv.FormatFloat(float64(queued.UnixNano())/1e9, 'f', 3, 64), true},
+		{"millis", jogger.QueueTimeMillis, strconv.FormatInt(queued.UnixNano()/1e6, 10), true},
+		{"micros", jogger.QueueTimeMicros, "t=" + strconv.FormatInt(queued.UnixNano()/1e3, 10), true},
+		{"auto", jogger.QueueTimeAuto, strconv.FormatInt(queued.UnixNano()/1e3, 10), true},
+		{"garbage", jogger.QueueTimeAuto, "t=soon", false},
+		{"wrong unit", jogger.QueueTimeMicros, strconv.FormatInt(queued.UnixNano()/1e6, 10), false},
+		{"missing", jogger.QueueTimeAuto, "", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+			h := jogger.Middleware(jogger.QueueTimeHeader("X-Request-Start", tc.format))(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.header != "" {
+				r.Header.Set("X-Request-Start", tc.header)
+			}
+			serve(t, h, r)
+
+			e := decodeEntries(t, buf)[0]
+			if _, ok := e["handler_ms"].(float64); !ok {
+				t.Errorf("expected handler_ms, got %v", e)
+			}
+			wait, ok := e["queue_wait_ms"].(float64)
+			if ok != tc.wait {
+				t.Fatalf("expected queue_wait_ms %v, got %v", tc.wait, e)
+			}
+			if ok && (wait < 240 || wait > 5000) {
+				t.Errorf("expected about 250ms of queue wait, got %v", wait)
+			}
+		})
+	}
+}
+
+func TestMiddlewareQueueTimeClockAhead(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	h := jogger.Middleware(jogger.QueueTimeHeader("X-Request-Start", jogger.QueueTimeMillis))(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Request-Start", strconv.FormatInt(time.Now().Add(time.Minute).UnixNano()/1e6, 10))
+	serve(t, h, r)
+
+	if e := decodeEntries(t, buf)[0]; e["queue_wait_ms"] != float64(0) {
+		t.Errorf("expected a proxy clock ahead to count as no wait, got %v", e["queue_wait_ms"])
+	}
+}
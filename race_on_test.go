@@ -0,0 +1,8 @@
+//go:build race
+// +build race
+
+package jogger_test
+
+// raceEnabled reports whether the race detector, which adds allocations
+// of its own, is on.
+const raceEnabled = true
@@ -0,0 +1,76 @@
+package jogger
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// RateLimitInfo is the rate limiting decision made for a request.
+type RateLimitInfo struct {
+	// Client identifies who the limit applies to, such as an API key ID or
+	// tenant.
+	Client string
+	// Limit is the number of requests allowed in the window, Remaining the
+	// number left and Reset when the window starts over.
+	Limit     int
+	Remaining int
+	Reset     time.Time
+	// Throttled reports whether the request was rejected.
+	Throttled bool
+}
+
+// A RateLimitInfoProvider reports the rate limiting decision for a
+// request, typically implemented by the application's limiter. ok is false
+// for requests it knows nothing about.
+type RateLimitInfoProvider interface {
+	RateLimitInfo(r *http.Request) (info RateLimitInfo, ok bool)
+}
+
+// RateLimitInfoFunc adapts a function to RateLimitInfoProvider.
+type RateLimitInfoFunc func(r *http.Request) (RateLimitInfo, bool)
+
+// RateLimitInfo calls f(r).
+func (f RateLimitInfoFunc) RateLimitInfo(r *http.Request) (RateLimitInfo, bool) {
+	return f(r)
+}
+
+// RateLimit adds the decision p reports for each request to the access log
+// as ratelimit.client, ratelimit.limit, ratelimit.remaining, ratelimit.reset
+// and ratelimit.throttled. Throttled requests log at Warn, as 429 responses
+// do anyway. p is asked after the handler; a panic in it is reported as an
+// internal error and leaves the access log without the fields.
+func RateLimit(p RateLimitInfoProvider) MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.rateLimit = p
+	}
+}
+
+// rateLimitFields asks p about r, recovering from a panic in it.
+func rateLimitFields(p RateLimitInfoProvider, r *http.Request) (fields []zap.Field, throttled bool) {
+	defer func() {
+		if v := recover(); v != nil {
+			reportInternalError(fmt.Errorf("jogger: rate limit info provider panicked: %v", v))
+			fields, throttled = nil, false
+		}
+	}()
+	info, ok := p.RateLimitInfo(r)
+	if !ok {
+		return nil, false
+	}
+	fields = make([]zap.Field, 0, 5)
+	if info.Client != "" {
+		fields = append(fields, zap.String("ratelimit.client", info.Client))
+	}
+	fields = append(fields,
+		zap.Int("ratelimit.limit", info.Limit),
+		zap.Int("ratelimit.remaining", info.Remaining),
+	)
+	if !info.Reset.IsZero() {
+		fields = append(fields, zap.Time("ratelimit.reset", info.Reset))
+	}
+	fields = append(fields, zap.Bool("ratelimit.throttled", info.Throttled))
+	return fields, info.Throttled
+}
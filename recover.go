@@ -0,0 +1,178 @@
+package jogger
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxPanicValueLength bounds the rendering of panic values that are neither
+// errors nor fmt.Stringers.
+const maxPanicValueLength = 1024
+
+// routeFuncKey holds the Middleware's RouteFunc so handlers further down
+// the chain, like Recoverer, report the same route as the access log.
+var routeFuncKey = &contextKey{"routeFunc"}
+
+// Recoverer returns a handler that recovers panics in next. A recovered
+// panic is logged at Error with its PanicField, the request ID and the
+// route, the request span is finished with an error, and a 500 is written
+// if the handler had not started its response. http.ErrAbortHandler only
+// finishes the span with that error, and is re-panicked so net/http can
+// abort the connection as usual.
+//
+// Recoverer starts the "http.request" span that handler logs are
+// correlated with. Place it inside Middleware so the request ID and the
+// panic's status code end up in the access log:
+//
+//	jogger.Middleware()(jogger.Recoverer(mux))
+func Recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span, ctx := StartSpan(r.Context(), "http.request")
+		r = r.WithContext(ctx)
+
+		rec, ok := recorderOf(w)
+		if !ok {
+			rec = &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+			w = rec.writer()
+		}
+
+		defer func() {
+			v := recover()
+			if v == nil {
+				span.Finish(nil)
+				return
+			}
+			if v == http.ErrAbortHandler {
+				err := http.ErrAbortHandler
+				span.Finish(&err)
+				panic(v)
+			}
+
+			err := fmt.Errorf("panic: %v", v)
+			FromContext(ctx).Error("panic recovered",
+				panicField(v, 0),
+				zap.String("route", routeOf(r)),
+			)
+			span.Finish(&err)
+
+			if !rec.wroteHeader && !rec.hijacked {
+				http.Error(rec, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func routeOf(r *http.Request) string {
+	if fn, ok := r.Context().Value(routeFuncKey).(func(*http.Request) string); ok {
+		return fn(r)
+	}
+	return r.URL.Path
+}
+
+// PanicField renders a recovered panic value. Call it in the deferred
+// function that recovered it:
+//
+//	defer func() {
+//		if v := recover(); v != nil {
+//			jogger.Error(ctx, "panic recovered", jogger.PanicField(v))
+//		}
+//	}()
+//
+// It adds four fields:
+//   - panic holds the message of an error, the String of a fmt.Stringer,
+//     or otherwise the value formatted with %+v and cut to 1 KiB.
+//   - panic_type is the Go type of the value.
+//   - panic_causes lists the messages of the errors an error wraps, if any.
+//   - stack is the stack of the panicking goroutine without runtime frames.
+//
+// Recoverer logs panics with it.
+func PanicField(v interface{}) zap.Field {
+	return panicField(v, 1)
+}
+
+// panicField is PanicField called skip frames below the deferred function.
+func panicField(v interface{}, skip int) zap.Field {
+	return zap.Inline(panicValue{v: v, stack: panicStack(skip + 1)})
+}
+
+// panicValue is the rendering of PanicField, with the stack captured when
+// the field was made.
+type panicValue struct {
+	v     interface{}
+	stack string
+}
+
+func (p panicValue) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	switch v := p.v.(type) {
+	case error:
+		enc.AddString("panic", v.Error())
+		if causes := errorCauses(v); len(causes) > 0 {
+			if err := enc.AddArray("panic_causes", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+				for _, c := range causes {
+					arr.AppendString(c)
+				}
+				return nil
+			})); err != nil {
+				return err
+			}
+		}
+	case fmt.Stringer:
+		enc.AddString("panic", v.String())
+	default:
+		s := fmt.Sprintf("%+v", v)
+		if len(s) > maxPanicValueLength {
+			s = truncateString(s, maxPanicValueLength)
+		}
+		enc.AddString("panic", s)
+	}
+	enc.AddString("panic_type", fmt.Sprintf("%T", p.v))
+	enc.AddString("stack", p.stack)
+	return nil
+}
+
+// maxPanicCauses bounds the wrapped errors listed in panic_causes.
+const maxPanicCauses = 16
+
+// errorCauses returns the messages of the errors err wraps, outermost
+// first.
+func errorCauses(err error) []string {
+	var causes []string
+	for cause := unwrapError(err); cause != nil && len(causes) < maxPanicCauses; cause = unwrapError(cause) {
+		causes = append(causes, cause.Error())
+	}
+	return causes
+}
+
+// panicStack formats the stack of the panicking goroutine from inside a
+// deferred recover, skipping skip more frames of the deferred function's
+// callees and leaving out runtime frames such as runtime.gopanic.
+func panicStack(skip int) string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(3+skip, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var b strings.Builder
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, "runtime.") {
+			b.WriteString(f.Function)
+			b.WriteString("\n\t")
+			b.WriteString(f.File)
+			b.WriteByte(':')
+			b.WriteString(strconv.Itoa(f.Line))
+			b.WriteByte('\n')
+		}
+		if !more {
+			break
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,167 @@
+package jogger_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+)
+
+func TestRecovererLogsPanic(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	h := jogger.Middleware(jogger.RouteFunc(func(*http.Request) string { return "/orders/{id}" }))(
+		jogger.Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			jogger.Info(r.Context(), "loading order")
+			var m map[string]int
+			m["boom"]++
+		})),
+	)
+	r := httptest.NewRequest("GET", "/orders/7", nil)
+	r.Header.Set("X-Request-ID", "req-panic")
+	rec := serve(t, h, r)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", rec.Code)
+	}
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 4 {
+		t.Fatalf("expected handler log, panic, span and access entries, got %d", len(entries))
+	}
+	handlerLog, panicLog, spanLog, access := entries[0], entries[1], entries[2], entries[3]
+
+	if panicLog["level"] != "error" || panicLog["msg"] != "panic recovered" {
+		t.Errorf("unexpected panic entry %v", panicLog)
+	}
+	if panicLog["requestID"] != "req-panic" || panicLog["route"] != "/orders/{id}" {
+		t.Errorf("missing correlation on panic entry: %v", panicLog)
+	}
+	if p, _ := panicLog["panic"].(string); !strings.Contains(p, "nil map") {
+		t.Errorf("unexpected panic value %q", p)
+	}
+	stack, _ := panicLog["stack"].(string)
+	if !strings.Contains(stack, "TestRecovererLogsPanic") {
+		t.Errorf("stack should include the panicking handler:\n%s", stack)
+	}
+	if strings.Contains(stack, "runtime.") {
+		t.Errorf("stack should not include runtime frames:\n%s", stack)
+	}
+
+	if spanLog["msg"] != "span finished with error" || spanLog["span"] != "http.request" {
+		t.Errorf("unexpected span entry %v", spanLog)
+	}
+	if e, _ := spanLog["error"].(string); !strings.HasPrefix(e, "panic: ") {
+		t.Errorf("expected synthesized panic error, got %q", e)
+	}
+	if handlerLog["span"] != spanLog["spanID"] {
+		t.Errorf("handler log should be correlated with the request span: %v", handlerLog)
+	}
+	if access["status"] != float64(500) {
+		t.Errorf("access log should record the 500, got %v", access["status"])
+	}
+}
+
+func TestRecovererKeepsStartedResponse(t *testing.T) {
+	configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	h := jogger.Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic("late")
+	}))
+	rec := serve(t, h, httptest.NewRequest("GET", "/", nil))
+
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("a started response must not be overwritten, got %d", rec.Code)
+	}
+}
+
+func TestRecovererRepanicsAbortHandler(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	h := jogger.Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("expected http.ErrAbortHandler to be re-panicked, got %v", v)
+		}
+		entries := decodeEntries(t, buf)
+		if len(entries) != 1 {
+			t.Fatalf("expected only the span entry, got %v", entries)
+		}
+		if e := entries[0]; e["msg"] != "span finished with error" || e["span"] != "http.request" || e["error"] != http.ErrAbortHandler.Error() {
+			t.Errorf("expected the span finished with the abort error, got %v", e)
+		}
+	}()
+	serve(t, h, httptest.NewRequest("GET", "/", nil))
+}
+
+func TestRecovererResponseCarriesRequestID(t *testing.T) {
+	configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	h := jogger.Middleware()(jogger.Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})))
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Request-ID", "req-500")
+	rec := serve(t, h, r)
+
+	if rec.Code != http.StatusInternalServerError || rec.Header().Get("X-Request-ID") != "req-500" {
+		t.Errorf("expected 500 with echoed request ID, got %d %v", rec.Code, rec.Header())
+	}
+}
+
+type orderID int
+
+func (id orderID) String() string { return fmt.Sprintf("order #%d", int(id)) }
+
+func TestPanicField(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	values := []interface{}{
+		wrappedError{errors.New("card declined")},
+		orderID(7),
+		struct{ Name string }{"x"},
+		strings.Repeat("x", 2000),
+	}
+	for _, v := range values {
+		func() {
+			defer func() {
+				jogger.Error(context.Background(), "panic recovered", jogger.PanicField(recover()))
+			}()
+			panic(v)
+		}()
+	}
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != len(values) {
+		t.Fatalf("expected %d entries, got %d", len(values), len(entries))
+	}
+	want := []struct{ panic, typ string }{
+		{"lookup: card declined", "jogger_test.wrappedError"},
+		{"order #7", "jogger_test.orderID"},
+		{"{Name:x}", "struct { Name string }"},
+	}
+	for i, w := range want {
+		if entries[i]["panic"] != w.panic || entries[i]["panic_type"] != w.typ {
+			t.Errorf("entry %d: expected panic %q of type %q, got %v", i, w.panic, w.typ, entries[i])
+		}
+	}
+	if causes, _ := entries[0]["panic_causes"].([]interface{}); len(causes) != 1 || causes[0] != "card declined" {
+		t.Errorf("expected the wrapped error in panic_causes, got %v", entries[0]["panic_causes"])
+	}
+	if p, _ := entries[3]["panic"].(string); len(p) > 1100 || !strings.Contains(p, "truncated") {
+		t.Errorf("expected a long value cut, got %d bytes", len(p))
+	}
+	stack, _ := entries[0]["stack"].(string)
+	if !strings.Contains(stack, "TestPanicField") || strings.Contains(stack, "runtime.") {
+		t.Errorf("unexpected stack:\n%s", stack)
+	}
+}
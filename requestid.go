@@ -0,0 +1,151 @@
+package jogger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const defaultMaxRequestIDLength = 128
+
+// Request ID formats accepted by SetRequestIDFormat.
+const (
+	RequestIDFormatAny  = ""
+	RequestIDFormatUUID = "uuid"
+	RequestIDFormatULID = "ulid"
+)
+
+// clientRequestIDKey names the client's request ID among the correlation
+// keys. The value is kept in the scope, see ClientRequestID.
+const clientRequestIDKey ContextKey = "clientRequestID"
+
+var (
+	maxRequestIDLength = int64(defaultMaxRequestIDLength)
+	requestIDFormat    atomic.Value
+)
+
+func init() {
+	requestIDFormat.Store(RequestIDFormatAny)
+}
+
+// SetMaxRequestIDLength sets the length in bytes SanitizeID truncates IDs
+// to. Non-positive values restore the default of 128.
+func SetMaxRequestIDLength(n int) {
+	if n <= 0 {
+		n = defaultMaxRequestIDLength
+	}
+	atomic.StoreInt64(&maxRequestIDLength, int64(n))
+}
+
+// SetRequestIDFormat makes WithRequestID, and so Extract and Middleware,
+// replace request IDs that are not in format with a generated UUID. The
+// sanitized original is kept in the client_request_id field. The default,
+// RequestIDFormatAny, accepts any ID.
+func SetRequestIDFormat(format string) error {
+	switch format {
+	case RequestIDFormatAny, RequestIDFormatUUID, RequestIDFormatULID:
+	default:
+		return fmt.Errorf("jogger: unknown request ID format %q", format)
+	}
+	requestIDFormat.Store(format)
+	return nil
+}
+
+// SanitizeID returns id without control characters, newlines included, and
+// invalid UTF-8, trimmed of surrounding spaces and truncated to the length
+// set with SetMaxRequestIDLength. Use it for IDs received from untrusted
+// sources before they are logged.
+func SanitizeID(id string) string {
+	max := int(atomic.LoadInt64(&maxRequestIDLength))
+	if len(id) <= max && isCleanID(id) {
+		return id
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(id); {
+		r, size := utf8.DecodeRuneInString(id[i:])
+		if (r != utf8.RuneError || size > 1) && !unicode.IsControl(r) {
+			b.WriteString(id[i : i+size])
+		}
+		i += size
+	}
+	s := strings.TrimSpace(b.String())
+	if len(s) > max {
+		cut := max
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		s = s[:cut]
+	}
+	return s
+}
+
+// isCleanID reports whether id is printable ASCII without leading or
+// trailing spaces, the common case SanitizeID returns unchanged.
+func isCleanID(id string) bool {
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x20 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return len(id) == 0 || (id[0] != ' ' && id[len(id)-1] != ' ')
+}
+
+// normalizeRequestID returns the request ID to store for id, and what the
+// client sent when id was replaced.
+func normalizeRequestID(id string) (rid, client string) {
+	if id == "" {
+		return "", ""
+	}
+	rid = SanitizeID(id)
+	if rid == "" {
+		return uuid.New().String(), ""
+	}
+	if !matchesRequestIDFormat(rid, requestIDFormat.Load().(string)) {
+		return uuid.New().String(), rid
+	}
+	return rid, ""
+}
+
+func matchesRequestIDFormat(id, format string) bool {
+	switch format {
+	case RequestIDFormatUUID:
+		_, err := uuid.Parse(id)
+		return err == nil && len(id) == 36
+	case RequestIDFormatULID:
+		return isULID(id)
+	}
+	return true
+}
+
+// isULID reports whether id is 26 characters of Crockford base32 whose
+// first character keeps the timestamp within 48 bits.
+func isULID(id string) bool {
+	if len(id) != 26 || id[0] > '7' {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		switch {
+		case c >= '0' && c <= '9':
+		case c >= 'A' && c <= 'Z' && c != 'I' && c != 'L' && c != 'O' && c != 'U':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ClientRequestID returns the request ID the client sent when it was
+// replaced for not matching the format set with SetRequestIDFormat, or "".
+func ClientRequestID(ctx context.Context) string {
+	return scopeOf(orBackground(ctx)).client
+}
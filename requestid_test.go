@@ -0,0 +1,110 @@
+package jogger_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+)
+
+func TestSanitizeID(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"req-1", "req-1"},
+		{"", ""},
+		{"req-1\nFAKE level=error msg=forged", "req-1FAKE level=error msg=forged"},
+		{"  padded\r\n", "padded"},
+		{"bad\xff\xfeutf8", "badutf8"},
+		{"\x1b[31mred\x1b[0m", "[31mred[0m"},
+		{"café-ü", "café-ü"},
+		{strings.Repeat("a", 10000), strings.Repeat("a", 128)},
+		{strings.Repeat("é", 100), strings.Repeat("é", 64)},
+	} {
+		if got := jogger.SanitizeID(tc.in); got != tc.want {
+			t.Errorf("SanitizeID(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestSetMaxRequestIDLength(t *testing.T) {
+	jogger.SetMaxRequestIDLength(8)
+	defer jogger.SetMaxRequestIDLength(0)
+
+	if got := jogger.RequestID(jogger.WithRequestID(context.Background(), "0123456789")); got != "01234567" {
+		t.Errorf("expected the request ID truncated to 8 bytes, got %q", got)
+	}
+}
+
+func TestWithRequestIDReplacesUnusableIDs(t *testing.T) {
+	if got := jogger.RequestID(jogger.WithRequestID(context.Background(), "\n\t")); got == "" || got == "\n\t" {
+		t.Errorf("expected a generated ID for an ID of control characters, got %q", got)
+	}
+	if got := jogger.RequestID(jogger.WithRequestID(context.Background(), "")); got != "" {
+		t.Errorf("expected no request ID to stay empty, got %q", got)
+	}
+}
+
+func TestSetRequestIDFormat(t *testing.T) {
+	if err := jogger.SetRequestIDFormat("snowflake"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+	defer jogger.SetRequestIDFormat(jogger.RequestIDFormatAny)
+
+	for _, tc := range []struct {
+		format, id string
+		valid      bool
+	}{
+		{jogger.RequestIDFormatUUID, "0f8fad5b-d9cb-469f-a165-70867728950e", true},
+		{jogger.RequestIDFormatUUID, "urn:uuid:0f8fad5b-d9cb-469f-a165-70867728950e", false},
+		{jogger.RequestIDFormatUUID, "req-1", false},
+		{jogger.RequestIDFormatULID, "01ARZ3NDEKTSV4RRFFQ69G5FAV", true},
+		{jogger.RequestIDFormatULID, "01arz3ndektsv4rrffq69g5fav", true},
+		{jogger.RequestIDFormatULID, "81ARZ3NDEKTSV4RRFFQ69G5FAV", false},
+		{jogger.RequestIDFormatULID, "01ARZ3NDEKTSV4RRFFQ69G5FAU", false},
+	} {
+		if err := jogger.SetRequestIDFormat(tc.format); err != nil {
+			t.Fatal(err)
+		}
+		ctx := jogger.WithRequestID(context.Background(), tc.id)
+		kept := jogger.RequestID(ctx) == tc.id
+		if kept != tc.valid {
+			t.Errorf("%s %q: expected kept=%v, got request ID %q", tc.format, tc.id, tc.valid, jogger.RequestID(ctx))
+		}
+		if !tc.valid && jogger.ClientRequestID(ctx) != tc.id {
+			t.Errorf("%s %q: expected the client's ID to be kept, got %q", tc.format, tc.id, jogger.ClientRequestID(ctx))
+		}
+	}
+}
+
+func TestMiddlewareSanitizesRequestIDHeader(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	if err := jogger.SetRequestIDFormat(jogger.RequestIDFormatUUID); err != nil {
+		t.Fatal(err)
+	}
+	defer jogger.SetRequestIDFormat(jogger.RequestIDFormatAny)
+
+	var fromHandler string
+	h := jogger.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fromHandler = jogger.RequestID(r.Context())
+	}))
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header["X-Request-Id"] = []string{"evil\n{\"level\":\"error\"}" + strings.Repeat("x", 500)}
+	rec := serve(t, h, r)
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 1 {
+		t.Fatalf("expected a single access entry, got %v", entries)
+	}
+	e := entries[0]
+	client, _ := e["client_request_id"].(string)
+	if strings.ContainsAny(client, "\n\r") || len(client) != 128 || !strings.HasPrefix(client, "evil{") {
+		t.Errorf("expected a sanitized, truncated client_request_id, got %q", client)
+	}
+	if e["requestID"] != fromHandler || rec.Header().Get(jogger.RequestIDHeader) != fromHandler || len(fromHandler) != 36 {
+		t.Errorf("expected a generated UUID everywhere, got %v, handler %q, header %q", e["requestID"], fromHandler, rec.Header().Get(jogger.RequestIDHeader))
+	}
+}
@@ -0,0 +1,36 @@
+package jogger
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// WithRequireRequestID flags entries logged with Info, Warn or Error on a
+// context without a request ID, to hunt down code paths that are not
+// correlated, such as an endpoint missing the middleware. Such entries get
+// correlation_missing=true and are counted in Stats().CorrelationMissing;
+// in development mode they also DPanic. Entries logged through FromContext,
+// as the middlewares and integrations do once they resolved the request
+// ID, are not checked.
+func WithRequireRequestID() Option {
+	return func(c *config) error {
+		c.requireRequestID = true
+		return nil
+	}
+}
+
+// requireRequestID adds correlation_missing to fields when the
+// configuration of ctx requires a request ID and ctx has none.
+func requireRequestID(ctx context.Context, lvl zapcore.Level, fields []zap.Field) []zap.Field {
+	s := scopeOf(ctx)
+	o := s.output()
+	if !o.cfg.requireRequestID || s.RequestID != "" || !o.base.Core().Enabled(lvl) {
+		return fields
+	}
+	atomic.AddUint64(&stats.correlationMissing, 1)
+	o.misuse("jogger: logging without a request ID")
+	return append(fields[:len(fields):len(fields)], zap.Bool("correlation_missing", true))
+}
@@ -0,0 +1,126 @@
+package jogger_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRequireRequestID(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithRequireRequestID())
+	before := jogger.Stats().CorrelationMissing
+
+	jogger.Info(context.Background(), "uncorrelated")
+	jogger.Debug(context.Background(), "below the level")
+	jogger.Warn(jogger.WithRequestID(context.Background(), "req-required"), "correlated")
+
+	entries := decodeEntries(t, buf)
+	if entries[0]["correlation_missing"] != true {
+		t.Errorf("expected correlation_missing on the uncorrelated entry, got %v", entries[0])
+	}
+	if _, ok := entries[1]["correlation_missing"]; ok {
+		t.Errorf("expected no flag with a request ID, got %v", entries[1])
+	}
+	if n := jogger.Stats().CorrelationMissing - before; n != 1 {
+		t.Errorf("expected one entry counted, got %d", n)
+	}
+}
+
+func TestRequireRequestIDOffByDefault(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	jogger.Error(context.Background(), "uncorrelated")
+	if _, ok := decodeEntries(t, buf)[0]["correlation_missing"]; ok {
+		t.Error("expected no flag without WithRequireRequestID")
+	}
+}
+
+func TestRequireRequestIDMiddleware(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithRequireRequestID())
+	h := jogger.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jogger.Info(r.Context(), "handled")
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	for _, e := range decodeEntries(t, buf) {
+		if _, ok := e["correlation_missing"]; ok {
+			t.Errorf("expected requests behind the middleware to be correlated, got %v", e)
+		}
+	}
+}
+
+func TestRequireRequestIDDevelopment(t *testing.T) {
+	configureBuffer(t, jogger.WithDevelopment(), jogger.WithRequireRequestID(), jogger.WithLevel(zapcore.InfoLevel))
+	expectPanic(t, "missing request ID", func() {
+		jogger.Info(context.Background(), "uncorrelated")
+	})
+}
+
+func TestRequireRequestIDInstance(t *testing.T) {
+	var buf bytes.Buffer
+	j, err := jogger.New(jogger.WithOutput(&buf), jogger.WithFormat(jogger.FormatJSON), jogger.WithRequireRequestID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := jogger.Stats().CorrelationMissing
+
+	j.Info(context.Background(), "uncorrelated")
+	j.Warn(context.Background(), "uncorrelated")
+	j.Error(jogger.WithRequestID(context.Background(), "req-required"), "correlated")
+
+	entries := decodeEntries(t, &buf)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	for _, e := range entries[:2] {
+		if e["correlation_missing"] != true {
+			t.Errorf("expected correlation_missing on the uncorrelated entry, got %v", e)
+		}
+	}
+	if _, ok := entries[2]["correlation_missing"]; ok {
+		t.Errorf("expected no flag with a request ID, got %v", entries[2])
+	}
+	if n := jogger.Stats().CorrelationMissing - before; n != 2 {
+		t.Errorf("expected two entries counted, got %d", n)
+	}
+}
+
+func TestRequireRequestIDIf(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithRequireRequestID(), jogger.WithLevel(zapcore.DebugLevel))
+	before := jogger.Stats().CorrelationMissing
+
+	jogger.InfoIf(context.Background(), true, "uncorrelated")
+	jogger.DebugIf(context.Background(), true, "debug is exempt")
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 2 || entries[0]["correlation_missing"] != true {
+		t.Fatalf("expected correlation_missing on the InfoIf entry, got %v", entries)
+	}
+	if _, ok := entries[1]["correlation_missing"]; ok {
+		t.Errorf("expected no flag at Debug, as with Debug, got %v", entries[1])
+	}
+	if n := jogger.Stats().CorrelationMissing - before; n != 1 {
+		t.Errorf("expected one entry counted, got %d", n)
+	}
+}
+
+func TestInstanceAndIfCheckFields(t *testing.T) {
+	j, err := jogger.New(jogger.WithOutput(ioutil.Discard), jogger.WithDevelopment())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectPanic(t, "reserved field on an instance", func() {
+		j.Info(context.Background(), "collides", zap.String("level", "x"))
+	})
+
+	configureBuffer(t, jogger.WithDevelopment())
+	expectPanic(t, "reserved field with InfoIf", func() {
+		jogger.InfoIf(context.Background(), true, "collides", zap.String("level", "x"))
+	})
+}
@@ -0,0 +1,347 @@
+package jogger
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+)
+
+// recordingWriter is the part of every writer handed to handlers: the
+// ResponseWriter itself, Unwrap for http.ResponseController, and access to
+// the recorder for handlers further down the chain, like Recoverer.
+type recordingWriter interface {
+	http.ResponseWriter
+	Unwrap() http.ResponseWriter
+	recorder() *responseRecorder
+}
+
+// Optional interfaces of the wrapped writer, as bits of writer's switch.
+const (
+	flusher = 1 << iota
+	hijacker
+	readerFrom
+	pusher
+	closeNotifier
+)
+
+// writer returns rr composed with exactly the optional interfaces its
+// wrapped writer implements, so type assertions in handlers, such as a
+// WebSocket upgrade's http.Hijacker check, give the same answer as without
+// the middleware.
+func (rr *responseRecorder) writer() http.ResponseWriter {
+	var supported int
+	if _, ok := rr.ResponseWriter.(http.Flusher); ok {
+		supported |= flusher
+	}
+	if _, ok := rr.ResponseWriter.(http.Hijacker); ok {
+		supported |= hijacker
+	}
+	if _, ok := rr.ResponseWriter.(io.ReaderFrom); ok {
+		supported |= readerFrom
+	}
+	if _, ok := rr.ResponseWriter.(http.Pusher); ok {
+		supported |= pusher
+	}
+	if _, ok := rr.ResponseWriter.(http.CloseNotifier); ok {
+		supported |= closeNotifier
+	}
+
+	switch supported {
+	case 0:
+		return struct {
+			recordingWriter
+		}{rr}
+	case flusher:
+		return struct {
+			recordingWriter
+			http.Flusher
+		}{rr, rr}
+	case hijacker:
+		return struct {
+			recordingWriter
+			http.Hijacker
+		}{rr, rr}
+	case flusher | hijacker:
+		return struct {
+			recordingWriter
+			http.Flusher
+			http.Hijacker
+		}{rr, rr, rr}
+	case readerFrom:
+		return struct {
+			recordingWriter
+			io.ReaderFrom
+		}{rr, rr}
+	case flusher | readerFrom:
+		return struct {
+			recordingWriter
+			http.Flusher
+			io.ReaderFrom
+		}{rr, rr, rr}
+	case hijacker | readerFrom:
+		return struct {
+			recordingWriter
+			http.Hijacker
+			io.ReaderFrom
+		}{rr, rr, rr}
+	case flusher | hijacker | readerFrom:
+		return struct {
+			recordingWriter
+			http.Flusher
+			http.Hijacker
+			io.ReaderFrom
+		}{rr, rr, rr, rr}
+	case pusher:
+		return struct {
+			recordingWriter
+			http.Pusher
+		}{rr, rr}
+	case flusher | pusher:
+		return struct {
+			recordingWriter
+			http.Flusher
+			http.Pusher
+		}{rr, rr, rr}
+	case hijacker | pusher:
+		return struct {
+			recordingWriter
+			http.Hijacker
+			http.Pusher
+		}{rr, rr, rr}
+	case flusher | hijacker | pusher:
+		return struct {
+			recordingWriter
+			http.Flusher
+			http.Hijacker
+			http.Pusher
+		}{rr, rr, rr, rr}
+	case readerFrom | pusher:
+		return struct {
+			recordingWriter
+			io.ReaderFrom
+			http.Pusher
+		}{rr, rr, rr}
+	case flusher | readerFrom | pusher:
+		return struct {
+			recordingWriter
+			http.Flusher
+			io.ReaderFrom
+			http.Pusher
+		}{rr, rr, rr, rr}
+	case hijacker | readerFrom | pusher:
+		return struct {
+			recordingWriter
+			http.Hijacker
+			io.ReaderFrom
+			http.Pusher
+		}{rr, rr, rr, rr}
+	case flusher | hijacker | readerFrom | pusher:
+		return struct {
+			recordingWriter
+			http.Flusher
+			http.Hijacker
+			io.ReaderFrom
+			http.Pusher
+		}{rr, rr, rr, rr, rr}
+	case closeNotifier:
+		return struct {
+			recordingWriter
+			http.CloseNotifier
+		}{rr, rr}
+	case flusher | closeNotifier:
+		return struct {
+			recordingWriter
+			http.Flusher
+			http.CloseNotifier
+		}{rr, rr, rr}
+	case hijacker | closeNotifier:
+		return struct {
+			recordingWriter
+			http.Hijacker
+			http.CloseNotifier
+		}{rr, rr, rr}
+	case flusher | hijacker | closeNotifier:
+		return struct {
+			recordingWriter
+			http.Flusher
+			http.Hijacker
+			http.CloseNotifier
+		}{rr, rr, rr, rr}
+	case readerFrom | closeNotifier:
+		return struct {
+			recordingWriter
+			io.ReaderFrom
+			http.CloseNotifier
+		}{rr, rr, rr}
+	case flusher | readerFrom | closeNotifier:
+		return struct {
+			recordingWriter
+			http.Flusher
+			io.ReaderFrom
+			http.CloseNotifier
+		}{rr, rr, rr, rr}
+	case hijacker | readerFrom | closeNotifier:
+		return struct {
+			recordingWriter
+			http.Hijacker
+			io.ReaderFrom
+			http.CloseNotifier
+		}{rr, rr, rr, rr}
+	case flusher | hijacker | readerFrom | closeNotifier:
+		return struct {
+			recordingWriter
+			http.Flusher
+			http.Hijacker
+			io.ReaderFrom
+			http.CloseNotifier
+		}{rr, rr, rr, rr, rr}
+	case pusher | closeNotifier:
+		return struct {
+			recordingWriter
+			http.Pusher
+			http.CloseNotifier
+		}{rr, rr, rr}
+	case flusher | pusher | closeNotifier:
+		return struct {
+			recordingWriter
+			http.Flusher
+			http.Pusher
+			http.CloseNotifier
+		}{rr, rr, rr, rr}
+	case hijacker | pusher | closeNotifier:
+		return struct {
+			recordingWriter
+			http.Hijacker
+			http.Pusher
+			http.CloseNotifier
+		}{rr, rr, rr, rr}
+	case flusher | hijacker | pusher | closeNotifier:
+		return struct {
+			recordingWriter
+			http.Flusher
+			http.Hijacker
+			http.Pusher
+			http.CloseNotifier
+		}{rr, rr, rr, rr, rr}
+	case readerFrom | pusher | closeNotifier:
+		return struct {
+			recordingWriter
+			io.ReaderFrom
+			http.Pusher
+			http.CloseNotifier
+		}{rr, rr, rr, rr}
+	case flusher | readerFrom | pusher | closeNotifier:
+		return struct {
+			recordingWriter
+			http.Flusher
+			io.ReaderFrom
+			http.Pusher
+			http.CloseNotifier
+		}{rr, rr, rr, rr, rr}
+	case hijacker | readerFrom | pusher | closeNotifier:
+		return struct {
+			recordingWriter
+			http.Hijacker
+			io.ReaderFrom
+			http.Pusher
+			http.CloseNotifier
+		}{rr, rr, rr, rr, rr}
+	case flusher | hijacker | readerFrom | pusher | closeNotifier:
+		return struct {
+			recordingWriter
+			http.Flusher
+			http.Hijacker
+			io.ReaderFrom
+			http.Pusher
+			http.CloseNotifier
+		}{rr, rr, rr, rr, rr, rr}
+	}
+	return rr
+}
+
+// recorderOf returns the recorder behind w when w was handed out by
+// Middleware or Recoverer.
+func recorderOf(w http.ResponseWriter) (*responseRecorder, bool) {
+	if rw, ok := w.(recordingWriter); ok {
+		return rw.recorder(), true
+	}
+	return nil, false
+}
+
+func (rr *responseRecorder) recorder() *responseRecorder {
+	return rr
+}
+
+// Unwrap returns the wrapped writer.
+func (rr *responseRecorder) Unwrap() http.ResponseWriter {
+	return rr.ResponseWriter
+}
+
+// Flush flushes the wrapped writer, if it can, and counts a streamed chunk.
+func (rr *responseRecorder) Flush() {
+	f, ok := rr.ResponseWriter.(http.Flusher)
+	if !ok {
+		return
+	}
+	rr.wroteHeader = true
+	f.Flush()
+	rr.chunks++
+	if rr.chunks == 1 && rr.onStream != nil {
+		rr.onStream()
+	}
+}
+
+var errNotHijacker = errors.New("jogger: response writer does not implement http.Hijacker")
+
+// Hijack hands the connection over from the wrapped writer and marks the
+// response as hijacked.
+func (rr *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := rr.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errNotHijacker
+	}
+	conn, buf, err := h.Hijack()
+	if err == nil {
+		rr.hijacked = true
+	}
+	return conn, buf, err
+}
+
+// ReadFrom copies src through the wrapped writer's ReadFrom, which lets
+// net/http use sendfile, unless the body is being captured.
+func (rr *responseRecorder) ReadFrom(src io.Reader) (int64, error) {
+	rf, ok := rr.ResponseWriter.(io.ReaderFrom)
+	if !ok || rr.tee != nil {
+		return io.Copy(writerOnly{rr}, src)
+	}
+	rr.wroteHeader = true
+	n, err := rf.ReadFrom(src)
+	rr.bytes += n
+	return n, err
+}
+
+// writerOnly hides ReadFrom so io.Copy does not call it back.
+type writerOnly struct {
+	io.Writer
+}
+
+// Push initiates an HTTP/2 server push through the wrapped writer.
+func (rr *responseRecorder) Push(target string, opts *http.PushOptions) error {
+	p, ok := rr.ResponseWriter.(http.Pusher)
+	if !ok {
+		return http.ErrNotSupported
+	}
+	return p.Push(target, opts)
+}
+
+// CloseNotify returns the wrapped writer's close notification channel.
+// http.CloseNotifier is deprecated, but older handlers still assert it.
+func (rr *responseRecorder) CloseNotify() <-chan bool {
+	cn, ok := rr.ResponseWriter.(http.CloseNotifier)
+	if !ok {
+		return make(chan bool)
+	}
+	return cn.CloseNotify()
+}
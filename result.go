@@ -0,0 +1,53 @@
+package jogger
+
+import "go.uber.org/zap"
+
+// Common results of FinishWithResult. Any short lowercase word will do;
+// these keep the usual ones spelled the same across services.
+const (
+	ResultOK            = "ok"
+	ResultError         = "error"
+	ResultCacheHit      = "cache_hit"
+	ResultCacheMiss     = "cache_miss"
+	ResultNotFound      = "not_found"
+	ResultConflictRetry = "conflict_retry"
+	ResultTimeout       = "timeout"
+	ResultCanceled      = "canceled"
+	ResultSkipped       = "skipped"
+)
+
+// maxResultLength bounds the length of a result.
+const maxResultLength = 32
+
+// invalidResult replaces results that are not valid.
+const invalidResult = "invalid"
+
+// FinishWithResult is Finish with a result, such as ResultCacheHit, written
+// in the result field of the finish entry and counted per span name by
+// WithErrorBudget. A non-nil err logs at Error as with Finish, unless a
+// ResultLevel option for result says otherwise. Results are at most 32
+// characters of a-z, 0-9 and _; others are written as "invalid", and
+// reported in development mode.
+func (s *Span) FinishWithResult(result string, err error) {
+	if !validResult(result) {
+		if s.strict != nil {
+			s.strict.misuse("jogger: invalid span result", zap.String("span", s.name), zap.String("result", result))
+		}
+		result = invalidResult
+	}
+	s.finish(err, result)
+}
+
+// validResult reports whether r is a non-empty, short, lowercase word.
+func validResult(r string) bool {
+	if r == "" || len(r) > maxResultLength {
+		return false
+	}
+	for i := 0; i < len(r); i++ {
+		c := r[i]
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package jogger_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestFinishWithResult(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithLevel(zapcore.DebugLevel))
+	ctx := context.Background()
+
+	span, _ := jogger.StartSpan(ctx, "cache.get")
+	span.FinishWithResult(jogger.ResultCacheHit, nil)
+	span, _ = jogger.StartSpan(ctx, "cache.get")
+	span.FinishWithResult(jogger.ResultTimeout, errors.New("deadline exceeded"))
+	span, _ = jogger.StartSpan(ctx, "cache.get")
+	span.FinishWithResult("Cache Hit!", nil)
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	want := []struct{ level, result string }{{"info", "cache_hit"}, {"error", "timeout"}, {"info", "invalid"}}
+	for i, w := range want {
+		if entries[i]["level"] != w.level || entries[i]["result"] != w.result {
+			t.Errorf("entry %d: expected %s with result %q, got %v", i, w.level, w.result, entries[i])
+		}
+	}
+	if entries[1]["error"] != "deadline exceeded" {
+		t.Errorf("expected the error logged, got %v", entries[1])
+	}
+}
+
+func TestResultLevelOverridesError(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithLevel(zapcore.DebugLevel))
+	jogger.ConfigureSpan("users.get", jogger.ResultLevel(jogger.ResultNotFound, zapcore.InfoLevel))
+	defer jogger.ConfigureSpan("users.get")
+
+	ctx := context.Background()
+	span, _ := jogger.StartSpan(ctx, "users.get", jogger.ResultLevel(jogger.ResultCacheMiss, zapcore.DebugLevel))
+	span.FinishWithResult(jogger.ResultNotFound, errors.New("no such user"))
+	span, _ = jogger.StartSpan(ctx, "users.get", jogger.ResultLevel(jogger.ResultCacheMiss, zapcore.DebugLevel))
+	span.FinishWithResult(jogger.ResultCacheMiss, nil)
+	span, _ = jogger.StartSpan(ctx, "users.get")
+	span.FinishWithResult(jogger.ResultCacheMiss, nil)
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	for i, level := range []string{"info", "debug", "info"} {
+		if entries[i]["level"] != level {
+			t.Errorf("entry %d: expected level %s, got %v", i, level, entries[i])
+		}
+	}
+	if entries[0]["error"] != "no such user" {
+		t.Errorf("expected the error kept, got %v", entries[0])
+	}
+}
+
+func TestFinishWithResultInvalidInDevelopment(t *testing.T) {
+	configureBuffer(t, jogger.WithDevelopment())
+	expectPanic(t, "invalid result", func() {
+		span, _ := jogger.StartSpan(context.Background(), "upper")
+		span.FinishWithResult("OK", nil)
+	})
+}
+
+func TestErrorBudgetCountsResults(t *testing.T) {
+	configureBuffer(t, jogger.WithErrorBudget(jogger.ErrorBudgetConfig{Interval: -1}))
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		span, _ := jogger.StartSpan(ctx, "cache.get")
+		span.FinishWithResult(jogger.ResultCacheHit, nil)
+	}
+	span, _ := jogger.StartSpan(ctx, "cache.get")
+	span.FinishWithResult(jogger.ResultCacheMiss, nil)
+	for i := 0; i < 25; i++ {
+		span, _ := jogger.StartSpan(ctx, "cache.get")
+		span.FinishWithResult("r"+string(rune('a'+i)), nil)
+	}
+
+	sb := jogger.Stats().ErrorBudgets["cache.get"]
+	if sb.Total != 29 || sb.Results["cache_hit"] != 3 || sb.Results["cache_miss"] != 1 {
+		t.Errorf("unexpected budget %+v", sb)
+	}
+	if len(sb.Results) != 21 || sb.Results["<high-cardinality>"] != 7 {
+		t.Errorf("expected 20 results and the rest capped, got %v", sb.Results)
+	}
+}
@@ -0,0 +1,85 @@
+package jogger
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// RetryPolicy controls how Retry repeats a failing operation.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of calls, including the first.
+	// Values below 1 mean a single attempt.
+	MaxAttempts int
+	// Backoff returns the delay after the given failed attempt, counted from
+	// 1. A nil Backoff retries immediately.
+	Backoff func(attempt int) time.Duration
+	// Retryable reports whether err is worth another attempt. A nil
+	// Retryable retries every error.
+	Retryable func(err error) bool
+}
+
+// ExponentialBackoff returns a Backoff doubling from base and capped at max.
+func ExponentialBackoff(base, max time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		d := base
+		for i := 1; i < attempt; i++ {
+			d *= 2
+			if d >= max || d <= 0 {
+				return max
+			}
+		}
+		if d > max {
+			return max
+		}
+		return d
+	}
+}
+
+// Retry calls fn until it succeeds, returns an error the policy does not
+// retry, or the attempts run out, and returns the last error. The calls run
+// inside a span called name that records every attempt as an "attempt"
+// event and finishes with an attempts tag. If ctx is done while waiting
+// between attempts, Retry stops and returns ctx.Err().
+func Retry(ctx context.Context, name string, policy RetryPolicy, fn func(ctx context.Context) error) (err error) {
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	span, ctx := StartSpan(ctx, name)
+	attempt := 0
+	defer func() {
+		span.SetTag("attempts", attempt)
+		span.Finish(&err)
+	}()
+
+	for {
+		attempt++
+		err = fn(ctx)
+		if err == nil {
+			span.AddEvent("attempt", zap.Int("attempt", attempt))
+			return nil
+		}
+		if attempt >= maxAttempts || (policy.Retryable != nil && !policy.Retryable(err)) {
+			span.AddEvent("attempt", zap.Int("attempt", attempt), zap.Error(err))
+			return err
+		}
+
+		var backoff time.Duration
+		if policy.Backoff != nil {
+			backoff = policy.Backoff(attempt)
+		}
+		span.AddEvent("attempt", zap.Int("attempt", attempt), zap.Error(err), zap.Duration("backoff", backoff))
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			err = ctx.Err()
+			return err
+		case <-timer.C:
+		}
+	}
+}
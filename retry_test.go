@@ -0,0 +1,132 @@
+package jogger_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+)
+
+var errTransient = errors.New("transient")
+
+func TestRetrySucceedsAfterFailures(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	calls := 0
+	err := jogger.Retry(context.Background(), "fetch-quote", jogger.RetryPolicy{
+		MaxAttempts: 5,
+		Backoff:     func(int) time.Duration { return time.Millisecond },
+	}, func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errTransient
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+
+	e := decodeEntries(t, buf)[0]
+	if e["level"] != "info" || e["attempts"] != float64(3) {
+		t.Errorf("expected successful span with 3 attempts, got %v", e)
+	}
+	events, _ := e["events"].([]interface{})
+	if len(events) != 3 {
+		t.Fatalf("expected 3 attempt events, got %v", e["events"])
+	}
+	first := events[0].(map[string]interface{})
+	if first["attempt"] != float64(1) || first["error"] != "transient" || first["backoff"] == nil {
+		t.Errorf("unexpected first attempt event %v", first)
+	}
+	last := events[2].(map[string]interface{})
+	if _, ok := last["error"]; ok {
+		t.Errorf("expected successful last attempt, got %v", last)
+	}
+}
+
+func TestRetryStopsOnNonRetryableError(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	permanent := errors.New("permanent")
+	calls := 0
+	err := jogger.Retry(context.Background(), "charge", jogger.RetryPolicy{
+		MaxAttempts: 5,
+		Retryable:   func(err error) bool { return err == errTransient },
+	}, func(context.Context) error {
+		calls++
+		return permanent
+	})
+	if err != permanent || calls != 1 {
+		t.Fatalf("expected one call returning the permanent error, got %d calls and %v", calls, err)
+	}
+
+	e := decodeEntries(t, buf)[0]
+	if e["level"] != "error" || e["attempts"] != float64(1) {
+		t.Errorf("expected errored span with 1 attempt, got %v", e)
+	}
+}
+
+func TestRetryExhaustsAttempts(t *testing.T) {
+	configureBuffer(t)
+
+	calls := 0
+	err := jogger.Retry(context.Background(), "flaky", jogger.RetryPolicy{MaxAttempts: 3}, func(context.Context) error {
+		calls++
+		return errTransient
+	})
+	if err != errTransient || calls != 3 {
+		t.Errorf("expected 3 calls and the last error, got %d calls and %v", calls, err)
+	}
+}
+
+func TestRetryRespectsCancellation(t *testing.T) {
+	configureBuffer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := jogger.Retry(ctx, "slow-backoff", jogger.RetryPolicy{
+		MaxAttempts: 5,
+		Backoff:     func(int) time.Duration { return time.Hour },
+	}, func(context.Context) error {
+		calls++
+		cancel()
+		return errTransient
+	})
+	if err != context.Canceled || calls != 1 {
+		t.Errorf("expected cancellation after one call, got %d calls and %v", calls, err)
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	b := jogger.ExponentialBackoff(100*time.Millisecond, time.Second)
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, w := range want {
+		if got := b(i + 1); got != w {
+			t.Errorf("attempt %d: expected %v, got %v", i+1, w, got)
+		}
+	}
+}
+
+func TestSpanAddEventIsBounded(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	span, _ := jogger.StartSpan(context.Background(), "many-events")
+	for i := 0; i < 40; i++ {
+		span.AddEvent("tick")
+	}
+	span.Finish(nil)
+
+	e := decodeEntries(t, buf)[0]
+	if events, _ := e["events"].([]interface{}); len(events) != 32 {
+		t.Errorf("expected 32 retained events, got %d", len(events))
+	}
+	if e["droppedEvents"] != float64(8) {
+		t.Errorf("expected 8 dropped events, got %v", e["droppedEvents"])
+	}
+}
@@ -0,0 +1,82 @@
+package jogger
+
+import (
+	"errors"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// A RoutePredicate selects the entries WithRoute copies to its sink. It
+// sees the fields added with With, such as the correlation fields, followed
+// by those of the call. It runs for every entry, before encoding, and must
+// be fast: compare keys and values, don't encode.
+type RoutePredicate func(ent zapcore.Entry, fields []zapcore.Field) bool
+
+// WithRoute copies the entries matching pred to an extra sink, in addition
+// to the main sinks: WithRoute(FieldEquals("channel", "billing"), ...)
+// sends billing entries to a file of their own. Routes compose; an entry
+// matching several goes to each of their sinks. The sink level and fields
+// apply as for WithSink, and its name must be unique among the sinks.
+func WithRoute(pred RoutePredicate, sc SinkConfig) Option {
+	return func(c *config) error {
+		if pred == nil {
+			return errors.New("jogger: nil route predicate")
+		}
+		var probe config
+		if err := WithSink(sc)(&probe); err != nil {
+			return err
+		}
+		c.routes = append(c.routes, route{pred: pred, sink: probe.sinks[0]})
+		return nil
+	}
+}
+
+// FieldEquals matches entries with a string field key equal to value.
+func FieldEquals(key, value string) RoutePredicate {
+	return func(_ zapcore.Entry, fields []zapcore.Field) bool {
+		for i := len(fields) - 1; i >= 0; i-- {
+			if f := fields[i]; f.Key == key {
+				return f.Type == zapcore.StringType && f.String == value
+			}
+		}
+		return false
+	}
+}
+
+type route struct {
+	pred RoutePredicate
+	sink SinkConfig
+}
+
+// routeCore writes the entries its predicate matches to the route's sink.
+// It keeps the fields added with With for the predicate.
+type routeCore struct {
+	zapcore.Core
+	pred RoutePredicate
+	with []zapcore.Field
+}
+
+func (c *routeCore) With(fields []zapcore.Field) zapcore.Core {
+	with := make([]zapcore.Field, 0, len(c.with)+len(fields))
+	with = append(append(with, c.with...), fields...)
+	return &routeCore{Core: c.Core.With(fields), pred: c.pred, with: with}
+}
+
+func (c *routeCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *routeCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	all := fields
+	if len(c.with) > 0 {
+		all = make([]zapcore.Field, 0, len(c.with)+len(fields))
+		all = append(append(all, c.with...), fields...)
+	}
+	if !c.pred(ent, all) {
+		return nil
+	}
+	return c.Core.Write(ent, fields)
+}
@@ -0,0 +1,54 @@
+package jogger_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestWithRoute(t *testing.T) {
+	var billing, errs bytes.Buffer
+	main := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON),
+		jogger.WithRoute(jogger.FieldEquals("channel", "billing"), jogger.SinkConfig{Name: "billing", Writer: &billing, Format: jogger.FormatJSON}),
+		jogger.WithRoute(func(ent zapcore.Entry, _ []zapcore.Field) bool { return ent.Level >= zapcore.ErrorLevel },
+			jogger.SinkConfig{Name: "errors", Writer: &errs, Format: jogger.FormatJSON}),
+	)
+	ctx := context.Background()
+
+	jogger.Info(ctx, "charged", zap.String("channel", "billing"))
+	jogger.FromContext(ctx).With(zap.String("channel", "billing")).Error("refund failed")
+	jogger.Error(ctx, "disk full")
+	jogger.Info(ctx, "unrelated", zap.String("channel", "search"))
+
+	if n := len(decodeEntries(t, main)); n != 4 {
+		t.Errorf("expected every entry on the main sink, got %d", n)
+	}
+	if e := decodeEntries(t, &billing); len(e) != 2 || e[0]["msg"] != "charged" || e[1]["msg"] != "refund failed" {
+		t.Errorf("expected the billing entries, With fields included, got %v", e)
+	}
+	if e := decodeEntries(t, &errs); len(e) != 2 || e[0]["msg"] != "refund failed" || e[1]["msg"] != "disk full" {
+		t.Errorf("expected the Error entries, got %v", e)
+	}
+}
+
+func TestWithRouteRejectsInvalidSettings(t *testing.T) {
+	for name, opt := range map[string]jogger.Option{
+		"predicate": jogger.WithRoute(nil, jogger.SinkConfig{Writer: &bytes.Buffer{}}),
+		"writer":    jogger.WithRoute(jogger.FieldEquals("a", "b"), jogger.SinkConfig{}),
+	} {
+		if err := jogger.Configure(opt); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if err := jogger.Configure(
+		jogger.WithSink(jogger.SinkConfig{Name: "x", Writer: &bytes.Buffer{}}),
+		jogger.WithRoute(jogger.FieldEquals("a", "b"), jogger.SinkConfig{Name: "x", Writer: &bytes.Buffer{}}),
+	); err == nil {
+		t.Error("expected an error for a duplicate sink name")
+	}
+	jogger.Configure()
+}
@@ -0,0 +1,79 @@
+package jogger
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+
+	"github.com/google/uuid"
+)
+
+// SampledHeader is the header Inject writes and Extract reads the root
+// sampling decision from, "1" for sampled and "0" for not.
+const SampledHeader = "X-Jogger-Sampled"
+
+// WithSampling keeps the Info-level access logs and span finishes of only
+// the given fraction, between 0 and 1, of requests. The decision is made
+// once per request at its root, by EnsureRequestID, and travels to other
+// services with the request ID. Warn and Error entries, and requests with
+// debug enabled, are always logged. The default rate of 1 logs everything.
+func WithSampling(rate float64) Option {
+	return func(c *config) error {
+		if rate < 0 || rate > 1 {
+			return errors.New("jogger: sampling rate must be between 0 and 1")
+		}
+		c.sampleRate = rate
+		return nil
+	}
+}
+
+// EnsureRequestID returns ctx with a request ID, generating one when ctx
+// has none, and with a sampling decision, drawing one at the configured
+// rate when none was propagated. Middleware calls it for every request;
+// call it where other work enters the process.
+func EnsureRequestID(ctx context.Context) context.Context {
+	ctx = orBackground(ctx)
+	if RequestID(ctx) == "" {
+		ctx = WithRequestID(ctx, uuid.New().String())
+	}
+	if scopeOf(ctx).Sampled == nil {
+		ctx = withSampled(ctx, sampleRoot(outputFor(ctx).cfg.sampleRate))
+	}
+	return ctx
+}
+
+func sampleRoot(rate float64) bool {
+	return rate >= 1 || rand.Float64() < rate
+}
+
+func withSampled(ctx context.Context, sampled bool) context.Context {
+	s := scopeOf(ctx)
+	s.Sampled = &sampled
+	return withScope(ctx, s)
+}
+
+// IsSampled reports whether the request in ctx is sampled, so application
+// code can make its verbose logging follow the same decision. Requests
+// without a decision and requests with debug enabled are sampled.
+func IsSampled(ctx context.Context) bool {
+	ctx = orBackground(ctx)
+	s := scopeOf(ctx)
+	return s.Sampled == nil || *s.Sampled || RequestDebugEnabled(s.RequestID)
+}
+
+func formatSampled(sampled bool) string {
+	if sampled {
+		return "1"
+	}
+	return "0"
+}
+
+func parseSampled(v string) (sampled, ok bool) {
+	switch v {
+	case "1", "true":
+		return true, true
+	case "0", "false":
+		return false, true
+	}
+	return false, false
+}
@@ -0,0 +1,103 @@
+package jogger_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+)
+
+func TestWithSamplingRejectsInvalidRates(t *testing.T) {
+	for _, rate := range []float64{-0.1, 1.5} {
+		if err := jogger.Configure(jogger.WithSampling(rate)); err == nil {
+			t.Errorf("expected an error for rate %v", rate)
+		}
+	}
+}
+
+func TestUnsampledRequestKeepsOnlyWarnings(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithSampling(0))
+	before := jogger.Stats().Dropped.Sampling
+
+	var sampled bool
+	var outgoing string
+	h := jogger.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		sampled = jogger.IsSampled(ctx)
+		span, ctx := jogger.StartSpan(ctx, "work")
+		span.Finish(nil)
+
+		h := http.Header{}
+		jogger.Inject(ctx, jogger.HeaderCarrier(h))
+		outgoing = h.Get(jogger.SampledHeader)
+
+		jogger.Warn(ctx, "kept")
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	serve(t, h, httptest.NewRequest("GET", "/ok", nil))
+
+	if sampled {
+		t.Error("expected IsSampled to be false at rate 0")
+	}
+	if outgoing != "0" {
+		t.Errorf("expected the decision to be propagated, got %q", outgoing)
+	}
+	entries := decodeEntries(t, buf)
+	if len(entries) != 1 || entries[0]["msg"] != "kept" {
+		t.Errorf("expected only the warning, got %v", entries)
+	}
+	if got := jogger.Stats().Dropped.Sampling - before; got != 2 {
+		t.Errorf("expected the span finish and the access log counted, got %d", got)
+	}
+
+	buf.Reset()
+	serve(t, h, httptest.NewRequest("GET", "/fail", nil))
+	entries = decodeEntries(t, buf)
+	if len(entries) != 2 || entries[1]["level"] != "error" {
+		t.Errorf("expected the warning and the 5xx access log, got %v", entries)
+	}
+}
+
+func TestPropagatedSamplingDecisionWins(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithSampling(0))
+
+	h := jogger.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set(jogger.SampledHeader, "1")
+	serve(t, h, r)
+
+	if entries := decodeEntries(t, buf); len(entries) != 1 {
+		t.Errorf("expected the upstream decision to keep the access log, got %v", entries)
+	}
+}
+
+func TestSamplingDecision(t *testing.T) {
+	configureBuffer(t, jogger.WithSampling(0))
+
+	if !jogger.IsSampled(context.Background()) {
+		t.Error("expected contexts without a decision to be sampled")
+	}
+
+	ctx := jogger.EnsureRequestID(context.Background())
+	if jogger.RequestID(ctx) == "" || jogger.IsSampled(ctx) {
+		t.Errorf("expected a generated request ID and an unsampled decision")
+	}
+	if again := jogger.EnsureRequestID(ctx); jogger.RequestID(again) != jogger.RequestID(ctx) {
+		t.Error("expected EnsureRequestID to keep an existing request ID")
+	}
+
+	carried := jogger.WithSnapshot(context.Background(), jogger.Snapshot(ctx))
+	if jogger.IsSampled(carried) {
+		t.Error("expected the decision to survive a snapshot")
+	}
+
+	jogger.EnableRequestDebug(jogger.RequestID(ctx))
+	defer jogger.DisableRequestDebug(jogger.RequestID(ctx))
+	if !jogger.IsSampled(ctx) {
+		t.Error("expected requests with debug enabled to be sampled")
+	}
+}
@@ -0,0 +1,153 @@
+package jogger
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Scope is what jogger keeps in a context about the work it belongs to.
+// It is stored as one value, so FromContext finds all of it with a single
+// lookup and its parts cannot get out of sync. WithRequestID, StartSpan
+// and the other functions of this package each store an updated copy.
+type Scope struct {
+	// RequestID is the ID set with WithRequestID.
+	RequestID string
+	// TraceID is logged as traceID when set, such as the ID of an
+	// OpenTelemetry trace the request belongs to.
+	TraceID string
+	// SpanID and SpanName describe the current span, see StartSpan.
+	SpanID   string
+	SpanName string
+	// Sampled is the root sampling decision, nil before one was made, see
+	// EnsureRequestID.
+	Sampled *bool
+	// Fields are added to every entry logged with the context, see
+	// WithFields.
+	Fields []zap.Field
+
+	userID   string         // set with WithUserID
+	tenantID string         // set with WithTenantID
+	client   string         // the client's request ID, see ClientRequestID
+	worker   interface{}    // set with WithWorkerID
+	zap      *zap.Logger    // set with WithZapLogger
+	name     string         // set with Named
+	prefix   string         // set with WithPrefix
+	instance *Jogger        // set with Jogger.WithContext
+	level    *zapcore.Level // set with WithMinLevel
+	tail     *tailBuffer    // set with StartTailSampling
+	children *spanChildren
+}
+
+var scopeContextKey = &contextKey{"scope"}
+
+// scopeValue is what a context stores its scope as. It also keeps the
+// logger FromContext derived from the scope, so that logging repeatedly
+// with a context builds its logger once.
+type scopeValue struct {
+	Scope
+	derived atomic.Value // *derivedLogger
+}
+
+// derivedLogger is a logger built for a scope. It stays valid as long as
+// the logger it was built on and the level are those it was built with.
+type derivedLogger struct {
+	root  *zap.Logger
+	level zapcore.Level
+	l     *zap.Logger
+}
+
+// scopeOf returns the scope of ctx. A context without a scope gets the
+// values stored under the deprecated exported keys, which the first scope
+// stored on top of it then carries.
+func scopeOf(ctx context.Context) Scope {
+	if stored, ok := ctx.Value(scopeContextKey).(*scopeValue); ok {
+		return stored.Scope
+	}
+	return legacyScope(ctx)
+}
+
+func withScope(ctx context.Context, s Scope) context.Context {
+	return context.WithValue(ctx, scopeContextKey, &scopeValue{Scope: s})
+}
+
+// logger returns the logger derived from v, building it again only when
+// the configuration, the request debugging of its request ID or the level
+// changed since it was last built.
+func (v *scopeValue) logger() *zap.Logger {
+	o := v.output()
+	root := v.zap
+	if root == nil {
+		root = o.loggerFor(v.RequestID)
+	}
+	lvl := Level()
+	if d, ok := v.derived.Load().(*derivedLogger); ok && d.root == root && d.level == lvl {
+		return d.l
+	}
+	l := v.derive(o)
+	v.derived.Store(&derivedLogger{root: root, level: lvl, l: l})
+	return l
+}
+
+// ScopeFrom returns a copy of the scope of ctx.
+func ScopeFrom(ctx context.Context) Scope {
+	s := scopeOf(orBackground(ctx))
+	s.Fields = append([]zap.Field(nil), s.Fields...)
+	if s.Sampled != nil {
+		sampled := *s.Sampled
+		s.Sampled = &sampled
+	}
+	return s
+}
+
+// WithScope returns a copy of ctx with the exported fields of s, such as
+// a scope taken with ScopeFrom in another context. The request ID is
+// checked as by WithRequestID when set. The logger, logger name, prefix
+// and instance of ctx are kept.
+func WithScope(ctx context.Context, s Scope) context.Context {
+	ctx = orBackground(ctx)
+	cur := scopeOf(ctx)
+	s.zap, s.name, s.prefix, s.instance, s.children, s.tail = cur.zap, cur.name, cur.prefix, cur.instance, cur.children, cur.tail
+	s.userID, s.tenantID, s.client, s.worker = cur.userID, cur.tenantID, cur.client, cur.worker
+	if s.RequestID != "" {
+		var client string
+		s.RequestID, client = normalizeRequestID(s.RequestID)
+		if client != "" {
+			s.client = client
+		}
+		ctx = context.WithValue(ctx, RequestIDKey, s.RequestID)
+	}
+	s.Fields = append([]zap.Field(nil), s.Fields...)
+	return withScope(ctx, s)
+}
+
+// WithFields returns a copy of ctx whose entries and spans carry fields, on
+// top of those of ctx.
+func WithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	ctx = orBackground(ctx)
+	s := scopeOf(ctx)
+	s.Fields = append(s.Fields[:len(s.Fields):len(s.Fields)], fields...)
+	return withScope(ctx, s)
+}
+
+// output returns the output of the instance of s, or of the default
+// instance.
+func (s *Scope) output() *output {
+	if s.instance != nil {
+		return s.instance.output()
+	}
+	return currentOutput()
+}
+
+// scopeFields appends the request ID and trace ID of s.
+func (s *Scope) scopeFields(names FieldNames, fields []zap.Field) []zap.Field {
+	if s.RequestID != "" {
+		fields = append(fields, zap.String(names.RequestID, s.RequestID))
+	}
+	if s.TraceID != "" {
+		fields = append(fields, zap.String("traceID", s.TraceID))
+	}
+	return fields
+}
@@ -0,0 +1,89 @@
+package jogger_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+)
+
+func TestScopeFrom(t *testing.T) {
+	ctx := jogger.WithRequestID(context.Background(), "req-scope")
+	span, ctx := jogger.StartSpan(ctx, "scope.work")
+	defer span.Finish(nil)
+	ctx = jogger.EnsureRequestID(ctx)
+
+	s := jogger.ScopeFrom(ctx)
+	if s.RequestID != "req-scope" || s.SpanID != jogger.SpanID(ctx) || s.SpanName != "scope.work" {
+		t.Errorf("unexpected scope %+v", s)
+	}
+	if s.Sampled == nil || !*s.Sampled {
+		t.Errorf("expected the sampling decision in the scope, got %v", s.Sampled)
+	}
+}
+
+func TestWithScopeAndFields(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	ctx := jogger.Named(context.Background(), "billing")
+	ctx = jogger.WithScope(ctx, jogger.Scope{RequestID: "req-with-scope", TraceID: "4bf92f3577b34da6a3ce929d0e0e4736"})
+	ctx = jogger.WithFields(ctx, zap.String("job", "invoice"))
+	jogger.Info(ctx, "scoped")
+	span, _ := jogger.StartSpan(ctx, "scope.span")
+	span.Finish(nil)
+
+	for _, e := range decodeEntries(t, buf) {
+		if e["requestID"] != "req-with-scope" || e["traceID"] != "4bf92f3577b34da6a3ce929d0e0e4736" || e["job"] != "invoice" {
+			t.Errorf("expected the scope on every entry, got %v", e)
+		}
+		if e["logger"] != "billing" {
+			t.Errorf("expected WithScope to keep the logger name, got %v", e["logger"])
+		}
+	}
+}
+
+func TestScopeIgnoresLegacyKeysOnceStored(t *testing.T) {
+	ctx := jogger.WithRequestID(context.Background(), "req-new")
+	ctx = context.WithValue(ctx, jogger.RequestIDKey, "req-old")
+	if id := jogger.RequestID(ctx); id != "req-new" {
+		t.Errorf("expected the scope to take precedence, got %q", id)
+	}
+}
+
+// deepContext returns a context with the correlation values stored first
+// and n unrelated values on top, as in handlers behind several middlewares.
+func deepContext(ctx context.Context, n int) context.Context {
+	type unrelated int
+	for i := 0; i < n; i++ {
+		ctx = context.WithValue(ctx, unrelated(i), i)
+	}
+	return ctx
+}
+
+func BenchmarkFromContext(b *testing.B) {
+	legacy := context.WithValue(context.Background(), jogger.RequestIDKey, "req-bench")
+	legacy = context.WithValue(legacy, jogger.SpanKey, "span-bench")
+	scoped := jogger.WithRequestID(context.Background(), "req-bench")
+	_, scoped = jogger.StartSpan(scoped, "bench")
+
+	if err := jogger.Configure(jogger.WithOutput(ioutil.Discard), jogger.WithFormat(jogger.FormatJSON)); err != nil {
+		b.Fatal(err)
+	}
+	defer jogger.Configure()
+	for _, bc := range []struct {
+		name string
+		ctx  context.Context
+	}{
+		{"legacyKeys", deepContext(legacy, 20)},
+		{"scope", deepContext(scoped, 20)},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				jogger.FromContext(bc.ctx)
+			}
+		})
+	}
+}
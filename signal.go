@@ -0,0 +1,93 @@
+package jogger
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var levelBeforeToggle = int32(zapcore.InfoLevel)
+
+// HandleSignals starts a goroutine that, until ctx is done, toggles between
+// the current level and Debug on SIGUSR1 and re-reads JOGGER_LEVEL and
+// JOGGER_FORMAT on SIGHUP, rebuilding the output. Signals are received on a
+// dedicated channel, so handlers registered by the application keep working.
+//
+// HandleSignals does nothing on platforms without these signals.
+func HandleSignals(ctx context.Context) {
+	if len(verbositySignals) == 0 {
+		return
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, verbositySignals...)
+
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig := <-ch:
+				handleSignal(sig)
+			}
+		}
+	}()
+}
+
+func handleSignal(sig os.Signal) {
+	switch sig {
+	case toggleSignal:
+		toggleDebug()
+	case reloadSignal:
+		reloadEnv()
+	}
+}
+
+func toggleDebug() {
+	from := Level()
+	to := zapcore.DebugLevel
+	if from == zapcore.DebugLevel {
+		to = zapcore.Level(atomic.LoadInt32(&levelBeforeToggle))
+	} else {
+		atomic.StoreInt32(&levelBeforeToggle, int32(from))
+	}
+	SetLevel(to)
+
+	announce("jogger: log level toggled", zap.Stringer("from", from), zap.Stringer("to", to))
+}
+
+func reloadEnv() {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	env, err := readEnv()
+	if err != nil {
+		baseLogger().Warn("jogger: configuration reload failed", zap.Error(err))
+		return
+	}
+
+	cfg := currentOutput().cfg
+	cfg.level, cfg.levelSource = env.level, env.levelSource
+	cfg.format, cfg.formatSource = env.format, env.formatSource
+
+	o, err := newOutput(cfg, level)
+	if err != nil {
+		baseLogger().Warn("jogger: configuration reload failed", zap.Error(err))
+		return
+	}
+	SetLevel(env.level)
+	std.swap(o)
+
+	announce("jogger: configuration reloaded", zap.Stringer("minLevel", env.level), zap.String("format", env.format))
+}
+
+// announce writes an Info entry that is not subject to the base level, so an
+// operator always sees confirmation of the change they asked for.
+func announce(msg string, fields ...zap.Field) {
+	currentOutput().debug.Info(msg, fields...)
+}
@@ -0,0 +1,13 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package jogger
+
+import "os"
+
+var (
+	toggleSignal os.Signal
+	reloadSignal os.Signal
+
+	verbositySignals []os.Signal
+)
@@ -0,0 +1,103 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package jogger_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap/zapcore"
+)
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func sendSignal(t *testing.T, sig syscall.Signal) {
+	t.Helper()
+	if err := syscall.Kill(os.Getpid(), sig); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHandleSignalsToggleDebug(t *testing.T) {
+	defer jogger.SetLevel(zapcore.InfoLevel)
+	jogger.SetLevel(zapcore.InfoLevel)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	jogger.HandleSignals(ctx)
+
+	sendSignal(t, syscall.SIGUSR1)
+	waitFor(t, "debug level", func() bool { return jogger.Level() == zapcore.DebugLevel })
+
+	sendSignal(t, syscall.SIGUSR1)
+	waitFor(t, "info level", func() bool { return jogger.Level() == zapcore.InfoLevel })
+}
+
+func TestHandleSignalsReloadEnv(t *testing.T) {
+	defer jogger.SetLevel(zapcore.InfoLevel)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	jogger.HandleSignals(ctx)
+
+	os.Setenv(jogger.EnvLevel, "warn")
+	os.Setenv(jogger.EnvFormat, jogger.FormatJSON)
+	defer func() {
+		os.Unsetenv(jogger.EnvLevel)
+		os.Unsetenv(jogger.EnvFormat)
+		sendSignal(t, syscall.SIGHUP)
+		waitFor(t, "console format", func() bool { return adminFormat(t) == jogger.FormatConsole })
+	}()
+
+	sendSignal(t, syscall.SIGHUP)
+	waitFor(t, "warn level", func() bool { return jogger.Level() == zapcore.WarnLevel })
+	waitFor(t, "json format", func() bool { return adminFormat(t) == jogger.FormatJSON })
+}
+
+func TestHandleSignalsStopsOnCancel(t *testing.T) {
+	defer jogger.SetLevel(zapcore.InfoLevel)
+	jogger.SetLevel(zapcore.InfoLevel)
+
+	// Keep SIGUSR1 from terminating the test binary once jogger stops listening.
+	own := make(chan os.Signal, 1)
+	signal.Notify(own, syscall.SIGUSR1)
+	defer signal.Stop(own)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	jogger.HandleSignals(ctx)
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+
+	sendSignal(t, syscall.SIGUSR1)
+	select {
+	case <-own:
+	case <-time.After(2 * time.Second):
+		t.Fatal("application handler did not receive SIGUSR1")
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	if jogger.Level() != zapcore.InfoLevel {
+		t.Errorf("expected level to stay info after cancel, got %v", jogger.Level())
+	}
+}
+
+func adminFormat(t *testing.T) string {
+	_, resp := doAdmin(t, http.MethodGet, "")
+	return resp.Format
+}
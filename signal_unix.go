@@ -0,0 +1,16 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package jogger
+
+import (
+	"os"
+	"syscall"
+)
+
+var (
+	toggleSignal os.Signal = syscall.SIGUSR1
+	reloadSignal os.Signal = syscall.SIGHUP
+
+	verbositySignals = []os.Signal{toggleSignal, reloadSignal}
+)
@@ -0,0 +1,149 @@
+package jogger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SinkConfig describes one destination of entries for WithSink.
+type SinkConfig struct {
+	// Name identifies the sink to WithSinkFields. It defaults to the name
+	// of Writer: "stdout", "stderr", the file name or "writer".
+	Name string
+	// Writer receives the encoded entries.
+	Writer io.Writer
+	// Format is FormatConsole or FormatJSON, the configured format when
+	// empty.
+	Format string
+	// Level is the lowest level written to the sink, on top of the logger
+	// level. A nil Level writes everything the logger level lets through.
+	Level zapcore.LevelEnabler
+	// Fields are added to the entries of this sink only.
+	Fields []zap.Field
+}
+
+// WithSink adds a sink. Entries are written to every sink whose level they
+// pass, each in its own format; WithOutput is ignored once a sink is added.
+// Requests with debug enabled still follow the sink levels.
+func WithSink(sc SinkConfig) Option {
+	return func(c *config) error {
+		if sc.Writer == nil {
+			return errors.New("jogger: nil sink writer")
+		}
+		if sc.Format != "" && sc.Format != FormatConsole && sc.Format != FormatJSON {
+			return fmt.Errorf("jogger: unknown format %q for sink", sc.Format)
+		}
+		if l, ok := sc.Level.(zapcore.Level); ok && (l < zapcore.DebugLevel || l > zapcore.FatalLevel) {
+			return fmt.Errorf("jogger: invalid level %d for sink", l)
+		}
+		sc.Fields = append([]zap.Field(nil), sc.Fields...)
+		c.sinks = append(c.sinks, sc)
+		return nil
+	}
+}
+
+// WithSinkFields adds fields to the entries written to the named sink only,
+// such as stream=audit on the audit output. Sinks go by SinkConfig.Name, the
+// sink of WithOutput by the name of its writer, and the audit output by
+// "audit". Naming a sink that is not configured is an error.
+func WithSinkFields(name string, fields ...zap.Field) Option {
+	return func(c *config) error {
+		if name == "" {
+			return errors.New("jogger: empty sink name")
+		}
+		if c.sinkFields == nil {
+			c.sinkFields = map[string][]zap.Field{}
+		}
+		c.sinkFields[name] = append(c.sinkFields[name], fields...)
+		return nil
+	}
+}
+
+// sink is a SinkConfig resolved against the configuration.
+type sink struct {
+	name   string
+	format string
+	enc    zapcore.Encoder
+	out    zapcore.WriteSyncer
+	level  zapcore.LevelEnabler
+	fields []zap.Field
+	route  RoutePredicate // set for the sinks of WithRoute
+}
+
+// buildSinks resolves the configured sinks, or the single sink of
+// WithOutput and WithFormat when none was added, followed by the route
+// sinks.
+func buildSinks(cfg config) ([]sink, error) {
+	configs := cfg.sinks
+	if len(configs) == 0 {
+		configs = []SinkConfig{{Writer: cfg.writer}}
+	}
+	sinks := make([]sink, 0, len(configs)+len(cfg.routes))
+	for i := 0; i < len(configs)+len(cfg.routes); i++ {
+		var s sink
+		var err error
+		if i < len(configs) {
+			s, err = newSink(cfg, configs[i])
+		} else {
+			r := cfg.routes[i-len(configs)]
+			s, err = newSink(cfg, r.sink)
+			s.route = r.pred
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, other := range sinks {
+			if other.name == s.name {
+				return nil, fmt.Errorf("jogger: duplicate sink name %q", s.name)
+			}
+		}
+		sinks = append(sinks, s)
+	}
+	return sinks, nil
+}
+
+func newSink(cfg config, sc SinkConfig) (sink, error) {
+	s := sink{
+		name:   sc.Name,
+		format: sc.Format,
+		out:    zapcore.AddSync(sc.Writer),
+		level:  sc.Level,
+	}
+	if s.name == "" {
+		s.name = sinkName(sc.Writer)
+	}
+	if s.format == "" {
+		s.format = cfg.format
+	}
+	enc, err := newEncoder(s.format)
+	if err != nil {
+		return sink{}, err
+	}
+	s.enc = enc
+	s.fields = append(append([]zap.Field(nil), sc.Fields...), cfg.sinkFields[s.name]...)
+	return s, nil
+}
+
+// enabler combines the sink level with base.
+func (s sink) enabler(base zapcore.LevelEnabler) zapcore.LevelEnabler {
+	if s.level == nil {
+		return base
+	}
+	return zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return s.level.Enabled(l) && base.Enabled(l)
+	})
+}
+
+// ioCore returns the core encoding entries for s, with its fields encoded
+// once up front.
+func (s sink) ioCore(enab zapcore.LevelEnabler) zapcore.Core {
+	core := zapcore.NewCore(s.enc, s.out, enab)
+	if len(s.fields) > 0 {
+		core = core.With(s.fields)
+	}
+	return core
+}
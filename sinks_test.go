@@ -0,0 +1,62 @@
+package jogger_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestWithSinkLevelsAndFormats(t *testing.T) {
+	var console, file bytes.Buffer
+	if err := jogger.Configure(
+		jogger.WithLevel(zapcore.DebugLevel),
+		jogger.WithSink(jogger.SinkConfig{Writer: &console, Format: jogger.FormatConsole, Level: zapcore.InfoLevel}),
+		jogger.WithSink(jogger.SinkConfig{Name: "file", Writer: &file, Format: jogger.FormatJSON, Fields: []zap.Field{zap.String("stream", "file")}}),
+	); err != nil {
+		t.Fatal(err)
+	}
+	defer jogger.Configure()
+	jogger.ResetStats()
+
+	jogger.Debug(context.Background(), "cache miss")
+	jogger.Info(context.Background(), "request served")
+	if err := jogger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(console.String(), "cache miss") || !strings.Contains(console.String(), "request served") {
+		t.Errorf("expected only the Info entry on the console sink, got %q", console.String())
+	}
+	if strings.Contains(console.String(), "stream") {
+		t.Errorf("expected the file fields to stay off the console sink, got %q", console.String())
+	}
+	entries := decodeEntries(t, &file)
+	if len(entries) != 2 || entries[0]["msg"] != "cache miss" || entries[0]["stream"] != "file" {
+		t.Errorf("expected both entries as JSON on the file sink, got %v", entries)
+	}
+	if n := jogger.Stats().Entries["info"]; n != 1 {
+		t.Errorf("expected the Info entry to be counted once across sinks, got %d", n)
+	}
+}
+
+func TestWithSinkRejectsInvalidSettings(t *testing.T) {
+	var buf bytes.Buffer
+	for name, opts := range map[string][]jogger.Option{
+		"nil writer": {jogger.WithSink(jogger.SinkConfig{})},
+		"format":     {jogger.WithSink(jogger.SinkConfig{Writer: &buf, Format: "xml"})},
+		"level":      {jogger.WithSink(jogger.SinkConfig{Writer: &buf, Level: zapcore.Level(42)})},
+		"duplicate": {
+			jogger.WithSink(jogger.SinkConfig{Name: "a", Writer: &buf}),
+			jogger.WithSink(jogger.SinkConfig{Name: "a", Writer: &buf}),
+		},
+	} {
+		if err := jogger.Configure(opts...); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
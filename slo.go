@@ -0,0 +1,56 @@
+package jogger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	sloMu sync.Mutex
+	slos  atomic.Value // map[string]time.Duration, replaced on every change
+)
+
+func init() {
+	slos.Store(map[string]time.Duration{})
+}
+
+// RegisterSLO sets the latency target of spans named spanName. Finish then
+// adds slo_target_ms and slo_breached to those spans and logs a breach as
+// a slow span, whatever the slow span threshold. A target of zero or less
+// removes the registration. It is safe to call while logging.
+func RegisterSLO(spanName string, target time.Duration) {
+	updateSLOs(map[string]time.Duration{spanName: target})
+}
+
+// SLOs returns a copy of the registered span latency targets.
+func SLOs() map[string]time.Duration {
+	cur := slos.Load().(map[string]time.Duration)
+	out := make(map[string]time.Duration, len(cur))
+	for name, target := range cur {
+		out[name] = target
+	}
+	return out
+}
+
+// updateSLOs applies several registrations at once, so readers see either
+// none or all of them.
+func updateSLOs(changes map[string]time.Duration) {
+	sloMu.Lock()
+	defer sloMu.Unlock()
+
+	next := SLOs()
+	for name, target := range changes {
+		if target > 0 {
+			next[name] = target
+		} else {
+			delete(next, name)
+		}
+	}
+	slos.Store(next)
+}
+
+func sloFor(spanName string) (time.Duration, bool) {
+	target, ok := slos.Load().(map[string]time.Duration)[spanName]
+	return target, ok
+}
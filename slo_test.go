@@ -0,0 +1,54 @@
+package jogger_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+)
+
+func TestSpanSLO(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	jogger.RegisterSLO("db.query", time.Millisecond)
+	defer jogger.RegisterSLO("db.query", 0)
+
+	span, _ := jogger.StartSpan(context.Background(), "db.query")
+	time.Sleep(3 * time.Millisecond)
+	span.Finish(nil)
+
+	span, _ = jogger.StartSpan(context.Background(), "db.query")
+	span.Finish(nil)
+
+	span, _ = jogger.StartSpan(context.Background(), "db.other")
+	span.Finish(nil)
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 3 {
+		t.Fatalf("expected three entries, got %v", entries)
+	}
+	if e := entries[0]; e["level"] != "warn" || e["slo_breached"] != true || e["slo_target_ms"] != float64(1) {
+		t.Errorf("expected a breach at warn below the slow threshold, got %v", e)
+	}
+	if e := entries[1]; e["level"] != "info" || e["slo_breached"] != false {
+		t.Errorf("expected a met SLO at info, got %v", e)
+	}
+	if _, ok := entries[2]["slo_breached"]; ok {
+		t.Errorf("expected no SLO fields without a registration, got %v", entries[2])
+	}
+}
+
+func TestRegisterSLO(t *testing.T) {
+	jogger.RegisterSLO("a", time.Second)
+	jogger.RegisterSLO("b", 2*time.Second)
+	defer jogger.RegisterSLO("b", 0)
+
+	slos := jogger.SLOs()
+	slos["c"] = time.Minute
+	jogger.RegisterSLO("a", 0)
+
+	got := jogger.SLOs()
+	if len(got) != 1 || got["b"] != 2*time.Second {
+		t.Errorf("expected only b registered, got %v", got)
+	}
+}
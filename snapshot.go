@@ -0,0 +1,92 @@
+package jogger
+
+import (
+	"context"
+	"sort"
+)
+
+// Fields is a plain copy of the correlation values in a context. It can be
+// handed to code that must not hold on to the original context, such as a
+// worker reading from a queue, and applied to a new context there.
+type Fields struct {
+	RequestID   string                     `json:"requestID,omitempty"`
+	TraceID     string                     `json:"traceID,omitempty"`
+	SpanID      string                     `json:"spanID,omitempty"`
+	Name        string                     `json:"name,omitempty"`
+	Prefix      string                     `json:"prefix,omitempty"`
+	Sampled     *bool                      `json:"sampled,omitempty"`
+	Correlation map[ContextKey]interface{} `json:"correlation,omitempty"`
+	Baggage     map[string]string          `json:"baggage,omitempty"`
+}
+
+// Snapshot captures the request ID, trace ID, current span, logger name and
+// prefix, baggage and registered correlation keys of ctx.
+func Snapshot(ctx context.Context) Fields {
+	ctx = orBackground(ctx)
+
+	s := scopeOf(ctx)
+	f := Fields{RequestID: s.RequestID, TraceID: s.TraceID, SpanID: s.SpanID, Name: s.name, Prefix: s.prefix}
+	if s.Sampled != nil {
+		sampled := *s.Sampled
+		f.Sampled = &sampled
+	}
+
+	for _, k := range registeredCorrelationKeys() {
+		v := k.value(ctx, &s)
+		if v == nil || v == "" {
+			continue
+		}
+		if f.Correlation == nil {
+			f.Correlation = make(map[ContextKey]interface{})
+		}
+		f.Correlation[k.key] = v
+	}
+	if baggageFrom(ctx) != nil {
+		f.Baggage = Baggage(ctx)
+	}
+	return f
+}
+
+// WithSnapshot returns a copy of ctx carrying the values captured in snap.
+// Values already in ctx are overridden, empty ones in snap are not applied.
+func WithSnapshot(ctx context.Context, snap Fields) context.Context {
+	ctx = orBackground(ctx)
+
+	if snap.RequestID != "" {
+		ctx = WithRequestID(ctx, snap.RequestID)
+	}
+	s := scopeOf(ctx)
+	if snap.TraceID != "" {
+		s.TraceID = snap.TraceID
+	}
+	if snap.SpanID != "" {
+		s.SpanID = snap.SpanID
+	}
+	if snap.Name != "" {
+		s.name = snap.Name
+	}
+	if snap.Prefix != "" {
+		s.prefix = snap.Prefix
+	}
+	if snap.Sampled != nil {
+		sampled := *snap.Sampled
+		s.Sampled = &sampled
+	}
+	ctx = withScope(ctx, s)
+	for k, v := range snap.Correlation {
+		if _, ok := scopedKeys[k]; ok {
+			ctx = withScopedValue(ctx, k, v)
+		} else {
+			ctx = context.WithValue(ctx, k, v)
+		}
+	}
+	keys := make([]string, 0, len(snap.Baggage))
+	for k := range snap.Baggage {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		ctx = WithBaggage(ctx, k, snap.Baggage[k])
+	}
+	return ctx
+}
@@ -0,0 +1,90 @@
+package jogger_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	ctx := jogger.WithRequestID(context.Background(), "req-snap")
+	ctx = jogger.WithTenantID(ctx, "acme")
+	ctx = context.WithValue(ctx, sessionKey, "sess-snap")
+	ctx = jogger.Named(ctx, "billing")
+	_, ctx = jogger.StartSpan(ctx, "snapshot-span")
+
+	snap := jogger.Snapshot(ctx)
+	if snap.RequestID != "req-snap" || snap.SpanID == "" || snap.Name != "billing" {
+		t.Fatalf("unexpected snapshot %+v", snap)
+	}
+
+	restored := jogger.WithSnapshot(context.Background(), snap)
+	if jogger.RequestID(restored) != "req-snap" {
+		t.Error("expected request ID to be restored")
+	}
+	if jogger.TenantID(restored) != "acme" {
+		t.Error("expected tenant ID to be restored")
+	}
+	if restored.Value(sessionKey) != "sess-snap" {
+		t.Error("expected registered correlation key to be restored")
+	}
+	if jogger.SpanID(restored) != snap.SpanID {
+		t.Error("expected span ID to be restored")
+	}
+}
+
+func TestSnapshotSurvivesCancellation(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	ctx, cancel := context.WithCancel(jogger.WithRequestID(context.Background(), "req-canceled"))
+	snap := jogger.Snapshot(ctx)
+	cancel()
+
+	jogger.Info(jogger.WithSnapshot(context.Background(), snap), "cleanup")
+
+	if e := decodeEntries(t, buf)[0]; e["requestID"] != "req-canceled" {
+		t.Errorf("expected requestID on cleanup log, got %v", e)
+	}
+}
+
+func TestSnapshotEmpty(t *testing.T) {
+	snap := jogger.Snapshot(context.Background())
+	if snap.RequestID != "" || snap.SpanID != "" || snap.Correlation != nil {
+		t.Errorf("expected empty snapshot, got %+v", snap)
+	}
+	if jogger.RequestID(jogger.WithSnapshot(nil, snap)) != "" {
+		t.Error("expected empty snapshot to apply nothing")
+	}
+}
+
+func ExampleSnapshot() {
+	type job struct {
+		snap jogger.Fields
+		item string
+	}
+
+	jobs := make(chan job)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				// The request context may be long gone; the snapshot still
+				// carries its correlation.
+				ctx := jogger.WithSnapshot(context.Background(), j.snap)
+				jogger.Info(ctx, "processing item", zap.String("item", j.item))
+			}
+		}()
+	}
+
+	ctx := jogger.WithRequestID(context.Background(), "req-123")
+	for _, item := range []string{"a", "b", "c"} {
+		jobs <- job{snap: jogger.Snapshot(ctx), item: item}
+	}
+	close(jobs)
+	wg.Wait()
+}
@@ -0,0 +1,260 @@
+package jogger
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// spanSettings are the per-span behaviours set with SpanOptions. Each
+// setting records whether it was set, so options registered for a name and
+// options passed to StartSpan can be layered.
+type spanSettings struct {
+	slow          time.Duration
+	level         zapcore.Level
+	levelSet      bool
+	slowLevel     zapcore.Level
+	slowLevelSet  bool
+	sampleRate    float64
+	sampleRateSet bool
+	silent        bool
+	lateTagGrace  time.Duration
+	resultLevels  map[string]zapcore.Level // shared, copied on change
+}
+
+// A SpanOption changes how a span's finish is logged. Options are passed to
+// ConfigureSpan for every span of a name, or to StartSpan for one span.
+type SpanOption func(*spanSettings)
+
+// SlowThreshold sets the duration after which the span is reported as
+// slow, instead of the global SlowSpanThreshold.
+func SlowThreshold(d time.Duration) SpanOption {
+	return func(s *spanSettings) {
+		s.slow = d
+	}
+}
+
+// FinishLevel sets the level of the successful finish entry, Info by
+// default. Errors and slow spans keep their levels.
+func FinishLevel(l zapcore.Level) SpanOption {
+	return func(s *spanSettings) {
+		s.level = l
+		s.levelSet = true
+	}
+}
+
+// SlowLevel sets the level of the finish entry of a slow span, instead of
+// the global SlowSpanLevel: Info for spans whose slowness is expected, such
+// as calls to an external provider, keeps them out of Warn-based alerts.
+func SlowLevel(l zapcore.Level) SpanOption {
+	return func(s *spanSettings) {
+		s.slowLevel = l
+		s.slowLevelSet = true
+	}
+}
+
+// SampleRate logs only the given fraction, between 0 and 1, of successful
+// finishes. Errors and slow spans are always logged.
+func SampleRate(rate float64) SpanOption {
+	return func(s *spanSettings) {
+		s.sampleRate = rate
+		s.sampleRateSet = true
+	}
+}
+
+// SilentOnSuccess toggles skipping the finish entry of spans that neither
+// fail nor run slowly.
+func SilentOnSuccess(on bool) SpanOption {
+	return func(s *spanSettings) {
+		s.silent = on
+	}
+}
+
+// LateTagGrace keeps tags set within d after Finish instead of dropping
+// them: they are logged together, once d has passed, in a "span tags
+// (late)" entry carrying the span ID. See Span.SetTag.
+func LateTagGrace(d time.Duration) SpanOption {
+	return func(s *spanSettings) {
+		s.lateTagGrace = d
+	}
+}
+
+// ResultLevel sets the level of the finish entry of FinishWithResult with
+// result, whether or not there was an error: Debug for an expected
+// "cache_miss", or Info for a "not_found" that is not a failure.
+func ResultLevel(result string, l zapcore.Level) SpanOption {
+	return func(s *spanSettings) {
+		levels := make(map[string]zapcore.Level, len(s.resultLevels)+1)
+		for r, lvl := range s.resultLevels {
+			levels[r] = lvl
+		}
+		levels[result] = l
+		s.resultLevels = levels
+	}
+}
+
+// logsSuccess decides whether a successful, fast finish is logged.
+func (s spanSettings) logsSuccess() bool {
+	if s.silent {
+		return false
+	}
+	if s.sampleRateSet && s.sampleRate < 1 {
+		return rand.Float64() < s.sampleRate
+	}
+	return true
+}
+
+func (s spanSettings) slowThreshold() time.Duration {
+	if s.slow > 0 {
+		return s.slow
+	}
+	return SlowSpanThreshold()
+}
+
+func (s spanSettings) slowFinishLevel() zapcore.Level {
+	if s.slowLevelSet {
+		return s.slowLevel
+	}
+	return SlowSpanLevel()
+}
+
+func (s spanSettings) successLevel() zapcore.Level {
+	if s.levelSet {
+		return s.level
+	}
+	return zapcore.InfoLevel
+}
+
+// spanPattern is a registered name containing *, split around the
+// wildcards once at registration.
+type spanPattern struct {
+	pattern  string
+	parts    []string
+	literal  int
+	order    int
+	settings spanSettings
+}
+
+func (p spanPattern) match(name string) bool {
+	first, last := p.parts[0], p.parts[len(p.parts)-1]
+	if len(name) < len(first)+len(last) || !strings.HasPrefix(name, first) || !strings.HasSuffix(name, last) {
+		return false
+	}
+	name = name[len(first) : len(name)-len(last)]
+	for _, part := range p.parts[1 : len(p.parts)-1] {
+		i := strings.Index(name, part)
+		if i < 0 {
+			return false
+		}
+		name = name[i+len(part):]
+	}
+	return true
+}
+
+// spanRegistry is replaced as a whole on every change; StartSpan only ever
+// reads it.
+type spanRegistry struct {
+	exact    map[string]spanSettings
+	patterns []spanPattern // most specific first
+	options  map[string][]SpanOption
+	next     int
+}
+
+var (
+	spanRegistryMu sync.Mutex
+	spanConfigs    atomic.Value // *spanRegistry
+)
+
+func init() {
+	spanConfigs.Store(&spanRegistry{exact: map[string]spanSettings{}, options: map[string][]SpanOption{}})
+}
+
+// ConfigureSpan sets the defaults of spans named name. The name may contain
+// * wildcards, as in "db.*": a span uses the options of its exact name if
+// registered, and otherwise those of the matching pattern with the most
+// literal characters, the earliest registered on ties. Options passed to
+// StartSpan override them. Calling ConfigureSpan again for a name replaces
+// its options, and calling it without options removes them. It is safe to
+// call while logging.
+func ConfigureSpan(name string, opts ...SpanOption) {
+	spanRegistryMu.Lock()
+	defer spanRegistryMu.Unlock()
+
+	old := spanConfigs.Load().(*spanRegistry)
+	reg := &spanRegistry{
+		exact:   map[string]spanSettings{},
+		options: make(map[string][]SpanOption, len(old.options)+1),
+		next:    old.next,
+	}
+	orders := map[string]int{}
+	for _, p := range old.patterns {
+		orders[p.pattern] = p.order
+	}
+	for n, o := range old.options {
+		reg.options[n] = o
+	}
+	if len(opts) == 0 {
+		delete(reg.options, name)
+	} else {
+		if _, ok := reg.options[name]; !ok {
+			orders[name] = reg.next
+			reg.next++
+		}
+		reg.options[name] = opts
+	}
+
+	for n, o := range reg.options {
+		var s spanSettings
+		for _, opt := range o {
+			opt(&s)
+		}
+		if !strings.Contains(n, "*") {
+			reg.exact[n] = s
+			continue
+		}
+		parts := strings.Split(n, "*")
+		reg.patterns = append(reg.patterns, spanPattern{
+			pattern:  n,
+			parts:    parts,
+			literal:  len(n) - len(parts) + 1,
+			order:    orders[n],
+			settings: s,
+		})
+	}
+	sort.Slice(reg.patterns, func(i, j int) bool {
+		a, b := reg.patterns[i], reg.patterns[j]
+		if a.literal != b.literal {
+			return a.literal > b.literal
+		}
+		return a.order < b.order
+	})
+	spanConfigs.Store(reg)
+}
+
+// spanSettingsFor resolves the settings of a span, layering opts over the
+// registered defaults.
+func spanSettingsFor(name string, opts []SpanOption) spanSettings {
+	reg := spanConfigs.Load().(*spanRegistry)
+	s, ok := reg.exact[name]
+	if !ok {
+		for _, p := range reg.patterns {
+			if p.match(name) {
+				s = p.settings
+				break
+			}
+		}
+	}
+	if len(opts) == 0 {
+		return s
+	}
+	out := s
+	for _, opt := range opts {
+		opt(&out)
+	}
+	return out
+}
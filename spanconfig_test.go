@@ -0,0 +1,141 @@
+package jogger_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap/zapcore"
+)
+
+// finishLevels finishes one span per name and returns the level of each
+// finish entry, or "" when none was logged.
+func finishLevels(t *testing.T, names ...string) []string {
+	t.Helper()
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithLevel(zapcore.DebugLevel))
+	var levels []string
+	for _, name := range names {
+		buf.Reset()
+		span, _ := jogger.StartSpan(context.Background(), name)
+		span.Finish(nil)
+		level := ""
+		if entries := decodeEntries(t, buf); len(entries) == 1 {
+			level, _ = entries[0]["level"].(string)
+		}
+		levels = append(levels, level)
+	}
+	return levels
+}
+
+func TestConfigureSpanMatching(t *testing.T) {
+	jogger.ConfigureSpan("db.*", jogger.FinishLevel(zapcore.DebugLevel))
+	jogger.ConfigureSpan("db.query.*", jogger.SilentOnSuccess(true))
+	jogger.ConfigureSpan("*.health", jogger.SlowThreshold(time.Nanosecond))
+	jogger.ConfigureSpan("db.ping", jogger.FinishLevel(zapcore.WarnLevel))
+	defer func() {
+		for _, name := range []string{"db.*", "db.query.*", "*.health", "db.ping"} {
+			jogger.ConfigureSpan(name)
+		}
+	}()
+
+	// db.health matches both db.* and *.health; the pattern with more
+	// literal characters wins.
+	got := finishLevels(t, "db.exec", "db.query.users", "db.ping", "db.health", "http.health", "cache.get", "db")
+	want := []string{"debug", "", "warn", "warn", "warn", "info", "info"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("span %d: expected level %q, got %q (all: %v)", i, want[i], got[i], got)
+		}
+	}
+}
+
+func TestConfigureSpanPrecedence(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	jogger.ConfigureSpan("job.*", jogger.SilentOnSuccess(true), jogger.SlowThreshold(time.Hour))
+	defer jogger.ConfigureSpan("job.*")
+
+	span, _ := jogger.StartSpan(context.Background(), "job.run", jogger.SilentOnSuccess(false))
+	span.Finish(nil)
+	span, _ = jogger.StartSpan(context.Background(), "job.run")
+	span.Finish(nil)
+	span, _ = jogger.StartSpan(context.Background(), "job.run", jogger.SlowThreshold(time.Nanosecond))
+	time.Sleep(time.Millisecond)
+	span.Finish(nil)
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("expected the silent span to be skipped, got %v", entries)
+	}
+	if entries[0]["msg"] != "span finished successfully" {
+		t.Errorf("expected the per-call option to win, got %v", entries[0])
+	}
+	if entries[1]["msg"] != "span finished slowly" || entries[1]["level"] != "warn" {
+		t.Errorf("expected the per-call threshold to win, got %v", entries[1])
+	}
+}
+
+func TestSlowLevel(t *testing.T) {
+	jogger.ConfigureSpan("*", jogger.SlowThreshold(time.Nanosecond))
+	jogger.ConfigureSpan("provider.*", jogger.SlowThreshold(time.Nanosecond), jogger.SlowLevel(zapcore.InfoLevel))
+	defer jogger.ConfigureSpan("*")
+	defer jogger.ConfigureSpan("provider.*")
+
+	if got := finishLevels(t, "checkout", "provider.webhook"); got[0] != "warn" || got[1] != "info" {
+		t.Errorf("expected warn for checkout and info for the provider, got %v", got)
+	}
+
+	jogger.SetSlowSpanLevel(zapcore.ErrorLevel)
+	defer jogger.SetSlowSpanLevel(zapcore.WarnLevel)
+	if got := finishLevels(t, "checkout", "provider.webhook"); got[0] != "error" || got[1] != "info" {
+		t.Errorf("expected the global level to apply to checkout only, got %v", got)
+	}
+}
+
+func TestSlowSpanHasSlowField(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	span, _ := jogger.StartSpan(context.Background(), "provider.call", jogger.SlowThreshold(time.Nanosecond), jogger.SlowLevel(zapcore.InfoLevel))
+	time.Sleep(time.Millisecond)
+	span.Finish(nil)
+	span, _ = jogger.StartSpan(context.Background(), "provider.call")
+	span.Finish(nil)
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %v", entries)
+	}
+	if entries[0]["slow"] != true || entries[0]["msg"] != "span finished slowly" {
+		t.Errorf("expected slow=true on the slow span, got %v", entries[0])
+	}
+	if _, ok := entries[1]["slow"]; ok {
+		t.Errorf("expected no slow field on a fast span, got %v", entries[1])
+	}
+}
+
+func TestConfigureSpanKeepsErrors(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	jogger.ConfigureSpan("noisy", jogger.SampleRate(0), jogger.SilentOnSuccess(true))
+	defer jogger.ConfigureSpan("noisy")
+
+	for i := 0; i < 10; i++ {
+		span, _ := jogger.StartSpan(context.Background(), "noisy")
+		span.Finish(nil)
+	}
+	err := context.Canceled
+	span, _ := jogger.StartSpan(context.Background(), "noisy")
+	span.Finish(&err)
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 1 || entries[0]["level"] != "error" {
+		t.Errorf("expected only the failed span, got %v", entries)
+	}
+}
+
+func TestConfigureSpanRemoval(t *testing.T) {
+	jogger.ConfigureSpan("tmp.*", jogger.SilentOnSuccess(true))
+	jogger.ConfigureSpan("tmp.*")
+
+	if got := finishLevels(t, "tmp.a"); got[0] != "info" {
+		t.Errorf("expected the default finish after removal, got %q", got[0])
+	}
+}
@@ -0,0 +1,65 @@
+package jogger
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const maxSpanEvents = 32
+
+type spanEvent struct {
+	name   string
+	at     time.Duration
+	fields []zap.Field
+}
+
+func (e spanEvent) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("name", e.name)
+	enc.AddDuration("at", e.at)
+	for _, f := range e.fields {
+		f.AddTo(enc)
+	}
+	return nil
+}
+
+type spanEvents []spanEvent
+
+func (es spanEvents) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, e := range es {
+		if err := enc.AppendObject(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddEvent records something that happened during the span. Events are
+// written as an "events" array on Finish, each with its offset from the
+// span start. A span keeps at most 32 events; later ones are only counted.
+func (s *Span) AddEvent(name string, fields ...zap.Field) {
+	at := time.Since(s.start)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.events) >= maxSpanEvents {
+		s.droppedEvents++
+		return
+	}
+	s.events = append(s.events, spanEvent{name: name, at: at, fields: fields})
+}
+
+// appendEventFields must be called with s.mu held.
+func (s *Span) appendEventFields(fields []zap.Field) []zap.Field {
+	if len(s.events) == 0 {
+		return fields
+	}
+	events := make(spanEvents, len(s.events))
+	copy(events, s.events)
+	fields = append(fields, zap.Array("events", events))
+	if s.droppedEvents > 0 {
+		fields = append(fields, zap.Int("droppedEvents", s.droppedEvents))
+	}
+	return fields
+}
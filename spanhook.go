@@ -0,0 +1,58 @@
+package jogger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// finishedSpan is what span hooks learn about a finished span.
+type finishedSpan struct {
+	name      string
+	id        string
+	parentID  string
+	requestID string
+	start     time.Time
+	duration  time.Duration
+	err       error
+	slow      bool   // finished without error over its threshold or SLO
+	result    string // of FinishWithResult, empty for Finish
+}
+
+// A spanHook observes every finished span. Hooks run synchronously in
+// Finish, before the finish entry is written, and must be fast.
+type spanHook func(finishedSpan)
+
+var (
+	spanHooksMu sync.Mutex
+	spanHooks   atomic.Value // map[string]spanHook
+)
+
+func init() {
+	spanHooks.Store(map[string]spanHook{})
+}
+
+// setSpanHook registers fn under name, replacing the previous hook of that
+// name. A nil fn removes it.
+func setSpanHook(name string, fn spanHook) {
+	spanHooksMu.Lock()
+	defer spanHooksMu.Unlock()
+
+	old := spanHooks.Load().(map[string]spanHook)
+	hooks := make(map[string]spanHook, len(old)+1)
+	for n, h := range old {
+		hooks[n] = h
+	}
+	if fn == nil {
+		delete(hooks, name)
+	} else {
+		hooks[name] = fn
+	}
+	spanHooks.Store(hooks)
+}
+
+func runSpanHooks(s finishedSpan) {
+	for _, h := range spanHooks.Load().(map[string]spanHook) {
+		h(s)
+	}
+}
@@ -0,0 +1,78 @@
+package jogger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const maxSpanLinks = 128
+
+type spanLink struct {
+	requestID, spanID string
+}
+
+// spanLinks encodes links with the configured field names.
+type spanLinks struct {
+	links []spanLink
+	names FieldNames
+}
+
+func (ls spanLinks) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, l := range ls.links {
+		err := enc.AppendObject(zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			if l.requestID != "" {
+				enc.AddString(ls.names.RequestID, l.requestID)
+			}
+			if l.spanID != "" {
+				enc.AddString(ls.names.SpanID, l.spanID)
+			}
+			return nil
+		}))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddLink records that the span works on behalf of another request, as a
+// batch flush does for the requests whose items it writes. Links are
+// written as a "links" array on Finish, each with the request and span ID,
+// so a query for one request also finds the shared span. A span keeps at
+// most 128 links; later ones are only counted. Empty IDs are ignored.
+func (s *Span) AddLink(requestID, spanID string) {
+	if requestID == "" && spanID == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.links) >= maxSpanLinks {
+		s.droppedLinks++
+		return
+	}
+	s.links = append(s.links, spanLink{requestID: requestID, spanID: spanID})
+}
+
+// LinkContext links the span to the request ID and current span of ctx,
+// see AddLink.
+func (s *Span) LinkContext(ctx context.Context) {
+	ctx = orBackground(ctx)
+	spanID := scopeOf(ctx).SpanID
+	s.AddLink(RequestID(ctx), spanID)
+}
+
+// appendLinkFields must be called with s.mu held.
+func (s *Span) appendLinkFields(fields []zap.Field) []zap.Field {
+	if len(s.links) == 0 {
+		return fields
+	}
+	links := make([]spanLink, len(s.links))
+	copy(links, s.links)
+	fields = append(fields, zap.Array("links", spanLinks{links, s.names}))
+	if s.droppedLinks > 0 {
+		fields = append(fields, zap.Int("droppedLinks", s.droppedLinks))
+	}
+	return fields
+}
@@ -0,0 +1,49 @@
+package jogger_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+)
+
+func TestSpanLinks(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	itemSpan, itemCtx := jogger.StartSpan(jogger.WithRequestID(context.Background(), "req-a"), "enqueue")
+	batch, _ := jogger.StartSpan(jogger.WithRequestID(context.Background(), "req-batch"), "batch.flush")
+	batch.LinkContext(itemCtx)
+	batch.AddLink("req-b", "")
+	batch.AddLink("", "")
+	batch.Finish(nil)
+	itemSpan.Finish(nil)
+
+	e := decodeEntries(t, buf)[0]
+	links, _ := e["links"].([]interface{})
+	if e["requestID"] != "req-batch" || len(links) != 2 {
+		t.Fatalf("expected two links on the batch span, got %v", e)
+	}
+	first := links[0].(map[string]interface{})
+	if first["requestID"] != "req-a" || first["spanID"] == nil || first["spanID"] == e["spanID"] {
+		t.Errorf("expected the item request and span in the first link, got %v", first)
+	}
+	if second := links[1].(map[string]interface{}); second["requestID"] != "req-b" || second["spanID"] != nil {
+		t.Errorf("expected a request-only second link, got %v", second)
+	}
+}
+
+func TestSpanLinksBounded(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	batch, _ := jogger.StartSpan(context.Background(), "batch.flush")
+	for i := 0; i < 130; i++ {
+		batch.AddLink(fmt.Sprintf("req-%d", i), "")
+	}
+	batch.Finish(nil)
+
+	e := decodeEntries(t, buf)[0]
+	if links := e["links"].([]interface{}); len(links) != 128 || e["droppedLinks"] != float64(2) {
+		t.Errorf("expected 128 links and 2 dropped, got %d and %v", len(links), e["droppedLinks"])
+	}
+}
@@ -0,0 +1,101 @@
+package jogger
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// tag returns the field of the last tag named key.
+func (s *Span) tag(key string) (zap.Field, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.fields) - 1; i >= 0; i-- {
+		if s.fields[i].Key == key {
+			return s.fields[i], true
+		}
+	}
+	return zap.Field{}, false
+}
+
+// StringTag returns the tag named key if it is a string, a []byte or a
+// fmt.Stringer such as json.Number.
+func (s *Span) StringTag(key string) (string, bool) {
+	f, ok := s.tag(key)
+	if !ok {
+		return "", false
+	}
+	switch f.Type {
+	case zapcore.StringType:
+		return f.String, true
+	case zapcore.ByteStringType, zapcore.BinaryType:
+		return string(f.Interface.([]byte)), true
+	case zapcore.StringerType:
+		return f.Interface.(fmt.Stringer).String(), true
+	}
+	return "", false
+}
+
+// IntTag returns the tag named key as an int64. Integers of any size,
+// floats without a fractional part and numeric strings, json.Number among
+// them, are converted; values that do not fit are not.
+func (s *Span) IntTag(key string) (int64, bool) {
+	f, ok := s.tag(key)
+	if !ok {
+		return 0, false
+	}
+	switch f.Type {
+	case zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type,
+		zapcore.Uint32Type, zapcore.Uint16Type, zapcore.Uint8Type:
+		return f.Integer, true
+	case zapcore.Uint64Type, zapcore.UintptrType:
+		if uint64(f.Integer) > math.MaxInt64 {
+			return 0, false
+		}
+		return f.Integer, true
+	case zapcore.Float64Type:
+		return floatToInt(math.Float64frombits(uint64(f.Integer)))
+	case zapcore.Float32Type:
+		return floatToInt(float64(math.Float32frombits(uint32(f.Integer))))
+	case zapcore.StringType:
+		n, err := strconv.ParseInt(f.String, 10, 64)
+		return n, err == nil
+	case zapcore.StringerType:
+		if num, ok := f.Interface.(json.Number); ok {
+			n, err := num.Int64()
+			return n, err == nil
+		}
+		n, err := strconv.ParseInt(f.Interface.(fmt.Stringer).String(), 10, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func floatToInt(v float64) (int64, bool) {
+	if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(v), true
+}
+
+// DurationTag returns the tag named key if it is a time.Duration or a
+// string such as "1.5s".
+func (s *Span) DurationTag(key string) (time.Duration, bool) {
+	f, ok := s.tag(key)
+	if !ok {
+		return 0, false
+	}
+	switch f.Type {
+	case zapcore.DurationType:
+		return time.Duration(f.Integer), true
+	case zapcore.StringType:
+		d, err := time.ParseDuration(f.String)
+		return d, err == nil
+	}
+	return 0, false
+}
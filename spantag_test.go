@@ -0,0 +1,156 @@
+package jogger_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+)
+
+type tagStringer struct{}
+
+func (tagStringer) String() string { return "stringer" }
+
+func TestSpanTagAccessors(t *testing.T) {
+	configureBuffer(t)
+
+	tests := []struct {
+		value    interface{}
+		str      string
+		strOK    bool
+		n        int64
+		nOK      bool
+		duration time.Duration
+		dOK      bool
+	}{
+		{value: "text", str: "text", strOK: true},
+		{value: "42", str: "42", strOK: true, n: 42, nOK: true},
+		{value: "1.5s", str: "1.5s", strOK: true, duration: 1500 * time.Millisecond, dOK: true},
+		{value: []byte("bytes"), str: "bytes", strOK: true},
+		{value: tagStringer{}, str: "stringer", strOK: true},
+		{value: json.Number("7"), str: "7", strOK: true, n: 7, nOK: true},
+		{value: json.Number("7.5"), str: "7.5", strOK: true},
+		{value: 3, n: 3, nOK: true},
+		{value: int8(-8), n: -8, nOK: true},
+		{value: uint32(32), n: 32, nOK: true},
+		{value: uint64(1 << 63), nOK: false},
+		{value: int64(1 << 62), n: 1 << 62, nOK: true},
+		{value: float64(12), n: 12, nOK: true},
+		{value: float32(2.5)},
+		{value: 1e30},
+		{value: 2 * time.Second, duration: 2 * time.Second, dOK: true},
+		{value: true},
+	}
+	for _, tt := range tests {
+		span, _ := jogger.StartSpan(context.Background(), "tags")
+		span.SetTag("key", tt.value)
+
+		if s, ok := span.StringTag("key"); s != tt.str || ok != tt.strOK {
+			t.Errorf("StringTag(%#v) = %q, %v, expected %q, %v", tt.value, s, ok, tt.str, tt.strOK)
+		}
+		if n, ok := span.IntTag("key"); n != tt.n || ok != tt.nOK {
+			t.Errorf("IntTag(%#v) = %d, %v, expected %d, %v", tt.value, n, ok, tt.n, tt.nOK)
+		}
+		if d, ok := span.DurationTag("key"); d != tt.duration || ok != tt.dOK {
+			t.Errorf("DurationTag(%#v) = %v, %v, expected %v, %v", tt.value, d, ok, tt.duration, tt.dOK)
+		}
+		span.Finish(nil)
+	}
+}
+
+func TestSpanTagLatestWins(t *testing.T) {
+	configureBuffer(t)
+	span, _ := jogger.StartSpan(context.Background(), "tags")
+	defer span.Finish(nil)
+
+	span.SetTag("attempt", 1)
+	span.SetTag("attempt", 2)
+	if n, ok := span.IntTag("attempt"); n != 2 || !ok {
+		t.Errorf("expected the last tag, got %d, %v", n, ok)
+	}
+	if _, ok := span.StringTag("missing"); ok {
+		t.Error("expected no tag")
+	}
+}
+
+func TestSpanSetTags(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	span, _ := jogger.StartSpan(context.Background(), "bulk")
+	span.SetTags(zap.String("db", "users"), zap.Int("rows", 3))
+	span.SetTagMap(map[string]interface{}{"zone": "b", "attempt": 2, "cache": false})
+	span.Finish(nil)
+
+	e := decodeEntries(t, buf)[0]
+	if e["db"] != "users" || e["rows"] != float64(3) || e["zone"] != "b" || e["attempt"] != float64(2) || e["cache"] != false {
+		t.Errorf("expected every tag in the finish entry, got %v", e)
+	}
+	line := buf.String()
+	if !(strings.Index(line, `"attempt"`) < strings.Index(line, `"cache"`) && strings.Index(line, `"cache"`) < strings.Index(line, `"zone"`)) {
+		t.Errorf("expected the map tags in key order, got %s", line)
+	}
+}
+
+func TestSpanSetTagsAfterFinish(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	jogger.ResetStats()
+	span, _ := jogger.StartSpan(context.Background(), fmt.Sprintf("bulk.late.%d", time.Now().UnixNano()))
+	span.Finish(nil)
+	span.SetTags(zap.Int("a", 1), zap.Int("b", 2))
+
+	if n := jogger.Stats().LateTags; n != 2 {
+		t.Errorf("expected two late tags counted, got %d", n)
+	}
+	for _, e := range decodeEntries(t, buf) {
+		if _, ok := e["a"]; ok {
+			t.Errorf("expected the late tags to be dropped, got %v", e)
+		}
+	}
+}
+
+func BenchmarkSpanTags(b *testing.B) {
+	if err := jogger.Configure(jogger.WithOutput(ioutil.Discard), jogger.WithFormat(jogger.FormatJSON)); err != nil {
+		b.Fatal(err)
+	}
+	defer jogger.Configure()
+	keys := make([]string, 10)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("tag%d", i)
+	}
+
+	// Spans are started before the timer so only setting tags is measured.
+	spans := func(n int) []jogger.Span {
+		out := make([]jogger.Span, n)
+		for i := range out {
+			out[i], _ = jogger.StartSpan(context.Background(), "bench")
+		}
+		return out
+	}
+
+	b.Run("SetTag", func(b *testing.B) {
+		s := spans(b.N)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for j, k := range keys {
+				s[i].SetTag(k, j)
+			}
+		}
+	})
+	b.Run("SetTags", func(b *testing.B) {
+		s := spans(b.N)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			s[i].SetTags(
+				zap.Int(keys[0], 0), zap.Int(keys[1], 1), zap.Int(keys[2], 2), zap.Int(keys[3], 3), zap.Int(keys[4], 4),
+				zap.Int(keys[5], 5), zap.Int(keys[6], 6), zap.Int(keys[7], 7), zap.Int(keys[8], 8), zap.Int(keys[9], 9),
+			)
+		}
+	})
+}
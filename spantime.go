@@ -0,0 +1,75 @@
+package jogger
+
+import (
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// WithSpanTimestamps toggles the span_start and span_end fields of span
+// finish entries, UTC times encoded by the sink like the entry time. span_end
+// is span_start plus the duration, both taken from the same clock reading,
+// so they add up to the precision of the time encoder. It is on by default with FormatJSON and off with
+// FormatConsole, where the entry time is shown anyway.
+func WithSpanTimestamps(on bool) Option {
+	return func(c *config) error {
+		c.spanTimestamps = &on
+		return nil
+	}
+}
+
+func (c config) spanTimestampsEnabled() bool {
+	if c.spanTimestamps != nil {
+		return *c.spanTimestamps
+	}
+	return c.format == FormatJSON
+}
+
+const defaultClockSkewThreshold = time.Second
+
+// WithClockSkewThreshold sets how far the wall clock may move differently
+// from the monotonic clock during a span, when NTP steps it for instance,
+// before Finish adds clock_skew_ms: the wall clock time that passed minus
+// the duration, in milliseconds. Timestamps of entries around such a span
+// are suspect. The default is one second; zero turns the check off.
+func WithClockSkewThreshold(d time.Duration) Option {
+	return func(c *config) error {
+		if d < 0 {
+			return errors.New("jogger: clock skew threshold must not be negative")
+		}
+		c.clockSkew = &d
+		return nil
+	}
+}
+
+func (c config) clockSkewThreshold() time.Duration {
+	if c.clockSkew != nil {
+		return *c.clockSkew
+	}
+	return defaultClockSkewThreshold
+}
+
+// clockSkew returns the clock_skew_ms field of a span started at the wall
+// clock time startWall and finished at now, elapsed later on the monotonic
+// clock, when the two clocks differ by more than threshold.
+func clockSkew(startWall, now time.Time, elapsed, threshold time.Duration) (zap.Field, bool) {
+	if threshold <= 0 {
+		return zap.Field{}, false
+	}
+	skew := now.Round(0).Sub(startWall) - elapsed
+	if skew <= threshold && skew >= -threshold {
+		return zap.Field{}, false
+	}
+	return zap.Float64("clock_skew_ms", float64(skew)/float64(time.Millisecond)), true
+}
+
+// spanTimestampFields returns span_start and span_end of a span started at
+// start that ran for elapsed.
+func spanTimestampFields(start time.Time, elapsed time.Duration) []zap.Field {
+	start = start.UTC()
+	return []zap.Field{
+		zap.Time("span_start", start),
+		zap.Time("span_end", start.Add(elapsed)),
+	}
+}
@@ -0,0 +1,53 @@
+package jogger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestClockSkew(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Configure(WithOutput(&buf), WithFormat(FormatJSON)); err != nil {
+		t.Fatal(err)
+	}
+	defer Configure()
+
+	// The wall clock is stepped forward by five seconds while the span runs.
+	span, _ := StartSpan(context.Background(), "skewed")
+	span.startWall = span.startWall.Add(-5 * time.Second)
+	span.Finish(nil)
+
+	var e map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &e); err != nil {
+		t.Fatal(err)
+	}
+	skew, ok := e["clock_skew_ms"].(float64)
+	if !ok || skew < 4900 || skew > 5100 {
+		t.Errorf("expected about 5000ms of skew, got %v", e["clock_skew_ms"])
+	}
+	if d := e["duration"].(float64); d < 0 || d > 1 {
+		t.Errorf("expected the monotonic duration, got %v", d)
+	}
+}
+
+func TestClockSkewThreshold(t *testing.T) {
+	start := time.Unix(1000, 0)
+	for _, tc := range []struct {
+		wall, elapsed, threshold time.Duration
+		want                     bool
+	}{
+		{wall: time.Second, elapsed: time.Second, threshold: time.Second},
+		{wall: 1500 * time.Millisecond, elapsed: time.Second, threshold: time.Second},
+		{wall: 3 * time.Second, elapsed: time.Second, threshold: time.Second, want: true},
+		{wall: -time.Second, elapsed: time.Second, threshold: time.Second, want: true},
+		{wall: time.Hour, elapsed: time.Second, threshold: 0},
+	} {
+		f, ok := clockSkew(start, start.Add(tc.wall), tc.elapsed, tc.threshold)
+		if ok != tc.want {
+			t.Errorf("wall %v, elapsed %v, threshold %v: expected skew %v, got %v", tc.wall, tc.elapsed, tc.threshold, tc.want, f)
+		}
+	}
+}
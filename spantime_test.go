@@ -0,0 +1,79 @@
+package jogger_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSpanTimestamps(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	span, _ := jogger.StartSpan(context.Background(), "timed")
+	time.Sleep(time.Millisecond)
+	span.Finish(nil)
+
+	e := decodeEntries(t, buf)[0]
+	start, err := time.Parse(time.RFC3339Nano, e["span_start"].(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	end, err := time.Parse(time.RFC3339Nano, e["span_end"].(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	elapsed := time.Duration(e["duration"].(float64) * float64(time.Second))
+	if diff := end.Sub(start) - elapsed; diff < -time.Millisecond || diff > time.Millisecond {
+		t.Errorf("expected span_end - span_start to equal the duration, got %v and %v", end.Sub(start), elapsed)
+	}
+	if start.Location() != time.UTC {
+		t.Errorf("expected UTC timestamps, got %v", e["span_start"])
+	}
+}
+
+func TestSpanTimestampsOff(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []jogger.Option
+	}{
+		{"console", []jogger.Option{jogger.WithFormat(jogger.FormatConsole)}},
+		{"disabled", []jogger.Option{jogger.WithFormat(jogger.FormatJSON), jogger.WithSpanTimestamps(false)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := configureBuffer(t, tc.opts...)
+			span, _ := jogger.StartSpan(context.Background(), "timed")
+			span.Finish(nil)
+			if strings.Contains(buf.String(), "span_start") {
+				t.Errorf("expected no span timestamps, got %s", buf)
+			}
+		})
+	}
+}
+
+func TestSpanTimestampsUseTimeEncoder(t *testing.T) {
+	configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	var buf bytes.Buffer
+	encCfg := zap.NewProductionEncoderConfig()
+	encCfg.EncodeTime = zapcore.EpochNanosTimeEncoder
+	custom := zap.New(zapcore.NewCore(zapcore.NewJSONEncoder(encCfg), zapcore.AddSync(&buf), zapcore.InfoLevel))
+
+	span, _ := jogger.StartSpan(jogger.WithZapLogger(context.Background(), custom), "timed")
+	time.Sleep(time.Millisecond)
+	span.Finish(nil)
+
+	e := decodeEntries(t, &buf)[0]
+	start, ok1 := e["span_start"].(float64)
+	end, ok2 := e["span_end"].(float64)
+	if !ok1 || !ok2 {
+		t.Fatalf("expected span timestamps in epoch nanoseconds, got %v and %v", e["span_start"], e["span_end"])
+	}
+	elapsed := e["duration"].(float64) * float64(time.Second)
+	if diff := end - start - elapsed; diff < -float64(time.Microsecond) || diff > float64(time.Microsecond) {
+		t.Errorf("expected span_end - span_start to equal the duration, got %v and %v", end-start, elapsed)
+	}
+}
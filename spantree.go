@@ -0,0 +1,255 @@
+package jogger
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxTreeSpans bounds the spans a span tree keeps per request.
+const maxTreeSpans = 256
+
+var spanTreeKey = &contextKey{"spanTree"}
+
+// spanTree collects the spans started under a context made with
+// CollectSpanTree.
+type spanTree struct {
+	mu        sync.Mutex
+	nodes     []*treeNode
+	truncated int
+}
+
+// treeNode is one span of a tree. Its fields are guarded by the tree's mu.
+type treeNode struct {
+	tree     *spanTree
+	name     string
+	id       string
+	parentID string
+	start    time.Time
+	duration time.Duration
+	err      string
+	finished bool
+}
+
+// CollectSpanTree returns a copy of ctx that records the spans started
+// under it, for EmitSpanTree. A context already collecting is returned as
+// is. At most 256 spans are kept; later ones are only counted.
+func CollectSpanTree(ctx context.Context) context.Context {
+	ctx = orBackground(ctx)
+	if spanTreeFrom(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, spanTreeKey, &spanTree{})
+}
+
+func spanTreeFrom(ctx context.Context) *spanTree {
+	t, _ := ctx.Value(spanTreeKey).(*spanTree)
+	return t
+}
+
+// add records a started span. It is a no-op on a nil tree.
+func (t *spanTree) add(name, id, parentID string, start time.Time) *treeNode {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.nodes) >= maxTreeSpans {
+		t.truncated++
+		return nil
+	}
+	n := &treeNode{tree: t, name: name, id: id, parentID: parentID, start: start}
+	t.nodes = append(t.nodes, n)
+	return n
+}
+
+func (n *treeNode) finish(d time.Duration, err error) {
+	if n == nil {
+		return
+	}
+	n.tree.mu.Lock()
+	defer n.tree.mu.Unlock()
+	n.duration = d
+	n.finished = true
+	if err != nil {
+		n.err = err.Error()
+	}
+}
+
+// EmitSpanTree logs one "span tree" entry for the spans collected under
+// ctx, see CollectSpanTree, as a nested spans array. Each node has its
+// name, duration, self time, which leaves out the time covered by its
+// children, and error; spans still open are marked unfinished and measured
+// up to now. Siblings are sorted by start time.
+func EmitSpanTree(ctx context.Context) {
+	ctx = orBackground(ctx)
+	t := spanTreeFrom(ctx)
+	if t == nil {
+		return
+	}
+	roots, count, truncated := t.build(time.Now())
+	fields := []zap.Field{zap.Array("spans", roots), zap.Int("spanCount", count)}
+	if truncated > 0 {
+		fields = append(fields, zap.Int("truncatedSpans", truncated))
+	}
+	FromContext(ctx).Info("span tree", fields...)
+}
+
+// treeView is a snapshot of a node with its children, for encoding.
+type treeView struct {
+	name       string
+	start, end time.Time
+	duration   time.Duration
+	self       time.Duration
+	err        string
+	unfinished bool
+	children   treeViews
+}
+
+type treeViews []*treeView
+
+func (t *spanTree) build(now time.Time) (treeViews, int, int) {
+	t.mu.Lock()
+	views := make(map[string]*treeView, len(t.nodes))
+	order := make([]*treeNode, len(t.nodes))
+	copy(order, t.nodes)
+	for _, n := range t.nodes {
+		d := n.duration
+		if !n.finished {
+			d = now.Sub(n.start)
+		}
+		views[n.id] = &treeView{name: n.name, start: n.start, end: n.start.Add(d), duration: d, err: n.err, unfinished: !n.finished}
+	}
+	truncated := t.truncated
+	t.mu.Unlock()
+
+	sort.SliceStable(order, func(i, j int) bool { return order[i].start.Before(order[j].start) })
+	var roots treeViews
+	for _, n := range order {
+		v := views[n.id]
+		if parent, ok := views[n.parentID]; ok {
+			parent.children = append(parent.children, v)
+		} else {
+			roots = append(roots, v)
+		}
+	}
+	for _, v := range views {
+		intervals := make([]interval, len(v.children))
+		for i, c := range v.children {
+			intervals[i] = interval{c.start, c.end}
+		}
+		v.self = v.duration - unionDuration(intervals, v.start, v.end)
+	}
+	return roots, len(order), truncated
+}
+
+func (vs treeViews) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, v := range vs {
+		if err := enc.AppendObject(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *treeView) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("name", v.name)
+	enc.AddDuration("duration", v.duration)
+	enc.AddDuration("self_time", v.self)
+	if v.err != "" {
+		enc.AddString("error", v.err)
+	}
+	if v.unfinished {
+		enc.AddBool("unfinished", true)
+	}
+	if len(v.children) > 0 {
+		return enc.AddArray("children", v.children)
+	}
+	return nil
+}
+
+// interval is a stretch of time a span covered.
+type interval struct {
+	start, end time.Time
+}
+
+// unionDuration returns how much of [from, to] the intervals cover, counting
+// overlapping stretches once.
+func unionDuration(intervals []interval, from, to time.Time) time.Duration {
+	if len(intervals) == 0 {
+		return 0
+	}
+	clipped := make([]interval, 0, len(intervals))
+	for _, iv := range intervals {
+		if iv.start.Before(from) {
+			iv.start = from
+		}
+		if iv.end.After(to) {
+			iv.end = to
+		}
+		if iv.end.After(iv.start) {
+			clipped = append(clipped, iv)
+		}
+	}
+	sort.Slice(clipped, func(i, j int) bool { return clipped[i].start.Before(clipped[j].start) })
+
+	var total time.Duration
+	var cur interval
+	for i, iv := range clipped {
+		switch {
+		case i == 0:
+			cur = iv
+		case !iv.start.After(cur.end):
+			if iv.end.After(cur.end) {
+				cur.end = iv.end
+			}
+		default:
+			total += cur.end.Sub(cur.start)
+			cur = iv
+		}
+	}
+	if len(clipped) > 0 {
+		total += cur.end.Sub(cur.start)
+	}
+	return total
+}
+
+// maxSpanChildren bounds the child intervals a span keeps for self_time.
+const maxSpanChildren = 1024
+
+// spanChildren collects the intervals of a span's direct children that
+// finish before it does.
+type spanChildren struct {
+	mu        sync.Mutex
+	intervals []interval
+	closed    bool
+}
+
+// add records a finished child. Children finishing after their parent, or
+// beyond the cap, are left out.
+func (c *spanChildren) add(iv interval) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed && len(c.intervals) < maxSpanChildren {
+		c.intervals = append(c.intervals, iv)
+	}
+}
+
+// close stops collecting and returns how much of [from, to] the children
+// covered.
+func (c *spanChildren) close(from, to time.Time) time.Duration {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return unionDuration(c.intervals, from, to)
+}
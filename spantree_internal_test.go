@@ -0,0 +1,31 @@
+package jogger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpanChildrenSelfTime(t *testing.T) {
+	at := func(ms int) time.Time { return time.Unix(0, 0).Add(time.Duration(ms) * time.Millisecond) }
+	for name, tc := range map[string]struct {
+		children []interval
+		covered  time.Duration
+	}{
+		"sequential": {[]interval{{at(10), at(30)}, {at(40), at(60)}}, 40 * time.Millisecond},
+		"nested":     {[]interval{{at(10), at(80)}}, 70 * time.Millisecond},
+		"concurrent": {[]interval{{at(10), at(50)}, {at(20), at(60)}, {at(30), at(40)}}, 50 * time.Millisecond},
+		"overrun":    {[]interval{{at(90), at(150)}}, 10 * time.Millisecond},
+	} {
+		c := &spanChildren{}
+		for _, iv := range tc.children {
+			c.add(iv)
+		}
+		if got := c.close(at(0), at(100)); got != tc.covered {
+			t.Errorf("%s: expected %v covered by children, got %v", name, tc.covered, got)
+		}
+		c.add(interval{at(0), at(100)})
+		if got := c.close(at(0), at(100)); got != tc.covered {
+			t.Errorf("%s: expected a child finishing after close to be left out, got %v", name, got)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package jogger_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+)
+
+func TestEmitSpanTree(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	ctx := jogger.CollectSpanTree(jogger.WithRequestID(context.Background(), "req-tree"))
+
+	root, rctx := jogger.StartSpan(ctx, "handler")
+	first, _ := jogger.StartSpan(rctx, "db.query")
+	time.Sleep(5 * time.Millisecond)
+	err := errors.New("timeout")
+	first.Finish(&err)
+	second, sctx := jogger.StartSpan(rctx, "cache.get")
+	open, _ := jogger.StartSpan(sctx, "cache.dial")
+	second.Finish(nil)
+	root.Finish(nil)
+	defer open.Finish(nil)
+	buf.Reset()
+
+	jogger.EmitSpanTree(ctx)
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 1 || entries[0]["msg"] != "span tree" || entries[0]["spanCount"] != float64(4) {
+		t.Fatalf("expected one span tree entry, got %v", entries)
+	}
+	roots := entries[0]["spans"].([]interface{})
+	if len(roots) != 1 {
+		t.Fatalf("expected one root, got %v", roots)
+	}
+	handler := roots[0].(map[string]interface{})
+	children := handler["children"].([]interface{})
+	if handler["name"] != "handler" || len(children) != 2 {
+		t.Fatalf("expected handler with two children, got %v", handler)
+	}
+	db, cache := children[0].(map[string]interface{}), children[1].(map[string]interface{})
+	if db["name"] != "db.query" || db["error"] != "timeout" || cache["name"] != "cache.get" {
+		t.Errorf("expected the children in start order, got %v and %v", db, cache)
+	}
+	if handler["self_time"].(float64) >= handler["duration"].(float64)-db["duration"].(float64) {
+		t.Errorf("expected the children's time left out of the self time, got %v", handler)
+	}
+	dial := cache["children"].([]interface{})[0].(map[string]interface{})
+	if dial["unfinished"] != true {
+		t.Errorf("expected the open span to be marked unfinished, got %v", dial)
+	}
+}
+
+func TestSpanFinishSelfTime(t *testing.T) {
+	buf := configureSyncBuffer(t)
+
+	root, rctx := jogger.StartSpan(context.Background(), "handler")
+	// Sequential children, the second with a nested grandchild.
+	first, _ := jogger.StartSpan(rctx, "first")
+	time.Sleep(10 * time.Millisecond)
+	first.Finish(nil)
+	second, sctx := jogger.StartSpan(rctx, "second")
+	nested, _ := jogger.StartSpan(sctx, "nested")
+	time.Sleep(10 * time.Millisecond)
+	nested.Finish(nil)
+	second.Finish(nil)
+	// Concurrent children overlapping each other.
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s, _ := jogger.StartSpan(rctx, "concurrent")
+			time.Sleep(20 * time.Millisecond)
+			s.Finish(nil)
+		}()
+	}
+	wg.Wait()
+	late, _ := jogger.StartSpan(rctx, "late")
+	root.Finish(nil)
+	late.Finish(nil)
+
+	spans := map[string]map[string]interface{}{}
+	for _, e := range decodeEntries(t, bytes.NewBufferString(buf.String())) {
+		spans[e["span"].(string)] = e
+	}
+	handler, secondEntry := spans["handler"], spans["second"]
+	if handler == nil || secondEntry == nil {
+		t.Fatalf("expected finish entries for the spans, got %v", spans)
+	}
+	if self, d := secondEntry["self_time"].(float64), secondEntry["duration"].(float64); self >= d-spans["nested"]["duration"].(float64)+0.002 {
+		t.Errorf("expected the nested child left out of second's self time, got %v of %v", self, d)
+	}
+	// The concurrent children cover about 20ms together; subtracting their
+	// summed 60ms would leave the self time near or below zero.
+	self, d := handler["self_time"].(float64), handler["duration"].(float64)
+	if self < 0 || self > d-0.035 {
+		t.Errorf("expected the union of the children left out of the handler's self time, got %v of %v", self, d)
+	}
+	if s := spans["late"]["self_time"]; s != spans["late"]["duration"] {
+		t.Errorf("expected a childless span's self time to equal its duration, got %v", spans["late"])
+	}
+}
+
+func TestEmitSpanTreeTruncates(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	ctx := jogger.CollectSpanTree(context.Background())
+	for i := 0; i < 300; i++ {
+		span, _ := jogger.StartSpan(ctx, "step", jogger.SilentOnSuccess(true))
+		span.Finish(nil)
+	}
+
+	jogger.EmitSpanTree(ctx)
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 1 || entries[0]["spanCount"] != float64(256) || entries[0]["truncatedSpans"] != float64(44) {
+		t.Errorf("expected the tree capped at 256 spans, got %v", entries)
+	}
+}
+
+func TestMiddlewareSpanTree(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	h := jogger.Middleware(jogger.SpanTree(true))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span, _ := jogger.StartSpan(r.Context(), "work", jogger.SilentOnSuccess(true))
+		span.Finish(nil)
+	}))
+	serve(t, h, httptest.NewRequest("GET", "/", nil))
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 2 || entries[0]["msg"] != "span tree" || entries[0]["requestID"] != entries[1]["requestID"] {
+		t.Errorf("expected the span tree before the access log, got %v", entries)
+	}
+}
@@ -0,0 +1,254 @@
+package jogger
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+const defaultMaxSQLLength = 2048
+
+var maxSQLLength = int64(defaultMaxSQLLength)
+
+// SetMaxSQLLength sets the length in bytes beyond which SanitizeSQL
+// truncates queries. Non-positive values restore the default of 2048.
+func SetMaxSQLLength(n int) {
+	if n <= 0 {
+		n = defaultMaxSQLLength
+	}
+	atomic.StoreInt64(&maxSQLLength, int64(n))
+}
+
+// SQL logs query under key after SanitizeSQL, which runs only if the entry
+// is encoded.
+func SQL(key, query string) zap.Field {
+	return zap.Stringer(key, sanitizedSQL(query))
+}
+
+type sanitizedSQL string
+
+func (q sanitizedSQL) String() string {
+	return SanitizeSQL(string(q))
+}
+
+// inList matches an IN list made only of placeholders, as left by the
+// literal replacement.
+var inList = regexp.MustCompile(`(?i)\bIN\s*\(\?(?: ?, ?\?)+\)`)
+
+// SanitizeSQL returns query with string, number and dollar-quoted literals
+// replaced by ?, comments removed, whitespace collapsed, IN lists of
+// several values shortened to "IN (?, ... N items)" and the result
+// truncated past the length set with SetMaxSQLLength. Quoted identifiers
+// and bind parameters such as $1 are kept.
+func SanitizeSQL(query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+	space := false
+	emit := func(s string) {
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteString(s)
+	}
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case isSQLSpace(c):
+			space = true
+			i++
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			i += end
+			space = true
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			i = skipBlockComment(query, i)
+			space = true
+		case c == '\'':
+			i = skipQuoted(query, i, '\'', true)
+			emit("?")
+		case c == '"' || c == '`':
+			end := skipQuoted(query, i, c, false)
+			emit(query[i:end])
+			i = end
+		case c == '[':
+			end, ok := bracketIdent(query, i)
+			if !ok {
+				// An array or map subscript, whose literals are replaced
+				// like any other.
+				emit("[")
+				i++
+				break
+			}
+			emit(query[i:end])
+			i = end
+		case c == '$':
+			if end, ok := skipDollarQuoted(query, i); ok {
+				emit("?")
+				i = end
+				break
+			}
+			end := i + 1
+			for end < len(query) && isSQLIdent(query[end]) {
+				end++
+			}
+			emit(query[i:end])
+			i = end
+		case isSQLDigit(c) || (c == '.' && i+1 < len(query) && isSQLDigit(query[i+1])):
+			i = skipNumber(query, i)
+			emit("?")
+		case isSQLIdent(c):
+			end := i
+			for end < len(query) && isSQLIdent(query[end]) {
+				end++
+			}
+			if end == i+1 && end < len(query) && query[end] == '\'' && strings.IndexByte("EeNnBbXx", c) >= 0 {
+				// Prefixed literal such as E'\n' or X'ff'.
+				i = end
+				break
+			}
+			emit(query[i:end])
+			i = end
+		default:
+			emit(query[i : i+1])
+			i++
+		}
+	}
+
+	out := inList.ReplaceAllStringFunc(b.String(), func(m string) string {
+		return m[:2] + " (?, ... " + strconv.Itoa(strings.Count(m, "?")) + " items)"
+	})
+	if max := int(atomic.LoadInt64(&maxSQLLength)); len(out) > max {
+		out = truncateString(out, max)
+	}
+	return out
+}
+
+// skipQuoted returns the index after the quote character q that closes the
+// literal starting at start. A doubled quote is an escaped quote, as is a
+// backslash-escaped one when backslash is set. Treating backslashes as
+// escapes in dialects where they are not can only hide more of the query,
+// never reveal a literal.
+func skipQuoted(s string, start int, q byte, backslash bool) int {
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if backslash {
+				i++
+			}
+		case q:
+			if i+1 < len(s) && s[i+1] == q {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// bracketIdent reports whether a bracket-quoted identifier such as
+// [user id] starts at start, and where it ends. Brackets holding a quote,
+// or starting with a digit or sign, are subscripts such as ARRAY['x'] or
+// data[1], not identifiers.
+func bracketIdent(s string, start int) (int, bool) {
+	end := strings.IndexByte(s[start:], ']')
+	if end <= 1 {
+		return 0, false
+	}
+	name := s[start+1 : start+end]
+	if isSQLDigit(name[0]) || strings.IndexByte("+-.", name[0]) >= 0 || strings.ContainsAny(name, "'\"`$") {
+		return 0, false
+	}
+	return start + end + 1, true
+}
+
+// skipBlockComment returns the index after the comment starting at start,
+// honoring nested comments as PostgreSQL does.
+func skipBlockComment(s string, start int) int {
+	depth := 0
+	for i := start; i < len(s)-1; i++ {
+		switch {
+		case s[i] == '/' && s[i+1] == '*':
+			depth++
+			i++
+		case s[i] == '*' && s[i+1] == '/':
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(s)
+}
+
+// skipDollarQuoted reports whether a PostgreSQL dollar-quoted string such
+// as $$text$$ or $tag$text$tag$ starts at start, and where it ends.
+func skipDollarQuoted(s string, start int) (int, bool) {
+	i := start + 1
+	if i < len(s) && isSQLDigit(s[i]) {
+		return 0, false
+	}
+	for i < len(s) && isSQLIdent(s[i]) {
+		i++
+	}
+	if i >= len(s) || s[i] != '$' {
+		return 0, false
+	}
+	tag := s[start : i+1]
+	end := strings.Index(s[i+1:], tag)
+	if end < 0 {
+		return len(s), true
+	}
+	return i + 1 + end + len(tag), true
+}
+
+func skipNumber(s string, start int) int {
+	i := start
+	if strings.HasPrefix(s[i:], "0x") || strings.HasPrefix(s[i:], "0X") {
+		i += 2
+		for i < len(s) && strings.IndexByte("0123456789abcdefABCDEF", s[i]) >= 0 {
+			i++
+		}
+		return i
+	}
+	for i < len(s) && (isSQLDigit(s[i]) || s[i] == '.') {
+		i++
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		j := i + 1
+		if j < len(s) && (s[j] == '+' || s[j] == '-') {
+			j++
+		}
+		if j < len(s) && isSQLDigit(s[j]) {
+			i = j
+			for i < len(s) && isSQLDigit(s[i]) {
+				i++
+			}
+		}
+	}
+	return i
+}
+
+func isSQLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isSQLDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isSQLIdent reports whether c can be part of an unquoted identifier.
+// Bytes of multi-byte UTF-8 sequences count, so non-ASCII names are
+// copied whole.
+func isSQLIdent(c byte) bool {
+	return c == '_' || c >= 0x80 || isSQLDigit(c) || (c|0x20 >= 'a' && c|0x20 <= 'z')
+}
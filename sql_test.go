@@ -0,0 +1,68 @@
+package jogger_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+)
+
+func TestSanitizeSQL(t *testing.T) {
+	cases := []struct {
+		name, in, want string
+	}{
+		{"plain", "SELECT id FROM users", "SELECT id FROM users"},
+		{"string literal", "SELECT * FROM users WHERE email = 'ann@example.com'", "SELECT * FROM users WHERE email = ?"},
+		{"numbers", "UPDATE t SET a = 42, b = -3.5e10, c = .5, d = 0xFF", "UPDATE t SET a = ?, b = -?, c = ?, d = ?"},
+		{"identifiers with digits", "SELECT t1.col2 FROM t1", "SELECT t1.col2 FROM t1"},
+		{"doubled quote escape", "SELECT 'it''s' , 'x'", "SELECT ? , ?"},
+		{"backslash escape", `SELECT 'a\'b', 'c'`, "SELECT ?, ?"},
+		{"quote inside comment", "SELECT 1 -- don't\nFROM t", "SELECT ? FROM t"},
+		{"block comment", "SELECT /* secret 'x' */ a FROM t", "SELECT a FROM t"},
+		{"nested comment", "SELECT /* a /* b */ c */ x", "SELECT x"},
+		{"comment at end", "SELECT a -- trailing", "SELECT a"},
+		{"double quoted identifier", `SELECT "weird ""name"" 42" FROM "T1"`, `SELECT "weird ""name"" 42" FROM "T1"`},
+		{"backtick identifier", "SELECT `order`, `x'y` FROM `t`", "SELECT `order`, `x'y` FROM `t`"},
+		{"bracket identifier", "SELECT [user id] FROM [t 1]", "SELECT [user id] FROM [t 1]"},
+		{"array literal", "SELECT ARRAY['ssn-123'] FROM t", "SELECT ARRAY[?] FROM t"},
+		{"map subscript", "SELECT data['secret_token'] FROM t", "SELECT data[?] FROM t"},
+		{"array index", "SELECT tags[2], m[ 'k' ] FROM t", "SELECT tags[?], m[ ? ] FROM t"},
+		{"unterminated bracket", "SELECT a[ 'x'", "SELECT a[ ?"},
+		{"bind parameters kept", "SELECT * FROM t WHERE a = $1 AND b = ? AND c = :name", "SELECT * FROM t WHERE a = $1 AND b = ? AND c = :name"},
+		{"dollar quoted", "SELECT $$it's $1$$, $fn$body 'x'$fn$", "SELECT ?, ?"},
+		{"prefixed literals", `SELECT E'\n', N'name', X'ff', col`, "SELECT ?, ?, ?, col"},
+		{"whitespace", "  SELECT\n\ta\r\n  FROM   t \n", "SELECT a FROM t"},
+		{"in list", "SELECT * FROM t WHERE id IN (1, 2, 3)", "SELECT * FROM t WHERE id IN (?, ... 3 items)"},
+		{"in list compact", "select * from t where id in ('a','b')", "select * from t where id in (?, ... 2 items)"},
+		{"in list without space", "SELECT * FROM t WHERE id IN(1,2,3)", "SELECT * FROM t WHERE id IN (?, ... 3 items)"},
+		{"single in", "SELECT * FROM t WHERE id IN (7)", "SELECT * FROM t WHERE id IN (?)"},
+		{"in subquery", "SELECT * FROM t WHERE id IN (SELECT id FROM u WHERE x = 1)", "SELECT * FROM t WHERE id IN (SELECT id FROM u WHERE x = ?)"},
+		{"unterminated string", "SELECT 'oops", "SELECT ?"},
+		{"unicode identifier", "SELECT prénom FROM gens WHERE âge > 30", "SELECT prénom FROM gens WHERE âge > ?"},
+	}
+	for _, c := range cases {
+		if got := jogger.SanitizeSQL(c.in); got != c.want {
+			t.Errorf("%s:\n  in   %q\n  got  %q\n  want %q", c.name, c.in, got, c.want)
+		}
+	}
+}
+
+func TestSanitizeSQLTruncates(t *testing.T) {
+	jogger.SetMaxSQLLength(20)
+	defer jogger.SetMaxSQLLength(0)
+
+	got := jogger.SanitizeSQL("SELECT a, b, c, d, e, f FROM t")
+	if !strings.HasPrefix(got, "SELECT a, b, c, d, e") || !strings.Contains(got, "truncated, was 30 bytes") {
+		t.Errorf("unexpected truncation %q", got)
+	}
+}
+
+func TestSQLField(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	jogger.FromContext(nil).Info("query", jogger.SQL("query", "SELECT * FROM t WHERE token = 'secret'"))
+
+	if e := decodeEntries(t, buf)[0]; e["query"] != "SELECT * FROM t WHERE token = ?" {
+		t.Errorf("unexpected query field %v", e["query"])
+	}
+}
@@ -0,0 +1,153 @@
+package jogger
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Formats of the stack traces of Stack and WithStacktrace.
+const (
+	// StackFormatArray renders one "pkg.Func dir/file.go:line" string per
+	// frame.
+	StackFormatArray = "array"
+	// StackFormatString renders the same frames as one newline-separated
+	// string.
+	StackFormatString = "string"
+)
+
+const defaultStackFrames = 32
+
+// StackConfig sets up the stack traces added by WithStacktrace.
+type StackConfig struct {
+	// Level is the lowest level whose entries get a stacktrace field.
+	Level zapcore.Level
+	// MaxFrames caps the number of frames, 32 when zero.
+	MaxFrames int
+	// Format is StackFormatArray, the default, or StackFormatString.
+	Format string
+}
+
+// WithStacktrace adds a stacktrace field to entries at sc.Level and above.
+// Frames of jogger, zap and the runtime are left out, so the trace starts
+// at the code that logged. The same settings apply to Stack.
+func WithStacktrace(sc StackConfig) Option {
+	return func(c *config) error {
+		if sc.Level < zapcore.DebugLevel || sc.Level > zapcore.FatalLevel {
+			return errors.New("jogger: invalid stacktrace level")
+		}
+		if sc.MaxFrames < 0 {
+			return errors.New("jogger: negative stacktrace depth")
+		}
+		if sc.MaxFrames == 0 {
+			sc.MaxFrames = defaultStackFrames
+		}
+		switch sc.Format {
+		case "":
+			sc.Format = StackFormatArray
+		case StackFormatArray, StackFormatString:
+		default:
+			return errors.New("jogger: unknown stacktrace format " + strconv.Quote(sc.Format))
+		}
+		c.stack = &sc
+		return nil
+	}
+}
+
+// Stack returns a field with the stack trace of its caller, skipping skip
+// more frames, such as those of a logging helper. It is filtered, capped
+// and formatted like the traces of WithStacktrace, using the settings of
+// the default instance.
+func Stack(key string, skip int) zap.Field {
+	sc := currentOutput().cfg.stack
+	if sc == nil {
+		sc = &StackConfig{MaxFrames: defaultStackFrames, Format: StackFormatArray}
+	}
+	return sc.field(key, captureStack(skip+1, sc.MaxFrames))
+}
+
+func (sc *StackConfig) field(key string, frames []string) zap.Field {
+	if sc.Format == StackFormatString {
+		return zap.String(key, strings.Join(frames, "\n"))
+	}
+	return zap.Strings(key, frames)
+}
+
+// joggerPackage is the import path of this package followed by a dot, the
+// prefix of the names of its functions.
+var joggerPackage = reflect.TypeOf(StackConfig{}).PkgPath() + "."
+
+// internalFrame reports whether fn belongs to jogger, zap or the runtime.
+func internalFrame(fn string) bool {
+	return strings.HasPrefix(fn, "runtime.") ||
+		strings.HasPrefix(fn, joggerPackage) ||
+		strings.HasPrefix(fn, "go.uber.org/zap")
+}
+
+// captureStack returns up to max frames of the caller's stack, skipping
+// skip frames above it and every internal frame.
+func captureStack(skip, max int) []string {
+	pcs := make([]uintptr, max+64)
+	n := runtime.Callers(skip+2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	out := make([]string, 0, max)
+	for len(out) < max {
+		f, more := frames.Next()
+		if f.Function != "" && !internalFrame(f.Function) {
+			out = append(out, formatFrame(f))
+		}
+		if !more {
+			break
+		}
+	}
+	return out
+}
+
+// formatFrame renders f as "pkg.Func dir/file.go:line".
+func formatFrame(f runtime.Frame) string {
+	fn := f.Function
+	if i := strings.LastIndexByte(fn, '/'); i >= 0 {
+		fn = fn[i+1:]
+	}
+	file := f.File
+	if i := strings.LastIndexByte(file, '/'); i >= 0 {
+		if j := strings.LastIndexByte(file[:i], '/'); j >= 0 {
+			file = file[j+1:]
+		}
+	}
+	return fn + " " + file + ":" + strconv.Itoa(f.Line)
+}
+
+// stackCore adds the stacktrace field of WithStacktrace. It sits right
+// around the sinks, so traces are only captured for entries that are
+// written.
+type stackCore struct {
+	zapcore.Core
+	cfg *StackConfig
+}
+
+func (c stackCore) With(fields []zapcore.Field) zapcore.Core {
+	return stackCore{c.Core.With(fields), c.cfg}
+}
+
+func (c stackCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c stackCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if ent.Level >= c.cfg.Level && !hasField(fields, "stacktrace") {
+		if frames := captureStack(0, c.cfg.MaxFrames); len(frames) > 0 {
+			fields = append(fields[:len(fields):len(fields)], c.cfg.field("stacktrace", frames))
+		}
+	}
+	return c.Core.Write(ent, fields)
+}
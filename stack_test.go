@@ -0,0 +1,71 @@
+package jogger_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestWithStacktrace(t *testing.T) {
+	buf := configureBuffer(t,
+		jogger.WithFormat(jogger.FormatJSON),
+		jogger.WithStacktrace(jogger.StackConfig{Level: zapcore.ErrorLevel, MaxFrames: 2}),
+	)
+
+	jogger.Warn(context.Background(), "no stack")
+	jogger.Error(context.Background(), "with stack")
+
+	entries := decodeEntries(t, buf)
+	if _, ok := entries[0]["stacktrace"]; ok {
+		t.Errorf("expected no stack below the level, got %v", entries[0])
+	}
+	frames, ok := entries[1]["stacktrace"].([]interface{})
+	if !ok || len(frames) != 2 {
+		t.Fatalf("expected two frames, got %v", entries[1]["stacktrace"])
+	}
+	first := frames[0].(string)
+	if !strings.HasPrefix(first, "jogger_test.TestWithStacktrace ") || !strings.Contains(first, "/stack_test.go:") {
+		t.Errorf("expected the first frame to be the test, got %q", first)
+	}
+	for _, f := range frames {
+		if strings.Contains(f.(string), "zap") || strings.HasPrefix(f.(string), "jogger.") {
+			t.Errorf("expected jogger and zap frames to be left out, got %q", f)
+		}
+	}
+}
+
+func logWithStack(ctx context.Context) {
+	jogger.Info(ctx, "helper", jogger.Stack("stack", 1))
+}
+
+func TestStackField(t *testing.T) {
+	buf := configureBuffer(t,
+		jogger.WithFormat(jogger.FormatJSON),
+		jogger.WithStacktrace(jogger.StackConfig{Level: zapcore.FatalLevel, Format: jogger.StackFormatString}),
+	)
+
+	logWithStack(context.Background())
+	jogger.Info(context.Background(), "direct", jogger.Stack("stack", 0), zap.Int("n", 1))
+
+	entries := decodeEntries(t, buf)
+	helper, _ := entries[0]["stack"].(string)
+	if !strings.HasPrefix(helper, "jogger_test.TestStackField ") {
+		t.Errorf("expected skip to leave out the helper, got %q", helper)
+	}
+	direct, _ := entries[1]["stack"].(string)
+	if !strings.HasPrefix(direct, "jogger_test.TestStackField ") || !strings.Contains(direct, "\n") {
+		t.Errorf("expected a newline-separated trace starting at the test, got %q", direct)
+	}
+}
+
+func TestWithStacktraceInvalid(t *testing.T) {
+	for _, sc := range []jogger.StackConfig{{Level: zapcore.Level(42)}, {MaxFrames: -1}, {Format: "xml"}} {
+		if err := jogger.Configure(jogger.WithStacktrace(sc)); err == nil {
+			t.Errorf("expected an error for %+v", sc)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package jogger
+
+import (
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Statistics is a point-in-time copy of the logger's counters.
+type Statistics struct {
+	Entries    map[string]uint64 `json:"entries"`
+	Dropped    DropCounts        `json:"dropped"`
+	SinkErrors uint64            `json:"sinkErrors"`
+	// LateTags counts span tags dropped because they were set after
+	// Finish.
+	LateTags uint64 `json:"lateTags"`
+	// CorrelationMissing counts entries flagged by WithRequireRequestID.
+	CorrelationMissing uint64     `json:"correlationMissing"`
+	LastError          *LastError `json:"lastError,omitempty"`
+	// Incident is set while incident mode is on, see SetIncidentMode.
+	Incident *IncidentStatus `json:"incident,omitempty"`
+	// ErrorBudgets holds the span outcomes by name with WithErrorBudget.
+	ErrorBudgets map[string]SpanBudget `json:"errorBudgets,omitempty"`
+}
+
+// DropCounts counts entries that were not written, by reason.
+type DropCounts struct {
+	// Sampling counts the Info access logs and span finishes of requests
+	// sampled out by WithSampling, and the entries WithTailSampling
+	// discarded for a successful request.
+	Sampling uint64 `json:"sampling"`
+	// Overflow counts entries dropped because a buffer was full: that of
+	// a network output, of WithTailSampling or of a sink writer calling
+	// CountOverflow.
+	Overflow uint64 `json:"overflow"`
+	// Suppressed counts entries dropped by SuppressMatching.
+	Suppressed uint64 `json:"suppressed"`
+}
+
+// LastError describes the most recent entry written at Error level or above.
+type LastError struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+type dropReason int
+
+const (
+	dropSampling dropReason = iota
+	dropOverflow
+	dropSuppressed
+)
+
+const numLevels = int(zapcore.FatalLevel-zapcore.DebugLevel) + 1
+
+// counters is kept as a package variable so the 64-bit words are aligned for
+// atomic access on 32-bit platforms.
+type counters struct {
+	entries            [numLevels]uint64
+	sampling           uint64
+	overflow           uint64
+	suppressed         uint64
+	sinkErrors         uint64
+	lateTags           uint64
+	correlationMissing uint64
+	lastError          atomic.Value
+}
+
+var stats counters
+
+// Stats returns the current logging counters.
+func Stats() Statistics {
+	s := Statistics{
+		Entries: make(map[string]uint64, numLevels),
+		Dropped: DropCounts{
+			Sampling:   atomic.LoadUint64(&stats.sampling),
+			Overflow:   atomic.LoadUint64(&stats.overflow),
+			Suppressed: atomic.LoadUint64(&stats.suppressed),
+		},
+		SinkErrors:         atomic.LoadUint64(&stats.sinkErrors),
+		LateTags:           atomic.LoadUint64(&stats.lateTags),
+		CorrelationMissing: atomic.LoadUint64(&stats.correlationMissing),
+	}
+	for i := range stats.entries {
+		lvl := zapcore.Level(i) + zapcore.DebugLevel
+		s.Entries[lvl.String()] = atomic.LoadUint64(&stats.entries[i])
+	}
+	if last, ok := stats.lastError.Load().(*LastError); ok && last != nil {
+		copied := *last
+		s.LastError = &copied
+	}
+	if st := CurrentIncident(); st.Active {
+		s.Incident = &st
+	}
+	s.ErrorBudgets = errorBudgets()
+	return s
+}
+
+// ResetStats zeroes every counter. It is intended for tests.
+func ResetStats() {
+	for i := range stats.entries {
+		atomic.StoreUint64(&stats.entries[i], 0)
+	}
+	atomic.StoreUint64(&stats.sampling, 0)
+	atomic.StoreUint64(&stats.overflow, 0)
+	atomic.StoreUint64(&stats.suppressed, 0)
+	atomic.StoreUint64(&stats.sinkErrors, 0)
+	atomic.StoreUint64(&stats.lateTags, 0)
+	atomic.StoreUint64(&stats.correlationMissing, 0)
+	stats.lastError.Store((*LastError)(nil))
+}
+
+// CountOverflow adds n to Stats().Dropped.Overflow. Sink writers that
+// buffer entries, such as the joggerotlp exporter, call it for the entries
+// they drop because the buffer is full.
+func CountOverflow(n int) {
+	if n > 0 {
+		atomic.AddUint64(&stats.overflow, uint64(n))
+	}
+}
+
+func countDrop(reason dropReason) {
+	switch reason {
+	case dropSampling:
+		atomic.AddUint64(&stats.sampling, 1)
+	case dropOverflow:
+		atomic.AddUint64(&stats.overflow, 1)
+	case dropSuppressed:
+		atomic.AddUint64(&stats.suppressed, 1)
+	}
+}
+
+func countEntry(ent zapcore.Entry) {
+	i := int(ent.Level - zapcore.DebugLevel)
+	if i >= 0 && i < numLevels {
+		atomic.AddUint64(&stats.entries[i], 1)
+	}
+	if ent.Level >= zapcore.ErrorLevel {
+		stats.lastError.Store(&LastError{Time: ent.Time, Message: ent.Message})
+	}
+}
+
+// statsCore counts entries as they are written to the wrapped core. Write
+// failures are counted and handed to the internal error reporter instead of
+// being returned, so they are reported exactly once.
+type statsCore struct {
+	zapcore.Core
+}
+
+func newStatsCore(c zapcore.Core) zapcore.Core {
+	return &statsCore{Core: c}
+}
+
+func (c *statsCore) With(fields []zapcore.Field) zapcore.Core {
+	return &statsCore{Core: c.Core.With(fields)}
+}
+
+func (c *statsCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *statsCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if err := c.Core.Write(ent, fields); err != nil {
+		atomic.AddUint64(&stats.sinkErrors, 1)
+		reportInternalError(err)
+		return nil
+	}
+	countEntry(ent)
+	return nil
+}
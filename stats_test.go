@@ -0,0 +1,89 @@
+package jogger_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestStatsCountsEntriesByLevel(t *testing.T) {
+	jogger.ResetStats()
+	defer jogger.ResetStats()
+
+	ctx := jogger.WithRequestID(context.Background(), "stats-test")
+	jogger.Debug(ctx, "not written at info level")
+	jogger.Info(ctx, "first")
+	jogger.Info(ctx, "second")
+	jogger.Warn(ctx, "careful")
+	jogger.Error(ctx, "broken")
+
+	s := jogger.Stats()
+	want := map[string]uint64{"debug": 0, "info": 2, "warn": 1, "error": 1}
+	for lvl, n := range want {
+		if s.Entries[lvl] != n {
+			t.Errorf("expected %d %s entries, got %d", n, lvl, s.Entries[lvl])
+		}
+	}
+	if s.LastError == nil || s.LastError.Message != "broken" {
+		t.Fatalf("expected last error 'broken', got %+v", s.LastError)
+	}
+	if s.LastError.Time.IsZero() {
+		t.Error("expected last error timestamp")
+	}
+}
+
+func TestStatsCountsSpanFinishes(t *testing.T) {
+	jogger.ResetStats()
+	defer jogger.ResetStats()
+
+	span, _ := jogger.StartSpan(context.Background(), "stats-span")
+	err := errors.New("span failed")
+	span.Finish(&err)
+
+	s := jogger.Stats()
+	if s.Entries[zapcore.ErrorLevel.String()] != 1 {
+		t.Errorf("expected 1 error entry, got %d", s.Entries["error"])
+	}
+	if s.LastError == nil || s.LastError.Message != "span finished with error" {
+		t.Errorf("unexpected last error %+v", s.LastError)
+	}
+}
+
+func TestStatsReset(t *testing.T) {
+	jogger.Error(context.Background(), "before reset")
+	jogger.ResetStats()
+
+	s := jogger.Stats()
+	for lvl, n := range s.Entries {
+		if n != 0 {
+			t.Errorf("expected 0 %s entries after reset, got %d", lvl, n)
+		}
+	}
+	if s.LastError != nil {
+		t.Errorf("expected no last error after reset, got %+v", s.LastError)
+	}
+}
+
+func TestStatsJSON(t *testing.T) {
+	jogger.ResetStats()
+	defer jogger.ResetStats()
+	jogger.Error(context.Background(), "json error")
+
+	b, err := json.Marshal(jogger.Stats())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"entries", "dropped", "sinkErrors", "lastError"} {
+		if _, ok := out[key]; !ok {
+			t.Errorf("expected %q in %s", key, b)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package jogger
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// maxSummaryKeys bounds the fingerprints and span names an exit summary
+// tracks; later ones are left out.
+const maxSummaryKeys = 1000
+
+const summaryTop = 5
+
+// WithExitSummary makes Shutdown write a summary of the run to w, such as
+// os.Stderr for a CLI tool: entries by level, the five most frequent error
+// fingerprints, the five slowest span names by their longest duration, and
+// the dropped entry counts. It is off by default; when off it costs
+// nothing.
+func WithExitSummary(w io.Writer) Option {
+	return func(c *config) error {
+		if w == nil {
+			return fmt.Errorf("jogger: nil exit summary writer")
+		}
+		c.exitSummary = w
+		return nil
+	}
+}
+
+type fingerprintCount struct {
+	message string
+	count   int
+}
+
+// exitSummary accumulates what WithExitSummary reports. Entry and drop
+// counts come from the stats counters, relative to when it was created.
+type exitSummary struct {
+	w     io.Writer
+	since Statistics
+
+	mu           sync.Mutex
+	fingerprints map[string]*fingerprintCount
+	spans        map[string]time.Duration
+}
+
+func newExitSummary(w io.Writer) *exitSummary {
+	if w == nil {
+		return nil
+	}
+	return &exitSummary{
+		w:            w,
+		since:        Stats(),
+		fingerprints: map[string]*fingerprintCount{},
+		spans:        map[string]time.Duration{},
+	}
+}
+
+func (s *exitSummary) countError(fp, msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.fingerprints[fp]; ok {
+		c.count++
+	} else if len(s.fingerprints) < maxSummaryKeys {
+		s.fingerprints[fp] = &fingerprintCount{message: msg, count: 1}
+	}
+}
+
+func (s *exitSummary) observeSpan(fs finishedSpan) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d, ok := s.spans[fs.name]; ok || len(s.spans) < maxSummaryKeys {
+		if fs.duration > d {
+			s.spans[fs.name] = fs.duration
+		}
+	}
+}
+
+// render writes the summary table.
+func (s *exitSummary) render() error {
+	now := Stats()
+	tw := tabwriter.NewWriter(s.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "jogger summary")
+
+	fmt.Fprint(tw, "entries")
+	for l := zapcore.DebugLevel; l <= zapcore.FatalLevel; l++ {
+		fmt.Fprintf(tw, "\t%s %d", l, now.Entries[l.String()]-s.since.Entries[l.String()])
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintf(tw, "dropped\tsampling %d\toverflow %d\tsuppressed %d\tlate tags %d\n",
+		now.Dropped.Sampling-s.since.Dropped.Sampling,
+		now.Dropped.Overflow-s.since.Dropped.Overflow,
+		now.Dropped.Suppressed-s.since.Dropped.Suppressed,
+		now.LateTags-s.since.LateTags)
+
+	s.mu.Lock()
+	fps := make([]string, 0, len(s.fingerprints))
+	for fp := range s.fingerprints {
+		fps = append(fps, fp)
+	}
+	sort.Slice(fps, func(i, j int) bool {
+		a, b := s.fingerprints[fps[i]], s.fingerprints[fps[j]]
+		if a.count != b.count {
+			return a.count > b.count
+		}
+		return fps[i] < fps[j]
+	})
+	if len(fps) > 0 {
+		fmt.Fprintln(tw, "top errors")
+	}
+	for i, fp := range fps {
+		if i == summaryTop {
+			break
+		}
+		c := s.fingerprints[fp]
+		fmt.Fprintf(tw, "\t%d\t%s\t%s\n", c.count, fp, c.message)
+	}
+
+	names := make([]string, 0, len(s.spans))
+	for n := range s.spans {
+		names = append(names, n)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if s.spans[names[i]] != s.spans[names[j]] {
+			return s.spans[names[i]] > s.spans[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	if len(names) > 0 {
+		fmt.Fprintln(tw, "slowest spans")
+	}
+	for i, n := range names {
+		if i == summaryTop {
+			break
+		}
+		fmt.Fprintf(tw, "\t%s\t%s\n", s.spans[n], n)
+	}
+	s.mu.Unlock()
+	return tw.Flush()
+}
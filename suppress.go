@@ -0,0 +1,133 @@
+package jogger
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// A SuppressFunc reports whether an entry is noise, such as the access log
+// of a readiness probe, given the entry and all of its fields, including
+// those of With.
+type SuppressFunc func(ent zapcore.Entry, fields []zapcore.Field) bool
+
+type suppression struct {
+	fn SuppressFunc
+}
+
+var (
+	suppressMu   sync.Mutex
+	suppressions atomic.Value // []*suppression, replaced on every change
+)
+
+func init() {
+	suppressions.Store([]*suppression(nil))
+}
+
+// SuppressMatching drops the entries below Warn of every instance for which
+// fn returns true, so probe traffic stays out of the logs while its
+// failures are still written. SuppressPaths and SuppressSpans build fn for
+// the access log of Middleware and for spans. Dropped entries are counted
+// in Stats. The returned function removes the registration.
+func SuppressMatching(fn SuppressFunc) (remove func()) {
+	s := &suppression{fn: fn}
+	suppressMu.Lock()
+	defer suppressMu.Unlock()
+	cur := suppressions.Load().([]*suppression)
+	suppressions.Store(append(cur[:len(cur):len(cur)], s))
+
+	return func() {
+		suppressMu.Lock()
+		defer suppressMu.Unlock()
+		cur := suppressions.Load().([]*suppression)
+		next := make([]*suppression, 0, len(cur))
+		for _, c := range cur {
+			if c != s {
+				next = append(next, c)
+			}
+		}
+		suppressions.Store(next)
+	}
+}
+
+// SuppressPaths matches the access log entries of Middleware for requests
+// to one of paths, such as "/healthz".
+func SuppressPaths(paths ...string) SuppressFunc {
+	return stringFieldIn(func() string { return "path" }, paths)
+}
+
+// SuppressSpans matches the finish entries of spans named one of names.
+// Entries logged within the spans carry their ID rather than their name and
+// are not matched.
+func SuppressSpans(names ...string) SuppressFunc {
+	return stringFieldIn(func() string { return currentOutput().cfg.fieldNames.Span }, names)
+}
+
+func stringFieldIn(key func() string, values []string) SuppressFunc {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return func(_ zapcore.Entry, fields []zapcore.Field) bool {
+		k := key()
+		for _, f := range fields {
+			if f.Key == k && f.Type == zapcore.StringType && set[f.String] {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// suppressed reports whether a registration matches the entry.
+func suppressed(ent zapcore.Entry, fields []zapcore.Field) bool {
+	for _, s := range suppressions.Load().([]*suppression) {
+		if s.fn(ent, fields) {
+			return true
+		}
+	}
+	return false
+}
+
+// suppressCore drops the entries of SuppressMatching and hands the others
+// to the taps of Tap. Like crashCore, it keeps the fields of With itself so
+// that matchers and taps see them.
+type suppressCore struct {
+	zapcore.Core
+	fields []zapcore.Field
+}
+
+func (c *suppressCore) With(fields []zapcore.Field) zapcore.Core {
+	return &suppressCore{
+		Core:   c.Core.With(fields),
+		fields: append(c.fields[:len(c.fields):len(c.fields)], fields...),
+	}
+}
+
+func (c *suppressCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *suppressCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	matching := ent.Level < zapcore.WarnLevel && len(suppressions.Load().([]*suppression)) > 0
+	tapped := taps.Load().([]*tap)
+	if !matching && len(tapped) == 0 {
+		return c.Core.Write(ent, fields)
+	}
+	all := fields
+	if len(c.fields) > 0 {
+		all = append(c.fields[:len(c.fields):len(c.fields)], fields...)
+	}
+	if matching && suppressed(ent, all) {
+		countDrop(dropSuppressed)
+		return nil
+	}
+	for _, tp := range tapped {
+		tp.fn(ent, all)
+	}
+	return c.Core.Write(ent, fields)
+}
@@ -0,0 +1,68 @@
+package jogger_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+)
+
+func TestSuppressPaths(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	remove := jogger.SuppressMatching(jogger.SuppressPaths("/healthz"))
+	defer remove()
+	jogger.ResetStats()
+
+	status := http.StatusOK
+	h := jogger.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	serve(t, h, httptest.NewRequest("GET", "/healthz", nil))
+	serve(t, h, httptest.NewRequest("GET", "/v1/users", nil))
+	status = http.StatusServiceUnavailable
+	serve(t, h, httptest.NewRequest("GET", "/healthz", nil))
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 2 || entries[0]["path"] != "/v1/users" || entries[1]["status"] != float64(503) {
+		t.Errorf("expected the successful probe suppressed, got %v", entries)
+	}
+	if got := jogger.Stats().Dropped.Suppressed; got != 1 {
+		t.Errorf("expected one suppressed entry, got %d", got)
+	}
+}
+
+func TestSuppressSpans(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	remove := jogger.SuppressMatching(jogger.SuppressSpans("db.ping"))
+	defer remove()
+
+	span, _ := jogger.StartSpan(context.Background(), "db.ping")
+	span.Finish(nil)
+	other, _ := jogger.StartSpan(context.Background(), "db.query")
+	other.Finish(nil)
+
+	failed, _ := jogger.StartSpan(context.Background(), "db.ping")
+	err := errors.New("connection refused")
+	failed.Finish(&err)
+
+	entries := decodeEntries(t, buf)
+	if len(entries) != 2 || entries[0]["span"] != "db.query" || entries[1]["msg"] != "span finished with error" {
+		t.Errorf("expected only the failed db.ping span logged, got %v", entries)
+	}
+}
+
+func TestSuppressMatchingRemove(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+	remove := jogger.SuppressMatching(jogger.SuppressSpans("db.ping"))
+	remove()
+
+	span, _ := jogger.StartSpan(context.Background(), "db.ping")
+	span.Finish(nil)
+
+	if entries := decodeEntries(t, buf); len(entries) != 1 {
+		t.Errorf("expected the span logged once the suppression is removed, got %v", entries)
+	}
+}
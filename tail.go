@@ -0,0 +1,189 @@
+package jogger
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// WithTailSampling makes requests sampled out by WithSampling keep their
+// entries below Warn, up to limit of them, instead of dropping or writing
+// them, until the request is over. A request that turns out to fail has
+// them written after all, so its whole story is in the logs; one that
+// succeeds has them discarded. The first Error entry of a request writes
+// the kept entries right away, and the later ones as usual. When more than
+// limit entries are logged, the oldest are dropped, counted in
+// Stats().Dropped.Overflow, and the count is reported when the rest are
+// written.
+//
+// Middleware and the joggergrpc interceptors start and finish the buffering
+// for each request, failing it on a 5xx status or a server error. Elsewhere,
+// use StartTailSampling and FinishTailSampling. Sampled requests are not
+// buffered.
+func WithTailSampling(limit int) Option {
+	return func(c *config) error {
+		if limit <= 0 {
+			return errors.New("jogger: tail sampling needs a positive buffer limit")
+		}
+		c.tailSampling = limit
+		return nil
+	}
+}
+
+// StartTailSampling returns ctx with a buffer for the entries of its
+// request, if WithTailSampling is set and the request is not sampled. The
+// request's sampling decision must have been made, see EnsureRequestID.
+// Each StartTailSampling must be followed by a FinishTailSampling.
+func StartTailSampling(ctx context.Context) context.Context {
+	ctx = orBackground(ctx)
+	s := scopeOf(ctx)
+	limit := outputFor(ctx).cfg.tailSampling
+	if limit <= 0 || s.tail != nil || s.zap != nil || IsSampled(ctx) {
+		return ctx
+	}
+	s.tail = &tailBuffer{limit: limit, note: FromContext(ctx)}
+	return withScope(ctx, s)
+}
+
+// FinishTailSampling ends the buffering of StartTailSampling: the kept
+// entries are written if failed is true, and discarded otherwise. Entries
+// logged with ctx afterwards are written as usual.
+func FinishTailSampling(ctx context.Context, failed bool) {
+	tail := scopeOf(orBackground(ctx)).tail
+	if tail == nil {
+		return
+	}
+	if failed {
+		tail.flush()
+	} else {
+		tail.discard()
+	}
+}
+
+// tailEntry is an entry kept by a tailBuffer, checked against the cores it
+// is to be written to.
+type tailEntry struct {
+	ce     *zapcore.CheckedEntry
+	fields []zapcore.Field
+}
+
+// tailBuffer keeps the entries of a request in a ring until it is flushed
+// or discarded.
+type tailBuffer struct {
+	limit int
+	note  *zap.Logger // logs the count of dropped entries
+
+	mu      sync.Mutex
+	done    bool
+	entries []tailEntry
+	next    int
+	dropped int
+}
+
+func (b *tailBuffer) buffering() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.done
+}
+
+// add keeps an entry and reports whether it did; it does not once the
+// buffer is done.
+func (b *tailBuffer) add(ce *zapcore.CheckedEntry, fields []zapcore.Field) bool {
+	e := tailEntry{ce, append([]zapcore.Field(nil), fields...)}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.done {
+		return false
+	}
+	if len(b.entries) < b.limit {
+		b.entries = append(b.entries, e)
+		return true
+	}
+	b.entries[b.next] = e
+	b.next = (b.next + 1) % b.limit
+	b.dropped++
+	countDrop(dropOverflow)
+	return true
+}
+
+// take ends the buffering and returns the kept entries, oldest first, and
+// how many were dropped to make room.
+func (b *tailBuffer) take() ([]tailEntry, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.done {
+		return nil, 0
+	}
+	b.done = true
+	entries := append(b.entries[b.next:len(b.entries):len(b.entries)], b.entries[:b.next]...)
+	b.entries = nil
+	return entries, b.dropped
+}
+
+// flush writes the kept entries.
+func (b *tailBuffer) flush() {
+	entries, dropped := b.take()
+	if dropped > 0 {
+		b.note.Info("jogger: tail sampling dropped older entries", zap.Int("dropped", dropped), zap.Int("limit", b.limit))
+	}
+	for _, e := range entries {
+		e.ce.Write(e.fields...)
+	}
+}
+
+// discard drops the kept entries.
+func (b *tailBuffer) discard() {
+	entries, _ := b.take()
+	for range entries {
+		countDrop(dropSampling)
+	}
+}
+
+// withTail routes the entries of l below Warn through the tail buffer of s.
+func (s *Scope) withTail(l *zap.Logger) *zap.Logger {
+	if s.tail == nil {
+		return l
+	}
+	tail := s.tail
+	return l.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return tailCore{c, tail}
+	}))
+}
+
+// tailCore keeps the entries below Warn in its buffer while it buffers.
+// Error entries write the buffer first, so the entries stay in order.
+type tailCore struct {
+	zapcore.Core
+	buf *tailBuffer
+}
+
+func (c tailCore) With(fields []zapcore.Field) zapcore.Core {
+	return tailCore{c.Core.With(fields), c.buf}
+}
+
+func (c tailCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	switch {
+	case ent.Level >= zapcore.ErrorLevel:
+		c.buf.flush()
+	case ent.Level < zapcore.WarnLevel && c.buf.buffering():
+		if c.Enabled(ent.Level) {
+			return ce.AddCore(ent, c)
+		}
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// Write keeps the entry, checked against the wrapped core now, so it is
+// written later as it would have been now.
+func (c tailCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	inner := c.Core.Check(ent, nil)
+	if inner == nil || c.buf.add(inner, fields) {
+		return nil
+	}
+	inner.Write(fields...)
+	return nil
+}
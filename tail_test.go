@@ -0,0 +1,119 @@
+package jogger_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+)
+
+// tailHandler logs an Info entry, a span and a warning, then fails with a
+// 500 on /fail, logs an error on /error, and logs three more Info entries
+// and fails with a 502 on /many.
+func tailHandler() http.Handler {
+	return jogger.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		jogger.Info(ctx, "loading")
+		span, ctx := jogger.StartSpan(ctx, "work")
+		span.Finish(nil)
+		jogger.Warn(ctx, "retrying")
+		switch r.URL.Path {
+		case "/fail":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/error":
+			jogger.Error(ctx, "failed", zap.Error(errors.New("boom")))
+			jogger.Info(ctx, "cleaning up")
+		case "/many":
+			for i := 0; i < 3; i++ {
+				jogger.Info(ctx, fmt.Sprint("step ", i))
+			}
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+}
+
+func TestTailSamplingKeepsFailedRequest(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithSampling(0), jogger.WithTailSampling(10))
+
+	r := httptest.NewRequest("GET", "/fail", nil)
+	r.Header.Set("X-Request-ID", "req-tail")
+	serve(t, tailHandler(), r)
+
+	entries := decodeEntries(t, buf)
+	want := []interface{}{"retrying", "loading", "span finished successfully", "GET /fail -> 500"}
+	if got := messages(entries); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for _, e := range entries {
+		if e["requestID"] != "req-tail" {
+			t.Errorf("expected the request ID on every entry, got %v", e)
+		}
+	}
+}
+
+func TestTailSamplingDiscardsSuccessfulRequest(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithSampling(0), jogger.WithTailSampling(10))
+	before := jogger.Stats().Dropped.Sampling
+
+	serve(t, tailHandler(), httptest.NewRequest("GET", "/ok", nil))
+
+	if got := messages(decodeEntries(t, buf)); !reflect.DeepEqual(got, []interface{}{"retrying"}) {
+		t.Errorf("expected only the warning, got %v", got)
+	}
+	// The buffered Info entry and span, and the access log.
+	if got := jogger.Stats().Dropped.Sampling - before; got != 3 {
+		t.Errorf("expected 3 dropped entries counted, got %d", got)
+	}
+}
+
+func TestTailSamplingErrorWritesBufferInOrder(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithSampling(0), jogger.WithTailSampling(10))
+
+	serve(t, tailHandler(), httptest.NewRequest("GET", "/error", nil))
+
+	want := []interface{}{"retrying", "loading", "span finished successfully", "failed", "cleaning up"}
+	if got := messages(decodeEntries(t, buf)); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestTailSamplingBufferOverflow(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithSampling(0), jogger.WithTailSampling(2))
+	before := jogger.Stats().Dropped.Overflow
+
+	serve(t, tailHandler(), httptest.NewRequest("GET", "/many", nil))
+
+	entries := decodeEntries(t, buf)
+	want := []interface{}{"retrying", "jogger: tail sampling dropped older entries", "step 1", "step 2", "GET /many -> 502"}
+	if got := messages(entries); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if entries[1]["dropped"] != float64(3) {
+		t.Errorf("expected 3 dropped entries, got %v", entries[1])
+	}
+	if got := jogger.Stats().Dropped.Overflow - before; got != 3 {
+		t.Errorf("expected 3 overflowed entries counted, got %d", got)
+	}
+}
+
+func TestTailSamplingLeavesSampledRequests(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON), jogger.WithTailSampling(10))
+
+	serve(t, tailHandler(), httptest.NewRequest("GET", "/ok", nil))
+
+	want := []interface{}{"loading", "span finished successfully", "retrying", "GET /ok -> 200"}
+	if got := messages(decodeEntries(t, buf)); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestWithTailSamplingRejectsInvalidLimit(t *testing.T) {
+	if err := jogger.Configure(jogger.WithTailSampling(0)); err == nil {
+		t.Error("expected an error for a zero limit")
+	}
+}
@@ -0,0 +1,50 @@
+package jogger
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// A TapFunc observes an entry written by any instance, with all of its
+// fields, including those of With. It runs synchronously in the logging
+// call and must not keep or modify fields.
+type TapFunc func(ent zapcore.Entry, fields []zapcore.Field)
+
+type tap struct {
+	fn TapFunc
+}
+
+var (
+	tapMu sync.Mutex
+	taps  atomic.Value // []*tap, replaced on every change
+)
+
+func init() {
+	taps.Store([]*tap(nil))
+}
+
+// Tap calls fn for every entry written from now on, after SuppressMatching
+// and the levels had their say, for tools such as joggertest. The returned
+// function removes the tap.
+func Tap(fn TapFunc) (remove func()) {
+	tp := &tap{fn: fn}
+	tapMu.Lock()
+	defer tapMu.Unlock()
+	cur := taps.Load().([]*tap)
+	taps.Store(append(cur[:len(cur):len(cur)], tp))
+
+	return func() {
+		tapMu.Lock()
+		defer tapMu.Unlock()
+		cur := taps.Load().([]*tap)
+		next := make([]*tap, 0, len(cur))
+		for _, c := range cur {
+			if c != tp {
+				next = append(next, c)
+			}
+		}
+		taps.Store(next)
+	}
+}
@@ -0,0 +1,35 @@
+package jogger_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestTapSeesWrittenEntries(t *testing.T) {
+	configureBuffer(t)
+	remove := jogger.SuppressMatching(jogger.SuppressSpans("probe"))
+	defer remove()
+
+	var got []string
+	untap := jogger.Tap(func(ent zapcore.Entry, fields []zapcore.Field) {
+		for _, f := range fields {
+			if f.Key == "requestID" {
+				got = append(got, ent.Message+" "+f.String)
+			}
+		}
+	})
+	ctx := jogger.WithRequestID(context.Background(), "req-tap")
+	jogger.Debug(ctx, "below the level")
+	jogger.Info(ctx, "tapped")
+	span, _ := jogger.StartSpan(ctx, "probe")
+	span.Finish(nil)
+	untap()
+	jogger.Info(ctx, "after removal")
+
+	if len(got) != 1 || got[0] != "tapped req-tap" {
+		t.Errorf("expected only the written entry tapped, with its With fields, got %q", got)
+	}
+}
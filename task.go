@@ -0,0 +1,43 @@
+package jogger
+
+import (
+	"context"
+	"time"
+)
+
+// Task carries a request's correlation from the code that enqueues work to
+// the worker that processes it. Create it with NewTask where the work is
+// produced and call Run in the worker.
+type Task struct {
+	Name     string
+	Snapshot Fields
+	Created  time.Time
+}
+
+// NewTask captures ctx's correlation values for a unit of work called name.
+func NewTask(ctx context.Context, name string) Task {
+	return Task{
+		Name:     name,
+		Snapshot: Snapshot(ctx),
+		Created:  time.Now(),
+	}
+}
+
+// Run calls fn with a context rebuilt from the task's snapshot, inside a
+// span named after the task. The span records queueLatency, the time between
+// NewTask and Run, and finishes with fn's error, which Run returns.
+func (t Task) Run(fn func(ctx context.Context) error) error {
+	return t.RunContext(context.Background(), fn)
+}
+
+// RunContext is like Run but derives the task context from parent, so the
+// worker's own deadline and cancellation apply to fn.
+func (t Task) RunContext(parent context.Context, fn func(ctx context.Context) error) (err error) {
+	latency := time.Since(t.Created)
+
+	span, ctx := StartSpan(WithSnapshot(parent, t.Snapshot), t.Name)
+	span.SetTag("queueLatency", latency)
+	defer span.Finish(&err)
+
+	return fn(ctx)
+}
@@ -0,0 +1,74 @@
+package jogger_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+)
+
+func TestTaskRun(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	ctx := jogger.WithTenantID(jogger.WithRequestID(context.Background(), "req-task"), "acme")
+	task := jogger.NewTask(ctx, "resize-image")
+
+	queue := make(chan jogger.Task, 1)
+	queue <- task
+	time.Sleep(20 * time.Millisecond)
+
+	var seen string
+	err := (<-queue).Run(func(ctx context.Context) error {
+		seen = jogger.RequestID(ctx)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seen != "req-task" {
+		t.Errorf("expected worker context to carry the request ID, got %q", seen)
+	}
+
+	e := decodeEntries(t, buf)[0]
+	if e["span"] != "resize-image" || e["requestID"] != "req-task" || e["tenantID"] != "acme" {
+		t.Errorf("unexpected span entry %v", e)
+	}
+	if latency, _ := e["queueLatency"].(float64); latency < 0.02 {
+		t.Errorf("expected queueLatency of at least 20ms, got %v", e["queueLatency"])
+	}
+}
+
+func TestTaskRunReturnsError(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	want := errors.New("worker failed")
+	task := jogger.NewTask(jogger.WithRequestID(context.Background(), "req-task-err"), "send-email")
+	if err := task.Run(func(context.Context) error { return want }); err != want {
+		t.Fatalf("expected task error to be returned, got %v", err)
+	}
+
+	e := decodeEntries(t, buf)[0]
+	if e["level"] != "error" || e["error"] != "worker failed" {
+		t.Errorf("expected errored span finish, got %v", e)
+	}
+}
+
+func TestTaskRunContextUsesWorkerContext(t *testing.T) {
+	configureBuffer(t)
+
+	worker, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	task := jogger.NewTask(jogger.WithRequestID(context.Background(), "req-task-ctx"), "canceled")
+	err := task.RunContext(worker, func(ctx context.Context) error {
+		if jogger.RequestID(ctx) != "req-task-ctx" {
+			t.Error("expected request ID in worker context")
+		}
+		return ctx.Err()
+	})
+	if err != context.Canceled {
+		t.Errorf("expected worker cancellation to reach fn, got %v", err)
+	}
+}
@@ -0,0 +1,17 @@
+// Command buildinfo logs one entry with build info fields. It is built by
+// TestWithBuildInfoBinary.
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/cheesycoffee/jogger"
+)
+
+func main() {
+	if err := jogger.Configure(jogger.WithFormat(jogger.FormatJSON), jogger.WithBuildInfo()); err != nil {
+		os.Exit(1)
+	}
+	jogger.Info(context.Background(), "build info")
+}
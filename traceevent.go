@@ -0,0 +1,148 @@
+package jogger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// maxTraceEvents bounds the spans kept by EnableTraceEventCapture.
+const maxTraceEvents = 100000
+
+// traceEvent is a complete ("X") event of the Chrome Trace Event Format, or
+// a metadata ("M") event naming a process.
+type traceEvent struct {
+	Name string            `json:"name"`
+	Cat  string            `json:"cat,omitempty"`
+	Ph   string            `json:"ph"`
+	Ts   float64           `json:"ts"`
+	Dur  float64           `json:"dur,omitempty"`
+	Pid  int               `json:"pid"`
+	Tid  uint64            `json:"tid"`
+	Args map[string]string `json:"args,omitempty"`
+}
+
+// traceRecorder keeps finished spans as trace events, one process per
+// request ID and one thread per goroutine.
+type traceRecorder struct {
+	path string
+
+	mu      sync.Mutex
+	events  []traceEvent
+	pids    map[string]int
+	dropped int
+}
+
+var traceCapture atomic.Value // *traceRecorder
+
+// EnableTraceEventCapture records every finished span as a Trace Event
+// Format event, to open in chrome://tracing or Perfetto. Each request ID
+// becomes a process and each goroutine a thread. The events are written to
+// path on Shutdown, and to any writer with DumpTraceEvents; an empty path
+// keeps them for DumpTraceEvents only. At most 100000 spans are kept, later
+// ones are counted as dropped. Enabling again discards the events so far.
+func EnableTraceEventCapture(path string) {
+	r := &traceRecorder{path: path, pids: map[string]int{}}
+	traceCapture.Store(r)
+	setSpanHook("traceEvents", r.record)
+}
+
+// DisableTraceEventCapture stops recording and discards the events.
+func DisableTraceEventCapture() {
+	setSpanHook("traceEvents", nil)
+	traceCapture.Store((*traceRecorder)(nil))
+}
+
+// DumpTraceEvents writes the events recorded so far as a Trace Event Format
+// JSON object.
+func DumpTraceEvents(w io.Writer) error {
+	r, _ := traceCapture.Load().(*traceRecorder)
+	if r == nil {
+		return errors.New("jogger: trace event capture is not enabled")
+	}
+	return r.dump(w)
+}
+
+// writeTraceEvents writes the capture file, on Shutdown.
+func writeTraceEvents() error {
+	r, _ := traceCapture.Load().(*traceRecorder)
+	if r == nil || r.path == "" {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := r.dump(&buf); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, buf.Bytes(), 0644)
+}
+
+func (r *traceRecorder) record(s finishedSpan) {
+	ev := traceEvent{
+		Name: s.name,
+		Cat:  "span",
+		Ph:   "X",
+		Ts:   float64(s.start.UnixNano()) / 1e3,
+		Dur:  float64(s.duration.Nanoseconds()) / 1e3,
+		Tid:  goroutineID(),
+		Args: map[string]string{"spanID": s.id},
+	}
+	if s.requestID != "" {
+		ev.Args["requestID"] = s.requestID
+	}
+	if s.err != nil {
+		ev.Args["error"] = s.err.Error()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.events) >= maxTraceEvents {
+		r.dropped++
+		return
+	}
+	pid, ok := r.pids[s.requestID]
+	if !ok {
+		pid = len(r.pids) + 1
+		r.pids[s.requestID] = pid
+		name := s.requestID
+		if name == "" {
+			name = "no request"
+		}
+		r.events = append(r.events, traceEvent{Name: "process_name", Ph: "M", Pid: pid, Args: map[string]string{"name": name}})
+	}
+	ev.Pid = pid
+	r.events = append(r.events, ev)
+}
+
+func (r *traceRecorder) dump(w io.Writer) error {
+	r.mu.Lock()
+	out := struct {
+		TraceEvents     []traceEvent      `json:"traceEvents"`
+		DisplayTimeUnit string            `json:"displayTimeUnit"`
+		OtherData       map[string]string `json:"otherData"`
+	}{
+		TraceEvents:     append([]traceEvent{}, r.events...),
+		DisplayTimeUnit: "ms",
+		OtherData:       map[string]string{"droppedEvents": strconv.Itoa(r.dropped)},
+	}
+	r.mu.Unlock()
+	return json.NewEncoder(w).Encode(out)
+}
+
+// goroutineID parses the ID of the calling goroutine from its stack header,
+// "goroutine 42 [running]:".
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
@@ -0,0 +1,95 @@
+package jogger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cheesycoffee/jogger"
+)
+
+type traceFile struct {
+	TraceEvents []struct {
+		Name string            `json:"name"`
+		Ph   string            `json:"ph"`
+		Ts   float64           `json:"ts"`
+		Dur  float64           `json:"dur"`
+		Pid  int               `json:"pid"`
+		Tid  uint64            `json:"tid"`
+		Args map[string]string `json:"args"`
+	} `json:"traceEvents"`
+}
+
+func TestTraceEventCapture(t *testing.T) {
+	configureBuffer(t)
+	path := filepath.Join(t.TempDir(), "trace.json")
+	jogger.EnableTraceEventCapture(path)
+	defer jogger.DisableTraceEventCapture()
+
+	for _, rid := range []string{"req-a", "req-b"} {
+		ctx := jogger.WithRequestID(context.Background(), rid)
+		outer, ctx := jogger.StartSpan(ctx, "handler")
+		inner, _ := jogger.StartSpan(ctx, "db.query")
+		time.Sleep(time.Millisecond)
+		err := errors.New("no rows")
+		inner.Finish(&err)
+		outer.Finish(nil)
+	}
+	if err := jogger.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tf traceFile
+	if err := json.Unmarshal(b, &tf); err != nil {
+		t.Fatal(err)
+	}
+	pids := map[string]int{}
+	spans := 0
+	for _, ev := range tf.TraceEvents {
+		switch ev.Ph {
+		case "M":
+			pids[ev.Args["name"]] = ev.Pid
+		case "X":
+			spans++
+			if ev.Dur <= 0 || ev.Ts <= 0 || ev.Tid == 0 || ev.Args["spanID"] == "" {
+				t.Errorf("incomplete event %+v", ev)
+			}
+			if ev.Pid != pids[ev.Args["requestID"]] {
+				t.Errorf("expected the event in its request's process, got %+v", ev)
+			}
+			if ev.Name == "db.query" && ev.Args["error"] != "no rows" {
+				t.Errorf("expected the span error in the args, got %+v", ev)
+			}
+		}
+	}
+	if spans != 4 || len(pids) != 2 || pids["req-a"] == pids["req-b"] {
+		t.Errorf("expected 4 spans in 2 processes, got %d spans and %v", spans, pids)
+	}
+}
+
+func TestDumpTraceEventsRequiresCapture(t *testing.T) {
+	jogger.DisableTraceEventCapture()
+	if err := jogger.DumpTraceEvents(&bytes.Buffer{}); err == nil {
+		t.Error("expected an error without capture enabled")
+	}
+
+	jogger.EnableTraceEventCapture("")
+	defer jogger.DisableTraceEventCapture()
+	var buf bytes.Buffer
+	if err := jogger.DumpTraceEvents(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var tf traceFile
+	if err := json.Unmarshal(buf.Bytes(), &tf); err != nil || len(tf.TraceEvents) != 0 {
+		t.Errorf("expected an empty trace, got %q, %v", buf.String(), err)
+	}
+}
@@ -0,0 +1,142 @@
+package jogger
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+type transportConfig struct {
+	connectionTiming bool
+}
+
+// A TransportOption configures Transport.
+type TransportOption func(*transportConfig)
+
+// WithConnectionTiming tags each call's span with where the time went:
+// dns_ms, connect_ms, tls_ms and ttfb_ms, the time from the request being
+// written to the first response byte, plus conn_reused. Phases that did not
+// happen, such as DNS on a reused connection, are left out.
+func WithConnectionTiming() TransportOption {
+	return func(c *transportConfig) {
+		c.connectionTiming = true
+	}
+}
+
+// Transport returns an http.RoundTripper that runs each request through
+// base, nil meaning http.DefaultTransport, inside an "http.client" span
+// tagged with the method, host, path and status code. The request ID and
+// propagated correlation values of the request's context are injected into
+// the outgoing headers.
+func Transport(base http.RoundTripper, opts ...TransportOption) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t := &transport{base: base}
+	for _, opt := range opts {
+		opt(&t.cfg)
+	}
+	return t
+}
+
+type transport struct {
+	base http.RoundTripper
+	cfg  transportConfig
+}
+
+func (t *transport) RoundTrip(r *http.Request) (resp *http.Response, err error) {
+	span, ctx := StartSpan(r.Context(), "http.client")
+	defer span.Finish(&err)
+	span.SetTag("method", r.Method)
+	span.SetTag("host", r.URL.Host)
+	span.SetTag("path", r.URL.Path)
+
+	var timing *connTiming
+	if t.cfg.connectionTiming {
+		timing = &connTiming{}
+		ctx = httptrace.WithClientTrace(ctx, timing.trace())
+	}
+
+	out := r.WithContext(ctx)
+	out.Header = cloneHeader(r.Header)
+	Inject(ctx, HeaderCarrier(out.Header))
+
+	resp, err = t.base.RoundTrip(out)
+	if timing != nil {
+		timing.tag(&span)
+	}
+	if err == nil {
+		span.SetTag("status", resp.StatusCode)
+	}
+	return resp, err
+}
+
+// cloneHeader copies h so injecting headers leaves the caller's request
+// untouched, as RoundTripper implementations must.
+func cloneHeader(h http.Header) http.Header {
+	out := make(http.Header, len(h)+1)
+	for k, v := range h {
+		out[k] = append([]string(nil), v...)
+	}
+	return out
+}
+
+// connTiming collects phase timestamps from httptrace callbacks, which may
+// run on dialing goroutines. Durations are differences of time.Now values
+// and therefore use the monotonic clock.
+type connTiming struct {
+	mu                      sync.Mutex
+	dnsStart, dnsDone       time.Time
+	connectStart, connDone  time.Time
+	tlsStart, tlsDone       time.Time
+	wroteRequest, firstByte time.Time
+	gotConn, reused         bool
+}
+
+func (c *connTiming) trace() *httptrace.ClientTrace {
+	record := func(field *time.Time, first bool) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if !first || field.IsZero() {
+			*field = time.Now()
+		}
+	}
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { record(&c.dnsStart, true) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { record(&c.dnsDone, false) },
+		// Dialing several addresses calls ConnectStart more than once;
+		// the phase spans from the first attempt to the last completion.
+		ConnectStart:      func(string, string) { record(&c.connectStart, true) },
+		ConnectDone:       func(string, string, error) { record(&c.connDone, false) },
+		TLSHandshakeStart: func() { record(&c.tlsStart, true) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { record(&c.tlsDone, false) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.gotConn, c.reused = true, info.Reused
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { record(&c.wroteRequest, false) },
+		GotFirstResponseByte: func() { record(&c.firstByte, false) },
+	}
+}
+
+func (c *connTiming) tag(span *Span) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tagPhase(span, "dns_ms", c.dnsStart, c.dnsDone)
+	tagPhase(span, "connect_ms", c.connectStart, c.connDone)
+	tagPhase(span, "tls_ms", c.tlsStart, c.tlsDone)
+	tagPhase(span, "ttfb_ms", c.wroteRequest, c.firstByte)
+	if c.gotConn {
+		span.SetTag("conn_reused", c.reused)
+	}
+}
+
+func tagPhase(span *Span, key string, start, end time.Time) {
+	if start.IsZero() || end.IsZero() {
+		return
+	}
+	span.SetTag(key, float64(end.Sub(start))/float64(time.Millisecond))
+}
@@ -0,0 +1,124 @@
+package jogger_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+)
+
+func get(t *testing.T, ctx context.Context, client *http.Client, url string) {
+	t.Helper()
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+}
+
+func TestTransportSpanAndPropagation(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	var gotRequestID string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRequestID = r.Header.Get("X-Request-ID")
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: jogger.Transport(&http.Transport{})}
+	get(t, jogger.WithRequestID(context.Background(), "req-out"), client, srv.URL+"/brew")
+
+	if gotRequestID != "req-out" {
+		t.Errorf("expected request ID injected, got %q", gotRequestID)
+	}
+	e := decodeEntries(t, buf)[0]
+	if e["span"] != "http.client" || e["requestID"] != "req-out" {
+		t.Errorf("unexpected span entry %v", e)
+	}
+	if e["method"] != "GET" || e["path"] != "/brew" || e["status"] != float64(http.StatusTeapot) {
+		t.Errorf("missing call tags: %v", e)
+	}
+	if _, ok := e["connect_ms"]; ok {
+		t.Error("connection timing should be off by default")
+	}
+}
+
+func TestTransportConnectionTiming(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: jogger.Transport(&http.Transport{}, jogger.WithConnectionTiming())}
+	get(t, context.Background(), client, srv.URL)
+	get(t, context.Background(), client, srv.URL)
+
+	entries := decodeEntries(t, buf)
+	first, second := entries[0], entries[1]
+
+	if first["conn_reused"] != false {
+		t.Errorf("expected a new connection first, got %v", first["conn_reused"])
+	}
+	if _, ok := first["connect_ms"].(float64); !ok {
+		t.Errorf("expected connect_ms on a new connection: %v", first)
+	}
+	if _, ok := first["ttfb_ms"].(float64); !ok {
+		t.Errorf("expected ttfb_ms: %v", first)
+	}
+	if _, ok := first["dns_ms"]; ok {
+		t.Errorf("no DNS lookup happens for an IP address: %v", first)
+	}
+
+	if second["conn_reused"] != true {
+		t.Errorf("expected the connection to be reused, got %v", second["conn_reused"])
+	}
+	if _, ok := second["connect_ms"]; ok {
+		t.Errorf("a reused connection has no connect phase: %v", second)
+	}
+	if _, ok := second["ttfb_ms"].(float64); !ok {
+		t.Errorf("expected ttfb_ms on a reused connection: %v", second)
+	}
+}
+
+func TestTransportTLSTiming(t *testing.T) {
+	buf := configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: jogger.Transport(srv.Client().Transport, jogger.WithConnectionTiming())}
+	get(t, context.Background(), client, srv.URL)
+
+	if e := decodeEntries(t, buf)[0]; e["tls_ms"] == nil {
+		t.Errorf("expected tls_ms for an HTTPS call: %v", e)
+	}
+}
+
+func TestTransportLeavesRequestHeadersAlone(t *testing.T) {
+	configureBuffer(t, jogger.WithFormat(jogger.FormatJSON))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest("GET", srv.URL, nil)
+	req = req.WithContext(jogger.WithRequestID(context.Background(), "req-out"))
+	resp, err := (&http.Client{Transport: jogger.Transport(&http.Transport{})}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if req.Header.Get("X-Request-ID") != "" {
+		t.Error("Transport must not modify the caller's request headers")
+	}
+}
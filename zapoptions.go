@@ -0,0 +1,34 @@
+package jogger
+
+import (
+	"errors"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// WithZapOptions adds zap options, such as zap.Hooks or zap.AddCaller, to
+// the loggers built from the configuration. The audit logger is left
+// alone. They are kept when SetLevel or a SIGHUP reload change the level,
+// and, like every option, must be passed again to a later Configure.
+func WithZapOptions(opts ...zap.Option) Option {
+	return func(c *config) error {
+		c.zapOptions = append(c.zapOptions, opts...)
+		return nil
+	}
+}
+
+// WithWrapCore wraps the core of the loggers built from the configuration,
+// the way zap.WrapCore does. The wrapper sits outside jogger's own cores:
+// it sees every entry before deduplication and the stats, with its fields
+// as logged, before jogger adds error_fingerprint, escapes or truncates
+// them. Several wrappers apply in order, the last one outermost.
+func WithWrapCore(wrap func(zapcore.Core) zapcore.Core) Option {
+	return func(c *config) error {
+		if wrap == nil {
+			return errors.New("jogger: nil core wrapper")
+		}
+		c.coreWrappers = append(c.coreWrappers, wrap)
+		return nil
+	}
+}
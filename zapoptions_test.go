@@ -0,0 +1,87 @@
+package jogger_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cheesycoffee/jogger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestWithZapOptions(t *testing.T) {
+	var hooked int32
+	configureBuffer(t, jogger.WithZapOptions(zap.Hooks(func(zapcore.Entry) error {
+		atomic.AddInt32(&hooked, 1)
+		return nil
+	})))
+
+	jogger.Info(context.Background(), "hooked")
+	jogger.SetLevel(zapcore.DebugLevel)
+	defer jogger.SetLevel(zapcore.InfoLevel)
+	jogger.Debug(context.Background(), "hooked after SetLevel")
+
+	if n := atomic.LoadInt32(&hooked); n != 2 {
+		t.Errorf("expected the hook to run for both entries, got %d", n)
+	}
+}
+
+// fieldSpy records the fields of the entries written through it.
+type fieldSpy struct {
+	zapcore.Core
+	seen *[][]zapcore.Field
+}
+
+func (s fieldSpy) With(fields []zapcore.Field) zapcore.Core {
+	return fieldSpy{s.Core.With(fields), s.seen}
+}
+
+func (s fieldSpy) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if s.Enabled(ent.Level) {
+		return ce.AddCore(ent, s)
+	}
+	return ce
+}
+
+func (s fieldSpy) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	*s.seen = append(*s.seen, fields)
+	return s.Core.Write(ent, fields)
+}
+
+func TestWithWrapCoreSitsOutside(t *testing.T) {
+	var seen [][]zapcore.Field
+	buf := configureBuffer(t,
+		jogger.WithFormat(jogger.FormatJSON),
+		jogger.WithWrapCore(func(c zapcore.Core) zapcore.Core { return fieldSpy{c, &seen} }),
+	)
+
+	jogger.Error(context.Background(), "failed", zap.Error(errors.New("boom")))
+
+	if len(seen) != 1 {
+		t.Fatalf("expected the wrapper to see one entry, got %d", len(seen))
+	}
+	for _, f := range seen[0] {
+		if f.Key == "error_fingerprint" {
+			t.Error("expected the wrapper to see the fields before jogger adds error_fingerprint")
+		}
+	}
+	if e := decodeEntries(t, buf)[0]; e["error_fingerprint"] == nil {
+		t.Errorf("expected the entry to still get its fingerprint, got %v", e)
+	}
+}
+
+func TestWithWrapCoreDropping(t *testing.T) {
+	before := jogger.Stats().Entries["info"]
+	buf := configureBuffer(t, jogger.WithWrapCore(func(c zapcore.Core) zapcore.Core {
+		return zapcore.NewNopCore()
+	}))
+	jogger.Info(context.Background(), "dropped")
+	if buf.Len() != 0 || jogger.Stats().Entries["info"] != before {
+		t.Errorf("expected an outer wrapper to keep entries from jogger's cores, got %q", buf.String())
+	}
+	if err := jogger.Configure(jogger.WithWrapCore(nil)); err == nil {
+		t.Error("expected an error for a nil wrapper")
+	}
+}